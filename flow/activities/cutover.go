@@ -0,0 +1,189 @@
+package activities
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go.temporal.io/sdk/activity"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/PeerDB-io/peer-flow/connectors"
+	"github.com/PeerDB-io/peer-flow/connectors/utils/monitoring"
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+	"github.com/PeerDB-io/peer-flow/model"
+	"github.com/PeerDB-io/peer-flow/peerdbenv"
+	"github.com/PeerDB-io/peer-flow/shared"
+)
+
+// FenceForCutover makes the mirrored source tables read-only, returning the LSN the destination needs to reach
+func (a *FlowableActivity) FenceForCutover(ctx context.Context, config *protos.FlowConnectionConfigs) (int64, error) {
+	ctx = context.WithValue(ctx, shared.FlowNameKey, config.FlowJobName)
+	srcConn, err := connectors.GetAs[connectors.CutoverConnector](ctx, config.Source)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get source connector: %w", err)
+	}
+	defer connectors.CloseConnector(ctx, srcConn)
+
+	tableIdentifiers := make([]string, 0, len(config.TableMappings))
+	for _, tm := range config.TableMappings {
+		tableIdentifiers = append(tableIdentifiers, tm.SourceTableIdentifier)
+	}
+	fenceLSN, err := srcConn.FenceForCutover(ctx, config.FlowJobName, tableIdentifiers)
+	if err != nil {
+		a.Alerter.LogFlowError(ctx, config.FlowJobName, err)
+		return 0, err
+	}
+	return fenceLSN, nil
+}
+
+func (a *FlowableActivity) UnfenceForCutover(ctx context.Context, config *protos.FlowConnectionConfigs) error {
+	ctx = context.WithValue(ctx, shared.FlowNameKey, config.FlowJobName)
+	srcConn, err := connectors.GetAs[connectors.CutoverConnector](ctx, config.Source)
+	if err != nil {
+		return fmt.Errorf("failed to get source connector: %w", err)
+	}
+	defer connectors.CloseConnector(ctx, srcConn)
+
+	return srcConn.UnfenceForCutover(ctx, config.FlowJobName)
+}
+
+// GetCutoverProgress returns the LSN synced to the destination along with its last synced and normalized batches
+func (a *FlowableActivity) GetCutoverProgress(
+	ctx context.Context,
+	config *protos.FlowConnectionConfigs,
+) (*model.CutoverProgress, error) {
+	ctx = context.WithValue(ctx, shared.FlowNameKey, config.FlowJobName)
+	dstConn, err := connectors.GetCDCSyncConnector(ctx, config.Destination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get destination connector: %w", err)
+	}
+	defer connectors.CloseConnector(ctx, dstConn)
+	normalizeConn, ok := dstConn.(connectors.CDCNormalizeConnector)
+	if !ok {
+		return nil, fmt.Errorf("destination %s does not normalize", config.Destination.Name)
+	}
+
+	latestLSNAtTarget, err := monitoring.GetLatestLSNAtTargetForCDCFlow(ctx, a.CatalogPool, config.FlowJobName)
+	if err != nil {
+		return nil, err
+	}
+	syncBatchID, err := dstConn.GetLastSyncBatchID(ctx, config.FlowJobName)
+	if err != nil {
+		return nil, err
+	}
+	normalizeBatchID, err := normalizeConn.GetLastNormalizeBatchID(ctx, config.FlowJobName)
+	if err != nil {
+		return nil, err
+	}
+	return &model.CutoverProgress{
+		LatestLSNAtTarget: latestLSNAtTarget,
+		SyncBatchID:       syncBatchID,
+		NormalizeBatchID:  normalizeBatchID,
+	}, nil
+}
+
+// CountCutoverRows counts rows of each table on both peers, tables in history modes are left out
+// as they keep more rows than the source, rows marked deleted on the destination aren't counted
+func (a *FlowableActivity) CountCutoverRows(
+	ctx context.Context,
+	config *protos.FlowConnectionConfigs,
+) ([]*protos.CutoverTableCount, error) {
+	ctx = context.WithValue(ctx, shared.FlowNameKey, config.FlowJobName)
+	srcConn, err := connectors.GetAs[connectors.CountRowsConnector](ctx, config.Source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source connector: %w", err)
+	}
+	defer connectors.CloseConnector(ctx, srcConn)
+
+	dstConn, err := connectors.GetAs[connectors.CountRowsConnector](ctx, config.Destination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get destination connector: %w", err)
+	}
+	defer connectors.CloseConnector(ctx, dstConn)
+
+	var softDeleteColName string
+	if config.SoftDelete {
+		softDeleteColName = config.SoftDeleteColName
+	}
+	counts := make([]*protos.CutoverTableCount, 0, len(config.TableMappings))
+	for _, tm := range config.TableMappings {
+		if shared.NormalizeModeForTable(config.NormalizeMode, config.TableMappings,
+			tm.DestinationTableIdentifier) != protos.NormalizeMode_NORMALIZE_MODE_MERGE {
+			continue
+		}
+		activity.RecordHeartbeat(ctx, "counting rows of "+tm.SourceTableIdentifier)
+		srcCount, err := srcConn.CountRows(ctx, tm.SourceTableIdentifier, "")
+		if err != nil {
+			return nil, err
+		}
+		dstCount, err := dstConn.CountRows(ctx, tm.DestinationTableIdentifier, softDeleteColName)
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, &protos.CutoverTableCount{
+			SourceTableIdentifier:      tm.SourceTableIdentifier,
+			DestinationTableIdentifier: tm.DestinationTableIdentifier,
+			SourceCount:                srcCount,
+			DestinationCount:           dstCount,
+		})
+	}
+	return counts, nil
+}
+
+// GetReverseMirrorStatus returns the status of a reverse mirror, which is running once its slot exists
+func (a *FlowableActivity) GetReverseMirrorStatus(ctx context.Context, workflowID string) (protos.FlowStatus, error) {
+	return model.GetFlowStatus(ctx, a.TemporalClient, workflowID)
+}
+
+// CreateReverseMirrorEntry adds a mirror created by a cutover to the catalog,
+// an entry already made for the same workflow is left as is so the activity can be retried
+func (a *FlowableActivity) CreateReverseMirrorEntry(
+	ctx context.Context,
+	config *protos.FlowConnectionConfigs,
+	workflowID string,
+) error {
+	var existingWorkflowID string
+	err := a.CatalogPool.QueryRow(ctx,
+		"SELECT coalesce(max(workflow_id), '') FROM flows WHERE name = $1", config.FlowJobName).Scan(&existingWorkflowID)
+	if err != nil {
+		return fmt.Errorf("failed to check for mirror %s: %w", config.FlowJobName, err)
+	}
+	if existingWorkflowID == workflowID {
+		return nil
+	} else if existingWorkflowID != "" {
+		return fmt.Errorf("mirror %s already exists", config.FlowJobName)
+	}
+
+	configBytes, err := proto.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config of mirror %s: %w", config.FlowJobName, err)
+	}
+	configBytes, err = peerdbenv.EncryptCatalogData(configBytes)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt config of mirror %s: %w", config.FlowJobName, err)
+	}
+
+	tx, err := a.CatalogPool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer shared.RollbackTx(tx, slog.Default())
+
+	for _, tm := range config.TableMappings {
+		if _, err := tx.Exec(ctx, `INSERT INTO flows (workflow_id, name, source_peer, destination_peer, description,
+			source_table_identifier, destination_table_identifier, config_proto)
+			SELECT $1, $2, s.id, d.id, $5, $6, $7, $8 FROM peers s, peers d WHERE s.name = $3 AND d.name = $4`,
+			workflowID, config.FlowJobName, config.Source.Name, config.Destination.Name,
+			"Reverse mirror created by cutover", tm.SourceTableIdentifier, tm.DestinationTableIdentifier, configBytes,
+		); err != nil {
+			return fmt.Errorf("failed to add mirror %s to catalog: %w", config.FlowJobName, err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit mirror %s to catalog: %w", config.FlowJobName, err)
+	}
+
+	activity.GetLogger(ctx).Info("added reverse mirror to catalog", slog.String("reverseMirror", config.FlowJobName))
+	return nil
+}
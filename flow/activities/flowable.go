@@ -13,6 +13,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.opentelemetry.io/otel/metric"
 	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/log"
 	"go.temporal.io/sdk/temporal"
 	"golang.org/x/sync/errgroup"
@@ -28,7 +29,9 @@ import (
 	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/model"
 	"github.com/PeerDB-io/peer-flow/otel_metrics"
+	"github.com/PeerDB-io/peer-flow/otel_tracing"
 	"github.com/PeerDB-io/peer-flow/peerdbenv"
+	"github.com/PeerDB-io/peer-flow/prom_metrics"
 	"github.com/PeerDB-io/peer-flow/shared"
 )
 
@@ -43,11 +46,12 @@ type CdcCacheEntry struct {
 }
 
 type FlowableActivity struct {
-	CatalogPool *pgxpool.Pool
-	Alerter     *alerting.Alerter
-	CdcCache    map[string]CdcCacheEntry
-	OtelManager *otel_metrics.OtelManager
-	CdcCacheRw  sync.RWMutex
+	CatalogPool    *pgxpool.Pool
+	Alerter        *alerting.Alerter
+	CdcCache       map[string]CdcCacheEntry
+	OtelManager    *otel_metrics.OtelManager
+	TemporalClient client.Client
+	CdcCacheRw     sync.RWMutex
 }
 
 func (a *FlowableActivity) CheckConnection(
@@ -178,14 +182,18 @@ func (a *FlowableActivity) CreateNormalizedTable(
 
 	tableExistsMapping := make(map[string]bool)
 	for tableIdentifier, tableSchema := range config.TableNameSchemaMapping {
+		normalizeMode := shared.NormalizeModeForTable(config.NormalizeMode, config.TableMappings, tableIdentifier)
+		if normalizeMode == protos.NormalizeMode_NORMALIZE_MODE_SCD2 && len(tableSchema.PrimaryKeyColumns) == 0 {
+			return nil, fmt.Errorf("table %s needs a primary key to keep SCD2 history", tableIdentifier)
+		}
+
 		var existing bool
 		existing, err = conn.SetupNormalizedTable(
 			ctx,
 			tx,
+			config,
 			tableIdentifier,
 			tableSchema,
-			config.SoftDeleteColName,
-			config.SyncedAtColName,
 		)
 		if err != nil {
 			a.Alerter.LogFlowError(ctx, config.FlowName, err)
@@ -299,6 +307,9 @@ func (a *FlowableActivity) StartNormalize(
 
 	dstConn, err := connectors.GetCDCNormalizeConnector(ctx, conn.Destination)
 	if errors.Is(err, errors.ErrUnsupported) {
+		if input.AdditionalDestination {
+			return nil, nil
+		}
 		err = monitoring.UpdateEndTimeForCDCBatch(ctx, a.CatalogPool, input.FlowConnectionConfigs.FlowJobName,
 			input.SyncBatchID)
 		return nil, err
@@ -312,21 +323,27 @@ func (a *FlowableActivity) StartNormalize(
 	})
 	defer shutdown()
 
-	res, err := dstConn.NormalizeRecords(ctx, &model.NormalizeRecordsRequest{
+	startTime := time.Now()
+	normalizeCtx, span := otel_tracing.Start(ctx, "NormalizeRecords",
+		otel_tracing.BatchID(input.SyncBatchID), otel_tracing.Peer(conn.Destination.Name))
+	res, err := dstConn.NormalizeRecords(normalizeCtx, &model.NormalizeRecordsRequest{
 		FlowJobName:            input.FlowConnectionConfigs.FlowJobName,
 		SyncBatchID:            input.SyncBatchID,
 		SoftDelete:             input.FlowConnectionConfigs.SoftDelete,
 		SoftDeleteColName:      input.FlowConnectionConfigs.SoftDeleteColName,
 		SyncedAtColName:        input.FlowConnectionConfigs.SyncedAtColName,
 		TableNameSchemaMapping: input.TableNameSchemaMapping,
+		NormalizeModes:         shared.NormalizeModes(conn.NormalizeMode, conn.TableMappings),
+		ReplicationOrigin:      conn.ReplicationOrigin,
 	})
+	otel_tracing.End(span, err)
 	if err != nil {
 		a.Alerter.LogFlowError(ctx, input.FlowConnectionConfigs.FlowJobName, err)
 		return nil, fmt.Errorf("failed to normalized records: %w", err)
 	}
 
 	// normalize flow did not run due to no records, no need to update end time.
-	if res.Done {
+	if res.Done && !input.AdditionalDestination {
 		err = monitoring.UpdateEndTimeForCDCBatch(
 			ctx,
 			a.CatalogPool,
@@ -341,10 +358,35 @@ func (a *FlowableActivity) StartNormalize(
 	// log the number of batches normalized
 	logger.Info(fmt.Sprintf("normalized records from batch %d to batch %d",
 		res.StartBatchID, res.EndBatchID))
+	if !input.AdditionalDestination && res.Done {
+		prom_metrics.NormalizeBatchDuration.WithLabelValues(conn.FlowJobName).Observe(time.Since(startTime).Seconds())
+	}
+	recordRawTableBacklog(ctx, conn.FlowJobName, conn.Destination.Name, dstConn)
 
 	return res, nil
 }
 
+// NormalizeRemainingBatches normalizes every batch synced to the destination that isn't normalized yet
+func (a *FlowableActivity) NormalizeRemainingBatches(
+	ctx context.Context,
+	input *protos.StartNormalizeInput,
+) (*model.NormalizeResponse, error) {
+	conn := input.FlowConnectionConfigs
+	ctx = context.WithValue(ctx, shared.FlowNameKey, conn.FlowJobName)
+	dstConn, err := connectors.GetCDCSyncConnector(ctx, conn.Destination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get destination connector: %w", err)
+	}
+	syncBatchID, err := dstConn.GetLastSyncBatchID(ctx, conn.FlowJobName)
+	connectors.CloseConnector(ctx, dstConn)
+	if err != nil {
+		return nil, err
+	}
+
+	input.SyncBatchID = syncBatchID
+	return a.StartNormalize(ctx, input)
+}
+
 // SetupQRepMetadataTables sets up the metadata tables for QReplication.
 func (a *FlowableActivity) SetupQRepMetadataTables(ctx context.Context, config *protos.QRepConfig) error {
 	conn, err := connectors.GetQRepSyncConnector(ctx, config.DestinationPeer)
@@ -547,13 +589,14 @@ func (a *FlowableActivity) SendWALHeartbeat(ctx context.Context) error {
 	return nil
 }
 
-func (a *FlowableActivity) RecordSlotSizes(ctx context.Context) error {
+// getCDCFlowConfigs returns the config of every CDC mirror in the catalog
+func (a *FlowableActivity) getCDCFlowConfigs(ctx context.Context) ([]*protos.FlowConnectionConfigs, error) {
 	rows, err := a.CatalogPool.Query(ctx, "SELECT DISTINCT ON (name) name, config_proto FROM flows WHERE query_string IS NULL")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	configs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*protos.FlowConnectionConfigs, error) {
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*protos.FlowConnectionConfigs, error) {
 		var flowName string
 		var configProto []byte
 		err := rows.Scan(&flowName, &configProto)
@@ -561,6 +604,10 @@ func (a *FlowableActivity) RecordSlotSizes(ctx context.Context) error {
 			return nil, err
 		}
 
+		configProto, err = peerdbenv.DecryptCatalogData(configProto)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt config of flow %s: %w", flowName, err)
+		}
 		var config protos.FlowConnectionConfigs
 		err = proto.Unmarshal(configProto, &config)
 		if err != nil {
@@ -569,6 +616,10 @@ func (a *FlowableActivity) RecordSlotSizes(ctx context.Context) error {
 
 		return &config, nil
 	})
+}
+
+func (a *FlowableActivity) RecordSlotSizes(ctx context.Context) error {
+	configs, err := a.getCDCFlowConfigs(ctx)
 	if err != nil {
 		return err
 	}
@@ -726,6 +777,10 @@ func (a *FlowableActivity) ReplicateXminPartition(ctx context.Context,
 	ctx = context.WithValue(ctx, shared.FlowNameKey, config.FlowJobName)
 	logger := activity.GetLogger(ctx)
 
+	partitionsInFlight := prom_metrics.QRepPartitionsInFlight.WithLabelValues(config.FlowJobName)
+	partitionsInFlight.Inc()
+	defer partitionsInFlight.Dec()
+
 	startTime := time.Now()
 	srcConn, err := connectors.GetQRepPullConnector(ctx, config.SourcePeer)
 	if err != nil {
@@ -849,3 +904,116 @@ func (a *FlowableActivity) AddTablesToPublication(ctx context.Context, cfg *prot
 	}
 	return err
 }
+
+func (a *FlowableActivity) RemoveTablesFromPublication(ctx context.Context, cfg *protos.FlowConnectionConfigs,
+	removedTableMappings []*protos.TableMapping,
+) error {
+	ctx = context.WithValue(ctx, shared.FlowNameKey, cfg.FlowJobName)
+	srcConn, err := connectors.GetCDCPullConnector(ctx, cfg.Source)
+	if err != nil {
+		return fmt.Errorf("failed to get source connector: %w", err)
+	}
+	defer connectors.CloseConnector(ctx, srcConn)
+
+	err = srcConn.RemoveTablesFromPublication(ctx, &protos.RemoveTablesFromPublicationInput{
+		FlowJobName:     cfg.FlowJobName,
+		PublicationName: cfg.PublicationName,
+		TablesToRemove:  removedTableMappings,
+	})
+	if err != nil {
+		a.Alerter.LogFlowError(ctx, cfg.FlowJobName, err)
+	}
+	return err
+}
+
+// RemoveTables cleans up removed tables on a destination, destinations without tables have nothing to clean up
+func (a *FlowableActivity) RemoveTables(ctx context.Context, config *protos.RemoveTablesInput) error {
+	ctx = context.WithValue(ctx, shared.FlowNameKey, config.FlowJobName)
+	logger := activity.GetLogger(ctx)
+	conn, err := connectors.GetAs[connectors.RemoveTablesConnector](ctx, config.Peer)
+	if errors.Is(err, errors.ErrUnsupported) {
+		logger.Info("destination does not support removing tables, skipping", slog.String("peer", config.Peer.Name))
+		return nil
+	} else if err != nil {
+		a.Alerter.LogFlowError(ctx, config.FlowJobName, err)
+		return fmt.Errorf("failed to get connector: %w", err)
+	}
+	defer connectors.CloseConnector(ctx, conn)
+
+	if err := conn.RemoveTables(ctx, config); err != nil {
+		a.Alerter.LogFlowError(ctx, config.FlowJobName, err)
+		return err
+	}
+	return nil
+}
+
+// SyncSequences advances sequences owned by destination columns to the value of their source sequence plus the margin
+func (a *FlowableActivity) SyncSequences(ctx context.Context, config *protos.FlowConnectionConfigs) error {
+	ctx = context.WithValue(ctx, shared.FlowNameKey, config.FlowJobName)
+	logger := activity.GetLogger(ctx)
+	srcConn, err := connectors.GetAs[connectors.GetSequenceValuesConnector](ctx, config.Source)
+	if errors.Is(err, errors.ErrUnsupported) {
+		logger.Info("source does not support sequences, skipping", slog.String("peer", config.Source.Name))
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get source connector: %w", err)
+	}
+	defer connectors.CloseConnector(ctx, srcConn)
+
+	dstConn, err := connectors.GetAs[connectors.SyncSequencesConnector](ctx, config.Destination)
+	if errors.Is(err, errors.ErrUnsupported) {
+		logger.Info("destination does not support sequences, skipping", slog.String("peer", config.Destination.Name))
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get destination connector: %w", err)
+	}
+	defer connectors.CloseConnector(ctx, dstConn)
+
+	srcTableIdentifiers := make([]string, 0, len(config.TableMappings))
+	dstTableIdentifiers := make(map[string]string, len(config.TableMappings))
+	for _, tm := range config.TableMappings {
+		srcTableIdentifiers = append(srcTableIdentifiers, tm.SourceTableIdentifier)
+		dstTableIdentifiers[tm.SourceTableIdentifier] = tm.DestinationTableIdentifier
+	}
+
+	values, err := srcConn.GetSequenceValues(ctx, srcTableIdentifiers)
+	if err != nil {
+		a.Alerter.LogFlowError(ctx, config.FlowJobName, err)
+		return err
+	}
+	for i := range values {
+		values[i].TableIdentifier = dstTableIdentifiers[values[i].TableIdentifier]
+		values[i].LastValue += int64(config.SequenceMargin)
+	}
+	if err := dstConn.SyncSequences(ctx, values); err != nil {
+		a.Alerter.LogFlowError(ctx, config.FlowJobName, err)
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("synced %d sequences", len(values)))
+	return nil
+}
+
+// SyncAllSequences syncs sequences of every mirror with sync_sequences set
+func (a *FlowableActivity) SyncAllSequences(ctx context.Context) error {
+	configs, err := a.getCDCFlowConfigs(ctx)
+	if err != nil {
+		return err
+	}
+
+	logger := activity.GetLogger(ctx)
+	for _, config := range configs {
+		if !config.SyncSequences {
+			continue
+		}
+		activity.RecordHeartbeat(ctx, "syncing sequences of "+config.FlowJobName)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// a failing mirror is retried next run without holding back the others
+		if err := a.SyncSequences(ctx, config); err != nil {
+			logger.Error("failed to sync sequences", slog.String("flowName", config.FlowJobName), slog.Any("error", err))
+		}
+	}
+	return nil
+}
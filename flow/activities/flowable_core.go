@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"reflect"
+	"slices"
 	"sync/atomic"
 	"time"
 
@@ -22,7 +23,9 @@ import (
 	"github.com/PeerDB-io/peer-flow/connectors/utils/monitoring"
 	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/model"
+	"github.com/PeerDB-io/peer-flow/otel_tracing"
 	"github.com/PeerDB-io/peer-flow/peerdbenv"
+	"github.com/PeerDB-io/peer-flow/prom_metrics"
 	"github.com/PeerDB-io/peer-flow/shared"
 )
 
@@ -52,6 +55,109 @@ func waitForCdcCache[TPull connectors.CDCPullConnectorCore](ctx context.Context,
 	}
 }
 
+// minConsumedOffsetRoutine reports the lowest offset consumed by all destinations of a multi-destination mirror
+// to the pull, so the slot never advances past a record some destination has yet to durably write
+func minConsumedOffsetRoutine(ctx context.Context, consumedOffset *atomic.Int64, dstConsumedOffsets []*atomic.Int64) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				minOffset := dstConsumedOffsets[0].Load()
+				for _, dstConsumedOffset := range dstConsumedOffsets[1:] {
+					minOffset = min(minOffset, dstConsumedOffset.Load())
+				}
+				shared.AtomicInt64Max(consumedOffset, minOffset)
+			}
+		}
+	}()
+	return cancel
+}
+
+// getSplitTxnPositions reads how far each destination got through a transaction split over batches,
+// dropping positions in transactions a destination has since synced to the end
+func getSplitTxnPositions(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	flowName string,
+	peerNames []string,
+	dstLastOffsets []int64,
+) ([]model.SplitTxnPosition, error) {
+	rows, err := pool.Query(ctx,
+		"SELECT peer_name, commit_lsn, records_synced FROM split_transactions WHERE flow_name = $1", flowName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get split transactions: %w", err)
+	}
+	positions := make(map[string]model.SplitTxnPosition)
+	var peerName string
+	var position model.SplitTxnPosition
+	if _, err := pgx.ForEachRow(rows, []any{&peerName, &position.CommitLSN, &position.Records}, func() error {
+		positions[peerName] = position
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to get split transactions: %w", err)
+	}
+
+	dstPositions := make([]model.SplitTxnPosition, len(peerNames))
+	for i, peerName := range peerNames {
+		if position, ok := positions[peerName]; ok && position.CommitLSN > dstLastOffsets[i] {
+			dstPositions[i] = position
+		}
+	}
+	return dstPositions, nil
+}
+
+// pullSplitTxnPosition is the position in the earliest split transaction which every destination reached,
+// destinations further in it skip the difference in the tee
+func pullSplitTxnPosition(dstPositions []model.SplitTxnPosition, dstLastOffsets []int64) model.SplitTxnPosition {
+	var pullPosition model.SplitTxnPosition
+	for _, position := range dstPositions {
+		if position.CommitLSN != 0 && (pullPosition.CommitLSN == 0 || position.CommitLSN < pullPosition.CommitLSN) {
+			pullPosition = position
+		}
+	}
+	for i, position := range dstPositions {
+		if pullPosition.CommitLSN <= dstLastOffsets[i] {
+			// synced the whole transaction
+			continue
+		} else if position.CommitLSN != pullPosition.CommitLSN {
+			return model.SplitTxnPosition{}
+		}
+		pullPosition.Records = min(pullPosition.Records, position.Records)
+	}
+	return pullPosition
+}
+
+// updateSplitTxnPosition stores where the last batch synced to a destination ended within a transaction
+func updateSplitTxnPosition(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	flowName string,
+	peerName string,
+	position model.SplitTxnPosition,
+) error {
+	if position.CommitLSN == 0 {
+		if _, err := pool.Exec(ctx,
+			"DELETE FROM split_transactions WHERE flow_name = $1 AND peer_name = $2", flowName, peerName,
+		); err != nil {
+			return fmt.Errorf("failed to clear split transaction: %w", err)
+		}
+		return nil
+	}
+	if _, err := pool.Exec(ctx,
+		`INSERT INTO split_transactions (flow_name, peer_name, commit_lsn, records_synced) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (flow_name, peer_name) DO UPDATE SET commit_lsn = $3, records_synced = $4`,
+		flowName, peerName, position.CommitLSN, position.Records,
+	); err != nil {
+		return fmt.Errorf("failed to update split transaction: %w", err)
+	}
+	return nil
+}
+
 func syncCore[TPull connectors.CDCPullConnectorCore, TSync connectors.CDCSyncConnectorCore, Items model.Items](
 	ctx context.Context,
 	a *FlowableActivity,
@@ -65,11 +171,18 @@ func syncCore[TPull connectors.CDCPullConnectorCore, TSync connectors.CDCSyncCon
 	ctx = context.WithValue(ctx, shared.FlowNameKey, flowName)
 	logger := activity.GetLogger(ctx)
 	activity.RecordHeartbeat(ctx, "starting flow...")
-	dstConn, err := connectors.GetAs[TSync](ctx, config.Destination)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get destination connector: %w", err)
+
+	// the primary destination comes first, it alone is tracked in catalog batch monitoring
+	dstConfigs := shared.DestinationConfigs(config)
+	dstConns := make([]TSync, 0, len(dstConfigs))
+	for _, dstConfig := range dstConfigs {
+		dstConn, err := connectors.GetAs[TSync](ctx, dstConfig.Destination)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get destination connector %s: %w", dstConfig.Destination.Name, err)
+		}
+		defer connectors.CloseConnector(ctx, dstConn)
+		dstConns = append(dstConns, dstConn)
 	}
-	defer connectors.CloseConnector(ctx, dstConn)
 
 	tblNameMapping := make(map[string]model.NameAndExclude, len(options.TableMappings))
 	for _, v := range options.TableMappings {
@@ -78,6 +191,7 @@ func syncCore[TPull connectors.CDCPullConnectorCore, TSync connectors.CDCSyncCon
 
 	var srcConn TPull
 	if sessionID == "" {
+		var err error
 		srcConn, err = connectors.GetAs[TPull](ctx, config.Source)
 		if err != nil {
 			return nil, err
@@ -88,6 +202,7 @@ func syncCore[TPull connectors.CDCPullConnectorCore, TSync connectors.CDCSyncCon
 			return nil, err
 		}
 	} else {
+		var err error
 		srcConn, err = waitForCdcCache[TPull](ctx, a, sessionID)
 		if err != nil {
 			return nil, err
@@ -107,10 +222,26 @@ func syncCore[TPull connectors.CDCPullConnectorCore, TSync connectors.CDCSyncCon
 		batchSize = 1_000_000
 	}
 
-	lastOffset, err := dstConn.GetLastOffset(ctx, config.FlowJobName)
+	// destinations may have reached different offsets if an earlier batch failed on some of them,
+	// pull from the earliest and let the others skip what they already have
+	dstLastOffsets := make([]int64, 0, len(dstConns))
+	for _, dstConn := range dstConns {
+		dstLastOffset, err := dstConn.GetLastOffset(ctx, config.FlowJobName)
+		if err != nil {
+			return nil, err
+		}
+		dstLastOffsets = append(dstLastOffsets, dstLastOffset)
+	}
+	lastOffset := slices.Min(dstLastOffsets)
+	peerNames := make([]string, 0, len(dstConfigs))
+	for _, dstConfig := range dstConfigs {
+		peerNames = append(peerNames, dstConfig.Destination.Name)
+	}
+	dstSplitTxns, err := getSplitTxnPositions(ctx, a.CatalogPool, flowName, peerNames, dstLastOffsets)
 	if err != nil {
 		return nil, err
 	}
+	splitTxn := pullSplitTxnPosition(dstSplitTxns, dstLastOffsets)
 	logger.Info("pulling records...", slog.Int64("LastOffset", lastOffset))
 	consumedOffset := atomic.Int64{}
 	consumedOffset.Store(lastOffset)
@@ -120,7 +251,8 @@ func syncCore[TPull connectors.CDCPullConnectorCore, TSync connectors.CDCSyncCon
 
 	errGroup, errCtx := errgroup.WithContext(ctx)
 	errGroup.Go(func() error {
-		return pull(srcConn, errCtx, a.CatalogPool, &model.PullRecordsRequest[Items]{
+		pullCtx, span := otel_tracing.Start(errCtx, "PullRecords", otel_tracing.Peer(config.Source.Name))
+		err := pull(srcConn, pullCtx, a.CatalogPool, &model.PullRecordsRequest[Items]{
 			FlowJobName:           flowName,
 			SrcTableIDNameMapping: options.SrcTableIdNameMapping,
 			TableNameMapping:      tblNameMapping,
@@ -133,16 +265,47 @@ func syncCore[TPull connectors.CDCPullConnectorCore, TSync connectors.CDCSyncCon
 			TableNameSchemaMapping:      options.TableNameSchemaMapping,
 			OverridePublicationName:     config.PublicationName,
 			OverrideReplicationSlotName: config.ReplicationSlotName,
+			BinaryTransfer:              config.BinaryTransfer,
+			SkipReplicatedChanges:       config.SkipReplicatedChanges,
+			SplitTxn:                    splitTxn,
 			RecordStream:                recordBatch,
 		})
+		otel_tracing.End(span, err)
+		return err
 	})
 
-	hasRecords := !recordBatch.WaitAndCheckEmpty()
+	dstRecordBatches := []*model.CDCStream[Items]{recordBatch}
+	dstConsumedOffsets := []*atomic.Int64{&consumedOffset}
+	if len(dstConns) > 1 {
+		var runTee func(context.Context) error
+		teeSplitTxns := make([]model.SplitTxnPosition, 0, len(dstSplitTxns))
+		for _, dstSplitTxn := range dstSplitTxns {
+			if dstSplitTxn.CommitLSN == splitTxn.CommitLSN {
+				dstSplitTxn.Records -= splitTxn.Records
+			}
+			teeSplitTxns = append(teeSplitTxns, dstSplitTxn)
+		}
+		dstRecordBatches, runTee = recordBatch.Tee(dstLastOffsets, teeSplitTxns)
+		errGroup.Go(func() error {
+			return runTee(errCtx)
+		})
+
+		dstConsumedOffsets = make([]*atomic.Int64, 0, len(dstConns))
+		for _, dstLastOffset := range dstLastOffsets {
+			dstConsumedOffset := &atomic.Int64{}
+			dstConsumedOffset.Store(dstLastOffset)
+			dstConsumedOffsets = append(dstConsumedOffsets, dstConsumedOffset)
+		}
+		stopMinConsumedOffset := minConsumedOffsetRoutine(errCtx, &consumedOffset, dstConsumedOffsets)
+		defer stopMinConsumedOffset()
+	}
+
+	hasRecords := !dstRecordBatches[0].WaitAndCheckEmpty()
 	logger.Info("current sync flow has records?", slog.Bool("hasRecords", hasRecords))
 
 	if !hasRecords {
 		// wait for the pull goroutine to finish
-		err = errGroup.Wait()
+		err := errGroup.Wait()
 		if err != nil {
 			a.Alerter.LogFlowError(ctx, flowName, err)
 			if temporal.IsApplicationError(err) {
@@ -153,9 +316,20 @@ func syncCore[TPull connectors.CDCPullConnectorCore, TSync connectors.CDCSyncCon
 		}
 		logger.Info("no records to push")
 
-		err := dstConn.ReplayTableSchemaDeltas(ctx, flowName, recordBatch.SchemaDeltas)
-		if err != nil {
-			return nil, fmt.Errorf("failed to sync schema: %w", err)
+		// a pull can still pass commits without mirrored changes, like a cutover fence
+		if lastCheckpoint := recordBatch.GetLastCheckpoint(); lastCheckpoint > 0 {
+			err := monitoring.UpdateLatestLSNAtTargetForCDCFlow(ctx, a.CatalogPool, flowName, lastCheckpoint)
+			if err != nil {
+				a.Alerter.LogFlowError(ctx, flowName, err)
+				return nil, err
+			}
+		}
+
+		for _, dstConn := range dstConns {
+			err := dstConn.ReplayTableSchemaDeltas(ctx, flowName, recordBatch.SchemaDeltas)
+			if err != nil {
+				return nil, fmt.Errorf("failed to sync schema: %w", err)
+			}
 		}
 
 		return &model.SyncResponse{
@@ -165,44 +339,59 @@ func syncCore[TPull connectors.CDCPullConnectorCore, TSync connectors.CDCSyncCon
 	}
 
 	var syncStartTime time.Time
-	var res *model.SyncResponse
-	errGroup.Go(func() error {
-		syncBatchID, err := dstConn.GetLastSyncBatchID(errCtx, flowName)
-		if err != nil && config.Destination.Type != protos.DBType_EVENTHUBS {
-			return err
-		}
-		syncBatchID += 1
-
-		err = monitoring.AddCDCBatchForFlow(errCtx, a.CatalogPool, flowName,
-			monitoring.CDCBatchInfo{
-				BatchID:     syncBatchID,
-				RowsInBatch: 0,
-				BatchEndlSN: 0,
-				StartTime:   startTime,
+	dstRes := make([]*model.SyncResponse, len(dstConns))
+	for i, dstConn := range dstConns {
+		dstConfig := dstConfigs[i]
+		errGroup.Go(func() error {
+			syncBatchID, err := dstConn.GetLastSyncBatchID(errCtx, flowName)
+			if err != nil && dstConfig.Destination.Type != protos.DBType_EVENTHUBS {
+				return err
+			}
+			syncBatchID += 1
+
+			if i == 0 {
+				err = monitoring.AddCDCBatchForFlow(errCtx, a.CatalogPool, flowName,
+					monitoring.CDCBatchInfo{
+						BatchID:     syncBatchID,
+						RowsInBatch: 0,
+						BatchEndlSN: 0,
+						StartTime:   startTime,
+					})
+				if err != nil {
+					a.Alerter.LogFlowError(ctx, flowName, err)
+					return err
+				}
+
+				syncStartTime = time.Now()
+			}
+
+			syncCtx, span := otel_tracing.Start(errCtx, "SyncRecords",
+				otel_tracing.BatchID(syncBatchID), otel_tracing.Peer(dstConfig.Destination.Name))
+			res, err := sync(dstConn, syncCtx, &model.SyncRecordsRequest[Items]{
+				SyncBatchID:            syncBatchID,
+				Records:                dstRecordBatches[i],
+				ConsumedOffset:         dstConsumedOffsets[i],
+				FlowJobName:            flowName,
+				TableMappings:          options.TableMappings,
+				StagingPath:            config.CdcStagingPath,
+				Script:                 config.Script,
+				TableNameSchemaMapping: options.TableNameSchemaMapping,
+				CommitInfo:             shared.NeedsCommitInfo(config.NormalizeMode, options.TableMappings) || config.ReplicationOrigin,
+				ReplicationOrigin:      config.ReplicationOrigin,
 			})
-		if err != nil {
-			a.Alerter.LogFlowError(ctx, flowName, err)
-			return err
-		}
+			otel_tracing.End(span, err)
+			if err != nil {
+				a.Alerter.LogFlowError(ctx, flowName, err)
+				return fmt.Errorf("failed to push records to %s: %w", dstConfig.Destination.Name, err)
+			}
+			dstRes[i] = res
 
-		syncStartTime = time.Now()
-		res, err = sync(dstConn, errCtx, &model.SyncRecordsRequest[Items]{
-			SyncBatchID:            syncBatchID,
-			Records:                recordBatch,
-			ConsumedOffset:         &consumedOffset,
-			FlowJobName:            flowName,
-			TableMappings:          options.TableMappings,
-			StagingPath:            config.CdcStagingPath,
-			Script:                 config.Script,
-			TableNameSchemaMapping: options.TableNameSchemaMapping,
+			if dstSplitTxn := dstRecordBatches[i].GetSplitTxn(); dstSplitTxn.CommitLSN != 0 || dstSplitTxns[i].CommitLSN != 0 {
+				return updateSplitTxnPosition(errCtx, a.CatalogPool, flowName, dstConfig.Destination.Name, dstSplitTxn)
+			}
+			return nil
 		})
-		if err != nil {
-			a.Alerter.LogFlowError(ctx, flowName, err)
-			return fmt.Errorf("failed to push records: %w", err)
-		}
-
-		return nil
-	})
+	}
 
 	err = errGroup.Wait()
 	if err != nil {
@@ -214,11 +403,27 @@ func syncCore[TPull connectors.CDCPullConnectorCore, TSync connectors.CDCSyncCon
 		}
 	}
 
+	res := dstRes[0]
+	if len(dstRes) > 1 {
+		res.AdditionalSyncBatchIDs = make(map[string]int64, len(dstRes)-1)
+		for i, additionalRes := range dstRes[1:] {
+			res.AdditionalSyncBatchIDs[dstConfigs[i+1].Destination.Name] = additionalRes.CurrentSyncBatchID
+		}
+	}
 	numRecords := res.NumRecordsSynced
 	syncDuration := time.Since(syncStartTime)
 
 	logger.Info(fmt.Sprintf("pushed %d records in %d seconds", numRecords, int(syncDuration.Seconds())))
+	prom_metrics.SyncBatchDuration.WithLabelValues(flowName).Observe(syncDuration.Seconds())
+	if lastCommitTime := recordBatch.GetLastCommitTime(); !lastCommitTime.IsZero() {
+		prom_metrics.SyncLag.WithLabelValues(flowName).Observe(time.Since(lastCommitTime).Seconds())
+	}
+	prom_metrics.AddRecordCounts(prom_metrics.RecordsSynced, flowName, res.TableNameRowsMapping)
+	for i, dstConn := range dstConns {
+		recordRawTableBacklog(ctx, flowName, dstConfigs[i].Destination.Name, dstConn)
+	}
 
+	// every destination has durably written the batch, so the slot may advance to its end
 	lastCheckpoint := recordBatch.GetLastCheckpoint()
 	srcConn.UpdateReplStateLastOffset(lastCheckpoint)
 
@@ -259,6 +464,35 @@ func syncCore[TPull connectors.CDCPullConnectorCore, TSync connectors.CDCSyncCon
 	return res, nil
 }
 
+// recordRawTableBacklog sets how many batches synced to conn, the connector of peerName, are not yet normalized,
+// skipped when metrics aren't scraped as it costs two metadata queries
+func recordRawTableBacklog(ctx context.Context, flowName string, peerName string, conn connectors.Connector) {
+	if !prom_metrics.Enabled() {
+		return
+	}
+	syncConn, ok := conn.(connectors.CDCSyncConnectorCore)
+	if !ok {
+		return
+	}
+	normConn, ok := conn.(connectors.CDCNormalizeConnector)
+	if !ok {
+		return
+	}
+
+	logger := activity.GetLogger(ctx)
+	syncBatchID, err := syncConn.GetLastSyncBatchID(ctx, flowName)
+	if err != nil {
+		logger.Warn("failed to get last sync batch id for metrics", slog.Any("error", err))
+		return
+	}
+	normBatchID, err := normConn.GetLastNormalizeBatchID(ctx, flowName)
+	if err != nil {
+		logger.Warn("failed to get last normalize batch id for metrics", slog.Any("error", err))
+		return
+	}
+	prom_metrics.SetRawTableBacklog(flowName, peerName, syncBatchID, normBatchID)
+}
+
 func (a *FlowableActivity) getPostgresPeerConfigs(ctx context.Context) ([]*protos.Peer, error) {
 	optionRows, err := a.CatalogPool.Query(ctx, `
 			SELECT DISTINCT p.name, p.options
@@ -276,6 +510,10 @@ func (a *FlowableActivity) getPostgresPeerConfigs(ctx context.Context) ([]*proto
 		if err != nil {
 			return nil, err
 		}
+		peerOptions, err = peerdbenv.DecryptCatalogData(peerOptions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt options for peer %s: %w", peerName, err)
+		}
 		var pgPeerConfig protos.PostgresConfig
 		unmarshalErr := proto.Unmarshal(peerOptions, &pgPeerConfig)
 		if unmarshalErr != nil {
@@ -303,6 +541,10 @@ func (a *FlowableActivity) replicateQRepPartition(ctx context.Context,
 	ctx = context.WithValue(ctx, shared.FlowNameKey, config.FlowJobName)
 	logger := log.With(activity.GetLogger(ctx), slog.String(string(shared.FlowNameKey), config.FlowJobName))
 
+	partitionsInFlight := prom_metrics.QRepPartitionsInFlight.WithLabelValues(config.FlowJobName)
+	partitionsInFlight.Inc()
+	defer partitionsInFlight.Dec()
+
 	srcConn, err := connectors.GetQRepPullConnector(ctx, config.SourcePeer)
 	if err != nil {
 		a.Alerter.LogFlowError(ctx, config.FlowJobName, err)
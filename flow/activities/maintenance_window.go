@@ -0,0 +1,129 @@
+package activities
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.temporal.io/sdk/activity"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+	"github.com/PeerDB-io/peer-flow/model"
+	"github.com/PeerDB-io/peer-flow/shared"
+)
+
+type maintenanceWindowMirror struct {
+	workflowID     string
+	windows        []*protos.MaintenanceWindow
+	skipUntil      time.Time
+	pausedByWindow bool
+	manuallyPaused bool
+}
+
+// GetMaintenanceWindowActions compares the maintenance windows of each mirror with whether a window paused it,
+// returning mirrors to pause as a window starts and those to resume as it ends or is skipped.
+// Mirrors paused manually are left alone until they are resumed manually
+func (a *FlowableActivity) GetMaintenanceWindowActions(ctx context.Context) ([]*model.MaintenanceWindowAction, error) {
+	rows, err := a.CatalogPool.Query(ctx, `SELECT f.name, f.workflow_id, w.cron_expression, w.duration_seconds,
+		coalesce(s.paused_by_window, false), coalesce(s.manually_paused, false), s.skip_until
+		FROM flows f
+		LEFT JOIN maintenance_windows w ON w.flow_name = f.name
+		LEFT JOIN maintenance_window_state s ON s.flow_name = f.name
+		WHERE w.id IS NOT NULL OR s.paused_by_window`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query maintenance windows: %w", err)
+	}
+	defer rows.Close()
+
+	mirrors := make(map[string]*maintenanceWindowMirror)
+	var flowName string
+	var workflowID pgtype.Text
+	var cronExpression pgtype.Text
+	var durationSeconds pgtype.Int8
+	var pausedByWindow bool
+	var manuallyPaused bool
+	var skipUntil pgtype.Timestamptz
+	for rows.Next() {
+		if err := rows.Scan(&flowName, &workflowID, &cronExpression, &durationSeconds,
+			&pausedByWindow, &manuallyPaused, &skipUntil); err != nil {
+			return nil, fmt.Errorf("failed to scan maintenance window: %w", err)
+		}
+		mirror, ok := mirrors[flowName]
+		if !ok {
+			mirror = &maintenanceWindowMirror{
+				workflowID:     workflowID.String,
+				skipUntil:      skipUntil.Time,
+				pausedByWindow: pausedByWindow,
+				manuallyPaused: manuallyPaused,
+			}
+			mirrors[flowName] = mirror
+		}
+		if cronExpression.Valid {
+			mirror.windows = append(mirror.windows, &protos.MaintenanceWindow{
+				CronExpression:  cronExpression.String,
+				DurationSeconds: durationSeconds.Int64,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read maintenance windows: %w", err)
+	}
+
+	logger := activity.GetLogger(ctx)
+	now := time.Now()
+	var actions []*model.MaintenanceWindowAction
+	for name, mirror := range mirrors {
+		if mirror.manuallyPaused || mirror.workflowID == "" {
+			continue
+		}
+		_, inWindow, err := shared.ActiveMaintenanceWindowEnd(mirror.windows, now, mirror.skipUntil)
+		if err != nil {
+			logger.Warn("skipping invalid maintenance window", slog.String(string(shared.FlowNameKey), name),
+				slog.Any("error", err))
+			continue
+		}
+		if inWindow != mirror.pausedByWindow {
+			actions = append(actions, &model.MaintenanceWindowAction{
+				FlowJobName: name,
+				WorkflowID:  mirror.workflowID,
+				Pause:       inWindow,
+			})
+		}
+	}
+	return actions, nil
+}
+
+// SetPausedByMaintenanceWindow records that a window paused or resumed a mirror
+func (a *FlowableActivity) SetPausedByMaintenanceWindow(ctx context.Context, flowName string, paused bool) error {
+	_, err := a.CatalogPool.Exec(ctx, `INSERT INTO maintenance_window_state (flow_name, paused_by_window) VALUES ($1, $2)
+		ON CONFLICT (flow_name) DO UPDATE SET paused_by_window = excluded.paused_by_window`, flowName, paused)
+	if err != nil {
+		return fmt.Errorf("failed to update maintenance window state of %s: %w", flowName, err)
+	}
+	return nil
+}
+
+// ApplyMaintenanceWindowAction pauses or resumes a mirror the same way a user would through the API,
+// returning false when a mirror to pause is not running yet so the pause is retried next run
+func (a *FlowableActivity) ApplyMaintenanceWindowAction(ctx context.Context, action *model.MaintenanceWindowAction) (bool, error) {
+	status, err := model.GetFlowStatus(ctx, a.TemporalClient, action.WorkflowID)
+	if err != nil {
+		return false, err
+	}
+	if action.Pause {
+		switch status {
+		case protos.FlowStatus_STATUS_RUNNING:
+			return true, model.PauseFlow(ctx, a.TemporalClient, action.WorkflowID)
+		case protos.FlowStatus_STATUS_PAUSING, protos.FlowStatus_STATUS_PAUSED:
+			return true, nil
+		default:
+			return false, nil
+		}
+	}
+	if status == protos.FlowStatus_STATUS_PAUSED {
+		return true, model.ResumeFlow(ctx, a.TemporalClient, action.WorkflowID)
+	}
+	return true, nil
+}
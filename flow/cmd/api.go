@@ -15,6 +15,7 @@ import (
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"go.temporal.io/api/workflowservice/v1"
 	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/interceptor"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health"
@@ -23,18 +24,23 @@ import (
 
 	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/logger"
+	"github.com/PeerDB-io/peer-flow/otel_tracing"
 	"github.com/PeerDB-io/peer-flow/peerdbenv"
+	"github.com/PeerDB-io/peer-flow/prom_metrics"
 	"github.com/PeerDB-io/peer-flow/shared"
 	peerflow "github.com/PeerDB-io/peer-flow/workflows"
 )
 
 type APIServerParams struct {
-	TemporalHostPort  string
-	TemporalNamespace string
-	TemporalCert      string
-	TemporalKey       string
-	Port              uint16
-	GatewayPort       uint16
+	TemporalHostPort        string
+	TemporalNamespace       string
+	TemporalCert            string
+	TemporalKey             string
+	Port                    uint16
+	GatewayPort             uint16
+	PrometheusMetricsPort   uint16
+	EnableOtelTracing       bool
+	EnablePrometheusMetrics bool
 }
 
 // setupGRPCGatewayServer sets up the grpc-gateway mux
@@ -110,6 +116,23 @@ func APIMain(ctx context.Context, args *APIServerParams) error {
 		clientOptions.ConnectionOptions = connOptions
 	}
 
+	if args.EnableOtelTracing {
+		tracerProvider, err := otel_tracing.SetupOtelTraceExporter("flow-api")
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := tracerProvider.Shutdown(context.Background()); err != nil {
+				slog.Error("Failed to shutdown tracer provider", slog.Any("error", err))
+			}
+		}()
+		tracingInterceptor, err := otel_tracing.NewTemporalTracingInterceptor()
+		if err != nil {
+			return fmt.Errorf("failed to create tracing interceptor: %w", err)
+		}
+		clientOptions.Interceptors = []interceptor.ClientInterceptor{tracingInterceptor}
+	}
+
 	tc, err := client.Dial(clientOptions)
 	if err != nil {
 		return fmt.Errorf("unable to create Temporal client: %w", err)
@@ -154,6 +177,11 @@ func APIMain(ctx context.Context, args *APIServerParams) error {
 		return fmt.Errorf("failed to listen: %w", err)
 	}
 
+	if args.EnablePrometheusMetrics {
+		stopPrometheusServer := prom_metrics.StartServer(args.PrometheusMetricsPort)
+		defer stopPrometheusServer()
+	}
+
 	slog.Info(fmt.Sprintf("Starting API server on port %d", args.Port))
 	go func() {
 		if err := grpcServer.Serve(lis); err != nil {
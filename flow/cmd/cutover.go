@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pglogrepl"
+	"go.temporal.io/sdk/client"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+	"github.com/PeerDB-io/peer-flow/shared"
+	peerflow "github.com/PeerDB-io/peer-flow/workflows"
+)
+
+func (h *FlowRequestHandler) CutoverMirror(
+	ctx context.Context,
+	req *protos.CutoverMirrorRequest,
+) (*protos.CutoverMirrorResponse, error) {
+	config, err := h.getFlowConfigFromCatalog(ctx, req.FlowJobName)
+	if err != nil {
+		return nil, err
+	}
+	if config.Source.Type != protos.DBType_POSTGRES || config.Destination.Type != protos.DBType_POSTGRES {
+		return nil, fmt.Errorf("mirror %s does not replicate from Postgres to Postgres", req.FlowJobName)
+	}
+
+	// a paused mirror never catches up, leaving the source fenced until the cutover times out
+	mirrorWorkflowID, err := h.getWorkflowID(ctx, req.FlowJobName)
+	if err != nil {
+		return nil, err
+	}
+	mirrorStatus, err := h.getWorkflowStatus(ctx, mirrorWorkflowID)
+	if err != nil {
+		return nil, err
+	}
+	if mirrorStatus != protos.FlowStatus_STATUS_RUNNING {
+		return nil, fmt.Errorf("mirror %s is %s, only running mirrors can be cut over", req.FlowJobName, mirrorStatus)
+	}
+
+	input := &protos.CutoverInput{
+		ConnectionConfigs:     config,
+		CreateReverseMirror:   req.CreateReverseMirror,
+		ReverseMirrorName:     req.ReverseMirrorName,
+		CatchUpTimeoutSeconds: req.CatchUpTimeoutSeconds,
+	}
+	if req.FenceLsn != "" {
+		fenceLSN, err := pglogrepl.ParseLSN(req.FenceLsn)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fence LSN %s: %w", req.FenceLsn, err)
+		}
+		input.FenceLsn = uint64(fenceLSN)
+	}
+	if req.CreateReverseMirror {
+		if shared.NeedsCommitInfo(config.NormalizeMode, config.TableMappings) {
+			return nil, fmt.Errorf("mirror %s normalizes tables in a history mode, which can't be reversed", req.FlowJobName)
+		}
+		if !config.SkipReplicatedChanges {
+			return nil, fmt.Errorf("mirror %s doesn't skip replicated changes, it would replicate changes of a reverse mirror back",
+				req.FlowJobName)
+		}
+		// the reverse mirror applies changes with a replication origin to the source
+		if err := checkCommitTimestamps(ctx, config.Source.GetPostgresConfig()); err != nil {
+			return nil, fmt.Errorf("source peer %s can't take a reverse mirror: %w", config.Source.Name, err)
+		}
+		reverseName := req.ReverseMirrorName
+		if reverseName == "" {
+			reverseName = req.FlowJobName + "_reverse"
+		}
+		exists, err := h.CheckIfMirrorNameExists(ctx, reverseName)
+		if err != nil {
+			return nil, err
+		} else if exists {
+			return nil, fmt.Errorf("mirror %s already exists", reverseName)
+		}
+	}
+
+	workflowID := fmt.Sprintf("%s-cutover-%s", req.FlowJobName, uuid.New())
+	workflowOptions := client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: h.peerflowTaskQueueID,
+		SearchAttributes: map[string]interface{}{
+			shared.MirrorNameSearchAttribute: req.FlowJobName,
+		},
+	}
+	if _, err := h.temporalClient.ExecuteWorkflow(ctx, workflowOptions, peerflow.CutoverFlowWorkflow, input); err != nil {
+		slog.Error("unable to start cutover workflow", slog.Any("error", err))
+		return nil, fmt.Errorf("unable to start cutover workflow: %w", err)
+	}
+
+	return &protos.CutoverMirrorResponse{
+		WorkflowId: workflowID,
+	}, nil
+}
+
+func (h *FlowRequestHandler) CutoverStatus(
+	ctx context.Context,
+	req *protos.CutoverStatusRequest,
+) (*protos.CutoverStatusResponse, error) {
+	res, err := h.temporalClient.QueryWorkflow(ctx, req.WorkflowId, "", shared.CutoverStatusQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status of cutover %s: %w", req.WorkflowId, err)
+	}
+	var status *protos.CutoverStatus
+	if err := res.Get(&status); err != nil {
+		return nil, fmt.Errorf("failed to get status of cutover %s: %w", req.WorkflowId, err)
+	}
+	return &protos.CutoverStatusResponse{
+		Status: status,
+	}, nil
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/PeerDB-io/peer-flow/alerting"
 	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/model"
+	"github.com/PeerDB-io/peer-flow/peerdbenv"
 	"github.com/PeerDB-io/peer-flow/shared"
 	peerflow "github.com/PeerDB-io/peer-flow/workflows"
 )
@@ -190,6 +191,10 @@ func (h *FlowRequestHandler) updateFlowConfigInCatalog(
 	if err != nil {
 		return fmt.Errorf("unable to marshal flow config: %w", err)
 	}
+	cfgBytes, err = peerdbenv.EncryptCatalogData(cfgBytes)
+	if err != nil {
+		return fmt.Errorf("unable to encrypt flow config: %w", err)
+	}
 
 	_, err = h.pool.Exec(ctx, "UPDATE flows SET config_proto = $1 WHERE name = $2", cfgBytes, cfg.FlowJobName)
 	if err != nil {
@@ -208,6 +213,19 @@ func (h *FlowRequestHandler) removeFlowEntryInCatalog(
 		return fmt.Errorf("unable to remove flow entry in catalog: %w", err)
 	}
 
+	_, err = h.pool.Exec(ctx, "DELETE FROM maintenance_windows WHERE flow_name = $1", flowName)
+	if err != nil {
+		return fmt.Errorf("unable to remove maintenance windows in catalog: %w", err)
+	}
+	_, err = h.pool.Exec(ctx, "DELETE FROM maintenance_window_state WHERE flow_name = $1", flowName)
+	if err != nil {
+		return fmt.Errorf("unable to remove maintenance window state in catalog: %w", err)
+	}
+	_, err = h.pool.Exec(ctx, "DELETE FROM split_transactions WHERE flow_name = $1", flowName)
+	if err != nil {
+		return fmt.Errorf("unable to remove split transactions in catalog: %w", err)
+	}
+
 	return nil
 }
 
@@ -293,6 +311,10 @@ func (h *FlowRequestHandler) updateQRepConfigInCatalog(
 	if err != nil {
 		return fmt.Errorf("unable to marshal qrep config: %w", err)
 	}
+	cfgBytes, err = peerdbenv.EncryptCatalogData(cfgBytes)
+	if err != nil {
+		return fmt.Errorf("unable to encrypt qrep config: %w", err)
+	}
 
 	_, err = h.pool.Exec(ctx,
 		"UPDATE flows SET config_proto = $1 WHERE name = $2",
@@ -304,6 +326,37 @@ func (h *FlowRequestHandler) updateQRepConfigInCatalog(
 	return nil
 }
 
+// updateQRepFlowConfig records the updated config in the catalog so it survives the mirror being recreated,
+// and signals a running qrep or xmin mirror with the update
+func (h *FlowRequestHandler) updateQRepFlowConfig(
+	ctx context.Context,
+	workflowID string,
+	flowJobName string,
+	update *protos.QRepFlowConfigUpdate,
+) error {
+	if err := shared.ValidateQRepFlowConfigUpdate(update); err != nil {
+		return fmt.Errorf("invalid qrep config update: %w", err)
+	}
+	cfg := h.getQRepConfigFromCatalog(ctx, flowJobName)
+	if cfg == nil {
+		return fmt.Errorf("unable to find qrep config of %s in catalog", flowJobName)
+	}
+
+	// persisted first so a mirror is never running with a config the catalog doesn't have
+	shared.ApplyQRepFlowConfigUpdate(cfg, update)
+	if err := h.updateQRepConfigInCatalog(ctx, cfg); err != nil {
+		slog.Error("unable to update qrep config in catalog",
+			slog.Any("error", err), slog.String("flowName", flowJobName))
+		return fmt.Errorf("unable to update qrep config in catalog: %w", err)
+	}
+
+	err := model.QRepDynamicPropertiesSignal.SignalClientWorkflow(ctx, h.temporalClient, workflowID, "", update)
+	if err != nil {
+		return fmt.Errorf("unable to signal workflow: %w", err)
+	}
+	return nil
+}
+
 func (h *FlowRequestHandler) ShutdownFlow(
 	ctx context.Context,
 	req *protos.ShutdownRequest,
@@ -330,6 +383,16 @@ func (h *FlowRequestHandler) ShutdownFlow(
 			ErrorMessage: fmt.Sprintf("unable to determine if workflow is cdc: %v", err),
 		}, fmt.Errorf("unable to determine if workflow is cdc: %w", err)
 	} else if isCdc {
+		if len(req.AdditionalDestinationPeers) == 0 {
+			// callers only know the primary destination, additional destinations live in the mirror config
+			if cfg, err := h.getFlowConfigFromCatalog(ctx, req.FlowJobName); err == nil {
+				req.AdditionalDestinationPeers = cfg.AdditionalDestinations
+			} else {
+				slog.Warn("unable to load flow config, additional destinations are not cleaned up",
+					logs, slog.Any("error", err))
+			}
+		}
+
 		workflowID := fmt.Sprintf("%s-dropflow-%s", req.FlowJobName, uuid.New())
 		workflowOptions := client.StartWorkflowOptions{
 			ID:        workflowID,
@@ -414,6 +477,15 @@ func (h *FlowRequestHandler) FlowStateChange(
 	}
 
 	if req.FlowConfigUpdate != nil && req.FlowConfigUpdate.GetCdcFlowConfigUpdate() != nil {
+		if removedTables := req.FlowConfigUpdate.GetCdcFlowConfigUpdate().RemovedTables; len(removedTables) > 0 {
+			state, err := h.getCDCWorkflowState(ctx, workflowID)
+			if err != nil {
+				return nil, err
+			}
+			if err := validateRemovedTables(state.SyncFlowOptions.TableMappings, removedTables); err != nil {
+				return nil, err
+			}
+		}
 		err = model.CDCDynamicPropertiesSignal.SignalClientWorkflow(
 			ctx,
 			h.temporalClient,
@@ -425,31 +497,21 @@ func (h *FlowRequestHandler) FlowStateChange(
 			return nil, fmt.Errorf("unable to signal workflow: %w", err)
 		}
 	}
+	if req.FlowConfigUpdate != nil && req.FlowConfigUpdate.GetQrepFlowConfigUpdate() != nil {
+		if err := h.updateQRepFlowConfig(ctx, workflowID, req.FlowJobName,
+			req.FlowConfigUpdate.GetQrepFlowConfigUpdate()); err != nil {
+			return nil, err
+		}
+	}
 
 	// in case we only want to update properties without changing status
 	if req.RequestedFlowState != protos.FlowStatus_STATUS_UNKNOWN {
 		if req.RequestedFlowState == protos.FlowStatus_STATUS_PAUSED &&
 			currState == protos.FlowStatus_STATUS_RUNNING {
-			err = h.updateWorkflowStatus(ctx, workflowID, protos.FlowStatus_STATUS_PAUSING)
-			if err != nil {
-				return nil, err
-			}
-			err = model.FlowSignal.SignalClientWorkflow(
-				ctx,
-				h.temporalClient,
-				workflowID,
-				"",
-				model.PauseSignal,
-			)
+			err = model.PauseFlow(ctx, h.temporalClient, workflowID)
 		} else if req.RequestedFlowState == protos.FlowStatus_STATUS_RUNNING &&
 			currState == protos.FlowStatus_STATUS_PAUSED {
-			err = model.FlowSignal.SignalClientWorkflow(
-				ctx,
-				h.temporalClient,
-				workflowID,
-				"",
-				model.NoopSignal,
-			)
+			err = model.ResumeFlow(ctx, h.temporalClient, workflowID)
 		} else if req.RequestedFlowState == protos.FlowStatus_STATUS_TERMINATED &&
 			(currState != protos.FlowStatus_STATUS_TERMINATED) {
 			err = h.updateWorkflowStatus(ctx, workflowID, protos.FlowStatus_STATUS_TERMINATING)
@@ -470,6 +532,13 @@ func (h *FlowRequestHandler) FlowStateChange(
 		if err != nil {
 			return nil, fmt.Errorf("unable to signal workflow: %w", err)
 		}
+		if req.RequestedFlowState == protos.FlowStatus_STATUS_PAUSED ||
+			req.RequestedFlowState == protos.FlowStatus_STATUS_RUNNING {
+			if err := h.recordManualStateChange(ctx, req.FlowJobName,
+				req.RequestedFlowState == protos.FlowStatus_STATUS_PAUSED); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return &protos.FlowStateChangeResponse{
@@ -606,6 +675,13 @@ func (h *FlowRequestHandler) CreatePeer(
 		}
 		esConfig := esConfigObject.ElasticsearchConfig
 		encodedConfig, encodingErr = proto.Marshal(esConfig)
+	case protos.DBType_WEBHOOK:
+		whConfigObject, ok := config.(*protos.Peer_WebhookConfig)
+		if !ok {
+			return wrongConfigResponse, nil
+		}
+		whConfig := whConfigObject.WebhookConfig
+		encodedConfig, encodingErr = proto.Marshal(whConfig)
 	default:
 		return wrongConfigResponse, nil
 	}
@@ -614,6 +690,12 @@ func (h *FlowRequestHandler) CreatePeer(
 			req.Peer.Type, req.Peer.Name, encodingErr))
 		return nil, encodingErr
 	}
+	encodedConfig, encodingErr = peerdbenv.EncryptCatalogData(encodedConfig)
+	if encodingErr != nil {
+		slog.Error(fmt.Sprintf("failed to encrypt peer configuration for %s peer %s : %v",
+			req.Peer.Type, req.Peer.Name, encodingErr))
+		return nil, encodingErr
+	}
 
 	_, err := h.pool.Exec(ctx, "INSERT INTO peers (name, type, options) VALUES ($1, $2, $3)",
 		req.Peer.Name, peerType, encodedConfig,
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+	"github.com/PeerDB-io/peer-flow/shared"
+)
+
+func (h *FlowRequestHandler) SetMaintenanceWindows(
+	ctx context.Context,
+	req *protos.SetMaintenanceWindowsRequest,
+) (*protos.SetMaintenanceWindowsResponse, error) {
+	if _, err := h.getWorkflowID(ctx, req.FlowJobName); err != nil {
+		return nil, err
+	}
+	for _, window := range req.Windows {
+		if err := shared.ValidateMaintenanceWindow(window); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer shared.RollbackTx(tx, slog.Default())
+
+	if _, err := tx.Exec(ctx, "DELETE FROM maintenance_windows WHERE flow_name = $1", req.FlowJobName); err != nil {
+		return nil, fmt.Errorf("unable to remove maintenance windows: %w", err)
+	}
+	for _, window := range req.Windows {
+		if _, err := tx.Exec(ctx,
+			"INSERT INTO maintenance_windows (flow_name, cron_expression, duration_seconds) VALUES ($1, $2, $3)",
+			req.FlowJobName, window.CronExpression, window.DurationSeconds,
+		); err != nil {
+			return nil, fmt.Errorf("unable to add maintenance window: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("unable to commit maintenance windows: %w", err)
+	}
+
+	return &protos.SetMaintenanceWindowsResponse{
+		Ok: true,
+	}, nil
+}
+
+func (h *FlowRequestHandler) SkipMaintenanceWindow(
+	ctx context.Context,
+	req *protos.SkipMaintenanceWindowRequest,
+) (*protos.SkipMaintenanceWindowResponse, error) {
+	status, err := h.getMaintenanceStatus(ctx, req.FlowJobName)
+	if err != nil {
+		return nil, err
+	}
+	if len(status.Windows) == 0 {
+		return nil, fmt.Errorf("mirror %s has no maintenance windows", req.FlowJobName)
+	}
+
+	now := time.Now()
+	skipUntil, ok, err := shared.ActiveMaintenanceWindowEnd(status.Windows, now, status.SkipUntil.AsTime())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// skip the next window which isn't already skipped
+		after := now
+		if status.SkipUntil != nil && status.SkipUntil.AsTime().After(now) {
+			after = status.SkipUntil.AsTime()
+		}
+		for _, window := range status.Windows {
+			_, end, err := shared.NextMaintenanceWindow(window, after)
+			if err != nil {
+				return nil, err
+			}
+			if skipUntil.IsZero() || end.Before(skipUntil) {
+				skipUntil = end
+			}
+		}
+	}
+
+	if err := h.setMaintenanceSkipUntil(ctx, req.FlowJobName, skipUntil); err != nil {
+		return nil, err
+	}
+	return &protos.SkipMaintenanceWindowResponse{
+		SkipUntil: timestamppb.New(skipUntil),
+	}, nil
+}
+
+func (h *FlowRequestHandler) setMaintenanceSkipUntil(ctx context.Context, flowName string, skipUntil time.Time) error {
+	_, err := h.pool.Exec(ctx, `INSERT INTO maintenance_window_state (flow_name, skip_until) VALUES ($1, $2)
+		ON CONFLICT (flow_name) DO UPDATE SET skip_until = greatest(maintenance_window_state.skip_until, excluded.skip_until)`,
+		flowName, skipUntil)
+	if err != nil {
+		return fmt.Errorf("unable to skip maintenance window: %w", err)
+	}
+	return nil
+}
+
+// recordManualStateChange hands control of a mirror paused through FlowStateChange to the user,
+// windows neither pause nor resume it until it is resumed, which also skips any active window
+func (h *FlowRequestHandler) recordManualStateChange(ctx context.Context, flowName string, paused bool) error {
+	if !paused {
+		status, err := h.getMaintenanceStatus(ctx, flowName)
+		if err != nil {
+			return err
+		}
+		end, ok, err := shared.ActiveMaintenanceWindowEnd(status.Windows, time.Now(), status.SkipUntil.AsTime())
+		if err != nil {
+			return err
+		}
+		if ok {
+			if err := h.setMaintenanceSkipUntil(ctx, flowName, end); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := h.pool.Exec(ctx, `INSERT INTO maintenance_window_state (flow_name, manually_paused) VALUES ($1, $2)
+		ON CONFLICT (flow_name) DO UPDATE SET manually_paused = excluded.manually_paused, paused_by_window = false`,
+		flowName, paused)
+	if err != nil {
+		return fmt.Errorf("unable to update maintenance window state: %w", err)
+	}
+	return nil
+}
+
+func (h *FlowRequestHandler) getMaintenanceStatus(ctx context.Context, flowName string) (*protos.MaintenanceStatus, error) {
+	rows, err := h.pool.Query(ctx,
+		"SELECT cron_expression, duration_seconds FROM maintenance_windows WHERE flow_name = $1 ORDER BY id", flowName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query maintenance windows: %w", err)
+	}
+	windows, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*protos.MaintenanceWindow, error) {
+		var window protos.MaintenanceWindow
+		err := row.Scan(&window.CronExpression, &window.DurationSeconds)
+		return &window, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read maintenance windows: %w", err)
+	}
+
+	status := &protos.MaintenanceStatus{
+		Windows: windows,
+	}
+	var skipUntil pgtype.Timestamptz
+	err = h.pool.QueryRow(ctx,
+		"SELECT paused_by_window, skip_until FROM maintenance_window_state WHERE flow_name = $1",
+		flowName).Scan(&status.PausedByWindow, &skipUntil)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("unable to query maintenance window state: %w", err)
+	}
+	if skipUntil.Valid {
+		status.SkipUntil = timestamppb.New(skipUntil.Time)
+	}
+
+	now := time.Now()
+	_, status.InWindow, err = shared.ActiveMaintenanceWindowEnd(windows, now, skipUntil.Time)
+	if err != nil {
+		return nil, err
+	}
+	var nextStart time.Time
+	for _, window := range windows {
+		start, _, err := shared.NextMaintenanceWindow(window, now)
+		if err != nil {
+			return nil, err
+		}
+		if nextStart.IsZero() || start.Before(nextStart) {
+			nextStart = start
+		}
+	}
+	if !nextStart.IsZero() {
+		status.NextWindowStart = timestamppb.New(nextStart)
+	}
+	return status, nil
+}
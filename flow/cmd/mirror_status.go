@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"log/slog"
 
@@ -11,6 +10,8 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/PeerDB-io/peer-flow/generated/protos"
+	"github.com/PeerDB-io/peer-flow/model"
+	"github.com/PeerDB-io/peer-flow/peerdbenv"
 	"github.com/PeerDB-io/peer-flow/shared"
 	peerflow "github.com/PeerDB-io/peer-flow/workflows"
 )
@@ -40,6 +41,13 @@ func (h *FlowRequestHandler) MirrorStatus(
 		}, nil
 	}
 
+	maintenanceStatus, err := h.getMaintenanceStatus(ctx, req.FlowJobName)
+	if err != nil {
+		return &protos.MirrorStatusResponse{
+			ErrorMessage: "unable to get maintenance windows: " + err.Error(),
+		}, nil
+	}
+
 	if cdcFlow {
 		cdcStatus, err := h.CDCFlowStatus(ctx, req)
 		if err != nil {
@@ -53,7 +61,8 @@ func (h *FlowRequestHandler) MirrorStatus(
 			Status: &protos.MirrorStatusResponse_CdcStatus{
 				CdcStatus: cdcStatus,
 			},
-			CurrentFlowState: currState,
+			CurrentFlowState:  currState,
+			MaintenanceStatus: maintenanceStatus,
 		}, nil
 	} else {
 		qrepStatus, err := h.QRepFlowStatus(ctx, req)
@@ -68,7 +77,8 @@ func (h *FlowRequestHandler) MirrorStatus(
 			Status: &protos.MirrorStatusResponse_QrepStatus{
 				QrepStatus: qrepStatus,
 			},
-			CurrentFlowState: currState,
+			CurrentFlowState:  currState,
+			MaintenanceStatus: maintenanceStatus,
 		}, nil
 	}
 }
@@ -204,6 +214,11 @@ func (h *FlowRequestHandler) cloneTableSummary(
 		}
 
 		if configBytes != nil {
+			configBytes, err := peerdbenv.DecryptCatalogData(configBytes)
+			if err != nil {
+				slog.Error("unable to decrypt config", slog.Any("error", err))
+				return nil, fmt.Errorf("unable to decrypt config: %w", err)
+			}
 			var config protos.QRepConfig
 			if err := proto.Unmarshal(configBytes, &config); err != nil {
 				slog.Error("unable to unmarshal config", slog.Any("error", err))
@@ -285,7 +300,7 @@ func (h *FlowRequestHandler) getFlowConfigFromCatalog(
 	ctx context.Context,
 	flowJobName string,
 ) (*protos.FlowConnectionConfigs, error) {
-	var configBytes sql.RawBytes
+	var configBytes []byte
 	var err error
 	var config protos.FlowConnectionConfigs
 
@@ -296,6 +311,12 @@ func (h *FlowRequestHandler) getFlowConfigFromCatalog(
 		return nil, fmt.Errorf("unable to query flow config from catalog: %w", err)
 	}
 
+	configBytes, err = peerdbenv.DecryptCatalogData(configBytes)
+	if err != nil {
+		slog.Error("unable to decrypt flow config", slog.Any("error", err))
+		return nil, fmt.Errorf("unable to decrypt flow config: %w", err)
+	}
+
 	err = proto.Unmarshal(configBytes, &config)
 	if err != nil {
 		slog.Error("unable to unmarshal flow config", slog.Any("error", err))
@@ -337,6 +358,12 @@ func (h *FlowRequestHandler) getQRepConfigFromCatalog(ctx context.Context, flowJ
 		return nil
 	}
 
+	configBytes, err := peerdbenv.DecryptCatalogData(configBytes)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("failed to decrypt config for %s: %s", flowJobName, err.Error()))
+		return nil
+	}
+
 	// Try unmarshaling
 	if err := proto.Unmarshal(configBytes, &config); err != nil {
 		slog.Warn(fmt.Sprintf("failed to unmarshal config for %s: %s", flowJobName, err.Error()))
@@ -358,18 +385,10 @@ func (h *FlowRequestHandler) isCDCFlow(ctx context.Context, flowJobName string)
 }
 
 func (h *FlowRequestHandler) getWorkflowStatus(ctx context.Context, workflowID string) (protos.FlowStatus, error) {
-	res, err := h.temporalClient.QueryWorkflow(ctx, workflowID, "", shared.FlowStatusQuery)
-	if err != nil {
-		slog.Error(fmt.Sprintf("failed to get status in workflow with ID %s: %s", workflowID, err.Error()))
-		return protos.FlowStatus_STATUS_UNKNOWN,
-			fmt.Errorf("failed to get status in workflow with ID %s: %w", workflowID, err)
-	}
-	var state protos.FlowStatus
-	err = res.Get(&state)
+	state, err := model.GetFlowStatus(ctx, h.temporalClient, workflowID)
 	if err != nil {
-		slog.Error(fmt.Sprintf("failed to get status in workflow with ID %s: %s", workflowID, err.Error()))
-		return protos.FlowStatus_STATUS_UNKNOWN,
-			fmt.Errorf("failed to get status in workflow with ID %s: %w", workflowID, err)
+		slog.Error(err.Error())
+		return protos.FlowStatus_STATUS_UNKNOWN, err
 	}
 	return state, nil
 }
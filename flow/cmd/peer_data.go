@@ -11,12 +11,14 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	connpostgres "github.com/PeerDB-io/peer-flow/connectors/postgres"
+	"github.com/PeerDB-io/peer-flow/connectors/utils"
 	"github.com/PeerDB-io/peer-flow/generated/protos"
+	"github.com/PeerDB-io/peer-flow/peerdbenv"
 	"github.com/PeerDB-io/peer-flow/shared"
 )
 
 func (h *FlowRequestHandler) getPGPeerConfig(ctx context.Context, peerName string) (*protos.PostgresConfig, error) {
-	var pgPeerOptions sql.RawBytes
+	var pgPeerOptions []byte
 	var pgPeerConfig protos.PostgresConfig
 	err := h.pool.QueryRow(ctx,
 		"SELECT options FROM peers WHERE name = $1 AND type=3", peerName).Scan(&pgPeerOptions)
@@ -24,12 +26,17 @@ func (h *FlowRequestHandler) getPGPeerConfig(ctx context.Context, peerName strin
 		return nil, err
 	}
 
+	pgPeerOptions, err = peerdbenv.DecryptCatalogData(pgPeerOptions)
+	if err != nil {
+		return nil, err
+	}
+
 	err = proto.Unmarshal(pgPeerOptions, &pgPeerConfig)
 	if err != nil {
 		return nil, err
 	}
 
-	return &pgPeerConfig, nil
+	return utils.ResolveConfigSecrets(ctx, &pgPeerConfig)
 }
 
 func (h *FlowRequestHandler) getConnForPGPeer(ctx context.Context, peerName string) (*connpostgres.SSHTunnel, *pgx.Conn, error) {
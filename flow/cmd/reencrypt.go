@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/PeerDB-io/peer-flow/peerdbenv"
+	"github.com/PeerDB-io/peer-flow/shared"
+)
+
+// catalog columns holding serialized peers, directly or embedded in mirror configs
+var encryptedCatalogColumns = []struct {
+	table  string
+	column string
+}{
+	{table: "peers", column: "options"},
+	{table: "flows", column: "config_proto"},
+	{table: "peerdb_stats.qrep_runs", column: "config_proto"},
+}
+
+// ReencryptCatalog rewrites every encrypted catalog blob with the current key,
+// run after rotating PEERDB_CURRENT_ENC_KEY_ID while the previous key is still in PEERDB_ENC_KEYS
+func ReencryptCatalog(ctx context.Context) error {
+	keys, err := peerdbenv.PeerDBEncKeys()
+	if err != nil {
+		return err
+	}
+	currentID := peerdbenv.PeerDBCurrentEncKeyID()
+
+	pool, err := peerdbenv.GetCatalogConnectionPoolFromEnv(ctx)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer shared.RollbackTx(tx, slog.Default())
+
+	for _, col := range encryptedCatalogColumns {
+		updated, err := reencryptColumn(ctx, tx, keys, currentID, col.table, col.column)
+		if err != nil {
+			return fmt.Errorf("failed to re-encrypt %s.%s: %w", col.table, col.column, err)
+		}
+		slog.Info("re-encrypted catalog column",
+			slog.String("table", col.table), slog.String("column", col.column), slog.Int("rows", updated))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func reencryptColumn(
+	ctx context.Context,
+	tx pgx.Tx,
+	keys peerdbenv.EncKeys,
+	currentID string,
+	table string,
+	column string,
+) (int, error) {
+	type blobRow struct {
+		ctid pgtype.TID
+		data []byte
+	}
+
+	//nolint:gosec
+	rows, err := tx.Query(ctx, fmt.Sprintf("SELECT ctid, %s FROM %s WHERE %s IS NOT NULL FOR UPDATE",
+		column, table, column))
+	if err != nil {
+		return 0, err
+	}
+	blobs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (blobRow, error) {
+		var r blobRow
+		err := row.Scan(&r.ctid, &r.data)
+		return r, err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, blob := range blobs {
+		keyID, _ := peerdbenv.EncKeyID(blob.data)
+		if keyID == currentID {
+			continue
+		}
+		plaintext, err := keys.Decrypt(blob.data)
+		if err != nil {
+			return 0, err
+		}
+		ciphertext, err := keys.Encrypt(currentID, plaintext)
+		if err != nil {
+			return 0, err
+		}
+		//nolint:gosec
+		if _, err := tx.Exec(ctx, fmt.Sprintf("UPDATE %s SET %s = $1 WHERE ctid = $2", table, column),
+			ciphertext, blob.ctid); err != nil {
+			return 0, err
+		}
+		updated += 1
+	}
+	return updated, nil
+}
@@ -11,6 +11,7 @@ import (
 	connpostgres "github.com/PeerDB-io/peer-flow/connectors/postgres"
 	"github.com/PeerDB-io/peer-flow/connectors/utils"
 	"github.com/PeerDB-io/peer-flow/generated/protos"
+	"github.com/PeerDB-io/peer-flow/shared"
 	"github.com/PeerDB-io/peer-flow/shared/telemetry"
 )
 
@@ -43,12 +44,51 @@ func (h *FlowRequestHandler) ValidateCDCMirror(
 			Ok: false,
 		}, errors.New("connection configs is nil")
 	}
+	if err := validateAdditionalDestinations(req.ConnectionConfigs); err != nil {
+		h.alerter.LogNonFlowWarning(ctx, telemetry.CreateMirror, req.ConnectionConfigs.FlowJobName, err.Error())
+		return &protos.ValidateCDCMirrorResponse{
+			Ok: false,
+		}, err
+	}
+	if err := validateNormalizeModes(req.ConnectionConfigs); err != nil {
+		h.alerter.LogNonFlowWarning(ctx, telemetry.CreateMirror, req.ConnectionConfigs.FlowJobName, err.Error())
+		return &protos.ValidateCDCMirrorResponse{
+			Ok: false,
+		}, err
+	}
+	if err := validateReplicationOrigin(ctx, req.ConnectionConfigs); err != nil {
+		h.alerter.LogNonFlowWarning(ctx, telemetry.CreateMirror, req.ConnectionConfigs.FlowJobName, err.Error())
+		return &protos.ValidateCDCMirrorResponse{
+			Ok: false,
+		}, err
+	}
+	if req.ConnectionConfigs.BinaryTransfer && req.ConnectionConfigs.System == protos.TypeSystem_PG {
+		err := errors.New("binary transfer is not supported for mirrors with the PG type system")
+		h.alerter.LogNonFlowWarning(ctx, telemetry.CreateMirror, req.ConnectionConfigs.FlowJobName, err.Error())
+		return &protos.ValidateCDCMirrorResponse{
+			Ok: false,
+		}, err
+	}
+	if err := validateTwoPhase(req.ConnectionConfigs); err != nil {
+		h.alerter.LogNonFlowWarning(ctx, telemetry.CreateMirror, req.ConnectionConfigs.FlowJobName, err.Error())
+		return &protos.ValidateCDCMirrorResponse{
+			Ok: false,
+		}, err
+	}
+
 	sourcePeerConfig := req.ConnectionConfigs.Source.GetPostgresConfig()
 	if sourcePeerConfig == nil {
 		slog.Error("/validatecdc source peer config is nil", slog.Any("peer", req.ConnectionConfigs.Source))
 		return nil, errors.New("source peer config is nil")
 	}
 
+	sourcePeerConfig, err := utils.ResolveConfigSecrets(ctx, sourcePeerConfig)
+	if err != nil {
+		return &protos.ValidateCDCMirrorResponse{
+			Ok: false,
+		}, err
+	}
+
 	pgPeer, err := connpostgres.NewPostgresConnector(ctx, sourcePeerConfig)
 	if err != nil {
 		displayErr := fmt.Errorf("failed to create postgres connector: %v", err)
@@ -85,6 +125,40 @@ func (h *FlowRequestHandler) ValidateCDCMirror(
 		}, displayErr
 	}
 
+	err = pgPeer.CheckReplicationRole(ctx, req.ConnectionConfigs.PublicationName, req.ConnectionConfigs.FailoverSlot)
+	if err != nil {
+		displayErr := fmt.Errorf("failed to validate replication role: %v", err)
+		h.alerter.LogNonFlowWarning(ctx, telemetry.CreateMirror, req.ConnectionConfigs.FlowJobName,
+			fmt.Sprint(displayErr),
+		)
+		return &protos.ValidateCDCMirrorResponse{
+			Ok: false,
+		}, displayErr
+	}
+
+	if req.ConnectionConfigs.TwoPhase || req.ConnectionConfigs.BinaryTransfer {
+		pgversion, err := pgPeer.MajorVersion(ctx)
+		if err != nil {
+			return &protos.ValidateCDCMirrorResponse{
+				Ok: false,
+			}, err
+		}
+		var displayErr error
+		if req.ConnectionConfigs.TwoPhase && pgversion < shared.POSTGRES_15 {
+			displayErr = fmt.Errorf("two-phase decoding needs Postgres 15 or later, found %d", pgversion)
+		} else if req.ConnectionConfigs.BinaryTransfer && pgversion < shared.POSTGRES_14 {
+			displayErr = fmt.Errorf("binary transfer needs Postgres 14 or later, found %d", pgversion)
+		}
+		if displayErr != nil {
+			h.alerter.LogNonFlowWarning(ctx, telemetry.CreateMirror, req.ConnectionConfigs.FlowJobName,
+				fmt.Sprint(displayErr),
+			)
+			return &protos.ValidateCDCMirrorResponse{
+				Ok: false,
+			}, displayErr
+		}
+	}
+
 	// Check source tables
 	sourceTables := make([]*utils.SchemaTable, 0, len(req.ConnectionConfigs.TableMappings))
 	for _, tableMapping := range req.ConnectionConfigs.TableMappings {
@@ -102,6 +176,18 @@ func (h *FlowRequestHandler) ValidateCDCMirror(
 		sourceTables = append(sourceTables, parsedTable)
 	}
 
+	if req.ConnectionConfigs.SyncSequences {
+		if err := pgPeer.CheckSequencePermissions(ctx, sourceTables); err != nil {
+			displayErr := fmt.Errorf("failed to validate sequence permissions: %v", err)
+			h.alerter.LogNonFlowWarning(ctx, telemetry.CreateMirror, req.ConnectionConfigs.FlowJobName,
+				fmt.Sprint(displayErr),
+			)
+			return &protos.ValidateCDCMirrorResponse{
+				Ok: false,
+			}, displayErr
+		}
+	}
+
 	pubName := req.ConnectionConfigs.PublicationName
 	if pubName != "" {
 		err = pgPeer.CheckSourceTables(ctx, sourceTables, pubName)
@@ -130,3 +216,108 @@ func (h *FlowRequestHandler) CheckIfMirrorNameExists(ctx context.Context, mirror
 
 	return nameExists.Bool, nil
 }
+
+// the sync state of each destination is keyed by its peer, so each needs a peer of its own
+func validateAdditionalDestinations(cfg *protos.FlowConnectionConfigs) error {
+	seen := map[string]struct{}{cfg.Destination.GetName(): {}}
+	for _, destination := range cfg.AdditionalDestinations {
+		if _, ok := seen[destination.Name]; ok {
+			return fmt.Errorf("destination peer %s is used more than once", destination.Name)
+		}
+		seen[destination.Name] = struct{}{}
+		if cfg.System == protos.TypeSystem_PG && destination.Type != protos.DBType_POSTGRES {
+			return fmt.Errorf("destination peer %s must be postgres for a mirror with the PG type system", destination.Name)
+		}
+	}
+	return nil
+}
+
+// mirrors with a replication origin resolve conflicting writes by commit time, which destinations must track
+func validateReplicationOrigin(ctx context.Context, cfg *protos.FlowConnectionConfigs) error {
+	if !cfg.ReplicationOrigin {
+		return nil
+	}
+	for _, dstConfig := range shared.DestinationConfigs(cfg) {
+		if dstConfig.Destination.GetPostgresConfig() == nil {
+			continue
+		}
+		if err := checkCommitTimestamps(ctx, dstConfig.Destination.GetPostgresConfig()); err != nil {
+			return fmt.Errorf("destination peer %s: %w", dstConfig.Destination.Name, err)
+		}
+	}
+	return nil
+}
+
+func checkCommitTimestamps(ctx context.Context, pgConfig *protos.PostgresConfig) error {
+	pgConfig, err := utils.ResolveConfigSecrets(ctx, pgConfig)
+	if err != nil {
+		return err
+	}
+	pgConn, err := connpostgres.NewPostgresConnector(ctx, pgConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create postgres connector: %w", err)
+	}
+	defer pgConn.Close()
+	return pgConn.CheckCommitTimestamps(ctx)
+}
+
+// a mirror keeps at least one table, removing every table is dropping the mirror
+func validateRemovedTables(tableMappings []*protos.TableMapping, removedTables []*protos.TableMapping) error {
+	if len(removedTables) == 0 {
+		return nil
+	}
+	removedSrcTables := make(map[string]struct{}, len(removedTables))
+	for _, tableMapping := range removedTables {
+		removedSrcTables[tableMapping.SourceTableIdentifier] = struct{}{}
+	}
+	for _, tableMapping := range tableMappings {
+		if _, ok := removedSrcTables[tableMapping.SourceTableIdentifier]; !ok {
+			return nil
+		}
+	}
+	return errors.New("cannot remove every table of a mirror, drop the mirror instead")
+}
+
+// two-phase decoding holds the checkpoint before pending PREPAREs, destinations which checkpoint
+// each record as it's sent would move past them and lose prepared transactions on restart
+func validateTwoPhase(cfg *protos.FlowConnectionConfigs) error {
+	if !cfg.TwoPhase {
+		return nil
+	}
+	if cfg.FailoverSlot {
+		return errors.New("two-phase decoding can't be combined with a failover slot")
+	}
+	for _, dstConfig := range shared.DestinationConfigs(cfg) {
+		switch dstConfig.Destination.GetType() {
+		case protos.DBType_KAFKA, protos.DBType_PUBSUB, protos.DBType_EVENTHUBS,
+			protos.DBType_ELASTICSEARCH, protos.DBType_WEBHOOK:
+			return fmt.Errorf("two-phase decoding is not supported for peer %s", dstConfig.Destination.GetName())
+		}
+	}
+	return nil
+}
+
+// history normalize modes are implemented for a subset of destinations, ClickHouse only keeps a change log
+func validateNormalizeModes(cfg *protos.FlowConnectionConfigs) error {
+	modes := shared.NormalizeModes(cfg.NormalizeMode, cfg.TableMappings)
+	for _, dstConfig := range shared.DestinationConfigs(cfg) {
+		destination := dstConfig.Destination
+		for dstTable, mode := range modes {
+			if mode == protos.NormalizeMode_NORMALIZE_MODE_MERGE {
+				continue
+			}
+			switch destination.GetType() {
+			case protos.DBType_POSTGRES, protos.DBType_SNOWFLAKE, protos.DBType_BIGQUERY:
+			case protos.DBType_CLICKHOUSE:
+				if mode == protos.NormalizeMode_NORMALIZE_MODE_SCD2 {
+					return fmt.Errorf("table %s: SCD2 normalize mode is not supported for ClickHouse peer %s",
+						dstTable, destination.Name)
+				}
+			default:
+				return fmt.Errorf("table %s: normalize mode %s is not supported for peer %s",
+					dstTable, mode, destination.Name)
+			}
+		}
+	}
+	return nil
+}
@@ -11,14 +11,18 @@ import (
 
 	"github.com/grafana/pyroscope-go"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/interceptor"
 	"go.temporal.io/sdk/worker"
 
 	"github.com/PeerDB-io/peer-flow/activities"
 	"github.com/PeerDB-io/peer-flow/alerting"
 	"github.com/PeerDB-io/peer-flow/logger"
 	"github.com/PeerDB-io/peer-flow/otel_metrics"
+	"github.com/PeerDB-io/peer-flow/otel_tracing"
 	"github.com/PeerDB-io/peer-flow/peerdbenv"
+	"github.com/PeerDB-io/peer-flow/prom_metrics"
 	"github.com/PeerDB-io/peer-flow/shared"
 	peerflow "github.com/PeerDB-io/peer-flow/workflows"
 )
@@ -31,8 +35,11 @@ type WorkerSetupOptions struct {
 	TemporalKey                        string
 	TemporalMaxConcurrentActivities    int
 	TemporalMaxConcurrentWorkflowTasks int
+	PrometheusMetricsPort              uint16
 	EnableProfiling                    bool
 	EnableOtelMetrics                  bool
+	EnableOtelTracing                  bool
+	EnablePrometheusMetrics            bool
 }
 
 type workerSetupResponse struct {
@@ -108,6 +115,21 @@ func WorkerSetup(opts *WorkerSetupOptions) (*workerSetupResponse, error) {
 		clientOptions.ConnectionOptions = connOptions
 	}
 
+	var tracerProvider *sdktrace.TracerProvider
+	if opts.EnableOtelTracing {
+		var err error
+		tracerProvider, err = otel_tracing.SetupOtelTraceExporter("flow-worker")
+		if err != nil {
+			return nil, err
+		}
+		tracingInterceptor, err := otel_tracing.NewTemporalTracingInterceptor()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create tracing interceptor: %w", err)
+		}
+		// workers created from the client use its interceptors as well
+		clientOptions.Interceptors = []interceptor.ClientInterceptor{tracingInterceptor}
+	}
+
 	conn, err := peerdbenv.GetCatalogConnectionPoolFromEnv(context.Background())
 	if err != nil {
 		return nil, fmt.Errorf("unable to create catalog connection pool: %w", err)
@@ -151,11 +173,16 @@ func WorkerSetup(opts *WorkerSetupOptions) (*workerSetupResponse, error) {
 			Int64GaugesCache:   make(map[string]*otel_metrics.Int64Gauge),
 		}
 	}
+	var stopPrometheusServer func()
+	if opts.EnablePrometheusMetrics {
+		stopPrometheusServer = prom_metrics.StartServer(opts.PrometheusMetricsPort)
+	}
 	w.RegisterActivity(&activities.FlowableActivity{
-		CatalogPool: conn,
-		Alerter:     alerting.NewAlerter(context.Background(), conn),
-		CdcCache:    make(map[string]activities.CdcCacheEntry),
-		OtelManager: otelManager,
+		CatalogPool:    conn,
+		Alerter:        alerting.NewAlerter(context.Background(), conn),
+		CdcCache:       make(map[string]activities.CdcCacheEntry),
+		OtelManager:    otelManager,
+		TemporalClient: c,
 	})
 
 	return &workerSetupResponse{
@@ -168,6 +195,15 @@ func WorkerSetup(opts *WorkerSetupOptions) (*workerSetupResponse, error) {
 					slog.Error("Failed to shutdown metrics provider", slog.Any("error", err))
 				}
 			}
+			if tracerProvider != nil {
+				err := tracerProvider.Shutdown(context.Background())
+				if err != nil {
+					slog.Error("Failed to shutdown tracer provider", slog.Any("error", err))
+				}
+			}
+			if stopPrometheusServer != nil {
+				stopPrometheusServer()
+			}
 			c.Close()
 		},
 	}, nil
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 
@@ -18,12 +19,11 @@ import (
 
 	metadataStore "github.com/PeerDB-io/peer-flow/connectors/external_metadata"
 	"github.com/PeerDB-io/peer-flow/connectors/utils"
-	numeric "github.com/PeerDB-io/peer-flow/datatypes"
 	"github.com/PeerDB-io/peer-flow/dynamicconf"
 	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/logger"
 	"github.com/PeerDB-io/peer-flow/model"
-	"github.com/PeerDB-io/peer-flow/model/qvalue"
+	"github.com/PeerDB-io/peer-flow/otel_tracing"
 	"github.com/PeerDB-io/peer-flow/peerdbenv"
 	"github.com/PeerDB-io/peer-flow/shared"
 )
@@ -154,7 +154,7 @@ func NewBigQueryConnector(ctx context.Context, config *protos.BigqueryConfig) (*
 		client:           client,
 		datasetID:        datasetID,
 		projectID:        projectID,
-		PostgresMetadata: metadataStore.NewPostgresMetadataFromCatalog(logger, catalogPool),
+		PostgresMetadata: metadataStore.NewPostgresMetadataFromCatalog(ctx, logger, catalogPool),
 		storageClient:    storageClient,
 		catalogPool:      catalogPool,
 		logger:           logger,
@@ -233,7 +233,8 @@ func (c *BigQueryConnector) ReplayTableSchemaDeltas(
 				}
 			}
 
-			addedColumnBigQueryType := qValueKindToBigQueryTypeString(addedColumn.Type)
+			addedColumnBigQueryType := bigQueryTypeString(bigQueryFieldSchema(
+				addedColumn.Name, addedColumn.Type, addedColumn.TypeModifier, addedColumn.Fields))
 			query := c.client.Query(fmt.Sprintf(
 				"ALTER TABLE %s ADD COLUMN IF NOT EXISTS `%s` %s",
 				dstDatasetTable.table, addedColumn.Name, addedColumnBigQueryType))
@@ -364,6 +365,7 @@ func (c *BigQueryConnector) syncRecordsViaAvro(
 ) (*model.SyncResponse, error) {
 	tableNameRowsMapping := utils.InitialiseTableRowsMap(req.TableMappings)
 	streamReq := model.NewRecordsToStreamRequest(req.Records.GetRecords(), tableNameRowsMapping, syncBatchID)
+	streamReq.CommitInfo = req.CommitInfo
 	stream, err := utils.RecordsToRawTableStream(streamReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert records to raw table stream: %w", err)
@@ -405,7 +407,7 @@ func (c *BigQueryConnector) NormalizeRecords(ctx context.Context, req *model.Nor
 
 	for batchId := normBatchID + 1; batchId <= req.SyncBatchID; batchId++ {
 		mergeErr := c.mergeTablesInThisBatch(ctx, batchId,
-			req.FlowJobName, rawTableName, req.TableNameSchemaMapping,
+			req.FlowJobName, rawTableName, req.TableNameSchemaMapping, req.NormalizeModes,
 			&protos.PeerDBColumns{
 				SoftDeleteColName: req.SoftDeleteColName,
 				SyncedAtColName:   req.SyncedAtColName,
@@ -434,6 +436,7 @@ func (c *BigQueryConnector) mergeTablesInThisBatch(
 	flowName string,
 	rawTableName string,
 	tableToSchema map[string]*protos.TableSchema,
+	normalizeModes map[string]protos.NormalizeMode,
 	peerdbColumns *protos.PeerDBColumns,
 ) error {
 	tableNames, err := c.getDistinctTableNamesInBatch(
@@ -444,6 +447,7 @@ func (c *BigQueryConnector) mergeTablesInThisBatch(
 	if err != nil {
 		return fmt.Errorf("couldn't get distinct table names to normalize: %w", err)
 	}
+	tableNames = utils.TablesToNormalize(tableNames, tableToSchema, c.logger)
 
 	tableNametoUnchangedToastCols, err := c.getTableNametoUnchangedCols(
 		ctx,
@@ -464,12 +468,35 @@ func (c *BigQueryConnector) mergeTablesInThisBatch(
 		mergeBatchId:       batchId,
 		peerdbCols:         peerdbColumns,
 		shortColumn:        map[string]string{},
+		normalizeModes:     normalizeModes,
 	}
 
 	for _, tableName := range tableNames {
 		unchangedToastColumns := tableNametoUnchangedToastCols[tableName]
 		dstDatasetTable, _ := c.convertToDatasetTable(tableName)
 
+		// history modes leave unchanged toast columns NULL, so the whole batch goes in one statement
+		var historyStmt string
+		switch normalizeModes[tableName] {
+		case protos.NormalizeMode_NORMALIZE_MODE_CHANGELOG:
+			historyStmt = mergeGen.generateChangelogStmt(tableName, dstDatasetTable)
+		case protos.NormalizeMode_NORMALIZE_MODE_SCD2:
+			historyStmt = mergeGen.generateSCD2Stmt(tableName, dstDatasetTable)
+		}
+		if historyStmt != "" {
+			c.logger.Info("running history statement for table " + tableName)
+			q := c.client.Query(historyStmt)
+			q.DefaultProjectID = c.projectID
+			q.DefaultDatasetID = dstDatasetTable.dataset
+			stmtCtx, span := otel_tracing.Start(ctx, "bigquery.HistoryStatement", otel_tracing.Table(tableName))
+			_, err := q.Read(stmtCtx)
+			otel_tracing.End(span, err)
+			if err != nil {
+				return fmt.Errorf("failed to execute history statement %s: %v", historyStmt, err)
+			}
+			continue
+		}
+
 		// normalize anything between last normalized batch id to last sync batchid
 		// TODO (kaushik): This is so that the statement size for individual merge statements
 		// doesn't exceed the limit. We should make this configurable.
@@ -484,7 +511,9 @@ func (c *BigQueryConnector) mergeTablesInThisBatch(
 			q := c.client.Query(mergeStmt)
 			q.DefaultProjectID = c.projectID
 			q.DefaultDatasetID = dstDatasetTable.dataset
-			_, err := q.Read(ctx)
+			stmtCtx, span := otel_tracing.Start(ctx, "bigquery.MergeStatement", otel_tracing.Table(tableName))
+			_, err := q.Read(stmtCtx)
+			otel_tracing.End(span, err)
 			if err != nil {
 				return fmt.Errorf("failed to execute merge statement %s: %v", mergeStmt, err)
 			}
@@ -521,6 +550,13 @@ func (c *BigQueryConnector) CreateRawTable(ctx context.Context, req *protos.Crea
 		{Name: "_peerdb_batch_id", Type: bigquery.IntegerFieldType},
 		{Name: "_peerdb_unchanged_toast_columns", Type: bigquery.StringFieldType},
 	}
+	baseSchema := schema
+	if req.CommitInfo {
+		schema = append(slices.Clone(schema),
+			&bigquery.FieldSchema{Name: shared.RawCommitLSNColName, Type: bigquery.IntegerFieldType},
+			&bigquery.FieldSchema{Name: shared.RawCommitTimeNsColName, Type: bigquery.IntegerFieldType},
+		)
+	}
 
 	// create the table
 	table := c.client.DatasetInProject(c.projectID, c.datasetID).Table(rawTableName)
@@ -528,6 +564,16 @@ func (c *BigQueryConnector) CreateRawTable(ctx context.Context, req *protos.Crea
 	// check if the table exists
 	tableRef, err := table.Metadata(ctx)
 	if err == nil {
+		// raw tables created before history modes were in use lack the commit columns
+		if req.CommitInfo && reflect.DeepEqual(tableRef.Schema, baseSchema) {
+			_, err := table.Update(ctx, bigquery.TableMetadataToUpdate{Schema: schema}, tableRef.ETag)
+			if err != nil {
+				return nil, fmt.Errorf("failed to add commit columns to table %s.%s: %w", c.datasetID, rawTableName, err)
+			}
+			return &protos.CreateRawTableOutput{
+				TableIdentifier: rawTableName,
+			}, nil
+		}
 		// table exists, check if the schema matches
 		if !reflect.DeepEqual(tableRef.Schema, schema) {
 			return nil, fmt.Errorf("table %s.%s already exists with different schema", c.datasetID, rawTableName)
@@ -589,10 +635,9 @@ func (c *BigQueryConnector) CleanupSetupNormalizedTables(_ context.Context, _ in
 func (c *BigQueryConnector) SetupNormalizedTable(
 	ctx context.Context,
 	tx interface{},
+	config *protos.SetupNormalizedTableBatchInput,
 	tableIdentifier string,
 	tableSchema *protos.TableSchema,
-	softDeleteColName string,
-	syncedAtColName string,
 ) (bool, error) {
 	datasetTablesSet := tx.(map[datasetTable]struct{})
 
@@ -632,39 +677,49 @@ func (c *BigQueryConnector) SetupNormalizedTable(
 	}
 
 	// convert the column names and types to bigquery types
-	columns := make([]*bigquery.FieldSchema, 0, len(tableSchema.Columns)+2)
+	columns := make([]*bigquery.FieldSchema, 0, len(tableSchema.Columns)+6)
 	for _, column := range tableSchema.Columns {
-		genericColType := column.Type
-		if genericColType == "numeric" {
-			precision, scale := numeric.GetNumericTypeForWarehouse(column.TypeModifier, numeric.BigQueryNumericCompatibility{})
-			columns = append(columns, &bigquery.FieldSchema{
-				Name:      column.Name,
-				Type:      bigquery.BigNumericFieldType,
-				Repeated:  qvalue.QValueKind(genericColType).IsArray(),
-				Precision: int64(precision),
-				Scale:     int64(scale),
-			})
-		} else {
-			columns = append(columns, &bigquery.FieldSchema{
-				Name:     column.Name,
-				Type:     qValueKindToBigQueryType(genericColType),
-				Repeated: qvalue.QValueKind(genericColType).IsArray(),
-			})
-		}
+		columns = append(columns, bigQueryFieldSchema(column.Name, column.Type, column.TypeModifier, column.Fields))
+	}
+
+	// history modes keep one row per change or version instead of soft deleting
+	normalizeMode := shared.NormalizeModeForTable(config.NormalizeMode, config.TableMappings, tableIdentifier)
+	switch normalizeMode {
+	case protos.NormalizeMode_NORMALIZE_MODE_CHANGELOG:
+		columns = append(columns,
+			&bigquery.FieldSchema{Name: shared.HistoryOpColName, Type: bigquery.StringFieldType},
+			&bigquery.FieldSchema{Name: shared.HistoryCommitTimeColName, Type: bigquery.TimestampFieldType},
+			&bigquery.FieldSchema{Name: shared.HistoryLSNColName, Type: bigquery.IntegerFieldType},
+			&bigquery.FieldSchema{Name: shared.HistoryBeforeColName, Type: bigquery.JSONFieldType},
+			&bigquery.FieldSchema{Name: shared.HistoryBatchIDColName, Type: bigquery.IntegerFieldType},
+		)
+	case protos.NormalizeMode_NORMALIZE_MODE_SCD2:
+		// rows of the initial load are the current version of their key
+		columns = append(columns,
+			&bigquery.FieldSchema{Name: shared.HistoryLSNColName, Type: bigquery.IntegerFieldType},
+			&bigquery.FieldSchema{Name: shared.SCD2ValidFromColName, Type: bigquery.TimestampFieldType},
+			&bigquery.FieldSchema{Name: shared.SCD2ValidToColName, Type: bigquery.TimestampFieldType},
+			&bigquery.FieldSchema{
+				Name:                   shared.SCD2IsCurrentColName,
+				Type:                   bigquery.BooleanFieldType,
+				DefaultValueExpression: "true",
+			},
+			&bigquery.FieldSchema{Name: shared.HistoryBatchIDColName, Type: bigquery.IntegerFieldType},
+		)
 	}
 
-	if softDeleteColName != "" {
+	if config.SoftDeleteColName != "" && normalizeMode == protos.NormalizeMode_NORMALIZE_MODE_MERGE {
 		columns = append(columns, &bigquery.FieldSchema{
-			Name:                   softDeleteColName,
+			Name:                   config.SoftDeleteColName,
 			Type:                   bigquery.BooleanFieldType,
 			Repeated:               false,
 			DefaultValueExpression: "false",
 		})
 	}
 
-	if syncedAtColName != "" {
+	if config.SyncedAtColName != "" {
 		columns = append(columns, &bigquery.FieldSchema{
-			Name:     syncedAtColName,
+			Name:     config.SyncedAtColName,
 			Type:     bigquery.TimestampFieldType,
 			Repeated: false,
 		})
@@ -674,9 +729,14 @@ func (c *BigQueryConnector) SetupNormalizedTable(
 	schema := bigquery.Schema(columns)
 
 	// cluster by the primary key if < 4 columns.
+	// change logs are only appended to, clustering by batch lets a retried batch find its rows without a full scan
 	var clustering *bigquery.Clustering
 	numPkeyCols := len(tableSchema.PrimaryKeyColumns)
-	if numPkeyCols > 0 && numPkeyCols < 4 {
+	if normalizeMode == protos.NormalizeMode_NORMALIZE_MODE_CHANGELOG {
+		clustering = &bigquery.Clustering{
+			Fields: []string{shared.HistoryBatchIDColName},
+		}
+	} else if numPkeyCols > 0 && numPkeyCols < 4 {
 		clustering = &bigquery.Clustering{
 			Fields: tableSchema.PrimaryKeyColumns,
 		}
@@ -684,10 +744,10 @@ func (c *BigQueryConnector) SetupNormalizedTable(
 
 	timePartitionEnabled := dynamicconf.PeerDBBigQueryEnableSyncedAtPartitioning(ctx)
 	var timePartitioning *bigquery.TimePartitioning
-	if timePartitionEnabled && syncedAtColName != "" {
+	if timePartitionEnabled && config.SyncedAtColName != "" {
 		timePartitioning = &bigquery.TimePartitioning{
 			Type:  bigquery.DayPartitioningType,
-			Field: syncedAtColName,
+			Field: config.SyncedAtColName,
 		}
 	}
 
@@ -932,3 +992,31 @@ func (c *BigQueryConnector) convertToDatasetTable(tableName string) (datasetTabl
 		return datasetTable{}, fmt.Errorf("invalid BigQuery table name: %s", tableName)
 	}
 }
+
+// RemoveTables deletes rows of tables no longer part of the mirror from the raw table and drops the tables
+func (c *BigQueryConnector) RemoveTables(ctx context.Context, req *protos.RemoveTablesInput) error {
+	rawTableName := c.getRawTableName(req.FlowJobName)
+	q := c.client.Query(fmt.Sprintf("DELETE FROM `%s` WHERE _peerdb_destination_table_name IN UNNEST(@tables)",
+		rawTableName))
+	q.DefaultProjectID = c.projectID
+	q.DefaultDatasetID = c.datasetID
+	q.Parameters = []bigquery.QueryParameter{{Name: "tables", Value: req.DestinationTableIdentifiers}}
+	if _, err := q.Read(ctx); err != nil {
+		return fmt.Errorf("failed to delete rows of removed tables from raw table %s: %w", rawTableName, err)
+	}
+	if req.KeepTables {
+		return nil
+	}
+
+	for _, tableIdentifier := range req.DestinationTableIdentifiers {
+		dstDatasetTable, err := c.convertToDatasetTable(tableIdentifier)
+		if err != nil {
+			return err
+		}
+		table := c.client.DatasetInProject(c.projectID, dstDatasetTable.dataset).Table(dstDatasetTable.table)
+		if err := table.Delete(ctx); err != nil && !strings.Contains(err.Error(), "notFound") {
+			return fmt.Errorf("failed to drop table %s: %w", tableIdentifier, err)
+		}
+	}
+	return nil
+}
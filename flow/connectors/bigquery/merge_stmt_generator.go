@@ -2,6 +2,7 @@ package connbigquery
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/PeerDB-io/peer-flow/generated/protos"
@@ -20,6 +21,54 @@ type mergeStmtGenerator struct {
 	rawDatasetTable datasetTable
 	// batch id currently to be merged
 	mergeBatchId int64
+	// tables normalized as history instead of being merged
+	normalizeModes map[string]protos.NormalizeMode
+}
+
+// rangeStruct builds the record of a range of kind from jsonRange, the object written by QValueRange
+func rangeStruct(jsonRange string, kind qvalue.QValueKind) string {
+	boundType := qValueKindToBigQueryTypeString(string(kind.RangeElementKind()))
+	return fmt.Sprintf("STRUCT(CAST(JSON_VALUE(%[1]s, '$.lower') AS %[2]s) AS lower,CAST(JSON_VALUE(%[1]s, '$.upper') AS %[2]s) AS upper,"+
+		"CAST(JSON_VALUE(%[1]s, '$.lower_inclusive') AS BOOL) AS lower_inclusive,"+
+		"CAST(JSON_VALUE(%[1]s, '$.upper_inclusive') AS BOOL) AS upper_inclusive,"+
+		"CAST(JSON_VALUE(%[1]s, '$.empty') AS BOOL) AS empty)", jsonRange, boundType)
+}
+
+// nestedValue builds a value of kind from jsonExpr, a JSON string formatted by the JSONValue of composites and arrays,
+// fields are the members of composites or of the composite elements of arrays
+func nestedValue(jsonExpr string, kind qvalue.QValueKind, fields []*protos.FieldDescription) string {
+	switch {
+	case kind.IsRange():
+		// empty is set on every range, so it is only missing when the range is null
+		return fmt.Sprintf("IF(JSON_VALUE(%s, '$.empty') IS NULL, NULL, %s)", jsonExpr, rangeStruct(jsonExpr, kind))
+	case kind.IsMultirange():
+		return fmt.Sprintf("ARRAY(SELECT %s FROM UNNEST(JSON_QUERY_ARRAY(%s)) AS r)", rangeStruct("r", kind.RangeKind()), jsonExpr)
+	case kind.IsArray():
+		// BigQuery arrays can't hold nulls
+		return fmt.Sprintf("ARRAY(SELECT %s FROM UNNEST(JSON_QUERY_ARRAY(%s)) AS e WHERE e != 'null')",
+			nestedValue("e", kind.ArrayElementKind(), fields), jsonExpr)
+	case kind == qvalue.QValueKindStruct && len(fields) > 0:
+		members := make([]string, 0, len(fields))
+		for _, field := range fields {
+			members = append(members, fmt.Sprintf("%s AS `%s`",
+				nestedValue(fmt.Sprintf("JSON_QUERY(%s, '$.%s')", jsonExpr, field.Name), qvalue.QValueKind(field.Type), field.Fields),
+				field.Name))
+		}
+		return fmt.Sprintf("IF(%[1]s IS NULL OR %[1]s = 'null', NULL, STRUCT(%[2]s))", jsonExpr, strings.Join(members, ","))
+	}
+
+	switch kind {
+	case qvalue.QValueKindJSON, qvalue.QValueKindHStore:
+		return fmt.Sprintf("PARSE_JSON(JSON_VALUE(%s),wide_number_mode=>'round')", jsonExpr)
+	case qvalue.QValueKindStruct:
+		return fmt.Sprintf("PARSE_JSON(%s,wide_number_mode=>'round')", jsonExpr)
+	case qvalue.QValueKindBytes, qvalue.QValueKindBit:
+		return fmt.Sprintf("FROM_BASE64(JSON_VALUE(%s))", jsonExpr)
+	case qvalue.QValueKindGeography, qvalue.QValueKindGeometry, qvalue.QValueKindPoint:
+		return fmt.Sprintf("ST_GEOGFROMTEXT(JSON_VALUE(%s))", jsonExpr)
+	default:
+		return fmt.Sprintf("CAST(JSON_VALUE(%s) AS %s)", jsonExpr, qValueKindToBigQueryTypeString(string(kind)))
+	}
 }
 
 // generateFlattenedCTE generates a flattened CTE.
@@ -45,7 +94,7 @@ func (m *mergeStmtGenerator) generateFlattenedCTE(dstTable string, normalizedTab
 		case qvalue.QValueKindArrayFloat32, qvalue.QValueKindArrayFloat64, qvalue.QValueKindArrayInt16,
 			qvalue.QValueKindArrayInt32, qvalue.QValueKindArrayInt64, qvalue.QValueKindArrayString,
 			qvalue.QValueKindArrayBoolean, qvalue.QValueKindArrayTimestamp, qvalue.QValueKindArrayTimestampTZ,
-			qvalue.QValueKindArrayDate:
+			qvalue.QValueKindArrayDate, qvalue.QValueKindVector:
 			castStmt = fmt.Sprintf("ARRAY(SELECT CAST(element AS %s) FROM "+
 				"UNNEST(CAST(JSON_VALUE_ARRAY(_peerdb_data, '$.%s') AS ARRAY<STRING>)) AS element WHERE element IS NOT null) AS `%s`",
 				bqTypeString, column.Name, shortCol)
@@ -68,8 +117,15 @@ func (m *mergeStmtGenerator) generateFlattenedCTE(dstTable string, normalizedTab
 		// 		" AS int64))) AS %s",
 		// 		column.Name, column.Name)
 		default:
-			castStmt = fmt.Sprintf("CAST(JSON_VALUE(_peerdb_data, '$.%s') AS %s) AS `%s`",
-				column.Name, bqTypeString, shortCol)
+			kind := qvalue.QValueKind(colType)
+			switch {
+			case kind.IsRange() || kind.IsMultirange() || kind.IsGenericArray() || kind == qvalue.QValueKindStruct:
+				castStmt = fmt.Sprintf("%s AS `%s`",
+					nestedValue(fmt.Sprintf("JSON_QUERY(_peerdb_data, '$.%s')", column.Name), kind, column.Fields), shortCol)
+			default:
+				castStmt = fmt.Sprintf("CAST(JSON_VALUE(_peerdb_data, '$.%s') AS %s) AS `%s`",
+					column.Name, bqTypeString, shortCol)
+			}
 		}
 		flattenedProjs = append(flattenedProjs, castStmt)
 	}
@@ -79,6 +135,14 @@ func (m *mergeStmtGenerator) generateFlattenedCTE(dstTable string, normalizedTab
 		"_peerdb_record_type AS _rt",
 		"_peerdb_unchanged_toast_columns AS _ut",
 	)
+	if m.normalizeModes[dstTable] != protos.NormalizeMode_NORMALIZE_MODE_MERGE {
+		flattenedProjs = append(
+			flattenedProjs,
+			"_peerdb_match_data AS _md",
+			"_peerdb_commit_lsn AS _lsn",
+			"_peerdb_commit_time_ns AS _ct",
+		)
+	}
 
 	// normalize anything between last normalized batch id to last sync batchid
 	return fmt.Sprintf("WITH _f AS "+
@@ -141,19 +205,106 @@ func (m *mergeStmtGenerator) generateDeDupedCTE(normalizedTableSchema *protos.Ta
 	return fmt.Sprintf(cte, pkeyColsStr)
 }
 
-// generateMergeStmt generates a merge statement.
-func (m *mergeStmtGenerator) generateMergeStmt(dstTable string, dstDatasetTable datasetTable, unchangedToastColumns []string) string {
-	normalizedTableSchema := m.tableSchemaMapping[dstTable]
-	// comma separated list of column names
-	columnCount := len(normalizedTableSchema.Columns)
-	backtickColNames := make([]string, 0, columnCount)
-	shortBacktickColNames := make([]string, 0, columnCount)
-	pureColNames := make([]string, 0, columnCount)
+// setShortColumns names each column of the table _c<i> for use within a statement,
+// returning the backticked column names of the table and their short names
+func (m *mergeStmtGenerator) setShortColumns(normalizedTableSchema *protos.TableSchema) ([]string, []string) {
+	backtickColNames := make([]string, 0, len(normalizedTableSchema.Columns))
+	shortBacktickColNames := make([]string, 0, len(normalizedTableSchema.Columns))
 	for i, col := range normalizedTableSchema.Columns {
 		shortCol := fmt.Sprintf("_c%d", i)
 		m.shortColumn[col.Name] = shortCol
 		backtickColNames = append(backtickColNames, fmt.Sprintf("`%s`", col.Name))
 		shortBacktickColNames = append(shortBacktickColNames, fmt.Sprintf("`%s`", shortCol))
+	}
+	return backtickColNames, shortBacktickColNames
+}
+
+// generateChangelogStmt appends every change of the batch as a row,
+// the insert is skipped when an earlier attempt of the batch already appended its rows
+func (m *mergeStmtGenerator) generateChangelogStmt(dstTable string, dstDatasetTable datasetTable) string {
+	normalizedTableSchema := m.tableSchemaMapping[dstTable]
+	insertColumns, selectColumns := m.setShortColumns(normalizedTableSchema)
+	insertColumns = append(insertColumns,
+		fmt.Sprintf("`%s`", shared.HistoryOpColName),
+		fmt.Sprintf("`%s`", shared.HistoryCommitTimeColName),
+		fmt.Sprintf("`%s`", shared.HistoryLSNColName),
+		fmt.Sprintf("`%s`", shared.HistoryBeforeColName),
+		fmt.Sprintf("`%s`", shared.HistoryBatchIDColName))
+	selectColumns = append(selectColumns,
+		"CASE _rt WHEN 0 THEN 'insert' WHEN 1 THEN 'update' ELSE 'delete' END",
+		"TIMESTAMP_MICROS(DIV(_ct,1000))",
+		"_lsn",
+		"PARSE_JSON(NULLIF(_md,''),wide_number_mode=>'round')",
+		strconv.FormatInt(m.mergeBatchId, 10))
+	if m.peerdbCols.SyncedAtColName != "" {
+		insertColumns = append(insertColumns, fmt.Sprintf("`%s`", m.peerdbCols.SyncedAtColName))
+		selectColumns = append(selectColumns, "CURRENT_TIMESTAMP")
+	}
+
+	return fmt.Sprintf("INSERT INTO `%s` (%s) %s SELECT %s FROM _f"+
+		" WHERE NOT EXISTS (SELECT 1 FROM `%s` WHERE `%s`=%d);",
+		dstDatasetTable.table, strings.Join(insertColumns, ","), m.generateFlattenedCTE(dstTable, normalizedTableSchema),
+		strings.Join(selectColumns, ","), dstDatasetTable.table, shared.HistoryBatchIDColName, m.mergeBatchId)
+}
+
+// generateSCD2Stmt closes the current version of each key changed in the batch,
+// then inserts the versions of the batch, each valid until the next change of its key.
+// Both run in one transaction and skip work already done by an earlier attempt of the batch
+// through the batch id of the versions
+func (m *mergeStmtGenerator) generateSCD2Stmt(dstTable string, dstDatasetTable datasetTable) string {
+	normalizedTableSchema := m.tableSchemaMapping[dstTable]
+	insertColumns, selectColumns := m.setShortColumns(normalizedTableSchema)
+	flattenedCTE := m.generateFlattenedCTE(dstTable, normalizedTableSchema)
+	partitionPkeys := strings.Join(m.transformedPkeyStrings(normalizedTableSchema, true), ",")
+
+	pkeySelectSQLArray := make([]string, 0, len(normalizedTableSchema.PrimaryKeyColumns))
+	for _, pkeyCol := range normalizedTableSchema.PrimaryKeyColumns {
+		pkeySelectSQLArray = append(pkeySelectSQLArray, fmt.Sprintf("ANY_VALUE(%s) AS %s",
+			m.shortColumn[pkeyCol], m.shortColumn[pkeyCol]))
+	}
+	closeSetSQL := fmt.Sprintf("`%s`=TIMESTAMP_MICROS(DIV(_d._fc,1000)),`%s`=FALSE",
+		shared.SCD2ValidToColName, shared.SCD2IsCurrentColName)
+	if m.peerdbCols.SyncedAtColName != "" {
+		closeSetSQL += fmt.Sprintf(",`%s`=CURRENT_TIMESTAMP", m.peerdbCols.SyncedAtColName)
+	}
+	closeStmt := fmt.Sprintf("UPDATE `%s` _t SET %s FROM (%s SELECT %s,MIN(_ct) AS _fc"+
+		" FROM _f GROUP BY %s) _d WHERE _t.`%s` AND %s AND (_t.`%s` IS NULL OR _t.`%s`!=%d);",
+		dstDatasetTable.table, closeSetSQL, flattenedCTE, strings.Join(pkeySelectSQLArray, ","), partitionPkeys,
+		shared.SCD2IsCurrentColName, strings.Join(m.transformedPkeyStrings(normalizedTableSchema, false), " AND "),
+		shared.HistoryBatchIDColName, shared.HistoryBatchIDColName, m.mergeBatchId)
+
+	insertColumns = append(insertColumns,
+		fmt.Sprintf("`%s`", shared.HistoryLSNColName),
+		fmt.Sprintf("`%s`", shared.SCD2ValidFromColName),
+		fmt.Sprintf("`%s`", shared.SCD2ValidToColName),
+		fmt.Sprintf("`%s`", shared.SCD2IsCurrentColName),
+		fmt.Sprintf("`%s`", shared.HistoryBatchIDColName))
+	selectColumns = append(selectColumns,
+		"_lsn",
+		"TIMESTAMP_MICROS(DIV(_ct,1000))",
+		"TIMESTAMP_MICROS(DIV(_nc,1000))",
+		"_nc IS NULL",
+		strconv.FormatInt(m.mergeBatchId, 10))
+	if m.peerdbCols.SyncedAtColName != "" {
+		insertColumns = append(insertColumns, fmt.Sprintf("`%s`", m.peerdbCols.SyncedAtColName))
+		selectColumns = append(selectColumns, "CURRENT_TIMESTAMP")
+	}
+	insertStmt := fmt.Sprintf("INSERT INTO `%s` (%s) %s,_v AS (SELECT *,LEAD(_ct) OVER"+
+		" (PARTITION BY %s ORDER BY _ct,_lsn,_peerdb_timestamp) AS _nc FROM _f)"+
+		" SELECT %s FROM _v WHERE _rt!=2 AND NOT EXISTS (SELECT 1 FROM `%s` WHERE `%s`=%d);",
+		dstDatasetTable.table, strings.Join(insertColumns, ","), flattenedCTE, partitionPkeys,
+		strings.Join(selectColumns, ","), dstDatasetTable.table, shared.HistoryBatchIDColName, m.mergeBatchId)
+
+	return fmt.Sprintf("BEGIN TRANSACTION; %s %s COMMIT TRANSACTION;", closeStmt, insertStmt)
+}
+
+// generateMergeStmt generates a merge statement.
+func (m *mergeStmtGenerator) generateMergeStmt(dstTable string, dstDatasetTable datasetTable, unchangedToastColumns []string) string {
+	normalizedTableSchema := m.tableSchemaMapping[dstTable]
+	// comma separated list of column names
+	backtickColNames, shortBacktickColNames := m.setShortColumns(normalizedTableSchema)
+	pureColNames := make([]string, 0, len(normalizedTableSchema.Columns))
+	for _, col := range normalizedTableSchema.Columns {
 		pureColNames = append(pureColNames, col.Name)
 	}
 	csep := strings.Join(backtickColNames, ", ")
@@ -2,6 +2,7 @@ package connbigquery
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/PeerDB-io/peer-flow/connectors/utils"
@@ -187,3 +188,34 @@ func TestGenerateUpdateStatement_WithUnchangedToastColsAndSoftDelete(t *testing.
 		t.Errorf("Unexpected result. Expected: %v,\nbut got: %v", expected, result)
 	}
 }
+
+func TestGenerateSCD2Stmt(t *testing.T) {
+	mergeGen := &mergeStmtGenerator{
+		tableSchemaMapping: map[string]*protos.TableSchema{
+			"tbl": {
+				Columns:           []*protos.FieldDescription{{Name: "id", Type: "int64"}, {Name: "val", Type: "string"}},
+				PrimaryKeyColumns: []string{"id"},
+			},
+		},
+		peerdbCols:      &protos.PeerDBColumns{},
+		shortColumn:     map[string]string{},
+		rawDatasetTable: datasetTable{project: "project", dataset: "dataset", table: "_peerdb_raw_flow"},
+		mergeBatchId:    7,
+		normalizeModes:  map[string]protos.NormalizeMode{"tbl": protos.NormalizeMode_NORMALIZE_MODE_SCD2},
+	}
+	stmt := mergeGen.generateSCD2Stmt("tbl", datasetTable{project: "project", dataset: "dataset", table: "tbl"})
+
+	// changes of one transaction share its commit, the raw timestamp orders them
+	for _, expected := range []string{
+		"ORDER BY _ct,_lsn,_peerdb_timestamp",
+		"(_t.`_peerdb_batch_id` IS NULL OR _t.`_peerdb_batch_id`!=7)",
+		"NOT EXISTS (SELECT 1 FROM `tbl` WHERE `_peerdb_batch_id`=7)",
+	} {
+		if !strings.Contains(stmt, expected) {
+			t.Errorf("Expected %s in statement: %s", expected, stmt)
+		}
+	}
+	if strings.Contains(stmt, "_lsn NOT IN") {
+		t.Errorf("Unexpected deduplication by LSN in statement: %s", stmt)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/model"
 	"github.com/PeerDB-io/peer-flow/model/qvalue"
+	"github.com/PeerDB-io/peer-flow/otel_tracing"
 	"github.com/PeerDB-io/peer-flow/shared"
 )
 
@@ -129,6 +130,15 @@ func getTransformedColumns(dstSchema *bigquery.Schema, syncedAtCol string, softD
 			transformedColumns = append(transformedColumns, "FALSE AS `"+col.Name+"`")
 			continue
 		}
+		// rows of the initial load are the current version of a SCD2 table, other history columns stay NULL
+		if shared.IsHistoryColumn(col.Name) {
+			if strings.EqualFold(col.Name, shared.SCD2IsCurrentColName) {
+				transformedColumns = append(transformedColumns, "TRUE AS `"+col.Name+"`")
+			} else {
+				transformedColumns = append(transformedColumns, "NULL AS `"+col.Name+"`")
+			}
+			continue
+		}
 
 		switch col.Type {
 		case bigquery.GeographyFieldType:
@@ -252,7 +262,7 @@ func DefineAvroSchema(dstTableName string,
 	avroFields := make([]AvroField, 0, len(dstTableMetadata.Schema))
 	qFields := make([]qvalue.QField, 0, len(avroFields))
 	for _, bqField := range dstTableMetadata.Schema {
-		if bqField.Name == syncedAtCol || bqField.Name == softDeleteCol {
+		if bqField.Name == syncedAtCol || bqField.Name == softDeleteCol || shared.IsHistoryColumn(bqField.Name) {
 			continue
 		}
 		avroField, err := GetAvroField(bqField)
@@ -281,6 +291,12 @@ func DefineAvroSchema(dstTableName string,
 }
 
 func GetAvroType(bqField *bigquery.FieldSchema) (interface{}, error) {
+	return getAvroType(bqField.Name, bqField)
+}
+
+// getAvroType returns the Avro type of bqField, records are named after path
+// the way qvalue.GetAvroFieldSchema names them so values converted by QValueToAvro match
+func getAvroType(path string, bqField *bigquery.FieldSchema) (interface{}, error) {
 	avroNumericPrecision := int16(bqField.Precision)
 	avroNumericScale := int16(bqField.Scale)
 	bqNumeric := numeric.BigQueryNumericCompatibility{}
@@ -288,9 +304,9 @@ func GetAvroType(bqField *bigquery.FieldSchema) (interface{}, error) {
 		avroNumericPrecision, avroNumericScale = bqNumeric.DefaultPrecisionAndScale()
 	}
 
-	considerRepeated := func(typ string, repeated bool) interface{} {
+	considerRepeated := func(typ interface{}, repeated bool) interface{} {
 		if repeated {
-			return qvalue.AvroSchemaArray{
+			return qvalue.AvroSchemaComplexArray{
 				Type:  "array",
 				Items: typ,
 			}
@@ -303,7 +319,7 @@ func GetAvroType(bqField *bigquery.FieldSchema) (interface{}, error) {
 	case bigquery.StringFieldType, bigquery.GeographyFieldType, bigquery.JSONFieldType:
 		return considerRepeated("string", bqField.Repeated), nil
 	case bigquery.BytesFieldType:
-		return "bytes", nil
+		return considerRepeated("bytes", bqField.Repeated), nil
 	case bigquery.IntegerFieldType:
 		return considerRepeated("long", bqField.Repeated), nil
 	case bigquery.FloatFieldType:
@@ -336,10 +352,10 @@ func GetAvroType(bqField *bigquery.FieldSchema) (interface{}, error) {
 		return dateSchema, nil
 
 	case bigquery.TimeFieldType:
-		return qvalue.AvroSchemaField{
+		return considerRepeated(qvalue.AvroSchemaField{
 			Type:        "long",
 			LogicalType: "time-micros",
-		}, nil
+		}, bqField.Repeated), nil
 	case bigquery.DateTimeFieldType:
 		return qvalue.AvroSchemaRecord{
 			Type: "record",
@@ -358,29 +374,37 @@ func GetAvroType(bqField *bigquery.FieldSchema) (interface{}, error) {
 			},
 		}, nil
 	case bigquery.BigNumericFieldType:
-		return qvalue.AvroSchemaNumeric{
+		return considerRepeated(qvalue.AvroSchemaNumeric{
 			Type:        "bytes",
 			LogicalType: "decimal",
 			Precision:   avroNumericPrecision,
 			Scale:       avroNumericScale,
-		}, nil
+		}, bqField.Repeated), nil
 	case bigquery.RecordFieldType:
 		avroFields := []qvalue.AvroSchemaField{}
 		for _, bqSubField := range bqField.Schema {
-			avroType, err := GetAvroType(bqSubField)
+			avroField, err := getAvroField(path+"_"+bqSubField.Name, bqSubField)
 			if err != nil {
 				return nil, err
 			}
 			avroFields = append(avroFields, qvalue.AvroSchemaField{
-				Name: bqSubField.Name,
-				Type: avroType,
+				Name: avroField.Name,
+				Type: avroField.Type,
 			})
 		}
-		return qvalue.AvroSchemaRecord{
+		recordSchema := qvalue.AvroSchemaRecord{
 			Type:   "record",
-			Name:   bqField.Name,
+			Name:   path,
 			Fields: avroFields,
-		}, nil
+		}
+		// multiranges and arrays of composites are repeated records
+		if bqField.Repeated {
+			return qvalue.AvroSchemaComplexArray{
+				Type:  "array",
+				Items: recordSchema,
+			}, nil
+		}
+		return recordSchema, nil
 
 	default:
 		return nil, fmt.Errorf("unsupported BigQuery field type: %s", bqField.Type)
@@ -388,7 +412,11 @@ func GetAvroType(bqField *bigquery.FieldSchema) (interface{}, error) {
 }
 
 func GetAvroField(bqField *bigquery.FieldSchema) (AvroField, error) {
-	avroType, err := GetAvroType(bqField)
+	return getAvroField(bqField.Name, bqField)
+}
+
+func getAvroField(path string, bqField *bigquery.FieldSchema) (AvroField, error) {
+	avroType, err := getAvroType(path, bqField)
 	if err != nil {
 		return AvroField{}, err
 	}
@@ -476,25 +504,35 @@ func (s *QRepAvroSyncMethod) writeToStage(
 	loader.UseAvroLogicalTypes = true
 	loader.DecimalTargetTypes = []bigquery.DecimalTargetType{bigquery.BigNumericTargetType}
 	loader.WriteDisposition = bigquery.WriteTruncate
-	job, err := loader.Run(ctx)
+	loadCtx, span := otel_tracing.Start(ctx, "bigquery.LoadJob", otel_tracing.Table(stagingTable.table))
+	err := runLoadJob(loadCtx, loader)
+	otel_tracing.End(span, err)
 	if err != nil {
-		return 0, fmt.Errorf("failed to run BigQuery load job: %w", err)
+		return 0, err
 	}
+	s.connector.logger.Info(fmt.Sprintf("Pushed from %s to BigQuery", avroFile.FilePath), idLog)
 
-	status, err := job.Wait(ctx)
+	err = s.connector.waitForTableReady(ctx, stagingTable)
 	if err != nil {
-		return 0, fmt.Errorf("failed to wait for BigQuery load job: %w", err)
+		return 0, fmt.Errorf("failed to wait for table to be ready: %w", err)
 	}
 
-	if err := status.Err(); err != nil {
-		return 0, fmt.Errorf("failed to load Avro file into BigQuery table: %w", err)
+	return avroFile.NumRecords, nil
+}
+
+func runLoadJob(ctx context.Context, loader *bigquery.Loader) error {
+	job, err := loader.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to run BigQuery load job: %w", err)
 	}
-	s.connector.logger.Info(fmt.Sprintf("Pushed from %s to BigQuery", avroFile.FilePath), idLog)
 
-	err = s.connector.waitForTableReady(ctx, stagingTable)
+	status, err := job.Wait(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("failed to wait for table to be ready: %w", err)
+		return fmt.Errorf("failed to wait for BigQuery load job: %w", err)
 	}
 
-	return avroFile.NumRecords, nil
+	if err := status.Err(); err != nil {
+		return fmt.Errorf("failed to load Avro file into BigQuery table: %w", err)
+	}
+	return nil
 }
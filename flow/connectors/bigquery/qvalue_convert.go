@@ -1,13 +1,27 @@
 package connbigquery
 
 import (
+	"fmt"
+	"strings"
+
 	"cloud.google.com/go/bigquery"
 
+	numeric "github.com/PeerDB-io/peer-flow/datatypes"
+	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/model/qvalue"
 )
 
 func qValueKindToBigQueryType(colType string) bigquery.FieldType {
-	switch qvalue.QValueKind(colType) {
+	kind := qvalue.QValueKind(colType)
+	// ranges are records of their bounds and flags, multiranges are repeated records
+	if kind.IsRange() || kind.IsMultirange() {
+		return bigquery.RecordFieldType
+	}
+	// other arrays are repeated fields of their elements
+	if kind.IsGenericArray() {
+		return qValueKindToBigQueryType(string(kind.ArrayElementKind()))
+	}
+	switch kind {
 	// boolean
 	case qvalue.QValueKindBoolean:
 		return bigquery.BooleanFieldType
@@ -43,7 +57,7 @@ func qValueKindToBigQueryType(colType string) bigquery.FieldType {
 	// FieldSchema must be set to true.
 	case qvalue.QValueKindArrayInt16, qvalue.QValueKindArrayInt32, qvalue.QValueKindArrayInt64:
 		return bigquery.IntegerFieldType
-	case qvalue.QValueKindArrayFloat32, qvalue.QValueKindArrayFloat64:
+	case qvalue.QValueKindArrayFloat32, qvalue.QValueKindArrayFloat64, qvalue.QValueKindVector:
 		return bigquery.FloatFieldType
 	case qvalue.QValueKindArrayBoolean:
 		return bigquery.BooleanFieldType
@@ -53,6 +67,9 @@ func qValueKindToBigQueryType(colType string) bigquery.FieldType {
 		return bigquery.DateFieldType
 	case qvalue.QValueKindGeography, qvalue.QValueKindGeometry, qvalue.QValueKindPoint:
 		return bigquery.GeographyFieldType
+	// composites without known members are loaded as JSON
+	case qvalue.QValueKindStruct:
+		return bigquery.JSONFieldType
 	// rest will be strings
 	default:
 		return bigquery.StringFieldType
@@ -91,7 +108,99 @@ func BigQueryTypeToQValueKind(fieldType bigquery.FieldType) qvalue.QValueKind {
 	}
 }
 
+// rangeBigQuerySchema is the schema of the records holding ranges of kind
+func rangeBigQuerySchema(kind qvalue.QValueKind) bigquery.Schema {
+	boundType := qValueKindToBigQueryType(string(kind.RangeElementKind()))
+	return bigquery.Schema{
+		{Name: "lower", Type: boundType},
+		{Name: "upper", Type: boundType},
+		{Name: "lower_inclusive", Type: bigquery.BooleanFieldType, Required: true},
+		{Name: "upper_inclusive", Type: bigquery.BooleanFieldType, Required: true},
+		{Name: "empty", Type: bigquery.BooleanFieldType, Required: true},
+	}
+}
+
+// bigQueryFieldSchema is the schema of a column or composite member of kind colType
+func bigQueryFieldSchema(name string, colType string, typeModifier int32, fields []*protos.FieldDescription) *bigquery.FieldSchema {
+	kind := qvalue.QValueKind(colType)
+	if kind.IsGenericArray() {
+		fieldSchema := bigQueryFieldSchema(name, string(kind.ArrayElementKind()), typeModifier, fields)
+		fieldSchema.Repeated = true
+		return fieldSchema
+	}
+	switch {
+	case kind == qvalue.QValueKindNumeric:
+		precision, scale := numeric.GetNumericTypeForWarehouse(typeModifier, numeric.BigQueryNumericCompatibility{})
+		return &bigquery.FieldSchema{
+			Name:      name,
+			Type:      bigquery.BigNumericFieldType,
+			Precision: int64(precision),
+			Scale:     int64(scale),
+		}
+	case kind.IsRange() || kind.IsMultirange():
+		return &bigquery.FieldSchema{
+			Name:     name,
+			Type:     bigquery.RecordFieldType,
+			Repeated: kind.IsMultirange(),
+			Schema:   rangeBigQuerySchema(kind.RangeKind()),
+		}
+	case kind == qvalue.QValueKindStruct && len(fields) > 0:
+		members := make(bigquery.Schema, 0, len(fields))
+		for _, field := range fields {
+			members = append(members, bigQueryFieldSchema(field.Name, field.Type, field.TypeModifier, field.Fields))
+		}
+		return &bigquery.FieldSchema{
+			Name:   name,
+			Type:   bigquery.RecordFieldType,
+			Schema: members,
+		}
+	case kind == qvalue.QValueKindStruct:
+		return &bigquery.FieldSchema{
+			Name: name,
+			Type: bigquery.JSONFieldType,
+		}
+	default:
+		return &bigquery.FieldSchema{
+			Name:     name,
+			Type:     qValueKindToBigQueryType(colType),
+			Repeated: kind.IsArray() || kind == qvalue.QValueKindVector,
+		}
+	}
+}
+
+// bigQueryTypeString is the type of fieldSchema in BigQuery DDL
+func bigQueryTypeString(fieldSchema *bigquery.FieldSchema) string {
+	var typeString string
+	switch fieldSchema.Type {
+	case bigquery.RecordFieldType:
+		members := make([]string, 0, len(fieldSchema.Schema))
+		for _, member := range fieldSchema.Schema {
+			members = append(members, fmt.Sprintf("`%s` %s", member.Name, bigQueryTypeString(member)))
+		}
+		typeString = "STRUCT<" + strings.Join(members, ", ") + ">"
+	case bigquery.FloatFieldType:
+		typeString = "FLOAT64"
+	case bigquery.BooleanFieldType:
+		typeString = "BOOL"
+	default:
+		typeString = string(fieldSchema.Type)
+	}
+	if fieldSchema.Repeated {
+		return "ARRAY<" + typeString + ">"
+	}
+	return typeString
+}
+
 func qValueKindToBigQueryTypeString(colType string) string {
+	kind := qvalue.QValueKind(colType)
+	if kind.IsRange() || kind.IsMultirange() {
+		boundType := qValueKindToBigQueryTypeString(string(kind.RangeElementKind()))
+		structType := fmt.Sprintf("STRUCT<lower %[1]s, upper %[1]s, lower_inclusive BOOL, upper_inclusive BOOL, empty BOOL>", boundType)
+		if kind.IsMultirange() {
+			return "ARRAY<" + structType + ">"
+		}
+		return structType
+	}
 	bqType := qValueKindToBigQueryType(colType)
 	bqTypeAsString := string(bqType)
 	// string(bigquery.FloatFieldType) is "FLOAT" which is not a BigQuery type.
@@ -105,11 +214,19 @@ func qValueKindToBigQueryTypeString(colType string) string {
 }
 
 func BigQueryFieldToQField(bqField *bigquery.FieldSchema) qvalue.QField {
+	var fields []qvalue.QField
+	if bqField.Type == bigquery.RecordFieldType {
+		fields = make([]qvalue.QField, 0, len(bqField.Schema))
+		for _, member := range bqField.Schema {
+			fields = append(fields, BigQueryFieldToQField(member))
+		}
+	}
 	return qvalue.QField{
 		Name:      bqField.Name,
 		Type:      BigQueryTypeToQValueKind(bqField.Type),
 		Precision: int16(bqField.Precision),
 		Scale:     int16(bqField.Scale),
 		Nullable:  !bqField.Required,
+		Fields:    fields,
 	}
 }
@@ -20,7 +20,7 @@ import (
 
 const (
 	checkIfTableExistsSQL = `SELECT exists(SELECT 1 FROM system.tables WHERE database = ? AND name = ?) AS table_exists;`
-	dropTableIfExistsSQL  = `DROP TABLE IF EXISTS %s;`
+	dropTableIfExistsSQL  = `DROP TABLE IF EXISTS %s%s;`
 )
 
 // getRawTableName returns the raw table name for the given table identifier.
@@ -45,7 +45,7 @@ func (c *ClickhouseConnector) checkIfTableExists(ctx context.Context, databaseNa
 func (c *ClickhouseConnector) CreateRawTable(ctx context.Context, req *protos.CreateRawTableInput) (*protos.CreateRawTableOutput, error) {
 	rawTableName := c.getRawTableName(req.FlowJobName)
 
-	createRawTableSQL := `CREATE TABLE IF NOT EXISTS %s (
+	createRawTableSQL := `CREATE TABLE IF NOT EXISTS %s%s (
 		_peerdb_uid String NOT NULL,
 		_peerdb_timestamp Int64 NOT NULL,
 		_peerdb_destination_table_name String NOT NULL,
@@ -54,19 +54,30 @@ func (c *ClickhouseConnector) CreateRawTable(ctx context.Context, req *protos.Cr
 		_peerdb_match_data String,
 		_peerdb_batch_id Int,
 		_peerdb_unchanged_toast_columns String
-	) ENGINE = ReplacingMergeTree ORDER BY _peerdb_uid;`
+	) ENGINE = %s ORDER BY _peerdb_uid;`
 
 	_, err := c.database.ExecContext(ctx,
-		fmt.Sprintf(createRawTableSQL, rawTableName))
+		fmt.Sprintf(createRawTableSQL, rawTableName,
+			onClusterClause(c.config.Cluster), c.mergeTreeEngine("ReplacingMergeTree")))
 	if err != nil {
 		return nil, fmt.Errorf("unable to create raw table: %w", err)
 	}
+	if req.CommitInfo {
+		// raw tables created before history modes were in use lack the commit columns
+		_, err = c.database.ExecContext(ctx, fmt.Sprintf(
+			"ALTER TABLE %s%s ADD COLUMN IF NOT EXISTS %s Nullable(Int64), ADD COLUMN IF NOT EXISTS %s Nullable(Int64)",
+			rawTableName, onClusterClause(c.config.Cluster), shared.RawCommitLSNColName, shared.RawCommitTimeNsColName))
+		if err != nil {
+			return nil, fmt.Errorf("unable to add commit columns to raw table: %w", err)
+		}
+	}
 	return &protos.CreateRawTableOutput{
 		TableIdentifier: rawTableName,
 	}, nil
 }
 
-func (c *ClickhouseConnector) syncRecordsViaAvro(
+// syncRecords loads the batch into the raw table, staged through S3 as Avro or inserted over the native protocol
+func (c *ClickhouseConnector) syncRecords(
 	ctx context.Context,
 	req *model.SyncRecordsRequest[model.RecordItems],
 	rawTableIdentifier string,
@@ -74,25 +85,42 @@ func (c *ClickhouseConnector) syncRecordsViaAvro(
 ) (*model.SyncResponse, error) {
 	tableNameRowsMapping := utils.InitialiseTableRowsMap(req.TableMappings)
 	streamReq := model.NewRecordsToStreamRequest(req.Records.GetRecords(), tableNameRowsMapping, syncBatchID)
+	streamReq.CommitInfo = req.CommitInfo
 	stream, err := utils.RecordsToRawTableStream(streamReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert records to raw table stream: %w", err)
 	}
 
-	qrepConfig := &protos.QRepConfig{
-		StagingPath:                c.credsProvider.BucketPath,
-		FlowJobName:                req.FlowJobName,
-		DestinationTableIdentifier: strings.ToLower(rawTableIdentifier),
-	}
-	avroSyncer := NewClickhouseAvroSyncMethod(qrepConfig, c)
-	destinationTableSchema, err := c.getTableSchema(qrepConfig.DestinationTableIdentifier)
-	if err != nil {
-		return nil, err
-	}
+	var numRecords int
+	if c.nativeIngest() {
+		qrepConfig := &protos.QRepConfig{
+			FlowJobName:                req.FlowJobName,
+			DestinationTableIdentifier: strings.ToLower(rawTableIdentifier),
+		}
+		destinationTableSchema, err := c.getTableSchema(qrepConfig.DestinationTableIdentifier)
+		if err != nil {
+			return nil, err
+		}
+		numRecords, err = NewClickhouseNativeSyncMethod(qrepConfig, c).SyncRecords(ctx, destinationTableSchema, stream, syncBatchID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		qrepConfig := &protos.QRepConfig{
+			StagingPath:                c.credsProvider.BucketPath,
+			FlowJobName:                req.FlowJobName,
+			DestinationTableIdentifier: strings.ToLower(rawTableIdentifier),
+		}
+		avroSyncer := NewClickhouseAvroSyncMethod(qrepConfig, c)
+		destinationTableSchema, err := c.getTableSchema(qrepConfig.DestinationTableIdentifier)
+		if err != nil {
+			return nil, err
+		}
 
-	numRecords, err := avroSyncer.SyncRecords(ctx, destinationTableSchema, stream, req.FlowJobName)
-	if err != nil {
-		return nil, err
+		numRecords, err = avroSyncer.SyncRecords(ctx, destinationTableSchema, stream, req.FlowJobName)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	err = c.ReplayTableSchemaDeltas(ctx, req.FlowJobName, req.Records.SchemaDeltas)
@@ -113,7 +141,7 @@ func (c *ClickhouseConnector) SyncRecords(ctx context.Context, req *model.SyncRe
 	rawTableName := c.getRawTableName(req.FlowJobName)
 	c.logger.Info("pushing records to Clickhouse table " + rawTableName)
 
-	res, err := c.syncRecordsViaAvro(ctx, req, rawTableName, req.SyncBatchID)
+	res, err := c.syncRecords(ctx, req, rawTableName, req.SyncBatchID)
 	if err != nil {
 		return nil, err
 	}
@@ -152,14 +180,15 @@ func (c *ClickhouseConnector) ReplayTableSchemaDeltas(ctx context.Context, flowJ
 		}
 
 		for _, addedColumn := range schemaDelta.AddedColumns {
-			clickhouseColType, err := qvalue.QValueKind(addedColumn.Type).ToDWHColumnType(protos.DBType_CLICKHOUSE)
+			clickhouseColType, err := qvalue.QValueKind(addedColumn.Type).ToDWHColumnTypeWithFields(
+				protos.DBType_CLICKHOUSE, addedColumn.Fields)
 			if err != nil {
 				return fmt.Errorf("failed to convert column type %s to clickhouse type: %w",
 					addedColumn.Type, err)
 			}
 			_, err = tableSchemaModifyTx.ExecContext(ctx,
-				fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS \"%s\" %s",
-					schemaDelta.DstTableName, addedColumn.Name, clickhouseColType))
+				fmt.Sprintf("ALTER TABLE %s%s ADD COLUMN IF NOT EXISTS \"%s\" %s",
+					schemaDelta.DstTableName, onClusterClause(c.config.Cluster), addedColumn.Name, clickhouseColType))
 			if err != nil {
 				return fmt.Errorf("failed to add column %s for table %s: %w", addedColumn.Name,
 					schemaDelta.DstTableName, err)
@@ -188,10 +217,32 @@ func (c *ClickhouseConnector) SyncFlowCleanup(ctx context.Context, jobName strin
 
 	// delete raw table if exists
 	rawTableIdentifier := c.getRawTableName(jobName)
-	_, err = c.database.ExecContext(ctx, fmt.Sprintf(dropTableIfExistsSQL, rawTableIdentifier))
+	_, err = c.database.ExecContext(ctx,
+		fmt.Sprintf(dropTableIfExistsSQL, rawTableIdentifier, onClusterClause(c.config.Cluster)))
 	if err != nil {
 		return fmt.Errorf("[snowflake drop mirror] unable to drop raw table: %w", err)
 	}
 
 	return nil
 }
+
+// RemoveTables deletes rows of tables no longer part of the mirror from the raw table and drops the tables
+func (c *ClickhouseConnector) RemoveTables(ctx context.Context, req *protos.RemoveTablesInput) error {
+	rawTableIdentifier := c.getRawTableName(req.FlowJobName)
+	for _, tableIdentifier := range req.DestinationTableIdentifiers {
+		_, err := c.database.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s%s DELETE WHERE _peerdb_destination_table_name = ?",
+			rawTableIdentifier, onClusterClause(c.config.Cluster)), tableIdentifier)
+		if err != nil {
+			return fmt.Errorf("unable to delete rows of table %s from raw table: %w", tableIdentifier, err)
+		}
+		if req.KeepTables {
+			continue
+		}
+		_, err = c.database.ExecContext(ctx,
+			fmt.Sprintf(dropTableIfExistsSQL, "`"+tableIdentifier+"`", onClusterClause(c.config.Cluster)))
+		if err != nil {
+			return fmt.Errorf("unable to drop table %s: %w", tableIdentifier, err)
+		}
+	}
+	return nil
+}
@@ -69,14 +69,37 @@ func (c *ClickhouseConnector) ValidateCheck(ctx context.Context) error {
 		return fmt.Errorf("failed to drop validation table %s: %w", validateDummyTableName, err)
 	}
 
-	validateErr := ValidateS3(ctx, c.credsProvider)
-	if validateErr != nil {
-		return fmt.Errorf("failed to validate S3 bucket: %w", validateErr)
+	// native ingestion may run without a stage
+	if c.credsProvider != nil {
+		validateErr := ValidateS3(ctx, c.credsProvider)
+		if validateErr != nil {
+			return fmt.Errorf("failed to validate S3 bucket: %w", validateErr)
+		}
 	}
 
 	return nil
 }
 
+func onClusterClause(cluster string) string {
+	if cluster == "" {
+		return ""
+	}
+	return " ON CLUSTER `" + cluster + "`"
+}
+
+// mergeTreeEngine returns the Replicated variant of engine when DDL runs on a cluster,
+// so internal tables are readable from whichever replica the next connection lands on
+func (c *ClickhouseConnector) mergeTreeEngine(engine string) string {
+	if c.config.Cluster == "" {
+		return engine
+	}
+	return "Replicated" + engine
+}
+
+func (c *ClickhouseConnector) nativeIngest() bool {
+	return c.config.IngestMode == protos.ClickhouseIngestMode_CLICKHOUSE_INGEST_MODE_NATIVE
+}
+
 func NewClickhouseConnector(
 	ctx context.Context,
 	config *protos.ClickhouseConfig,
@@ -93,6 +116,31 @@ func NewClickhouseConnector(
 		return nil, err
 	}
 
+	var credsProvider *utils.ClickHouseS3Credentials
+	// in native mode S3 is only needed for snapshots, and only when a stage is configured
+	if config.IngestMode != protos.ClickhouseIngestMode_CLICKHOUSE_INGEST_MODE_NATIVE ||
+		config.S3Path != "" || peerdbenv.PeerDBClickhouseAWSS3BucketName() != "" {
+		credsProvider, err = getS3Credentials(ctx, config, database)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ClickhouseConnector{
+		database:           database,
+		PostgresMetadata:   pgMetadata,
+		tableSchemaMapping: nil,
+		config:             config,
+		logger:             logger,
+		credsProvider:      credsProvider,
+	}, nil
+}
+
+func getS3Credentials(
+	ctx context.Context,
+	config *protos.ClickhouseConfig,
+	database *sql.DB,
+) (*utils.ClickHouseS3Credentials, error) {
 	credentialsProvider, err := utils.GetAWSCredentialsProvider(ctx, "clickhouse", utils.PeerAWSCredentials{
 		Credentials: aws.Credentials{
 			AccessKeyID:     config.AccessKeyId,
@@ -152,14 +200,7 @@ func NewClickhouseConnector(
 		}
 	}
 
-	return &ClickhouseConnector{
-		database:           database,
-		PostgresMetadata:   pgMetadata,
-		tableSchemaMapping: nil,
-		config:             config,
-		logger:             logger,
-		credsProvider:      &clickHouseS3CredentialsNew,
-	}, nil
+	return &clickHouseS3CredentialsNew, nil
 }
 
 func connect(ctx context.Context, config *protos.ClickhouseConfig) (*sql.DB, error) {
@@ -8,15 +8,18 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/PeerDB-io/peer-flow/connectors/utils"
 	"github.com/PeerDB-io/peer-flow/datatypes"
 	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/model"
 	"github.com/PeerDB-io/peer-flow/model/qvalue"
+	"github.com/PeerDB-io/peer-flow/otel_tracing"
+	"github.com/PeerDB-io/peer-flow/shared"
 )
 
 const (
 	signColName    = "_peerdb_is_deleted"
-	signColType    = "Int8"
+	signColType    = "UInt8"
 	versionColName = "_peerdb_version"
 	versionColType = "Int64"
 )
@@ -35,10 +38,9 @@ func (c *ClickhouseConnector) CleanupSetupNormalizedTables(_ context.Context, _
 func (c *ClickhouseConnector) SetupNormalizedTable(
 	ctx context.Context,
 	tx interface{},
+	config *protos.SetupNormalizedTableBatchInput,
 	tableIdentifier string,
 	tableSchema *protos.TableSchema,
-	softDeleteColName string,
-	syncedAtColName string,
 ) (bool, error) {
 	tableAlreadyExists, err := c.checkIfTableExists(ctx, c.config.Database, tableIdentifier)
 	if err != nil {
@@ -48,11 +50,26 @@ func (c *ClickhouseConnector) SetupNormalizedTable(
 		return true, nil
 	}
 
+	var tableSettings *protos.ClickhouseTableSettings
+	for _, tableMapping := range config.TableMappings {
+		if tableMapping.DestinationTableIdentifier == tableIdentifier {
+			tableSettings = tableMapping.ClickhouseSettings
+			break
+		}
+	}
+
+	var softDeleteColName string
+	if config.SoftDelete {
+		softDeleteColName = config.SoftDeleteColName
+	}
 	normalizedTableCreateSQL, err := generateCreateTableSQLForNormalizedTable(
 		tableIdentifier,
 		tableSchema,
 		softDeleteColName,
-		syncedAtColName,
+		config.SyncedAtColName,
+		c.config.Cluster,
+		tableSettings,
+		shared.NormalizeModeForTable(config.NormalizeMode, config.TableMappings, tableIdentifier),
 	)
 	if err != nil {
 		return false, fmt.Errorf("error while generating create table sql for normalized table: %w", err)
@@ -65,19 +82,48 @@ func (c *ClickhouseConnector) SetupNormalizedTable(
 	return false, nil
 }
 
+// clickhouseTimeRangeExpr extracts a date or timestamp range column, bounds are parsed like date and timestamp columns
+func clickhouseTimeRangeExpr(column string, kind qvalue.QValueKind) string {
+	rangeKind := kind.RangeKind()
+	tupleType, _ := rangeKind.ToDWHColumnType(protos.DBType_CLICKHOUSE)
+	tupleExpr := func(jsonRange string) string {
+		bound := func(name string) string {
+			if rangeKind.RangeElementKind() == qvalue.QValueKindDate {
+				return fmt.Sprintf("toDate(parseDateTime64BestEffortOrNull(JSONExtractString(%s, '%s')))", jsonRange, name)
+			}
+			return fmt.Sprintf("parseDateTime64BestEffortOrNull(JSONExtractString(%s, '%s'), 6)", jsonRange, name)
+		}
+		return fmt.Sprintf("CAST(tuple(%s, %s, JSONExtractBool(%[3]s, 'lower_inclusive'), JSONExtractBool(%[3]s, 'upper_inclusive'), "+
+			"JSONExtractBool(%[3]s, 'empty')), '%[4]s')", bound("lower"), bound("upper"), jsonRange, tupleType)
+	}
+	if kind.IsMultirange() {
+		return fmt.Sprintf("arrayMap(r -> %s, JSONExtractArrayRaw(_peerdb_data, '%s'))", tupleExpr("r"), column)
+	}
+	return tupleExpr(fmt.Sprintf("JSONExtractRaw(_peerdb_data, '%s')", column))
+}
+
 func generateCreateTableSQLForNormalizedTable(
 	normalizedTable string,
 	tableSchema *protos.TableSchema,
-	_ string, // softDeleteColName
+	softDeleteColName string,
 	syncedAtColName string,
+	cluster string,
+	tableSettings *protos.ClickhouseTableSettings,
+	normalizeMode protos.NormalizeMode,
 ) (string, error) {
+	// closing versions would need updates in place, which ClickHouse tables are not built for
+	if normalizeMode == protos.NormalizeMode_NORMALIZE_MODE_SCD2 {
+		return "", fmt.Errorf("table %s: SCD2 normalize mode is not supported for ClickHouse", normalizedTable)
+	}
+	isChangelog := normalizeMode == protos.NormalizeMode_NORMALIZE_MODE_CHANGELOG
+
 	var stmtBuilder strings.Builder
-	stmtBuilder.WriteString(fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s` (", normalizedTable))
+	stmtBuilder.WriteString(fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s`%s (", normalizedTable, onClusterClause(cluster)))
 
 	for _, column := range tableSchema.Columns {
 		colName := column.Name
 		colType := qvalue.QValueKind(column.Type)
-		clickhouseType, err := colType.ToDWHColumnType(protos.DBType_CLICKHOUSE)
+		clickhouseType, err := colType.ToDWHColumnTypeWithFields(protos.DBType_CLICKHOUSE, column.Fields)
 		if err != nil {
 			return "", fmt.Errorf("error while converting column type to clickhouse type: %w", err)
 		}
@@ -91,7 +137,17 @@ func generateCreateTableSQLForNormalizedTable(
 			stmtBuilder.WriteString(fmt.Sprintf("`%s` %s, ", colName, clickhouseType))
 		}
 	}
-	// TODO support soft delete
+	// a change log keeps one row per change, changes of a transaction share its LSN so the version,
+	// the raw timestamp of each change, is part of the sorting key and only rows of retried batches get replaced
+	if isChangelog {
+		stmtBuilder.WriteString(fmt.Sprintf("`%s` String, `%s` DateTime64(9), `%s` Int64, `%s` Nullable(String), ",
+			shared.HistoryOpColName, shared.HistoryCommitTimeColName, shared.HistoryLSNColName, shared.HistoryBeforeColName))
+	}
+	// soft deleted rows stay in the table, ReplacingMergeTree drops them from FINAL reads through is_deleted
+	isSoftDelete := softDeleteColName != "" && !isChangelog
+	if isSoftDelete {
+		stmtBuilder.WriteString(fmt.Sprintf("`%s` %s DEFAULT 0, ", softDeleteColName, signColType))
+	}
 	// synced at column will be added to all normalized tables
 	if syncedAtColName != "" {
 		colName := strings.ToLower(syncedAtColName)
@@ -99,14 +155,47 @@ func generateCreateTableSQLForNormalizedTable(
 	}
 
 	// add sign and version columns
-	stmtBuilder.WriteString(fmt.Sprintf("`%s` %s, ", signColName, signColType))
+	if !isChangelog {
+		stmtBuilder.WriteString(fmt.Sprintf("`%s` %s, ", signColName, signColType))
+	}
 	stmtBuilder.WriteString(fmt.Sprintf("`%s` %s", versionColName, versionColType))
 
-	stmtBuilder.WriteString(fmt.Sprintf(") ENGINE = ReplacingMergeTree(`%s`) ", versionColName))
+	// tables created on a cluster are replicated across it
+	engine := "ReplacingMergeTree"
+	replicated := false
+	if cluster != "" || tableSettings.GetEngine() == protos.ClickhouseTableEngine_CH_ENGINE_REPLICATED_REPLACING_MERGE_TREE {
+		engine = "ReplicatedReplacingMergeTree"
+		replicated = true
+	}
+	if isSoftDelete {
+		stmtBuilder.WriteString(fmt.Sprintf(") ENGINE = %s(`%s`, `%s`) ", engine, versionColName, softDeleteColName))
+	} else {
+		stmtBuilder.WriteString(fmt.Sprintf(") ENGINE = %s(`%s`) ", engine, versionColName))
+	}
 
-	pkeys := tableSchema.PrimaryKeyColumns
-	if len(pkeys) > 0 {
-		pkeyStr := strings.Join(pkeys, ",")
+	if partitionBy := tableSettings.GetPartitionBy(); partitionBy != "" {
+		stmtBuilder.WriteString("PARTITION BY ")
+		stmtBuilder.WriteString(partitionBy)
+		stmtBuilder.WriteString(" ")
+	}
+
+	lsnOrderBy := ""
+	noSortingKey := false
+	if isChangelog {
+		lsnOrderBy = ",`" + shared.HistoryLSNColName + "`,`" + versionColName + "`"
+	}
+	if orderBy := tableSettings.GetOrderBy(); len(orderBy) > 0 {
+		// primary key defaults to the sorting key
+		stmtBuilder.WriteString("ORDER BY (")
+		stmtBuilder.WriteString(strings.Join(orderBy, ","))
+		stmtBuilder.WriteString(lsnOrderBy)
+		stmtBuilder.WriteString(")")
+	} else if pkeys := tableSchema.PrimaryKeyColumns; len(pkeys) > 0 {
+		quotedPkeys := make([]string, 0, len(pkeys))
+		for _, pkey := range pkeys {
+			quotedPkeys = append(quotedPkeys, "`"+pkey+"`")
+		}
+		pkeyStr := strings.Join(quotedPkeys, ",")
 
 		stmtBuilder.WriteString("PRIMARY KEY (")
 		stmtBuilder.WriteString(pkeyStr)
@@ -114,7 +203,24 @@ func generateCreateTableSQLForNormalizedTable(
 
 		stmtBuilder.WriteString("ORDER BY (")
 		stmtBuilder.WriteString(pkeyStr)
+		stmtBuilder.WriteString(lsnOrderBy)
 		stmtBuilder.WriteString(")")
+	} else if isChangelog {
+		stmtBuilder.WriteString("ORDER BY (`" + shared.HistoryLSNColName + "`,`" + versionColName + "`)")
+	} else {
+		stmtBuilder.WriteString("ORDER BY tuple()")
+		noSortingKey = true
+	}
+
+	if ttl := tableSettings.GetTtl(); ttl != "" {
+		stmtBuilder.WriteString(" TTL ")
+		stmtBuilder.WriteString(ttl)
+	}
+
+	// without a sorting key nothing collapses rows inserted twice, so blocks resent by a retried partition
+	// are deduplicated like on replicated tables, where deduplication is on by default
+	if noSortingKey && !replicated {
+		stmtBuilder.WriteString(" SETTINGS non_replicated_deduplication_window = 1000")
 	}
 
 	return stmtBuilder.String(), nil
@@ -146,6 +252,7 @@ func (c *ClickhouseConnector) NormalizeRecords(ctx context.Context, req *model.N
 		c.logger.Error("[clickhouse] error while getting distinct table names in batch", "error", err)
 		return nil, err
 	}
+	destinationTableNames = utils.TablesToNormalize(destinationTableNames, req.TableNameSchemaMapping, c.logger)
 
 	rawTbl := c.getRawTableName(req.FlowJobName)
 
@@ -168,7 +275,7 @@ func (c *ClickhouseConnector) NormalizeRecords(ctx context.Context, req *model.N
 
 			colSelector.WriteString(fmt.Sprintf("`%s`,", cn))
 			colType := qvalue.QValueKind(ct)
-			clickhouseType, err := colType.ToDWHColumnType(protos.DBType_CLICKHOUSE)
+			clickhouseType, err := colType.ToDWHColumnTypeWithFields(protos.DBType_CLICKHOUSE, column.Fields)
 			if err != nil {
 				return nil, fmt.Errorf("error while converting column type to clickhouse type: %w", err)
 			}
@@ -187,13 +294,43 @@ func (c *ClickhouseConnector) NormalizeRecords(ctx context.Context, req *model.N
 					cn,
 				))
 			default:
-				projection.WriteString(fmt.Sprintf("JSONExtract(_peerdb_data, '%s', '%s') AS `%s`,", cn, clickhouseType, cn))
+				if (colType.IsRange() || colType.IsMultirange()) && (colType.RangeElementKind() == qvalue.QValueKindDate ||
+					colType.RangeElementKind() == qvalue.QValueKindTimestamp || colType.RangeElementKind() == qvalue.QValueKindTimestampTZ) {
+					projection.WriteString(fmt.Sprintf("%s AS `%s`,", clickhouseTimeRangeExpr(cn, colType), cn))
+				} else {
+					projection.WriteString(fmt.Sprintf("JSONExtract(_peerdb_data, '%s', '%s') AS `%s`,", cn, clickhouseType, cn))
+				}
 			}
 		}
 
-		// add _peerdb_sign as _peerdb_record_type / 2
-		projection.WriteString(fmt.Sprintf("intDiv(_peerdb_record_type, 2) AS `%s`,", signColName))
-		colSelector.WriteString(fmt.Sprintf("`%s`,", signColName))
+		isChangelog := req.NormalizeModes[tbl] == protos.NormalizeMode_NORMALIZE_MODE_CHANGELOG
+		if isChangelog {
+			projection.WriteString(fmt.Sprintf(
+				"multiIf(_peerdb_record_type = 0, 'insert', _peerdb_record_type = 1, 'update', 'delete') AS `%s`,",
+				shared.HistoryOpColName))
+			projection.WriteString(fmt.Sprintf("fromUnixTimestamp64Nano(assumeNotNull(%s)) AS `%s`,",
+				shared.RawCommitTimeNsColName, shared.HistoryCommitTimeColName))
+			projection.WriteString(fmt.Sprintf("assumeNotNull(%s) AS `%s`,", shared.RawCommitLSNColName, shared.HistoryLSNColName))
+			projection.WriteString(fmt.Sprintf("nullIf(_peerdb_match_data, '') AS `%s`,", shared.HistoryBeforeColName))
+			colSelector.WriteString(fmt.Sprintf("`%s`,`%s`,`%s`,`%s`,", shared.HistoryOpColName,
+				shared.HistoryCommitTimeColName, shared.HistoryLSNColName, shared.HistoryBeforeColName))
+		} else {
+			// add _peerdb_sign as _peerdb_record_type / 2
+			projection.WriteString(fmt.Sprintf("intDiv(_peerdb_record_type, 2) AS `%s`,", signColName))
+			colSelector.WriteString(fmt.Sprintf("`%s`,", signColName))
+		}
+
+		// tables created before soft delete support lack the column
+		if req.SoftDelete && req.SoftDeleteColName != "" && !isChangelog {
+			hasSoftDeleteCol, err := c.tableHasColumn(tbl, req.SoftDeleteColName)
+			if err != nil {
+				return nil, err
+			}
+			if hasSoftDeleteCol {
+				projection.WriteString(fmt.Sprintf("intDiv(_peerdb_record_type, 2) AS `%s`,", req.SoftDeleteColName))
+				colSelector.WriteString(fmt.Sprintf("`%s`,", req.SoftDeleteColName))
+			}
+		}
 
 		// add _peerdb_timestamp as _peerdb_version
 		projection.WriteString(fmt.Sprintf("_peerdb_timestamp AS `%s`", versionColName))
@@ -222,7 +359,9 @@ func (c *ClickhouseConnector) NormalizeRecords(ctx context.Context, req *model.N
 		q := insertIntoSelectQuery.String()
 		c.logger.Info("[clickhouse] insert into select query " + q)
 
-		_, err = c.database.ExecContext(ctx, q)
+		stmtCtx, span := otel_tracing.Start(ctx, "clickhouse.NormalizeTable", otel_tracing.Table(tbl))
+		_, err = c.database.ExecContext(stmtCtx, q)
+		otel_tracing.End(span, err)
 		if err != nil {
 			return nil, fmt.Errorf("error while inserting into normalized table: %w", err)
 		}
@@ -242,6 +381,19 @@ func (c *ClickhouseConnector) NormalizeRecords(ctx context.Context, req *model.N
 	}, nil
 }
 
+func (c *ClickhouseConnector) tableHasColumn(tableName string, columnName string) (bool, error) {
+	columns, err := c.getTableSchema(tableName)
+	if err != nil {
+		return false, fmt.Errorf("failed to get schema of table %s: %w", tableName, err)
+	}
+	for _, column := range columns {
+		if column.Name() == columnName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (c *ClickhouseConnector) getDistinctTableNamesInBatch(
 	ctx context.Context,
 	flowJobName string,
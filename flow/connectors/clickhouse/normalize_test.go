@@ -0,0 +1,100 @@
+package connclickhouse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+	"github.com/PeerDB-io/peer-flow/model/qvalue"
+)
+
+func TestGenerateCreateTableSQLForNormalizedTable(t *testing.T) {
+	tableSchema := &protos.TableSchema{
+		Columns: []*protos.FieldDescription{
+			{Name: "id", Type: string(qvalue.QValueKindInt64)},
+			{Name: "created_at", Type: string(qvalue.QValueKindTimestamp)},
+		},
+		PrimaryKeyColumns: []string{"id"},
+	}
+
+	stmt, err := generateCreateTableSQLForNormalizedTable("tbl", tableSchema, "", "_peerdb_synced_at", "", nil,
+		protos.NormalizeMode_NORMALIZE_MODE_MERGE)
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE IF NOT EXISTS `tbl` (`id` Int64, `created_at` DateTime64(6), "+
+		"`_peerdb_synced_at` DateTime64(9) DEFAULT now(), `_peerdb_is_deleted` UInt8, `_peerdb_version` Int64) "+
+		"ENGINE = ReplacingMergeTree(`_peerdb_version`) PRIMARY KEY (`id`) ORDER BY (`id`)", stmt)
+
+	stmt, err = generateCreateTableSQLForNormalizedTable("tbl", tableSchema, "", "", "main", nil,
+		protos.NormalizeMode_NORMALIZE_MODE_MERGE)
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE IF NOT EXISTS `tbl` ON CLUSTER `main` (`id` Int64, `created_at` DateTime64(6), "+
+		"`_peerdb_is_deleted` UInt8, `_peerdb_version` Int64) "+
+		"ENGINE = ReplicatedReplacingMergeTree(`_peerdb_version`) PRIMARY KEY (`id`) ORDER BY (`id`)", stmt)
+
+	stmt, err = generateCreateTableSQLForNormalizedTable("tbl", tableSchema, "_peerdb_soft_deleted", "", "main",
+		&protos.ClickhouseTableSettings{
+			Engine:      protos.ClickhouseTableEngine_CH_ENGINE_REPLICATED_REPLACING_MERGE_TREE,
+			OrderBy:     []string{"toDate(created_at)", "id"},
+			PartitionBy: "toYYYYMM(created_at)",
+			Ttl:         "created_at + INTERVAL 30 DAY",
+		}, protos.NormalizeMode_NORMALIZE_MODE_MERGE)
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE IF NOT EXISTS `tbl` ON CLUSTER `main` (`id` Int64, `created_at` DateTime64(6), "+
+		"`_peerdb_soft_deleted` UInt8 DEFAULT 0, `_peerdb_is_deleted` UInt8, `_peerdb_version` Int64) "+
+		"ENGINE = ReplicatedReplacingMergeTree(`_peerdb_version`, `_peerdb_soft_deleted`) "+
+		"PARTITION BY toYYYYMM(created_at) ORDER BY (toDate(created_at),id) TTL created_at + INTERVAL 30 DAY", stmt)
+
+	stmt, err = generateCreateTableSQLForNormalizedTable("nopkey", &protos.TableSchema{}, "", "", "", nil,
+		protos.NormalizeMode_NORMALIZE_MODE_MERGE)
+	require.NoError(t, err)
+	require.Contains(t, stmt, "ORDER BY tuple() SETTINGS non_replicated_deduplication_window = 1000")
+
+	stmt, err = generateCreateTableSQLForNormalizedTable("nopkey", &protos.TableSchema{}, "", "", "main", nil,
+		protos.NormalizeMode_NORMALIZE_MODE_MERGE)
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(stmt, "ORDER BY tuple()"))
+}
+
+func TestGenerateCreateTableSQLForCompositeColumns(t *testing.T) {
+	members := []*protos.FieldDescription{
+		{Name: "street", Type: string(qvalue.QValueKindString)},
+		{Name: "zip", Type: string(qvalue.QValueKindInt32)},
+	}
+	tableSchema := &protos.TableSchema{
+		Columns: []*protos.FieldDescription{
+			{Name: "id", Type: string(qvalue.QValueKindInt64)},
+			{Name: "home", Type: string(qvalue.QValueKindStruct), TypeName: "address", Fields: members},
+			{Name: "previous", Type: string(qvalue.QValueKindArrayOf(qvalue.QValueKindStruct)), TypeName: "address", Fields: members},
+			{Name: "ids", Type: string(qvalue.QValueKindArrayOf(qvalue.QValueKindUUID))},
+		},
+		PrimaryKeyColumns: []string{"id"},
+	}
+
+	stmt, err := generateCreateTableSQLForNormalizedTable("tbl", tableSchema, "", "", "", nil,
+		protos.NormalizeMode_NORMALIZE_MODE_MERGE)
+	require.NoError(t, err)
+	require.Contains(t, stmt, "`home` Tuple(`street` String, `zip` Int32), "+
+		"`previous` Array(Tuple(`street` String, `zip` Int32)), `ids` Array(UUID), ")
+}
+
+func TestGenerateCreateTableSQLForHistoryTable(t *testing.T) {
+	tableSchema := &protos.TableSchema{
+		Columns: []*protos.FieldDescription{
+			{Name: "id", Type: string(qvalue.QValueKindInt64)},
+		},
+		PrimaryKeyColumns: []string{"id"},
+	}
+
+	stmt, err := generateCreateTableSQLForNormalizedTable("tbl", tableSchema, "_peerdb_soft_deleted", "", "", nil,
+		protos.NormalizeMode_NORMALIZE_MODE_CHANGELOG)
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE IF NOT EXISTS `tbl` (`id` Int64, `_peerdb_op` String, "+
+		"`_peerdb_commit_time` DateTime64(9), `_peerdb_lsn` Int64, `_peerdb_before` Nullable(String), `_peerdb_version` Int64) "+
+		"ENGINE = ReplacingMergeTree(`_peerdb_version`) PRIMARY KEY (`id`) ORDER BY (`id`,`_peerdb_lsn`,`_peerdb_version`)", stmt)
+
+	_, err = generateCreateTableSQLForNormalizedTable("tbl", tableSchema, "", "", "", nil,
+		protos.NormalizeMode_NORMALIZE_MODE_SCD2)
+	require.Error(t, err)
+}
@@ -39,6 +39,12 @@ func (c *ClickhouseConnector) SyncQRepRecords(
 	}
 	c.logger.Info("Called QRep sync function and obtained table schema", flowLog)
 
+	// snapshots keep going through S3 when a stage is available, it scales better for large tables
+	if c.credsProvider == nil {
+		nativeSync := NewClickhouseNativeSyncMethod(config, c)
+		return nativeSync.SyncQRepRecords(ctx, config, partition, tblSchema, stream)
+	}
+
 	avroSync := NewClickhouseAvroSyncMethod(config, c)
 
 	return avroSync.SyncQRepRecords(ctx, config, partition, tblSchema, stream)
@@ -108,7 +114,8 @@ func (c *ClickhouseConnector) SetupQRepMetadataTables(ctx context.Context, confi
 	}
 
 	if config.WriteMode.WriteType == protos.QRepWriteType_QREP_WRITE_MODE_OVERWRITE {
-		_, err = c.database.ExecContext(ctx, "TRUNCATE TABLE "+config.DestinationTableIdentifier)
+		_, err = c.database.ExecContext(ctx,
+			"TRUNCATE TABLE "+config.DestinationTableIdentifier+onClusterClause(c.config.Cluster))
 		if err != nil {
 			return fmt.Errorf("failed to TRUNCATE table before query replication: %w", err)
 		}
@@ -120,16 +127,17 @@ func (c *ClickhouseConnector) SetupQRepMetadataTables(ctx context.Context, confi
 func (c *ClickhouseConnector) createQRepMetadataTable(ctx context.Context) error {
 	// Define the schema
 	schemaStatement := `
-	CREATE TABLE IF NOT EXISTS %s (
+	CREATE TABLE IF NOT EXISTS %s%s (
 		flowJobName String,
 		partitionID String,
 		syncPartition String,
 		syncStartTime DateTime64,
 		syncFinishTime DateTime64
-		) ENGINE = MergeTree()
+		) ENGINE = %s()
 		ORDER BY partitionID;
 	`
-	queryString := fmt.Sprintf(schemaStatement, qRepMetadataTableName)
+	queryString := fmt.Sprintf(schemaStatement, qRepMetadataTableName,
+		onClusterClause(c.config.Cluster), c.mergeTreeEngine("MergeTree"))
 	_, err := c.database.ExecContext(ctx, queryString)
 	if err != nil {
 		c.logger.Error("failed to create table "+qRepMetadataTableName,
@@ -155,7 +163,7 @@ func (c *ClickhouseConnector) CleanupQRepFlow(ctx context.Context, config *proto
 // dropStage drops the stage for the given job.
 func (c *ClickhouseConnector) dropStage(ctx context.Context, stagingPath string, job string) error {
 	// if s3 we need to delete the contents of the bucket
-	if strings.HasPrefix(stagingPath, "s3://") {
+	if strings.HasPrefix(stagingPath, "s3://") && c.credsProvider != nil {
 		s3o, err := utils.NewS3BucketAndPrefix(stagingPath)
 		if err != nil {
 			c.logger.Error("failed to create S3 bucket and prefix", slog.Any("error", err))
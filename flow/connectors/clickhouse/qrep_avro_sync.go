@@ -15,6 +15,7 @@ import (
 	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/model"
 	"github.com/PeerDB-io/peer-flow/model/qvalue"
+	"github.com/PeerDB-io/peer-flow/otel_tracing"
 	"github.com/PeerDB-io/peer-flow/shared"
 )
 
@@ -52,7 +53,10 @@ func (s *ClickhouseAvroSyncMethod) CopyStageToDestination(ctx context.Context, a
 		s.config.DestinationTableIdentifier, avroFileUrl,
 		creds.AWS.AccessKeyID, creds.AWS.SecretAccessKey, creds.AWS.SessionToken)
 
-	_, err = s.connector.database.ExecContext(ctx, query)
+	copyCtx, span := otel_tracing.Start(ctx, "clickhouse.CopyStageToDestination",
+		otel_tracing.Table(s.config.DestinationTableIdentifier))
+	_, err = s.connector.database.ExecContext(copyCtx, query)
+	otel_tracing.End(span, err)
 
 	return err
 }
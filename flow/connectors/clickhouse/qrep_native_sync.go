@@ -0,0 +1,431 @@
+package connclickhouse
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/activity"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+	"github.com/PeerDB-io/peer-flow/model"
+	"github.com/PeerDB-io/peer-flow/model/qvalue"
+)
+
+const defaultNativeInsertBatchSize = 100_000
+
+// ClickhouseNativeSyncMethod streams records straight into Clickhouse over the native protocol,
+// records are sent as INSERT blocks of up to InsertBatchSize rows, each committed on its own
+type ClickhouseNativeSyncMethod struct {
+	config    *protos.QRepConfig
+	connector *ClickhouseConnector
+}
+
+func NewClickhouseNativeSyncMethod(
+	config *protos.QRepConfig,
+	connector *ClickhouseConnector,
+) *ClickhouseNativeSyncMethod {
+	return &ClickhouseNativeSyncMethod{
+		config:    config,
+		connector: connector,
+	}
+}
+
+func (s *ClickhouseNativeSyncMethod) batchSize() int {
+	if s.connector.config.InsertBatchSize == 0 {
+		return defaultNativeInsertBatchSize
+	}
+	return int(s.connector.config.InsertBatchSize)
+}
+
+// SyncRecords inserts batch syncBatchID into the raw table. The batch is sent as several blocks committed
+// one by one, so rows of an earlier attempt which failed partway through are deleted first
+func (s *ClickhouseNativeSyncMethod) SyncRecords(
+	ctx context.Context,
+	dstTableSchema []*sql.ColumnType,
+	stream *model.QRecordStream,
+	syncBatchID int64,
+) (int, error) {
+	if err := s.deleteRawBatch(ctx, syncBatchID); err != nil {
+		return 0, err
+	}
+	numRecords, err := s.insertStream(ctx, dstTableSchema, stream, "")
+	if err != nil {
+		return 0, err
+	}
+	s.connector.logger.Info(fmt.Sprintf("inserted %d records over native protocol", numRecords),
+		slog.String("destinationTable", s.config.DestinationTableIdentifier))
+	return numRecords, nil
+}
+
+func (s *ClickhouseNativeSyncMethod) deleteRawBatch(ctx context.Context, syncBatchID int64) error {
+	rawTable := s.config.DestinationTableIdentifier
+	var numRows uint64
+	//nolint:gosec
+	if err := s.connector.database.QueryRowContext(ctx,
+		fmt.Sprintf("SELECT count() FROM %s WHERE _peerdb_batch_id = ?", rawTable), syncBatchID,
+	).Scan(&numRows); err != nil {
+		return fmt.Errorf("failed to check raw table for rows of batch %d: %w", syncBatchID, err)
+	}
+	if numRows == 0 {
+		return nil
+	}
+
+	s.connector.logger.Warn("deleting rows of an earlier attempt to sync batch",
+		slog.Int64("syncBatchID", syncBatchID), slog.Uint64("rows", numRows))
+	if _, err := s.connector.database.ExecContext(ctx,
+		deleteRawBatchSQL(rawTable, s.connector.config.Cluster), syncBatchID); err != nil {
+		return fmt.Errorf("failed to delete rows of batch %d from raw table: %w", syncBatchID, err)
+	}
+	return nil
+}
+
+// deleteRawBatchSQL waits for the delete to apply on every replica, so the batch isn't inserted alongside rows pending deletion
+func deleteRawBatchSQL(rawTable string, cluster string) string {
+	return fmt.Sprintf("ALTER TABLE %s%s DELETE WHERE _peerdb_batch_id = ? SETTINGS mutations_sync = 2",
+		rawTable, onClusterClause(cluster))
+}
+
+// SyncQRepRecords inserts the records of a partition. Blocks committed by an earlier attempt of the partition
+// are skipped by Clickhouse, as every block carries a deduplication token made of the partition id and its position
+func (s *ClickhouseNativeSyncMethod) SyncQRepRecords(
+	ctx context.Context,
+	config *protos.QRepConfig,
+	partition *protos.QRepPartition,
+	dstTableSchema []*sql.ColumnType,
+	stream *model.QRecordStream,
+) (int, error) {
+	startTime := time.Now()
+	dedupToken := fmt.Sprintf("%s_%s", config.FlowJobName, partition.PartitionId)
+	numRecords, err := s.insertStream(ctx, dstTableSchema, stream, dedupToken)
+	if err != nil {
+		s.connector.logger.Error("Failed to insert records into Clickhouse", slog.Any("error", err))
+		return 0, err
+	}
+
+	// reuse the metadata bookkeeping of the Avro path
+	avroSync := NewClickhouseAvroSyncMethod(config, s.connector)
+	if err := avroSync.insertMetadata(ctx, partition, config.FlowJobName, startTime); err != nil {
+		return -1, err
+	}
+
+	activity.RecordHeartbeat(ctx, "finished syncing records")
+
+	return numRecords, nil
+}
+
+func (s *ClickhouseNativeSyncMethod) insertStream(
+	ctx context.Context,
+	dstTableSchema []*sql.ColumnType,
+	stream *model.QRecordStream,
+	dedupToken string,
+) (int, error) {
+	schema := stream.Schema()
+	sourceIdx := make(map[string]int, len(schema.Fields))
+	for i, field := range schema.Fields {
+		sourceIdx[strings.ToLower(field.Name)] = i
+	}
+
+	columns := make([]*sql.ColumnType, 0, len(dstTableSchema))
+	columnIdx := make([]int, 0, len(dstTableSchema))
+	quotedColumns := make([]string, 0, len(dstTableSchema))
+	for _, col := range dstTableSchema {
+		colName := col.Name()
+		if strings.EqualFold(colName, s.config.SoftDeleteColName) ||
+			strings.EqualFold(colName, signColName) ||
+			strings.EqualFold(colName, s.config.SyncedAtColName) ||
+			strings.EqualFold(colName, versionColName) {
+			continue
+		}
+		idx, ok := sourceIdx[strings.ToLower(colName)]
+		if !ok {
+			return 0, fmt.Errorf("column %s of table %s not present in source records",
+				colName, s.config.DestinationTableIdentifier)
+		}
+		columns = append(columns, col)
+		columnIdx = append(columnIdx, idx)
+		quotedColumns = append(quotedColumns, "`"+colName+"`")
+	}
+	//nolint:gosec
+	insertSQL := fmt.Sprintf("INSERT INTO %s (%s)", s.config.DestinationTableIdentifier, strings.Join(quotedColumns, ","))
+
+	batchSize := s.batchSize()
+	numRecords := 0
+	args := make([]any, len(columns))
+	for block := 0; ; block++ {
+		blockCtx := ctx
+		if dedupToken != "" {
+			blockCtx = clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{
+				"insert_deduplication_token": fmt.Sprintf("%s_%d", dedupToken, block),
+			}))
+		}
+		inserted, done, err := s.insertBatch(blockCtx, insertSQL, columns, columnIdx, args, batchSize, stream)
+		numRecords += inserted
+		if err != nil {
+			return 0, err
+		}
+		if done {
+			break
+		}
+	}
+
+	if err := stream.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read records from stream: %w", err)
+	}
+	return numRecords, nil
+}
+
+// insertBatch sends up to batchSize records as one block, done reports whether the stream was exhausted
+func (s *ClickhouseNativeSyncMethod) insertBatch(
+	ctx context.Context,
+	insertSQL string,
+	columns []*sql.ColumnType,
+	columnIdx []int,
+	args []any,
+	batchSize int,
+	stream *model.QRecordStream,
+) (int, bool, error) {
+	tx, err := s.connector.database.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to begin insert batch: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			s.connector.logger.Error("failed to rollback insert batch", slog.Any("error", err))
+		}
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, insertSQL)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to prepare insert batch: %w", err)
+	}
+	defer stmt.Close()
+
+	numRecords := 0
+	done := false
+	for numRecords < batchSize {
+		record, ok := <-stream.Records
+		if !ok {
+			done = true
+			break
+		}
+		for i, col := range columns {
+			val, err := qvalueToClickhouseNative(col.DatabaseTypeName(), record[columnIdx[i]])
+			if err != nil {
+				return 0, false, fmt.Errorf("failed to convert column %s: %w", col.Name(), err)
+			}
+			args[i] = val
+		}
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return 0, false, fmt.Errorf("failed to append record to insert batch: %w", err)
+		}
+		numRecords += 1
+	}
+
+	if numRecords == 0 {
+		return 0, done, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, false, fmt.Errorf("failed to send insert batch: %w", err)
+	}
+	return numRecords, done, nil
+}
+
+// qvalueToClickhouseNative converts a QValue to the Go type the driver expects for the column,
+// the driver does no implicit conversion between numeric widths
+func qvalueToClickhouseNative(dbType string, qv qvalue.QValue) (any, error) {
+	if qv == nil {
+		return nil, nil
+	}
+	val := qv.Value()
+	if val == nil {
+		return nil, nil
+	}
+
+	colType := dbType
+	for _, wrapper := range []string{"Nullable(", "LowCardinality("} {
+		if strings.HasPrefix(colType, wrapper) {
+			colType = strings.TrimSuffix(strings.TrimPrefix(colType, wrapper), ")")
+		}
+	}
+
+	switch colType {
+	case "Int8", "Int16", "Int32", "Int64", "UInt8", "UInt16", "UInt32", "UInt64":
+		i, ok := nativeInt64(val)
+		if !ok {
+			return nil, fmt.Errorf("cannot insert %T into %s", val, dbType)
+		}
+		switch colType {
+		case "Int8":
+			return int8(i), nil
+		case "Int16":
+			return int16(i), nil
+		case "Int32":
+			return int32(i), nil
+		case "Int64":
+			return i, nil
+		case "UInt8":
+			return uint8(i), nil
+		case "UInt16":
+			return uint16(i), nil
+		case "UInt32":
+			return uint32(i), nil
+		default:
+			return uint64(i), nil
+		}
+	case "Float32", "Float64":
+		var f float64
+		switch v := val.(type) {
+		case float32:
+			f = float64(v)
+		case float64:
+			f = v
+		default:
+			i, ok := nativeInt64(val)
+			if !ok {
+				return nil, fmt.Errorf("cannot insert %T into %s", val, dbType)
+			}
+			f = float64(i)
+		}
+		if colType == "Float32" {
+			return float32(f), nil
+		}
+		return f, nil
+	case "Bool":
+		if b, ok := val.(bool); ok {
+			return b, nil
+		}
+		i, ok := nativeInt64(val)
+		if !ok {
+			return nil, fmt.Errorf("cannot insert %T into %s", val, dbType)
+		}
+		return i != 0, nil
+	case "UUID":
+		if v, ok := qv.(qvalue.QValueUUID); ok {
+			return uuid.UUID(v.Val), nil
+		}
+		return val, nil
+	case "String":
+		return qvalueToClickhouseString(qv, val)
+	}
+
+	if strings.HasPrefix(colType, "FixedString(") {
+		return qvalueToClickhouseString(qv, val)
+	}
+	switch v := qv.(type) {
+	case qvalue.QValueArray:
+		elemType := strings.TrimSuffix(strings.TrimPrefix(colType, "Array("), ")")
+		elements := make([]any, 0, len(v.Val))
+		for _, element := range v.Val {
+			elementVal, err := qvalueToClickhouseNative(elemType, element)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, elementVal)
+		}
+		return elements, nil
+	case qvalue.QValueStruct, qvalue.QValueRange, qvalue.QValueMultirange:
+		return clickhouseTupleValue(qv), nil
+	}
+	// Decimal, Date and DateTime columns accept decimal.Decimal and time.Time, arrays are already typed slices
+	return val, nil
+}
+
+// clickhouseTupleValue converts composites and ranges to maps of their members for named Tuple columns
+func clickhouseTupleValue(qv qvalue.QValue) any {
+	switch v := qv.(type) {
+	case nil:
+		return nil
+	case qvalue.QValueStruct:
+		members := make(map[string]any, len(v.Val))
+		for name, member := range v.Val {
+			if memberQv, ok := member.(qvalue.QValue); ok {
+				members[name] = clickhouseTupleValue(memberQv)
+			} else {
+				members[name] = member
+			}
+		}
+		return members
+	case qvalue.QValueArray:
+		elements := make([]any, 0, len(v.Val))
+		for _, element := range v.Val {
+			elements = append(elements, clickhouseTupleValue(element))
+		}
+		return elements
+	case qvalue.QValueRange:
+		return v.Val.Map(func(bound any) any { return bound })
+	case qvalue.QValueMultirange:
+		ranges := make([]any, 0, len(v.Val))
+		for _, r := range v.Val {
+			ranges = append(ranges, r.Map(func(bound any) any { return bound }))
+		}
+		return ranges
+	case qvalue.QValueUUID:
+		return uuid.UUID(v.Val)
+	default:
+		return qv.Value()
+	}
+}
+
+func qvalueToClickhouseString(qv qvalue.QValue, val any) (string, error) {
+	switch v := qv.(type) {
+	case qvalue.QValueQChar:
+		return string([]byte{v.Val}), nil
+	case qvalue.QValueBytes:
+		return string(v.Val), nil
+	case qvalue.QValueBit:
+		return string(v.Val), nil
+	case qvalue.QValueUUID:
+		return uuid.UUID(v.Val).String(), nil
+	case qvalue.QValueTime:
+		return v.Val.Format("15:04:05.999999"), nil
+	case qvalue.QValueTimeTZ:
+		return v.Val.Format("15:04:05.999999-0700"), nil
+	case qvalue.QValueStruct:
+		b, err := json.Marshal(v.JSONValue())
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal struct: %w", err)
+		}
+		return string(b), nil
+	}
+	if s, ok := val.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(val), nil
+}
+
+func nativeInt64(val any) (int64, bool) {
+	switch v := val.(type) {
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,51 @@
+package connclickhouse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PeerDB-io/peer-flow/model/qvalue"
+)
+
+func TestQValueToClickhouseNative(t *testing.T) {
+	id := uuid.New()
+	tm := time.Date(2024, 1, 1, 12, 30, 45, 123456000, time.UTC)
+	cases := []struct {
+		dbType   string
+		qv       qvalue.QValue
+		expected any
+	}{
+		{"Int32", qvalue.QValueInt64{Val: 2}, int32(2)},
+		{"Nullable(Int16)", qvalue.QValueInt32{Val: 7}, int16(7)},
+		{"Nullable(Int64)", qvalue.QValueNull(qvalue.QValueKindInt64), nil},
+		{"Float32", qvalue.QValueFloat64{Val: 1.5}, float32(1.5)},
+		{"Bool", qvalue.QValueBoolean{Val: true}, true},
+		{"String", qvalue.QValueBytes{Val: []byte("abc")}, "abc"},
+		{"LowCardinality(String)", qvalue.QValueString{Val: "x"}, "x"},
+		{"FixedString(1)", qvalue.QValueQChar{Val: 'c'}, "c"},
+		{"String", qvalue.QValueTime{Val: tm}, "12:30:45.123456"},
+		{"String", qvalue.QValueStruct{Val: map[string]interface{}{"a": 1}}, `{"a":1}`},
+		{"UUID", qvalue.QValueUUID{Val: id}, id},
+		{"String", qvalue.QValueUUID{Val: id}, id.String()},
+		{"DateTime64(6)", qvalue.QValueTimestamp{Val: tm}, tm},
+	}
+
+	for _, tc := range cases {
+		val, err := qvalueToClickhouseNative(tc.dbType, tc.qv)
+		require.NoError(t, err, tc.dbType)
+		require.Equal(t, tc.expected, val, tc.dbType)
+	}
+
+	_, err := qvalueToClickhouseNative("Int32", qvalue.QValueString{Val: "1"})
+	require.Error(t, err)
+}
+
+func TestDeleteRawBatchSQL(t *testing.T) {
+	require.Equal(t, "ALTER TABLE _peerdb_raw_m DELETE WHERE _peerdb_batch_id = ? SETTINGS mutations_sync = 2",
+		deleteRawBatchSQL("_peerdb_raw_m", ""))
+	require.Equal(t, "ALTER TABLE _peerdb_raw_m ON CLUSTER `c1` DELETE WHERE _peerdb_batch_id = ? SETTINGS mutations_sync = 2",
+		deleteRawBatchSQL("_peerdb_raw_m", "c1"))
+}
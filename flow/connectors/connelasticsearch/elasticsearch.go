@@ -95,10 +95,99 @@ func (esc *ElasticsearchConnector) CreateRawTable(ctx context.Context,
 	return &protos.CreateRawTableOutput{TableIdentifier: "n/a"}, nil
 }
 
-// we handle schema changes by not handling them since no mapping is being enforced right now
+func (esc *ElasticsearchConnector) StartSetupNormalizedTables(_ context.Context) (any, error) {
+	return nil, nil
+}
+
+func (esc *ElasticsearchConnector) FinishSetupNormalizedTables(_ context.Context, _ any) error {
+	return nil
+}
+
+func (esc *ElasticsearchConnector) CleanupSetupNormalizedTables(_ context.Context, _ any) {
+}
+
+// SetupNormalizedTable creates the index with explicit mappings, so values aren't left to dynamic mapping
+func (esc *ElasticsearchConnector) SetupNormalizedTable(
+	ctx context.Context,
+	tx any,
+	config *protos.SetupNormalizedTableBatchInput,
+	tableIdentifier string,
+	tableSchema *protos.TableSchema,
+) (bool, error) {
+	existsRes, err := esc.client.Indices.Exists([]string{tableIdentifier},
+		esc.client.Indices.Exists.WithContext(ctx))
+	if err != nil {
+		return false, fmt.Errorf("[es] failed to check if index %s exists: %w", tableIdentifier, err)
+	}
+	existsRes.Body.Close()
+	if existsRes.StatusCode == http.StatusOK {
+		return true, nil
+	} else if existsRes.StatusCode != http.StatusNotFound {
+		return false, fmt.Errorf("[es] failed to check if index %s exists: %s", tableIdentifier, existsRes.Status())
+	}
+
+	properties := make(map[string]any, len(tableSchema.Columns))
+	for _, column := range tableSchema.Columns {
+		if mapping := qvalueKindToMapping(qvalue.QValueKind(column.Type), column.TypeModifier); mapping != nil {
+			properties[column.Name] = mapping
+		}
+	}
+	body, err := json.Marshal(map[string]any{
+		"mappings": map[string]any{"properties": properties},
+	})
+	if err != nil {
+		return false, fmt.Errorf("[es] failed to marshal mappings for index %s: %w", tableIdentifier, err)
+	}
+
+	createRes, err := esc.client.Indices.Create(tableIdentifier,
+		esc.client.Indices.Create.WithContext(ctx),
+		esc.client.Indices.Create.WithBody(bytes.NewReader(body)))
+	if err != nil {
+		return false, fmt.Errorf("[es] failed to create index %s: %w", tableIdentifier, err)
+	}
+	defer createRes.Body.Close()
+	if createRes.IsError() {
+		return false, fmt.Errorf("[es] failed to create index %s: %s", tableIdentifier, createRes.String())
+	}
+	esc.logger.Info("[es] created index with mappings", slog.String("index", tableIdentifier))
+	return false, nil
+}
+
+// ReplayTableSchemaDeltas adds mappings for added columns, existing mappings can't change in place
 func (esc *ElasticsearchConnector) ReplayTableSchemaDeltas(ctx context.Context,
 	flowJobName string, schemaDeltas []*protos.TableSchemaDelta,
 ) error {
+	for _, schemaDelta := range schemaDeltas {
+		if schemaDelta == nil || len(schemaDelta.AddedColumns) == 0 {
+			continue
+		}
+
+		properties := make(map[string]any, len(schemaDelta.AddedColumns))
+		for _, addedColumn := range schemaDelta.AddedColumns {
+			if mapping := qvalueKindToMapping(qvalue.QValueKind(addedColumn.Type), addedColumn.TypeModifier); mapping != nil {
+				properties[addedColumn.Name] = mapping
+			}
+		}
+		if len(properties) == 0 {
+			continue
+		}
+		body, err := json.Marshal(map[string]any{"properties": properties})
+		if err != nil {
+			return fmt.Errorf("[es] failed to marshal mappings for index %s: %w", schemaDelta.DstTableName, err)
+		}
+
+		res, err := esc.client.Indices.PutMapping([]string{schemaDelta.DstTableName}, bytes.NewReader(body),
+			esc.client.Indices.PutMapping.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("[es] failed to update mappings for index %s: %w", schemaDelta.DstTableName, err)
+		}
+		res.Body.Close()
+		if res.IsError() {
+			return fmt.Errorf("[es] failed to update mappings for index %s: %s", schemaDelta.DstTableName, res.Status())
+		}
+		esc.logger.Info("[es] added mappings for new columns",
+			slog.String("index", schemaDelta.DstTableName), slog.Int("columns", len(properties)))
+	}
 	return nil
 }
 
@@ -106,12 +195,11 @@ func recordItemsProcessor(items model.RecordItems) ([]byte, error) {
 	qRecordJsonMap := make(map[string]any)
 
 	for key, val := range items.ColToVal {
-		if r, ok := val.(qvalue.QValueJSON); ok { // JSON is stored as a string, fix that
-			qRecordJsonMap[key] = json.RawMessage(
-				shared.UnsafeFastStringToReadOnlyBytes(r.Val))
-		} else {
-			qRecordJsonMap[key] = val.Value()
+		esVal, err := qvalueToElasticsearch(val)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert column %s: %w", key, err)
 		}
+		qRecordJsonMap[key] = esVal
 	}
 
 	return json.Marshal(qRecordJsonMap)
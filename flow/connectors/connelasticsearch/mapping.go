@@ -0,0 +1,155 @@
+package connelasticsearch
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/PeerDB-io/peer-flow/datatypes"
+	"github.com/PeerDB-io/peer-flow/model/qvalue"
+	"github.com/PeerDB-io/peer-flow/shared"
+)
+
+// scaled_float is backed by a long, larger precisions would lose digits
+const maxScaledFloatPrecision = 18
+
+var textMapping = map[string]any{
+	"type": "text",
+	"fields": map[string]any{
+		"keyword": map[string]any{"type": "keyword", "ignore_above": 256},
+	},
+}
+
+// qvalueKindToMapping returns the field mapping for a column,
+// nil leaves the field to dynamic mapping.
+// arrays map to their element type since every Elasticsearch field may hold multiple values
+func qvalueKindToMapping(kind qvalue.QValueKind, typeModifier int32) map[string]any {
+	if kind.IsGenericArray() {
+		return qvalueKindToMapping(kind.ArrayElementKind(), typeModifier)
+	}
+	switch kind {
+	case qvalue.QValueKindBoolean, qvalue.QValueKindArrayBoolean:
+		return map[string]any{"type": "boolean"}
+	case qvalue.QValueKindInt16, qvalue.QValueKindArrayInt16:
+		return map[string]any{"type": "short"}
+	case qvalue.QValueKindInt32, qvalue.QValueKindArrayInt32:
+		return map[string]any{"type": "integer"}
+	case qvalue.QValueKindInt64, qvalue.QValueKindArrayInt64:
+		return map[string]any{"type": "long"}
+	case qvalue.QValueKindFloat32, qvalue.QValueKindArrayFloat32:
+		return map[string]any{"type": "float"}
+	case qvalue.QValueKindFloat64, qvalue.QValueKindArrayFloat64:
+		return map[string]any{"type": "double"}
+	case qvalue.QValueKindVector:
+		if typeModifier <= 0 {
+			// dense_vector infers its dimensions from the first document
+			return map[string]any{"type": "dense_vector"}
+		}
+		return map[string]any{"type": "dense_vector", "dims": typeModifier}
+	case qvalue.QValueKindNumeric:
+		if typeModifier == -1 {
+			// unconstrained numeric, keep every digit
+			return map[string]any{"type": "keyword"}
+		}
+		precision, scale := datatypes.ParseNumericTypmod(typeModifier)
+		if precision > maxScaledFloatPrecision {
+			return map[string]any{"type": "keyword"}
+		}
+		return map[string]any{"type": "scaled_float", "scaling_factor": math.Pow10(int(scale))}
+	case qvalue.QValueKindString, qvalue.QValueKindArrayString, qvalue.QValueKindQChar:
+		return textMapping
+	case qvalue.QValueKindEnum:
+		return map[string]any{"type": "keyword"}
+	case qvalue.QValueKindUUID, qvalue.QValueKindTime, qvalue.QValueKindTimeTZ, qvalue.QValueKindInterval,
+		qvalue.QValueKindCIDR, qvalue.QValueKindINET, qvalue.QValueKindMacaddr, qvalue.QValueKindInvalid:
+		return map[string]any{"type": "keyword"}
+	case qvalue.QValueKindTimestamp, qvalue.QValueKindTimestampTZ,
+		qvalue.QValueKindArrayTimestamp, qvalue.QValueKindArrayTimestampTZ:
+		return map[string]any{"type": "date"}
+	case qvalue.QValueKindDate, qvalue.QValueKindArrayDate:
+		return map[string]any{"type": "date", "format": "strict_date"}
+	case qvalue.QValueKindBytes, qvalue.QValueKindBit:
+		return map[string]any{"type": "binary"}
+	case qvalue.QValueKindHStore:
+		return map[string]any{"type": "flattened"}
+	case qvalue.QValueKindGeometry, qvalue.QValueKindGeography:
+		return map[string]any{"type": "geo_shape"}
+	case qvalue.QValueKindPoint:
+		return map[string]any{"type": "geo_point"}
+	default:
+		// json may hold any value, struct has no fixed shape
+		return nil
+	}
+}
+
+// qvalueToElasticsearch converts a value to the JSON form its mapping expects
+func qvalueToElasticsearch(qv qvalue.QValue) (any, error) {
+	switch v := qv.(type) {
+	case qvalue.QValueJSON: // JSON is stored as a string, fix that
+		return json.RawMessage(shared.UnsafeFastStringToReadOnlyBytes(v.Val)), nil
+	case qvalue.QValueHStore:
+		hstoreJSON, err := datatypes.ParseHstore(v.Val)
+		if err != nil {
+			return nil, err
+		}
+		return json.RawMessage(shared.UnsafeFastStringToReadOnlyBytes(hstoreJSON)), nil
+	case qvalue.QValueNumeric:
+		// scaled_float coerces strings, keyword keeps them as is
+		return v.Val.String(), nil
+	case qvalue.QValueRange:
+		return v.JSONValue(), nil
+	case qvalue.QValueMultirange:
+		return v.JSONValue(), nil
+	case qvalue.QValueStruct:
+		return v.JSONValue(), nil
+	case qvalue.QValueArray:
+		return v.JSONValue(), nil
+	case qvalue.QValueGeometry:
+		return geoShapeWKT(v.Val), nil
+	case qvalue.QValueGeography:
+		return geoShapeWKT(v.Val), nil
+	case qvalue.QValueUUID:
+		return uuid.UUID(v.Val).String(), nil
+	case qvalue.QValueQChar:
+		return string(v.Val), nil
+	case qvalue.QValueDate:
+		return v.Val.Format("2006-01-02"), nil
+	case qvalue.QValueArrayDate:
+		dates := make([]string, 0, len(v.Val))
+		for _, date := range v.Val {
+			dates = append(dates, date.Format("2006-01-02"))
+		}
+		return dates, nil
+	case qvalue.QValueTime:
+		return v.Val.Format("15:04:05.999999"), nil
+	case qvalue.QValueTimeTZ:
+		return v.Val.Format("15:04:05.999999-0700"), nil
+	case qvalue.QValueFloat32:
+		if math.IsNaN(float64(v.Val)) || math.IsInf(float64(v.Val), 0) {
+			return nil, nil
+		}
+		return v.Val, nil
+	case qvalue.QValueFloat64:
+		if math.IsNaN(v.Val) || math.IsInf(v.Val, 0) {
+			return nil, nil
+		}
+		return v.Val, nil
+	default:
+		return qv.Value(), nil
+	}
+}
+
+// geoShapeWKT strips the SRID of EWKT, which geo_shape doesn't accept, invalid shapes are left out
+func geoShapeWKT(ewkt string) any {
+	if ewkt == "" {
+		return nil
+	}
+	if strings.HasPrefix(ewkt, "SRID=") {
+		if _, wkt, ok := strings.Cut(ewkt, ";"); ok {
+			return wkt
+		}
+	}
+	return ewkt
+}
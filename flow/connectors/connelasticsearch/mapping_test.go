@@ -0,0 +1,53 @@
+package connelasticsearch
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PeerDB-io/peer-flow/datatypes"
+	"github.com/PeerDB-io/peer-flow/model/qvalue"
+)
+
+func TestNumericMapping(t *testing.T) {
+	require.Equal(t, map[string]any{"type": "keyword"}, qvalueKindToMapping(qvalue.QValueKindNumeric, -1))
+	require.Equal(t, map[string]any{"type": "scaled_float", "scaling_factor": float64(100)},
+		qvalueKindToMapping(qvalue.QValueKindNumeric, datatypes.MakeNumericTypmod(10, 2)))
+	require.Equal(t, map[string]any{"type": "keyword"},
+		qvalueKindToMapping(qvalue.QValueKindNumeric, datatypes.MakeNumericTypmod(30, 2)))
+	require.Equal(t, map[string]any{"type": "geo_shape"}, qvalueKindToMapping(qvalue.QValueKindGeometry, -1))
+	require.Equal(t, map[string]any{"type": "date"}, qvalueKindToMapping(qvalue.QValueKindTimestampTZ, -1))
+	require.Nil(t, qvalueKindToMapping(qvalue.QValueKindJSON, -1))
+}
+
+func TestQValueToElasticsearch(t *testing.T) {
+	id := uuid.New()
+	record := map[string]qvalue.QValue{
+		"id":      qvalue.QValueUUID{Val: id},
+		"price":   qvalue.QValueNumeric{Val: decimal.RequireFromString("12.50")},
+		"day":     qvalue.QValueDate{Val: time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)},
+		"ratio":   qvalue.QValueFloat64{Val: math.NaN()},
+		"doc":     qvalue.QValueJSON{Val: `{"a":1}`},
+		"tags":    qvalue.QValueHStore{Val: `"k"=>"v"`},
+		"initial": qvalue.QValueQChar{Val: 'x'},
+		"shape":   qvalue.QValueGeometry{Val: "SRID=4326;POINT (1 2)"},
+		"area":    qvalue.QValueGeography{Val: "POLYGON ((0 0, 1 0, 1 1, 0 0))"},
+		"invalid": qvalue.QValueGeometry{Val: ""},
+	}
+	converted := make(map[string]any, len(record))
+	for k, v := range record {
+		val, err := qvalueToElasticsearch(v)
+		require.NoError(t, err)
+		converted[k] = val
+	}
+	body, err := json.Marshal(converted)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id":"`+id.String()+`","price":"12.5","day":"2024-02-03","ratio":null,`+
+		`"doc":{"a":1},"tags":{"k":"v"},"initial":"x","shape":"POINT (1 2)",`+
+		`"area":"POLYGON ((0 0, 1 0, 1 1, 0 0))","invalid":null}`, string(body))
+}
@@ -17,7 +17,6 @@ import (
 	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/model"
 	"github.com/PeerDB-io/peer-flow/model/qvalue"
-	"github.com/PeerDB-io/peer-flow/shared"
 )
 
 func (esc *ElasticsearchConnector) SetupQRepMetadataTables(ctx context.Context,
@@ -98,12 +97,12 @@ func (esc *ElasticsearchConnector) SyncQRepRecords(ctx context.Context, config *
 			docId = upsertKeyColsHash(qRecord, upsertKeyColIndices)
 		}
 		for i, field := range schema.Fields {
-			if r, ok := qRecord[i].(qvalue.QValueJSON); ok { // JSON is stored as a string, fix that
-				qRecordJsonMap[field.Name] = json.RawMessage(
-					shared.UnsafeFastStringToReadOnlyBytes(r.Val))
-			} else {
-				qRecordJsonMap[field.Name] = qRecord[i].Value()
+			esVal, err := qvalueToElasticsearch(qRecord[i])
+			if err != nil {
+				esc.logger.Error("[es] failed to convert record", slog.Any("error", err))
+				return 0, fmt.Errorf("[es] failed to convert column %s: %w", field.Name, err)
 			}
+			qRecordJsonMap[field.Name] = esVal
 		}
 		qRecordJsonBytes, err := json.Marshal(qRecordJsonMap)
 		if err != nil {
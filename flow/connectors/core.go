@@ -19,10 +19,13 @@ import (
 	conns3 "github.com/PeerDB-io/peer-flow/connectors/s3"
 	connsnowflake "github.com/PeerDB-io/peer-flow/connectors/snowflake"
 	connsqlserver "github.com/PeerDB-io/peer-flow/connectors/sqlserver"
+	"github.com/PeerDB-io/peer-flow/connectors/utils"
+	connwebhook "github.com/PeerDB-io/peer-flow/connectors/webhook"
 	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/logger"
 	"github.com/PeerDB-io/peer-flow/model"
 	"github.com/PeerDB-io/peer-flow/otel_metrics"
+	"github.com/PeerDB-io/peer-flow/shared"
 )
 
 type Connector interface {
@@ -81,6 +84,9 @@ type CDCPullConnectorCore interface {
 
 	// AddTablesToPublication adds additional tables added to a mirror to the publication also
 	AddTablesToPublication(ctx context.Context, req *protos.AddTablesToPublicationInput) error
+
+	// RemoveTablesFromPublication removes tables removed from a mirror from the publication also
+	RemoveTablesFromPublication(ctx context.Context, req *protos.RemoveTablesFromPublicationInput) error
 }
 
 type CDCPullConnector interface {
@@ -115,10 +121,9 @@ type NormalizedTablesConnector interface {
 	SetupNormalizedTable(
 		ctx context.Context,
 		tx any,
+		config *protos.SetupNormalizedTableBatchInput,
 		tableIdentifier string,
 		tableSchema *protos.TableSchema,
-		softDeleteColName string,
-		syncedAtColName string,
 	) (bool, error)
 }
 
@@ -175,6 +180,9 @@ type CDCNormalizeConnector interface {
 	// NormalizeRecords merges records pushed earlier into the destination table.
 	// This method should be idempotent, and should be able to be called multiple times with the same request.
 	NormalizeRecords(ctx context.Context, req *model.NormalizeRecordsRequest) (*model.NormalizeResponse, error)
+
+	// GetLastNormalizeBatchID gets the last batch normalized on the destination from the metadata table
+	GetLastNormalizeBatchID(ctx context.Context, jobName string) (int64, error)
 }
 
 type QRepPullConnector interface {
@@ -218,7 +226,53 @@ type RenameTablesConnector interface {
 	RenameTables(context.Context, *protos.RenameTablesInput) (*protos.RenameTablesOutput, error)
 }
 
+type RemoveTablesConnector interface {
+	Connector
+
+	// RemoveTables deletes rows of tables removed from a mirror from the raw table, dropping the tables unless KeepTables is set
+	RemoveTables(context.Context, *protos.RemoveTablesInput) error
+}
+
+type GetSequenceValuesConnector interface {
+	Connector
+
+	// GetSequenceValues returns the last value of sequences owned by columns of the given tables,
+	// skipping sequences which haven't handed out a value yet
+	GetSequenceValues(ctx context.Context, tableIdentifiers []string) ([]model.SequenceValue, error)
+}
+
+type SyncSequencesConnector interface {
+	Connector
+
+	// SyncSequences advances sequences owned by the given columns to at least their value, never moving one back
+	SyncSequences(ctx context.Context, values []model.SequenceValue) error
+}
+
+type CutoverConnector interface {
+	Connector
+
+	// FenceForCutover makes the tables read-only and returns the LSN of a fence following the last write to them
+	FenceForCutover(ctx context.Context, flowJobName string, tableIdentifiers []string) (int64, error)
+	// UnfenceForCutover makes the tables fenced for a mirror writable again
+	UnfenceForCutover(ctx context.Context, flowJobName string) error
+}
+
+type CountRowsConnector interface {
+	Connector
+
+	// CountRows counts rows of a table, leaving out those marked deleted when softDeleteColName is set
+	CountRows(ctx context.Context, tableIdentifier string, softDeleteColName string) (int64, error)
+}
+
+// GetConnector resolves secret references in config every time it is called,
+// so rotated credentials are picked up by the next connector
 func GetConnector(ctx context.Context, config *protos.Peer) (Connector, error) {
+	config, err := utils.ResolvePeerSecrets(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, shared.PeerNameKey, config.Name)
+
 	switch inner := config.Config.(type) {
 	case *protos.Peer_PostgresConfig:
 		return connpostgres.NewPostgresConnector(ctx, inner.PostgresConfig)
@@ -242,6 +296,8 @@ func GetConnector(ctx context.Context, config *protos.Peer) (Connector, error) {
 		return connpubsub.NewPubSubConnector(ctx, inner.PubsubConfig)
 	case *protos.Peer_ElasticsearchConfig:
 		return connelasticsearch.NewElasticsearchConnector(ctx, inner.ElasticsearchConfig)
+	case *protos.Peer_WebhookConfig:
+		return connwebhook.NewWebhookConnector(ctx, inner.WebhookConfig)
 	default:
 		return nil, errors.ErrUnsupported
 	}
@@ -307,6 +363,7 @@ var (
 	_ CDCSyncConnector = &conns3.S3Connector{}
 	_ CDCSyncConnector = &connclickhouse.ClickhouseConnector{}
 	_ CDCSyncConnector = &connelasticsearch.ElasticsearchConnector{}
+	_ CDCSyncConnector = &connwebhook.WebhookConnector{}
 
 	_ CDCSyncPgConnector = &connpostgres.PostgresConnector{}
 
@@ -322,6 +379,7 @@ var (
 	_ NormalizedTablesConnector = &connbigquery.BigQueryConnector{}
 	_ NormalizedTablesConnector = &connsnowflake.SnowflakeConnector{}
 	_ NormalizedTablesConnector = &connclickhouse.ClickhouseConnector{}
+	_ NormalizedTablesConnector = &connelasticsearch.ElasticsearchConnector{}
 
 	_ QRepPullConnector = &connpostgres.PostgresConnector{}
 	_ QRepPullConnector = &connsqlserver.SQLServerConnector{}
@@ -333,6 +391,7 @@ var (
 	_ QRepSyncConnector = &conns3.S3Connector{}
 	_ QRepSyncConnector = &connclickhouse.ClickhouseConnector{}
 	_ QRepSyncConnector = &connelasticsearch.ElasticsearchConnector{}
+	_ QRepSyncConnector = &connwebhook.WebhookConnector{}
 
 	_ QRepConsolidateConnector = &connsnowflake.SnowflakeConnector{}
 	_ QRepConsolidateConnector = &connclickhouse.ClickhouseConnector{}
@@ -340,6 +399,16 @@ var (
 	_ RenameTablesConnector = &connsnowflake.SnowflakeConnector{}
 	_ RenameTablesConnector = &connbigquery.BigQueryConnector{}
 
+	_ RemoveTablesConnector = &connpostgres.PostgresConnector{}
+	_ RemoveTablesConnector = &connsnowflake.SnowflakeConnector{}
+	_ RemoveTablesConnector = &connbigquery.BigQueryConnector{}
+	_ RemoveTablesConnector = &connclickhouse.ClickhouseConnector{}
+
+	_ GetSequenceValuesConnector = &connpostgres.PostgresConnector{}
+	_ SyncSequencesConnector     = &connpostgres.PostgresConnector{}
+	_ CutoverConnector           = &connpostgres.PostgresConnector{}
+	_ CountRowsConnector         = &connpostgres.PostgresConnector{}
+
 	_ ValidationConnector = &connsnowflake.SnowflakeConnector{}
 	_ ValidationConnector = &connclickhouse.ClickhouseConnector{}
 	_ ValidationConnector = &connbigquery.BigQueryConnector{}
@@ -19,6 +19,7 @@ import (
 	"github.com/PeerDB-io/peer-flow/logger"
 	"github.com/PeerDB-io/peer-flow/model"
 	"github.com/PeerDB-io/peer-flow/peerdbenv"
+	"github.com/PeerDB-io/peer-flow/prom_metrics"
 	"github.com/PeerDB-io/peer-flow/pua"
 	"github.com/PeerDB-io/peer-flow/shared"
 )
@@ -246,6 +247,7 @@ func (c *EventHubConnector) processBatch(
 				ls.Push(pua.LuaRecord.New(ls, record))
 				err := ls.PCall(1, -1, nil)
 				if err != nil {
+					prom_metrics.LuaScriptErrors.WithLabelValues(req.FlowJobName).Inc()
 					return 0, fmt.Errorf("script failed: %w", err)
 				}
 
@@ -292,12 +294,24 @@ func (c *EventHubConnector) processBatch(
 				// Scoped eventhub is of the form peer_name.eventhub_name.partition_column
 				// partition_column is the column in the table that is used to determine
 				// the partition key for the eventhub.
+				// Without a value for the partition column, the primary key is used
+				// so that changes to the same row land on the same partition.
 				partitionKey := event.Hub.PartitionKeyValue
 				if partitionKey == "" {
 					partitionColumn := event.Hub.PartitionKeyColumn
-					partitionValue := record.GetItems().GetColumnValue(partitionColumn).Value()
+					var partitionValue any
+					if qv := record.GetItems().GetColumnValue(partitionColumn); qv != nil {
+						partitionValue = qv.Value()
+					}
 					if partitionValue != nil {
 						partitionKey = fmt.Sprint(partitionValue)
+					} else {
+						var err error
+						partitionKey, err = model.RecToPartitionKey(req.TableNameSchemaMapping, record)
+						if err != nil {
+							c.logger.Error("failed to get partition key", slog.Any("error", err))
+							return 0, err
+						}
 					}
 
 					partitionKey = utils.HashedPartitionKey(partitionKey, ehConfig.PartitionCount)
@@ -15,6 +15,7 @@ import (
 	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/logger"
 	"github.com/PeerDB-io/peer-flow/peerdbenv"
+	"github.com/PeerDB-io/peer-flow/shared"
 )
 
 const (
@@ -22,9 +23,12 @@ const (
 	qrepTableName          = "metadata_qrep_partitions"
 )
 
+// PostgresMetadata keeps sync state in the catalog, a mirror with several destinations
+// has a row per destination peer
 type PostgresMetadata struct {
-	pool   *pgxpool.Pool
-	logger log.Logger
+	pool     *pgxpool.Pool
+	logger   log.Logger
+	peerName string
 }
 
 func NewPostgresMetadata(ctx context.Context) (*PostgresMetadata, error) {
@@ -33,16 +37,15 @@ func NewPostgresMetadata(ctx context.Context) (*PostgresMetadata, error) {
 		return nil, fmt.Errorf("failed to create catalog connection pool: %w", err)
 	}
 
-	return &PostgresMetadata{
-		pool:   pool,
-		logger: logger.LoggerFromCtx(ctx),
-	}, nil
+	return NewPostgresMetadataFromCatalog(ctx, logger.LoggerFromCtx(ctx), pool), nil
 }
 
-func NewPostgresMetadataFromCatalog(logger log.Logger, pool *pgxpool.Pool) *PostgresMetadata {
+func NewPostgresMetadataFromCatalog(ctx context.Context, logger log.Logger, pool *pgxpool.Pool) *PostgresMetadata {
+	peerName, _ := ctx.Value(shared.PeerNameKey).(string)
 	return &PostgresMetadata{
-		pool:   pool,
-		logger: logger,
+		pool:     pool,
+		logger:   logger,
+		peerName: peerName,
 	}
 }
 
@@ -74,7 +77,7 @@ func (p *PostgresMetadata) GetLastOffset(ctx context.Context, jobName string) (i
 	row := p.pool.QueryRow(ctx,
 		`SELECT last_offset FROM `+
 			lastSyncStateTableName+
-			` WHERE job_name = $1`, jobName)
+			` WHERE job_name = $1 AND peer_name = $2`, jobName, p.peerName)
 	var offset pgtype.Int8
 	err := row.Scan(&offset)
 	if err != nil {
@@ -93,7 +96,7 @@ func (p *PostgresMetadata) GetLastOffset(ctx context.Context, jobName string) (i
 
 func (p *PostgresMetadata) GetLastSyncBatchID(ctx context.Context, jobName string) (int64, error) {
 	row := p.pool.QueryRow(ctx,
-		`SELECT sync_batch_id FROM `+lastSyncStateTableName+` WHERE job_name = $1`, jobName)
+		`SELECT sync_batch_id FROM `+lastSyncStateTableName+` WHERE job_name = $1 AND peer_name = $2`, jobName, p.peerName)
 
 	var syncBatchID pgtype.Int8
 	err := row.Scan(&syncBatchID)
@@ -115,7 +118,7 @@ func (p *PostgresMetadata) GetLastNormalizeBatchID(ctx context.Context, jobName
 	rows := p.pool.QueryRow(ctx,
 		`SELECT normalize_batch_id FROM `+
 			lastSyncStateTableName+
-			` WHERE job_name = $1`, jobName)
+			` WHERE job_name = $1 AND peer_name = $2`, jobName, p.peerName)
 
 	var normalizeBatchID pgtype.Int8
 	err := rows.Scan(&normalizeBatchID)
@@ -136,12 +139,12 @@ func (p *PostgresMetadata) GetLastNormalizeBatchID(ctx context.Context, jobName
 func (p *PostgresMetadata) SetLastOffset(ctx context.Context, jobName string, offset int64) error {
 	p.logger.Info("updating last offset", "offset", offset)
 	_, err := p.pool.Exec(ctx, `
-		INSERT INTO `+lastSyncStateTableName+` (job_name, last_offset, sync_batch_id)
-		VALUES ($1, $2, $3)
-		ON CONFLICT (job_name)
+		INSERT INTO `+lastSyncStateTableName+` (job_name, peer_name, last_offset, sync_batch_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (job_name, peer_name)
 		DO UPDATE SET last_offset = GREATEST(`+lastSyncStateTableName+`.last_offset, excluded.last_offset),
 			updated_at = NOW()
-	`, jobName, offset, 0)
+	`, jobName, p.peerName, offset, 0)
 	if err != nil {
 		p.logger.Error("failed to update last offset", "error", err)
 		return err
@@ -153,14 +156,14 @@ func (p *PostgresMetadata) SetLastOffset(ctx context.Context, jobName string, of
 func (p *PostgresMetadata) FinishBatch(ctx context.Context, jobName string, syncBatchID int64, offset int64) error {
 	p.logger.Info("finishing batch", "SyncBatchID", syncBatchID, "offset", offset)
 	_, err := p.pool.Exec(ctx, `
-		INSERT INTO `+lastSyncStateTableName+` (job_name, last_offset, sync_batch_id)
-		VALUES ($1, $2, $3)
-		ON CONFLICT (job_name)
+		INSERT INTO `+lastSyncStateTableName+` (job_name, peer_name, last_offset, sync_batch_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (job_name, peer_name)
 		DO UPDATE SET
 			last_offset = GREATEST(`+lastSyncStateTableName+`.last_offset, excluded.last_offset),
 			sync_batch_id = GREATEST(`+lastSyncStateTableName+`.sync_batch_id, excluded.sync_batch_id),
 			updated_at = NOW()
-	`, jobName, offset, syncBatchID)
+	`, jobName, p.peerName, offset, syncBatchID)
 	if err != nil {
 		p.logger.Error("failed to finish batch", slog.Any("error", err))
 		return err
@@ -173,7 +176,7 @@ func (p *PostgresMetadata) UpdateNormalizeBatchID(ctx context.Context, jobName s
 	p.logger.Info("updating normalize batch id for job")
 	_, err := p.pool.Exec(ctx,
 		`UPDATE `+lastSyncStateTableName+
-			` SET normalize_batch_id=$2 WHERE job_name=$1`, jobName, batchID)
+			` SET normalize_batch_id=$3 WHERE job_name=$1 AND peer_name=$2`, jobName, p.peerName, batchID)
 	if err != nil {
 		p.logger.Error("failed to update normalize batch id", slog.Any("error", err))
 		return err
@@ -216,7 +219,7 @@ func (p *PostgresMetadata) IsQRepPartitionSynced(ctx context.Context, req *proto
 
 func (p *PostgresMetadata) SyncFlowCleanup(ctx context.Context, jobName string) error {
 	_, err := p.pool.Exec(ctx,
-		`DELETE FROM `+lastSyncStateTableName+` WHERE job_name = $1`, jobName)
+		`DELETE FROM `+lastSyncStateTableName+` WHERE job_name = $1 AND peer_name = $2`, jobName, p.peerName)
 	if err != nil {
 		return err
 	}
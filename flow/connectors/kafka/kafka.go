@@ -22,6 +22,7 @@ import (
 	"github.com/PeerDB-io/peer-flow/logger"
 	"github.com/PeerDB-io/peer-flow/model"
 	"github.com/PeerDB-io/peer-flow/peerdbenv"
+	"github.com/PeerDB-io/peer-flow/prom_metrics"
 	"github.com/PeerDB-io/peer-flow/pua"
 	"github.com/PeerDB-io/peer-flow/shared"
 )
@@ -263,12 +264,14 @@ Loop:
 				ls.Push(pua.LuaRecord.New(ls, record))
 				err := ls.PCall(1, -1, nil)
 				if err != nil {
+					prom_metrics.LuaScriptErrors.WithLabelValues(req.FlowJobName).Inc()
 					queueErr(fmt.Errorf("script failed: %w", err))
 					return nil
 				}
 
 				args := ls.GetTop()
 				results := make([]*kgo.Record, 0, args)
+				var defaultKey []byte
 				for i := range args {
 					kr, err := lvalueToKafkaRecord(ls, ls.Get(i-args))
 					if err != nil {
@@ -279,6 +282,20 @@ Loop:
 						if kr.Topic == "" {
 							kr.Topic = record.GetDestinationTableName()
 						}
+						// keep changes to the same row on the same partition
+						if kr.Key == nil {
+							if defaultKey == nil {
+								key, err := model.RecToPartitionKey(req.TableNameSchemaMapping, record)
+								if err != nil {
+									queueErr(err)
+									return nil
+								}
+								defaultKey = []byte(key)
+							}
+							if len(defaultKey) > 0 {
+								kr.Key = defaultKey
+							}
+						}
 						results = append(results, kr)
 						record.PopulateCountMap(tableNameRowsMapping)
 					}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/model"
+	"github.com/PeerDB-io/peer-flow/prom_metrics"
 	"github.com/PeerDB-io/peer-flow/pua"
 )
 
@@ -68,6 +69,7 @@ Loop:
 				ls.Push(pua.LuaRecord.New(ls, record))
 				err := ls.PCall(1, -1, nil)
 				if err != nil {
+					prom_metrics.LuaScriptErrors.WithLabelValues(config.FlowJobName).Inc()
 					queueErr(fmt.Errorf("script failed: %w", err))
 					return nil
 				}
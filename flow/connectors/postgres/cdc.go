@@ -2,8 +2,14 @@ package connpostgres
 
 import (
 	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jackc/pglogrepl"
@@ -21,6 +27,8 @@ import (
 	"github.com/PeerDB-io/peer-flow/logger"
 	"github.com/PeerDB-io/peer-flow/model"
 	"github.com/PeerDB-io/peer-flow/model/qvalue"
+	"github.com/PeerDB-io/peer-flow/prom_metrics"
+	"github.com/PeerDB-io/peer-flow/shared"
 )
 
 type PostgresCDCSource struct {
@@ -33,9 +41,19 @@ type PostgresCDCSource struct {
 	publication            string
 	typeMap                *pgtype.Map
 	commitLock             *pglogrepl.BeginMessage
+	// set between StreamStart and StreamStop, while changes of the in-progress transaction streamXid arrive
+	inStream  bool
+	streamXid uint32
+	// set by the OriginMessage of a transaction a mirror applied, its changes are skipped until it commits
+	skipOrigin            bool
+	skipReplicatedChanges bool
+	// set between BEGIN PREPARE and PREPARE, while changes of the two-phase transaction prepareXid arrive
+	prepareXid uint32
 
 	// for partitioned tables, maps child relid to parent relid
 	childToParentRelIDMapping map[uint32]uint32
+	// relations which aren't mirrored tables whose partitions were already reread
+	checkedRelIDs map[uint32]struct{}
 
 	// for storing chema delta audit logs to catalog
 	catalogPool *pgxpool.Pool
@@ -52,6 +70,7 @@ type PostgresCDCConfig struct {
 	FlowJobName            string
 	Slot                   string
 	Publication            string
+	SkipReplicatedChanges  bool
 }
 
 type startReplicationOpts struct {
@@ -62,6 +81,8 @@ type startReplicationOpts struct {
 
 // Create a new PostgresCDCSource
 func (c *PostgresConnector) NewPostgresCDCSource(cdcConfig *PostgresCDCConfig) *PostgresCDCSource {
+	typeMap := pgtype.NewMap()
+	shared.RegisterCustomTypes(typeMap, c.customTypesMapping)
 	return &PostgresCDCSource{
 		PostgresConnector:         c,
 		srcTableIDNameMapping:     cdcConfig.SrcTableIDNameMapping,
@@ -71,10 +92,12 @@ func (c *PostgresConnector) NewPostgresCDCSource(cdcConfig *PostgresCDCConfig) *
 		slot:                      cdcConfig.Slot,
 		publication:               cdcConfig.Publication,
 		childToParentRelIDMapping: cdcConfig.ChildToParentRelIDMap,
-		typeMap:                   pgtype.NewMap(),
+		checkedRelIDs:             make(map[uint32]struct{}),
+		typeMap:                   typeMap,
 		commitLock:                nil,
 		catalogPool:               cdcConfig.CatalogPool,
 		flowJobName:               cdcConfig.FlowJobName,
+		skipReplicatedChanges:     cdcConfig.SkipReplicatedChanges,
 	}
 }
 
@@ -216,13 +239,94 @@ func processTuple[Items model.Items](
 	return items, unchangedToastColumns, nil
 }
 
+// supportsBinaryFormat is whether binaryToText converts dataType, types without a binary send function
+// are sent in text format even in binary mode
+func supportsBinaryFormat(typeMap *pgtype.Map, customTypes map[uint32]shared.CustomDataType, dataType uint32) bool {
+	if _, ok := typeMap.TypeForOID(dataType); ok || dataType == uint32(oid.T_timetz) {
+		return true
+	}
+	customType, ok := customTypes[dataType]
+	if !ok {
+		return false
+	}
+	switch customTypeToQKind(customType) {
+	case qvalue.QValueKindGeography, qvalue.QValueKindGeometry, qvalue.QValueKindHStore,
+		qvalue.QValueKindEnum, qvalue.QValueKindVector:
+		return true
+	default:
+		return false
+	}
+}
+
+// binaryToText converts data of dataType from the binary format pgoutput sends in binary mode to the text format
+func (p *PostgresCDCSource) binaryToText(data []byte, dataType uint32) ([]byte, error) {
+	if dt, ok := p.typeMap.TypeForOID(dataType); ok {
+		value, err := dt.Codec.DecodeValue(p.typeMap, dataType, pgtype.BinaryFormatCode, data)
+		if err != nil {
+			return nil, err
+		}
+		return p.typeMap.Encode(dataType, pgtype.TextFormatCode, value, nil)
+	} else if dataType == uint32(oid.T_timetz) {
+		// microseconds since midnight followed by the offset in seconds west of UTC
+		if len(data) != 12 {
+			return nil, fmt.Errorf("invalid timetz of %d bytes", len(data))
+		}
+		micros := int64(binary.BigEndian.Uint64(data))
+		zone := time.FixedZone("", -int(int32(binary.BigEndian.Uint32(data[8:]))))
+		if micros == 24*int64(time.Hour/time.Microsecond) {
+			// edge case, Postgres supports this extreme value for time
+			micros -= 1
+		}
+		t := time.Date(0, 1, 1, 0, 0, 0, 0, zone).Add(time.Duration(micros) * time.Microsecond)
+		return []byte(t.Format("15:04:05.999999-0700")), nil
+	}
+
+	customType, ok := p.customTypesMapping[dataType]
+	if ok {
+		switch customTypeToQKind(customType) {
+		case qvalue.QValueKindGeography, qvalue.QValueKindGeometry:
+			// the text format is the hex of the binary EWKB
+			return []byte(hex.EncodeToString(data)), nil
+		case qvalue.QValueKindHStore:
+			hstore, err := geo.HstoreBinaryToText(data)
+			return []byte(hstore), err
+		case qvalue.QValueKindEnum:
+			// enums are sent as their label
+			return data, nil
+		case qvalue.QValueKindVector:
+			vector, err := geo.ParseVectorBinary(data)
+			if err != nil {
+				return nil, err
+			}
+			elements := make([]string, 0, len(vector))
+			for _, f := range vector {
+				elements = append(elements, strconv.FormatFloat(float64(f), 'g', -1, 32))
+			}
+			return []byte("[" + strings.Join(elements, ",") + "]"), nil
+		}
+	}
+	// a column of such a type was added while streaming, replication restarts in text format
+	return nil, fmt.Errorf("binary format of type %d is not supported", dataType)
+}
+
 func (p *PostgresCDCSource) decodeColumnData(data []byte, dataType uint32, formatCode int16) (qvalue.QValue, error) {
 	var parsedData any
 	var err error
+	if formatCode == pgtype.BinaryFormatCode {
+		if _, ok := p.typeMap.TypeForOID(dataType); !ok && customTypeToQKind(p.customTypesMapping[dataType]) != qvalue.QValueKindVector {
+			// only pgx decodes the binary format, continue with the text format of other types
+			data, err = p.binaryToText(data, dataType)
+			if err != nil {
+				return nil, err
+			}
+			formatCode = pgtype.TextFormatCode
+		}
+	}
+
 	if dt, ok := p.typeMap.TypeForOID(dataType); ok {
 		if dt.Name == "uuid" || dt.Name == "cidr" || dt.Name == "inet" || dt.Name == "macaddr" {
 			// below is required to decode above types to string
-			parsedData, err = dt.Codec.DecodeDatabaseSQLValue(p.typeMap, dataType, pgtype.TextFormatCode, data)
+			parsedData, err = dt.Codec.DecodeDatabaseSQLValue(p.typeMap, dataType, formatCode, data)
 		} else {
 			parsedData, err = dt.Codec.DecodeValue(p.typeMap, dataType, formatCode, data)
 		}
@@ -260,9 +364,9 @@ func (p *PostgresCDCSource) decodeColumnData(data []byte, dataType uint32, forma
 		return retVal, nil
 	}
 
-	typeName, ok := p.customTypesMapping[dataType]
+	customType, ok := p.customTypesMapping[dataType]
 	if ok {
-		customQKind := customTypeToQKind(typeName)
+		customQKind := customTypeToQKind(customType)
 		switch customQKind {
 		case qvalue.QValueKindGeography, qvalue.QValueKindGeometry:
 			wkt, err := geo.GeoValidate(string(data))
@@ -275,6 +379,19 @@ func (p *PostgresCDCSource) decodeColumnData(data []byte, dataType uint32, forma
 			}
 		case qvalue.QValueKindHStore:
 			return qvalue.QValueHStore{Val: string(data)}, nil
+		case qvalue.QValueKindEnum:
+			return qvalue.QValueEnum{Val: string(data)}, nil
+		case qvalue.QValueKindVector:
+			var vector []float32
+			if formatCode == pgtype.BinaryFormatCode {
+				vector, err = geo.ParseVectorBinary(data)
+			} else {
+				vector, err = geo.ParseVectorText(string(data))
+			}
+			if err != nil {
+				return nil, err
+			}
+			return qvalue.QValueVector{Val: vector}, nil
 		case qvalue.QValueKindString:
 			return qvalue.QValueString{Val: string(data)}, nil
 		default:
@@ -310,10 +427,12 @@ func PullCdcRecords[Items model.Items](
 
 	var standByLastLogged time.Time
 	cdcRecordsStorage := utils.NewCDCStore[Items](p.flowJobName)
+	pulledCounts := make(map[string]*model.RecordTypeCounts)
 	defer func() {
 		if cdcRecordsStorage.IsEmpty() {
 			records.SignalAsEmpty()
 		}
+		prom_metrics.AddRecordCounts(prom_metrics.RecordsPulled, p.flowJobName, pulledCounts)
 		logger.Info(fmt.Sprintf("[finished] PullRecords streamed %d records", cdcRecordsStorage.Len()))
 		err := cdcRecordsStorage.Close()
 		if err != nil {
@@ -339,6 +458,12 @@ func PullCdcRecords[Items model.Items](
 		}
 		records.AddRecord(rec)
 
+		tableName := rec.GetDestinationTableName()
+		if _, ok := pulledCounts[tableName]; !ok {
+			pulledCounts[tableName] = &model.RecordTypeCounts{}
+		}
+		rec.PopulateCountMap(pulledCounts)
+
 		if cdcRecordsStorage.Len() == 1 {
 			records.SignalAsNotEmpty()
 			nextStandbyMessageDeadline = time.Now().Add(standbyMessageTimeout)
@@ -347,6 +472,182 @@ func PullCdcRecords[Items model.Items](
 		return nil
 	}
 
+	// addRecord deduplicates rec against the records pulled so far before adding it
+	addRecord := func(rec model.Record[Items]) error {
+		tableName := rec.GetDestinationTableName()
+		switch r := rec.(type) {
+		case *model.UpdateRecord[Items]:
+			// tableName here is destination tableName.
+			// should be ideally sourceTableName as we are in PullRecords.
+			// will change in future
+			isFullReplica := req.TableNameSchemaMapping[tableName].IsReplicaIdentityFull
+			if isFullReplica {
+				err := addRecordWithKey(model.TableWithPkey{}, rec)
+				if err != nil {
+					return err
+				}
+			} else {
+				tablePkeyVal, err := model.RecToTablePKey[Items](req.TableNameSchemaMapping, rec)
+				if err != nil {
+					return err
+				}
+
+				latestRecord, ok, err := cdcRecordsStorage.Get(tablePkeyVal)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					err = addRecordWithKey(tablePkeyVal, rec)
+				} else {
+					// iterate through unchanged toast cols and set them in new record
+					updatedCols := r.NewItems.UpdateIfNotExists(latestRecord.GetItems())
+					for _, col := range updatedCols {
+						delete(r.UnchangedToastColumns, col)
+					}
+					err = addRecordWithKey(tablePkeyVal, rec)
+				}
+				if err != nil {
+					return err
+				}
+			}
+
+		case *model.InsertRecord[Items]:
+			isFullReplica := req.TableNameSchemaMapping[tableName].IsReplicaIdentityFull
+			if isFullReplica {
+				err := addRecordWithKey(model.TableWithPkey{}, rec)
+				if err != nil {
+					return err
+				}
+			} else {
+				tablePkeyVal, err := model.RecToTablePKey[Items](req.TableNameSchemaMapping, rec)
+				if err != nil {
+					return err
+				}
+
+				err = addRecordWithKey(tablePkeyVal, rec)
+				if err != nil {
+					return err
+				}
+			}
+		case *model.DeleteRecord[Items]:
+			isFullReplica := req.TableNameSchemaMapping[tableName].IsReplicaIdentityFull
+			if isFullReplica {
+				err := addRecordWithKey(model.TableWithPkey{}, rec)
+				if err != nil {
+					return err
+				}
+			} else {
+				tablePkeyVal, err := model.RecToTablePKey[Items](req.TableNameSchemaMapping, rec)
+				if err != nil {
+					return err
+				}
+
+				latestRecord, ok, err := cdcRecordsStorage.Get(tablePkeyVal)
+				if err != nil {
+					return err
+				}
+				if ok {
+					r.Items = latestRecord.GetItems()
+					if updateRecord, ok := latestRecord.(*model.UpdateRecord[Items]); ok {
+						r.UnchangedToastColumns = updateRecord.UnchangedToastColumns
+					}
+				} else {
+					// there is nothing to backfill the items in the delete record with,
+					// so don't update the row with this record
+					// add sentinel value to prevent update statements from selecting
+					r.UnchangedToastColumns = map[string]struct{}{
+						"_peerdb_not_backfilled_delete": {},
+					}
+				}
+
+				// A delete can only be followed by an INSERT, which does not need backfilling
+				// No need to store DeleteRecords in memory or disk.
+				err = addRecordWithKey(model.TableWithPkey{}, rec)
+				if err != nil {
+					return err
+				}
+			}
+
+		case *model.RelationRecord[Items]:
+			tableSchemaDelta := r.TableSchemaDelta
+			if len(tableSchemaDelta.AddedColumns) > 0 {
+				logger.Info(fmt.Sprintf("Detected schema change for table %s, addedColumns: %v",
+					tableSchemaDelta.SrcTableName, tableSchemaDelta.AddedColumns))
+				records.AddSchemaDelta(req.TableNameMapping, tableSchemaDelta)
+			}
+		}
+		return nil
+	}
+
+	// addCommittingTxn adds the records of p.committingTxn until the batch is full, returning whether all were added.
+	// Until then the checkpoint stays before the commit, so a restart decodes the whole transaction again,
+	// and the stream reports how many records were added so the next pull skips those already synced.
+	// The records are checkpointed before the commit too, a destination storing the checkpoint of
+	// some of them as its offset still receives the rest
+	addCommittingTxn := func() (bool, error) {
+		committing := p.committingTxn
+		txn := committing.txn.(*utils.TxnStore[Items])
+		checkpointID := p.checkpointBefore(committing.commitLSN - 1)
+		// a batch always takes some of the transaction, so it is added even with the smallest batch size
+		if cdcRecordsStorage.IsEmpty() || uint32(cdcRecordsStorage.Len()) < req.MaxBatchSize {
+			next, err := txn.IterateFrom(committing.next, func(rec model.Record[Items]) (bool, error) {
+				rec.SetCommit(checkpointID, int64(committing.commitLSN), committing.commitTime)
+				if err := addRecord(rec); err != nil {
+					return false, err
+				}
+				return uint32(cdcRecordsStorage.Len()) < req.MaxBatchSize, nil
+			})
+			committing.next = next
+			if err != nil {
+				return false, err
+			}
+		}
+		if committing.next < txn.Len() {
+			logger.Info(fmt.Sprintf("batch full with %d of %d records of committed transaction, adding the rest next batch",
+				committing.next, txn.Len()))
+			records.UpdateLatestCheckpoint(checkpointID)
+			records.SetSplitTxn(model.SplitTxnPosition{CommitLSN: int64(committing.commitLSN), Records: committing.next})
+			return false, nil
+		}
+
+		p.committingTxn = nil
+		if err := txn.Close(); err != nil {
+			logger.Warn("failed to clean up buffered transaction", slog.Any("error", err))
+		}
+		records.UpdateLatestCheckpoint(p.checkpointBefore(committing.commitLSN))
+		return true, nil
+	}
+
+	// splitTxnSynced is the number of records of the transaction committed at commitLSN which every destination synced
+	splitTxnSynced := func(commitLSN pglogrepl.LSN) int {
+		if req.SplitTxn.CommitLSN != int64(commitLSN) {
+			return 0
+		}
+		return req.SplitTxn.Records
+	}
+
+	// commitBufferedTxn adds the records buffered for a streamed or prepared transaction once it commits,
+	// returning false when the batch filled up first
+	commitBufferedTxn := func(xid uint32, commitTime time.Time, commitLSN pglogrepl.LSN) (bool, error) {
+		txn, ok := p.streamedTxns[xid].(*utils.TxnStore[Items])
+		delete(p.streamedTxns, xid)
+		if !ok {
+			records.UpdateLatestCheckpoint(p.checkpointBefore(commitLSN))
+			return true, nil
+		}
+		logger.Info(fmt.Sprintf("transaction %d committed with %d buffered records", xid, txn.Len()))
+		p.committingTxn = &committingTxn{txn: txn, commitTime: commitTime, commitLSN: commitLSN, next: splitTxnSynced(commitLSN)}
+		return addCommittingTxn()
+	}
+
+	if p.committingTxn != nil {
+		// the last batch may have failed after adding records of the transaction, resume after those synced
+		p.committingTxn.next = splitTxnSynced(p.committingTxn.commitLSN)
+		if added, err := addCommittingTxn(); err != nil || !added {
+			return err
+		}
+	}
+
 	pkmRequiresResponse := false
 	waitingForCommit := false
 
@@ -366,7 +667,7 @@ func PullCdcRecords[Items model.Items](
 			}
 		}
 
-		if p.commitLock == nil {
+		if !p.inTransaction() {
 			cdclen := cdcRecordsStorage.Len()
 			if cdclen >= 0 && uint32(cdclen) >= req.MaxBatchSize {
 				return nil
@@ -387,7 +688,7 @@ func PullCdcRecords[Items model.Items](
 			if !cdcRecordsStorage.IsEmpty() {
 				logger.Info(fmt.Sprintf("standby deadline reached, have %d records", cdcRecordsStorage.Len()))
 
-				if p.commitLock == nil {
+				if !p.inTransaction() {
 					logger.Info(
 						fmt.Sprintf("no commit lock, returning currently accumulated records - %d",
 							cdcRecordsStorage.Len()))
@@ -420,7 +721,7 @@ func PullCdcRecords[Items model.Items](
 			return fmt.Errorf("consumeStream preempted: %w", ctxErr)
 		}
 
-		if err != nil && p.commitLock == nil {
+		if err != nil && !p.inTransaction() {
 			if pgconn.Timeout(err) {
 				logger.Info(fmt.Sprintf("Stand-by deadline reached, returning currently accumulated records - %d",
 					cdcRecordsStorage.Len()))
@@ -467,112 +768,102 @@ func PullCdcRecords[Items model.Items](
 
 			logger.Debug(fmt.Sprintf("XLogData => WALStart %s ServerWALEnd %s ServerTime %s\n",
 				xld.WALStart, xld.ServerWALEnd, xld.ServerTime))
-			rec, err := processMessage(ctx, p, records, xld, clientXLogPos, processor)
+			logicalMsg, err := parseTwoPhaseMessage(xld.WALData)
 			if err != nil {
-				return fmt.Errorf("error processing message: %w", err)
+				return fmt.Errorf("error parsing logical message: %w", err)
+			}
+			if logicalMsg == nil {
+				logicalMsg, err = pglogrepl.ParseV2(xld.WALData, p.inStream)
+				if err != nil {
+					return fmt.Errorf("error parsing logical message: %w", err)
+				}
 			}
 
-			if rec != nil {
-				tableName := rec.GetDestinationTableName()
-				switch r := rec.(type) {
-				case *model.UpdateRecord[Items]:
-					// tableName here is destination tableName.
-					// should be ideally sourceTableName as we are in PullRecords.
-					// will change in future
-					isFullReplica := req.TableNameSchemaMapping[tableName].IsReplicaIdentityFull
-					if isFullReplica {
-						err := addRecordWithKey(model.TableWithPkey{}, rec)
-						if err != nil {
-							return err
-						}
-					} else {
-						tablePkeyVal, err := model.RecToTablePKey[Items](req.TableNameSchemaMapping, rec)
-						if err != nil {
-							return err
-						}
-
-						latestRecord, ok, err := cdcRecordsStorage.Get(tablePkeyVal)
-						if err != nil {
-							return err
-						}
-						if !ok {
-							err = addRecordWithKey(tablePkeyVal, rec)
-						} else {
-							// iterate through unchanged toast cols and set them in new record
-							updatedCols := r.NewItems.UpdateIfNotExists(latestRecord.GetItems())
-							for _, col := range updatedCols {
-								delete(r.UnchangedToastColumns, col)
-							}
-							err = addRecordWithKey(tablePkeyVal, rec)
-						}
-						if err != nil {
-							return err
-						}
-					}
-
-				case *model.InsertRecord[Items]:
-					isFullReplica := req.TableNameSchemaMapping[tableName].IsReplicaIdentityFull
-					if isFullReplica {
-						err := addRecordWithKey(model.TableWithPkey{}, rec)
-						if err != nil {
-							return err
-						}
-					} else {
-						tablePkeyVal, err := model.RecToTablePKey[Items](req.TableNameSchemaMapping, rec)
-						if err != nil {
-							return err
-						}
-
-						err = addRecordWithKey(tablePkeyVal, rec)
-						if err != nil {
-							return err
-						}
+			switch msg := logicalMsg.(type) {
+			case *pglogrepl.StreamStartMessageV2:
+				logger.Debug(fmt.Sprintf("StreamStartMessage => XID: %d, FirstSegment: %d", msg.Xid, msg.FirstSegment))
+				p.inStream = true
+				p.streamXid = msg.Xid
+				if _, ok := p.streamedTxns[msg.Xid]; !ok {
+					p.streamedTxns[msg.Xid] = utils.NewTxnStore[Items](p.flowJobName, msg.Xid)
+				}
+			case *pglogrepl.StreamStopMessageV2:
+				p.inStream = false
+			case *pglogrepl.StreamAbortMessageV2:
+				logger.Debug(fmt.Sprintf("StreamAbortMessage => XID: %d, SubXID: %d", msg.Xid, msg.SubXid))
+				if err := abortStreamedTxn[Items](p, msg); err != nil {
+					return err
+				}
+			case *pglogrepl.StreamCommitMessageV2:
+				logger.Debug(fmt.Sprintf("StreamCommitMessage => XID: %d, CommitLSN: %v, TransactionEndLSN: %v",
+					msg.Xid, msg.CommitLSN, msg.TransactionEndLSN))
+				if added, err := commitBufferedTxn(msg.Xid, msg.CommitTime, msg.CommitLSN); err != nil || !added {
+					return err
+				}
+			case *prepareMessage:
+				logger.Debug(fmt.Sprintf("PrepareMessage %c => XID: %d, GID: %s, PrepareLSN: %v",
+					msg.Type(), msg.Xid, msg.GID, msg.PrepareLSN))
+				switch msg.Type() {
+				case messageTypeBeginPrepare:
+					p.prepareXid = msg.Xid
+					p.streamedTxns[msg.Xid] = utils.NewTxnStore[Items](p.flowJobName, msg.Xid)
+				case messageTypePrepare:
+					p.prepareXid = 0
+					p.preparedTxns[msg.Xid] = msg.PrepareLSN
+				case messageTypeStreamPrepare:
+					p.preparedTxns[msg.Xid] = msg.PrepareLSN
+				}
+			case *pglogrepl.LogicalDecodingMessageV2:
+				if msg.Prefix == shared.CutoverFenceMessagePrefix {
+					logger.Info(fmt.Sprintf("cutover fence of %s at %v, returning after its commit", msg.Content, msg.LSN))
+					waitingForCommit = true
+				}
+			case *commitPreparedMessage:
+				logger.Debug(fmt.Sprintf("CommitPreparedMessage => XID: %d, GID: %s, CommitLSN: %v",
+					msg.Xid, msg.GID, msg.CommitLSN))
+				if _, ok := p.streamedTxns[msg.Xid]; !ok {
+					// replication restarted past its PREPARE, Postgres won't decode the transaction again
+					logger.Warn("COMMIT PREPARED of transaction not decoded at PREPARE, its changes are not replicated",
+						slog.Uint64("xid", uint64(msg.Xid)), slog.String("gid", msg.GID))
+				}
+				delete(p.preparedTxns, msg.Xid)
+				if added, err := commitBufferedTxn(msg.Xid, msg.CommitTime, msg.CommitLSN); err != nil || !added {
+					return err
+				}
+			case *rollbackPreparedMessage:
+				logger.Debug(fmt.Sprintf("RollbackPreparedMessage => XID: %d, GID: %s", msg.Xid, msg.GID))
+				delete(p.preparedTxns, msg.Xid)
+				if txn, ok := p.streamedTxns[msg.Xid]; ok {
+					delete(p.streamedTxns, msg.Xid)
+					if err := txn.Close(); err != nil {
+						logger.Warn("failed to clean up prepared transaction", slog.Any("error", err))
 					}
-				case *model.DeleteRecord[Items]:
-					isFullReplica := req.TableNameSchemaMapping[tableName].IsReplicaIdentityFull
-					if isFullReplica {
-						err := addRecordWithKey(model.TableWithPkey{}, rec)
-						if err != nil {
-							return err
-						}
-					} else {
-						tablePkeyVal, err := model.RecToTablePKey[Items](req.TableNameSchemaMapping, rec)
-						if err != nil {
-							return err
-						}
+				}
+			default:
+				rec, err := processMessage(ctx, p, records, xld, logicalMsg, clientXLogPos, processor)
+				if err != nil {
+					return fmt.Errorf("error processing message: %w", err)
+				}
 
-						latestRecord, ok, err := cdcRecordsStorage.Get(tablePkeyVal)
-						if err != nil {
-							return err
-						}
-						if ok {
-							r.Items = latestRecord.GetItems()
-							if updateRecord, ok := latestRecord.(*model.UpdateRecord[Items]); ok {
-								r.UnchangedToastColumns = updateRecord.UnchangedToastColumns
+				if rec != nil {
+					if xid, ok := p.bufferedXid(); ok {
+						switch txn := p.streamedTxns[xid].(type) {
+						case *utils.TxnStore[Items]:
+							if err := txn.Append(logger, inStreamXid(logicalMsg), rec); err != nil {
+								return err
 							}
-						} else {
-							// there is nothing to backfill the items in the delete record with,
-							// so don't update the row with this record
-							// add sentinel value to prevent update statements from selecting
-							r.UnchangedToastColumns = map[string]struct{}{
-								"_peerdb_not_backfilled_delete": {},
+						case skippedTxn:
+							// relations describe the table rather than the transaction, keep their schema changes
+							if _, ok := rec.(*model.RelationRecord[Items]); ok {
+								if err := addRecord(rec); err != nil {
+									return err
+								}
 							}
+						default:
+							return fmt.Errorf("change buffered for unknown transaction %d", xid)
 						}
-
-						// A delete can only be followed by an INSERT, which does not need backfilling
-						// No need to store DeleteRecords in memory or disk.
-						err = addRecordWithKey(model.TableWithPkey{}, rec)
-						if err != nil {
-							return err
-						}
-					}
-
-				case *model.RelationRecord[Items]:
-					tableSchemaDelta := r.TableSchemaDelta
-					if len(tableSchemaDelta.AddedColumns) > 0 {
-						logger.Info(fmt.Sprintf("Detected schema change for table %s, addedColumns: %v",
-							tableSchemaDelta.SrcTableName, tableSchemaDelta.AddedColumns))
-						records.AddSchemaDelta(req.TableNameMapping, tableSchemaDelta)
+					} else if err := addRecord(rec); err != nil {
+						return err
 					}
 				}
 			}
@@ -584,6 +875,102 @@ func PullCdcRecords[Items model.Items](
 	}
 }
 
+// inTransaction is true from a BeginMessage until its commit, while a chunk of an in-progress transaction streams,
+// and from a BEGIN PREPARE until its PREPARE, pulls only return outside of them
+func (p *PostgresCDCSource) inTransaction() bool {
+	return p.commitLock != nil || p.inStream || p.prepareXid != 0
+}
+
+// bufferedXid is the transaction whose changes are currently buffered until it commits, if any
+func (p *PostgresCDCSource) bufferedXid() (uint32, bool) {
+	if p.inStream {
+		return p.streamXid, true
+	} else if p.prepareXid != 0 {
+		return p.prepareXid, true
+	}
+	return 0, false
+}
+
+// checkpointBefore holds lsn back before the PREPARE of transactions awaiting COMMIT PREPARED,
+// so replication restarted from the checkpoint decodes them again instead of losing their buffered changes
+func (p *PostgresCDCSource) checkpointBefore(lsn pglogrepl.LSN) int64 {
+	for _, prepareLSN := range p.preparedTxns {
+		if prepareLSN <= lsn {
+			lsn = prepareLSN - 1
+		}
+	}
+	return int64(lsn)
+}
+
+// inStreamXid is the XID of the subtransaction which made a change streamed as part of an in-progress transaction
+func inStreamXid(msg pglogrepl.Message) uint32 {
+	switch msg := msg.(type) {
+	case *pglogrepl.InsertMessageV2:
+		return msg.Xid
+	case *pglogrepl.UpdateMessageV2:
+		return msg.Xid
+	case *pglogrepl.DeleteMessageV2:
+		return msg.Xid
+	case *pglogrepl.RelationMessageV2:
+		return msg.Xid
+	default:
+		return 0
+	}
+}
+
+// committingTxn is a committed streamed or prepared transaction whose buffered records are added over several batches,
+// txn is the *utils.TxnStore of the Items being pulled and next the position of its first record not yet added
+type committingTxn struct {
+	txn        io.Closer
+	commitTime time.Time
+	commitLSN  pglogrepl.LSN
+	next       int
+}
+
+// skippedTxn stands in for the buffer of an in-progress or prepared transaction a mirror applied, its changes are dropped
+type skippedTxn struct{}
+
+func (skippedTxn) Close() error {
+	return nil
+}
+
+// abortStreamedTxn discards the changes of an aborted in-progress transaction,
+// or only those of a subtransaction when SubXid differs from Xid
+func abortStreamedTxn[Items model.Items](p *PostgresCDCSource, msg *pglogrepl.StreamAbortMessageV2) error {
+	txn, ok := p.streamedTxns[msg.Xid]
+	if !ok {
+		return nil
+	}
+	if msg.SubXid != msg.Xid {
+		if txn, ok := txn.(*utils.TxnStore[Items]); ok {
+			txn.AbortSubxact(msg.SubXid)
+		}
+		return nil
+	}
+	delete(p.streamedTxns, msg.Xid)
+	return txn.Close()
+}
+
+// skipOriginTxn drops the changes of the current transaction, applied by a mirror under one of its replication origins,
+// only when the mirror skips replicated changes so chained mirrors pass them on
+func (p *PostgresCDCSource) skipOriginTxn(msg *pglogrepl.OriginMessage) {
+	p.logger.Debug(fmt.Sprintf("OriginMessage => CommitLSN: %v, Name: %s", msg.CommitLSN, msg.Name))
+	if !p.skipReplicatedChanges || !strings.HasPrefix(msg.Name, shared.ReplicationOriginPrefix) {
+		return
+	}
+	xid, ok := p.bufferedXid()
+	if !ok {
+		p.skipOrigin = true
+		return
+	}
+	if txn, ok := p.streamedTxns[xid]; ok {
+		if err := txn.Close(); err != nil {
+			p.logger.Warn("failed to clean up buffered transaction", slog.Any("error", err))
+		}
+	}
+	p.streamedTxns[xid] = skippedTxn{}
+}
+
 func (p *PostgresCDCSource) baseRecord(lsn pglogrepl.LSN) model.BaseRecord {
 	var nano int64
 	if p.commitLock != nil {
@@ -600,33 +987,48 @@ func processMessage[Items model.Items](
 	p *PostgresCDCSource,
 	batch *model.CDCStream[Items],
 	xld pglogrepl.XLogData,
+	logicalMsg pglogrepl.Message,
 	currentClientXlogPos pglogrepl.LSN,
 	processor replProcessor[Items],
 ) (model.Record[Items], error) {
 	logger := logger.LoggerFromCtx(ctx)
-	logicalMsg, err := pglogrepl.Parse(xld.WALData)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing logical message: %w", err)
-	}
-
 	switch msg := logicalMsg.(type) {
 	case *pglogrepl.BeginMessage:
 		logger.Debug(fmt.Sprintf("BeginMessage => FinalLSN: %v, XID: %v", msg.FinalLSN, msg.Xid))
 		logger.Debug("Locking PullRecords at BeginMessage, awaiting CommitMessage")
 		p.commitLock = msg
-	case *pglogrepl.InsertMessage:
-		return processInsertMessage(p, xld.WALStart, msg, processor)
-	case *pglogrepl.UpdateMessage:
-		return processUpdateMessage(p, xld.WALStart, msg, processor)
-	case *pglogrepl.DeleteMessage:
-		return processDeleteMessage(p, xld.WALStart, msg, processor)
+	case *pglogrepl.OriginMessage:
+		p.skipOriginTxn(msg)
+	case *pglogrepl.InsertMessageV2:
+		if p.skipOrigin {
+			return nil, nil
+		}
+		return processInsertMessage(p, xld.WALStart, &msg.InsertMessage, processor)
+	case *pglogrepl.UpdateMessageV2:
+		if p.skipOrigin {
+			return nil, nil
+		}
+		return processUpdateMessage(p, xld.WALStart, &msg.UpdateMessage, processor)
+	case *pglogrepl.DeleteMessageV2:
+		if p.skipOrigin {
+			return nil, nil
+		}
+		return processDeleteMessage(p, xld.WALStart, &msg.DeleteMessage, processor)
 	case *pglogrepl.CommitMessage:
 		// for a commit message, update the last checkpoint id for the record batch.
 		logger.Debug(fmt.Sprintf("CommitMessage => CommitLSN: %v, TransactionEndLSN: %v",
 			msg.CommitLSN, msg.TransactionEndLSN))
-		batch.UpdateLatestCheckpoint(int64(msg.CommitLSN))
+		batch.UpdateLatestCheckpoint(p.checkpointBefore(msg.CommitLSN))
 		p.commitLock = nil
-	case *pglogrepl.RelationMessage:
+		p.skipOrigin = false
+	case *pglogrepl.RelationMessageV2:
+		// pgoutput sends a relation before the first change to it and again once it is attached or detached,
+		// a relation which isn't a mirrored table may be a partition attached after partitions were read
+		if _, ok := p.srcTableIDNameMapping[msg.RelationID]; !ok {
+			if err := p.checkPartitionParent(ctx, msg.RelationID); err != nil {
+				return nil, err
+			}
+		}
 		// treat all relation messages as corresponding to parent if partitioned.
 		msg.RelationID = p.getParentRelIDIfPartitioned(msg.RelationID)
 
@@ -637,9 +1039,9 @@ func processMessage[Items model.Items](
 		logger.Debug(fmt.Sprintf("RelationMessage => RelationID: %d, Namespace: %s, RelationName: %s, Columns: %v",
 			msg.RelationID, msg.Namespace, msg.RelationName, msg.Columns))
 
-		return processRelationMessage[Items](ctx, p, currentClientXlogPos, msg)
+		return processRelationMessage[Items](ctx, p, currentClientXlogPos, &msg.RelationMessage)
 
-	case *pglogrepl.TruncateMessage:
+	case *pglogrepl.TruncateMessageV2:
 		logger.Warn("TruncateMessage not supported")
 	}
 
@@ -805,9 +1207,9 @@ func processRelationMessage[Items model.Items](
 		case protos.TypeSystem_Q:
 			qKind := p.postgresOIDToQValueKind(column.DataType)
 			if qKind == qvalue.QValueKindInvalid {
-				typeName, ok := p.customTypesMapping[column.DataType]
+				customType, ok := p.customTypesMapping[column.DataType]
 				if ok {
-					qKind = customTypeToQKind(typeName)
+					qKind = customTypeToQKind(customType)
 				}
 			}
 			currRelMap[column.Name] = string(qKind)
@@ -829,12 +1231,21 @@ func processRelationMessage[Items model.Items](
 		if _, ok := prevRelMap[column.Name]; !ok {
 			// only add to delta if not excluded
 			if _, ok := p.tableNameMapping[p.srcTableIDNameMapping[currRel.RelationID]].Exclude[column.Name]; !ok {
-				schemaDelta.AddedColumns = append(schemaDelta.AddedColumns, &protos.FieldDescription{
-					Name:         column.Name,
-					Type:         currRelMap[column.Name],
-					TypeModifier: column.TypeModifier,
-				},
-				)
+				var addedColumn *protos.FieldDescription
+				if prevSchema.System == protos.TypeSystem_Q {
+					addedColumn = p.qFieldDescription(column.Name, column.DataType, column.TypeModifier)
+				} else {
+					addedColumn = &protos.FieldDescription{
+						Name:         column.Name,
+						Type:         currRelMap[column.Name],
+						TypeModifier: column.TypeModifier,
+					}
+					if customType, ok := p.customTypesMapping[column.DataType]; ok && customType.Type == 'e' {
+						addedColumn.TypeName = customType.Name
+						addedColumn.EnumLabels = customType.EnumLabels
+					}
+				}
+				schemaDelta.AddedColumns = append(schemaDelta.AddedColumns, addedColumn)
 			}
 			// present in previous and current relation messages, but data types have changed.
 			// so we add it to AddedColumns and DroppedColumns, knowing that we process DroppedColumns first.
@@ -863,11 +1274,65 @@ func processRelationMessage[Items model.Items](
 	return nil, nil
 }
 
+// getParentRelIDIfPartitioned maps a partition to its topmost mirrored ancestor,
+// which is more than one level up for partitions of partitions
 func (p *PostgresCDCSource) getParentRelIDIfPartitioned(relID uint32) uint32 {
-	parentRelID, ok := p.childToParentRelIDMapping[relID]
-	if ok {
-		return parentRelID
+	mirroredRelID := relID
+	for {
+		parentRelID, ok := p.childToParentRelIDMapping[relID]
+		if !ok {
+			return mirroredRelID
+		}
+		relID = parentRelID
+		if _, ok := p.srcTableIDNameMapping[relID]; ok {
+			mirroredRelID = relID
+		}
+	}
+}
+
+// checkPartitionParent rereads partitions of partitioned tables the first time a relation which isn't a mirrored table
+// is seen, later relation messages for it only look up its own parent and reread partitions once that changed
+func (p *PostgresCDCSource) checkPartitionParent(ctx context.Context, relID uint32) error {
+	if _, ok := p.checkedRelIDs[relID]; !ok {
+		if err := p.refreshChildToParentRelIDMapping(ctx, relID); err != nil {
+			return err
+		}
+		p.checkedRelIDs[relID] = struct{}{}
+		return nil
 	}
 
-	return relID
+	var parentRelID pgtype.Uint32
+	if err := p.conn.QueryRow(ctx, `SELECT parent.oid FROM pg_inherits
+		JOIN pg_class parent ON pg_inherits.inhparent = parent.oid
+		WHERE pg_inherits.inhrelid = $1 AND parent.relkind = 'p'`, relID).Scan(&parentRelID); err != nil &&
+		!errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("error querying parent of relation %d: %w", relID, err)
+	}
+	if knownParentRelID, ok := p.childToParentRelIDMapping[relID]; ok == parentRelID.Valid &&
+		knownParentRelID == parentRelID.Uint32 {
+		return nil
+	}
+	return p.refreshChildToParentRelIDMapping(ctx, relID)
+}
+
+// refreshChildToParentRelIDMapping rereads partitions of partitioned tables
+// so changes to a partition attached or detached while streaming go to the right table
+func (p *PostgresCDCSource) refreshChildToParentRelIDMapping(ctx context.Context, relID uint32) error {
+	prevParentRelID := p.getParentRelIDIfPartitioned(relID)
+	childToParentRelIDMap, err := GetChildToParentRelIDMap(ctx, p.conn)
+	if err != nil {
+		return fmt.Errorf("error refreshing child to parent relid map: %w", err)
+	}
+	p.childToParentRelIDMapping = childToParentRelIDMap
+
+	if parentRelID := p.getParentRelIDIfPartitioned(relID); parentRelID != prevParentRelID {
+		if _, ok := p.srcTableIDNameMapping[parentRelID]; ok {
+			p.logger.Info("partition attached to mirrored table",
+				slog.Uint64("relId", uint64(relID)), slog.String("table", p.srcTableIDNameMapping[parentRelID]))
+		} else if tableName, ok := p.srcTableIDNameMapping[prevParentRelID]; ok {
+			p.logger.Info("partition detached from mirrored table",
+				slog.Uint64("relId", uint64(relID)), slog.String("table", tableName))
+		}
+	}
+	return nil
 }
@@ -0,0 +1,151 @@
+package connpostgres
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/jackc/pglogrepl"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/lib/pq/oid"
+
+	"github.com/PeerDB-io/peer-flow/logger"
+	"github.com/PeerDB-io/peer-flow/shared"
+)
+
+func TestGetParentRelIDIfPartitioned(t *testing.T) {
+	// events is partitioned by year and each year by month, logs_2024 is a mirrored partition of unmirrored logs
+	p := &PostgresCDCSource{
+		srcTableIDNameMapping: map[uint32]string{
+			100: "public.events",
+			300: "public.logs_2024",
+		},
+		childToParentRelIDMapping: map[uint32]uint32{
+			101: 100,
+			102: 101,
+			103: 101,
+			300: 200,
+			301: 300,
+			401: 400,
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		relID    uint32
+		expected uint32
+	}{
+		{name: "mirrored table", relID: 100, expected: 100},
+		{name: "partition", relID: 101, expected: 100},
+		{name: "partition of a partition", relID: 103, expected: 100},
+		{name: "mirrored partition of unmirrored table", relID: 300, expected: 300},
+		{name: "partition of mirrored partition", relID: 301, expected: 300},
+		{name: "partition of unmirrored table", relID: 401, expected: 401},
+		{name: "unknown relation", relID: 500, expected: 500},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if relID := p.getParentRelIDIfPartitioned(tc.relID); relID != tc.expected {
+				t.Errorf("expected %d, got %d", tc.expected, relID)
+			}
+		})
+	}
+}
+
+func TestSkipOriginTxn(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		origin                string
+		skipReplicatedChanges bool
+		expected              bool
+	}{
+		{name: "mirror origin", origin: "peerdb_sync_reverse", skipReplicatedChanges: true, expected: true},
+		{name: "mirror origin passed on", origin: "peerdb_sync_reverse", skipReplicatedChanges: false, expected: false},
+		{name: "other origin", origin: "pg_16384", skipReplicatedChanges: true, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &PostgresCDCSource{
+				PostgresConnector: &PostgresConnector{
+					logger:       logger.LoggerFromCtx(context.Background()),
+					streamedTxns: make(map[uint32]io.Closer),
+				},
+				skipReplicatedChanges: tc.skipReplicatedChanges,
+			}
+			p.skipOriginTxn(&pglogrepl.OriginMessage{Name: tc.origin})
+			if p.skipOrigin != tc.expected {
+				t.Errorf("expected skipOrigin %v, got %v", tc.expected, p.skipOrigin)
+			}
+
+			// changes of an in-progress transaction are buffered, skipping replaces its buffer
+			p.inStream = true
+			p.streamXid = 7
+			p.skipOriginTxn(&pglogrepl.OriginMessage{Name: tc.origin})
+			if _, skipped := p.streamedTxns[7].(skippedTxn); skipped != tc.expected {
+				t.Errorf("expected streamed transaction skipped %v, got %v", tc.expected, skipped)
+			}
+		})
+	}
+}
+
+func TestBinaryToText(t *testing.T) {
+	const (
+		enumOID     = 16400
+		geometryOID = 16500
+		domainOID   = 16600
+	)
+	customTypes := map[uint32]shared.CustomDataType{
+		enumOID:     {Name: "mood", Type: 'e', EnumLabels: []string{"sad", "happy"}},
+		geometryOID: {Name: "geometry", Type: 'b'},
+		domainOID:   {Name: "positive_int", Type: 'd'},
+	}
+	p := &PostgresCDCSource{
+		PostgresConnector: &PostgresConnector{customTypesMapping: customTypes},
+		typeMap:           pgtype.NewMap(),
+	}
+
+	timetz := make([]byte, 12)
+	binary.BigEndian.PutUint64(timetz, uint64((13*3600+30*60)*1_000_000))
+	// offset in seconds west of UTC, so -2h is UTC+2
+	westOfUTC := int32(-2 * 3600)
+	binary.BigEndian.PutUint32(timetz[8:], uint32(westOfUTC))
+
+	testCases := []struct {
+		name     string
+		data     []byte
+		dataType uint32
+		expected string
+	}{
+		{name: "int4", data: []byte{0, 0, 0, 42}, dataType: uint32(oid.T_int4), expected: "42"},
+		{name: "bool", data: []byte{1}, dataType: uint32(oid.T_bool), expected: "t"},
+		{name: "bytea", data: []byte{0xde, 0xad}, dataType: uint32(oid.T_bytea), expected: `\xdead`},
+		{name: "timetz", data: timetz, dataType: uint32(oid.T_timetz), expected: "13:30:00+0200"},
+		{name: "enum", data: []byte("happy"), dataType: enumOID, expected: "happy"},
+		{name: "geometry", data: []byte{0x01, 0x02}, dataType: geometryOID, expected: "0102"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if !supportsBinaryFormat(p.typeMap, customTypes, tc.dataType) {
+				t.Fatalf("expected binary format of %d to be supported", tc.dataType)
+			}
+			text, err := p.binaryToText(tc.data, tc.dataType)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(text) != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, string(text))
+			}
+		})
+	}
+
+	// domains have no codec, mirrors with them fall back to text format
+	if supportsBinaryFormat(p.typeMap, customTypes, domainOID) {
+		t.Error("expected binary format of domain to be unsupported")
+	}
+	if _, err := p.binaryToText([]byte{0, 0, 0, 1}, domainOID); err == nil {
+		t.Error("expected error converting binary format of domain")
+	}
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/jackc/pglogrepl"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/lib/pq/oid"
+	"go.temporal.io/sdk/temporal"
 
 	"github.com/PeerDB-io/peer-flow/connectors/utils"
 	numeric "github.com/PeerDB-io/peer-flow/datatypes"
@@ -28,9 +32,37 @@ const (
 		_peerdb_timestamp BIGINT NOT NULL,_peerdb_destination_table_name TEXT NOT NULL,_peerdb_data JSONB NOT NULL,
 		_peerdb_record_type INTEGER NOT NULL, _peerdb_match_data JSONB,_peerdb_batch_id INTEGER,
 		_peerdb_unchanged_toast_columns TEXT)`
+	addRawTableCommitInfoSQL = `ALTER TABLE %s.%s ADD COLUMN IF NOT EXISTS _peerdb_commit_lsn BIGINT,
+		ADD COLUMN IF NOT EXISTS _peerdb_commit_time_ns BIGINT`
 	createRawTableBatchIDIndexSQL  = "CREATE INDEX IF NOT EXISTS %s_batchid_idx ON %s.%s(_peerdb_batch_id)"
 	createRawTableDstTableIndexSQL = "CREATE INDEX IF NOT EXISTS %s_dst_table_idx ON %s.%s(_peerdb_destination_table_name)"
 
+	createReplicationOriginSQL = `SELECT pg_replication_origin_create($1)
+	WHERE NOT EXISTS(SELECT 1 FROM pg_replication_origin WHERE roname=$1)`
+	setupReplicationOriginSQL = "SELECT pg_replication_origin_session_setup($1)"
+	resetReplicationOriginSQL = "SELECT pg_replication_origin_session_reset()"
+	dropReplicationOriginsSQL = "SELECT pg_replication_origin_drop(roname) FROM pg_replication_origin WHERE roname = ANY($1)"
+
+	getSequenceValuesSQL = `SELECT t.name, a.attname, pg_sequence_last_value(d.objid)
+	FROM unnest($1::text[], $2::text[]) AS t(name, quoted)
+	JOIN pg_depend d ON d.refobjid = to_regclass(t.quoted) AND d.classid = 'pg_class'::regclass
+		AND d.refclassid = 'pg_class'::regclass AND d.deptype IN ('a', 'i')
+	JOIN pg_class s ON s.oid = d.objid AND s.relkind = 'S'
+	JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+	WHERE pg_sequence_last_value(d.objid) IS NOT NULL`
+	getUnreadableSequencesSQL = `SELECT s.oid::regclass::text
+	FROM unnest($1::text[]) AS t(quoted)
+	JOIN pg_depend d ON d.refobjid = to_regclass(t.quoted) AND d.classid = 'pg_class'::regclass
+		AND d.refclassid = 'pg_class'::regclass AND d.deptype IN ('a', 'i')
+	JOIN pg_class s ON s.oid = d.objid AND s.relkind = 'S'
+	WHERE NOT has_sequence_privilege(s.oid, 'SELECT') AND NOT has_sequence_privilege(s.oid, 'USAGE')`
+	getColumnSequenceSQL = `SELECT a.attname IS NOT NULL, pg_get_serial_sequence($1, a.attname)
+	FROM (SELECT 1) AS one LEFT JOIN pg_attribute a
+	ON a.attrelid = to_regclass($1) AND a.attname = $2 AND a.attnum > 0 AND NOT a.attisdropped`
+	createColumnSequenceSQL = "CREATE SEQUENCE IF NOT EXISTS %s OWNED BY %s.%s"
+	setColumnSequenceSQL    = "ALTER TABLE %s ALTER COLUMN %s SET DEFAULT nextval(%s::regclass)"
+	syncSequenceSQL         = "SELECT setval($1::text::regclass, GREATEST($2, COALESCE(pg_sequence_last_value($1::text::regclass), 0)))"
+
 	getLastOffsetSQL            = "SELECT lsn_offset FROM %s.%s WHERE mirror_job_name=$1"
 	setLastOffsetSQL            = "UPDATE %s.%s SET lsn_offset=GREATEST(lsn_offset, $1) WHERE mirror_job_name=$2"
 	getLastSyncBatchID_SQL      = "SELECT sync_batch_id FROM %s.%s WHERE mirror_job_name=$1"
@@ -48,16 +80,16 @@ const (
 	ARRAY_AGG(DISTINCT _peerdb_unchanged_toast_columns) FROM %s.%s WHERE
 	_peerdb_batch_id>$1 AND _peerdb_batch_id<=$2 AND _peerdb_record_type!=2 GROUP BY _peerdb_destination_table_name`
 	mergeStatementSQL = `WITH src_rank AS (
-		SELECT _peerdb_data,_peerdb_record_type,_peerdb_unchanged_toast_columns,
-		RANK() OVER (PARTITION BY %s ORDER BY _peerdb_timestamp DESC) AS _peerdb_rank
-		FROM %s.%s WHERE _peerdb_batch_id>$1 AND _peerdb_batch_id<=$2 AND _peerdb_destination_table_name=$3
+		SELECT _peerdb_data,_peerdb_record_type,_peerdb_unchanged_toast_columns%[1]s,
+		RANK() OVER (PARTITION BY %[2]s ORDER BY _peerdb_timestamp DESC) AS _peerdb_rank
+		FROM %[3]s.%[4]s WHERE _peerdb_batch_id>$1 AND _peerdb_batch_id<=$2 AND _peerdb_destination_table_name=$3
 	)
-	MERGE INTO %s dst
-	USING (SELECT %s,_peerdb_record_type,_peerdb_unchanged_toast_columns FROM src_rank WHERE _peerdb_rank=1) src
-	ON %s
+	MERGE INTO %[5]s dst
+	USING (SELECT %[6]s,_peerdb_record_type,_peerdb_unchanged_toast_columns%[1]s FROM src_rank WHERE _peerdb_rank=1) src
+	ON %[7]s
 	WHEN NOT MATCHED AND src._peerdb_record_type!=2 THEN
-	INSERT (%s) VALUES (%s) %s
-	WHEN MATCHED AND src._peerdb_record_type=2 THEN %s`
+	INSERT (%[8]s) VALUES (%[9]s) %[10]s
+	WHEN MATCHED AND src._peerdb_record_type=2%[11]s THEN %[12]s`
 	fallbackUpsertStatementSQL = `WITH src_rank AS (
 		SELECT _peerdb_data,_peerdb_record_type,_peerdb_unchanged_toast_columns,
 		RANK() OVER (PARTITION BY %s ORDER BY _peerdb_timestamp DESC) AS _peerdb_rank
@@ -72,9 +104,27 @@ const (
 	)
 	%s src_rank WHERE %s AND src_rank._peerdb_rank=1 AND src_rank._peerdb_record_type=2`
 
-	dropTableIfExistsSQL     = "DROP TABLE IF EXISTS %s.%s"
-	deleteJobMetadataSQL     = "DELETE FROM %s.%s WHERE mirror_job_name=$1"
-	getNumConnectionsForUser = "SELECT COUNT(*) FROM pg_stat_activity WHERE usename=$1 AND client_addr IS NOT NULL"
+	changelogStatementSQL = `INSERT INTO %s (%s) SELECT %s FROM %s.%s
+	WHERE _peerdb_batch_id>$1 AND _peerdb_batch_id<=$2 AND _peerdb_destination_table_name=$3
+	ORDER BY _peerdb_commit_time_ns,_peerdb_commit_lsn,_peerdb_timestamp`
+	scd2CloseStatementSQL = `WITH src AS (
+		SELECT %s,MIN(_peerdb_commit_time_ns) AS _peerdb_first_commit
+		FROM %s.%s WHERE _peerdb_batch_id>$1 AND _peerdb_batch_id<=$2 AND _peerdb_destination_table_name=$3
+		GROUP BY %s
+	)
+	UPDATE %s dst SET %s FROM src WHERE dst.%s AND %s`
+	scd2InsertStatementSQL = `WITH src AS (
+		SELECT %s,_peerdb_record_type,_peerdb_commit_lsn,_peerdb_commit_time_ns,
+		LEAD(_peerdb_commit_time_ns) OVER
+		(PARTITION BY %s ORDER BY _peerdb_commit_time_ns,_peerdb_commit_lsn,_peerdb_timestamp) AS _peerdb_next_commit
+		FROM %s.%s WHERE _peerdb_batch_id>$1 AND _peerdb_batch_id<=$2 AND _peerdb_destination_table_name=$3
+	)
+	INSERT INTO %s (%s) SELECT %s FROM src WHERE _peerdb_record_type!=2`
+
+	dropTableIfExistsSQL           = "DROP TABLE IF EXISTS %s.%s"
+	deleteRawTableRowsForTablesSQL = "DELETE FROM %s.%s WHERE _peerdb_destination_table_name = ANY($1)"
+	deleteJobMetadataSQL           = "DELETE FROM %s.%s WHERE mirror_job_name=$1"
+	getNumConnectionsForUser       = "SELECT COUNT(*) FROM pg_stat_activity WHERE usename=$1 AND client_addr IS NOT NULL"
 )
 
 type ReplicaIdentityType rune
@@ -308,6 +358,145 @@ func (c *PostgresConnector) GetSlotInfo(ctx context.Context, slotName string) ([
 	return getSlotInfo(ctx, c.conn, slotName, c.config.Database)
 }
 
+// isInRecovery checks if the server is a hot standby, where publications can't be created or dropped
+func (c *PostgresConnector) isInRecovery(ctx context.Context) (bool, error) {
+	var inRecovery bool
+	if err := c.conn.QueryRow(ctx, "SELECT pg_is_in_recovery()").Scan(&inRecovery); err != nil {
+		return false, fmt.Errorf("error checking if server is in recovery: %w", err)
+	}
+	return inRecovery, nil
+}
+
+// errSlotOnPrimary is returned when the connector is on a standby while the slot is only usable on the primary,
+// as after a failover when the first host which accepts connections is no longer the primary
+var errSlotOnPrimary = errors.New("slot is only usable on the primary")
+
+// checkReplicationSlot resolves slot on the server before streaming from it.
+// After a failover this is the new primary, so a slot which didn't survive fails clearly here,
+// while one that did is streamed from the last synced offset without a resync.
+// Returns whether the slot decodes prepared transactions at PREPARE
+func (c *PostgresConnector) checkReplicationSlot(ctx context.Context, slot string) (bool, error) {
+	pgversion, err := c.MajorVersion(ctx)
+	if err != nil {
+		return false, fmt.Errorf("error getting PG version: %w", err)
+	}
+	syncedSelector := "false"
+	if pgversion >= shared.POSTGRES_17 {
+		syncedSelector = "synced"
+	}
+	twoPhaseSelector := "false"
+	if pgversion >= shared.POSTGRES_15 {
+		twoPhaseSelector = "two_phase"
+	}
+
+	var confirmedFlushLSN pgtype.Text
+	var synced bool
+	var twoPhase bool
+	var inRecovery bool
+	err = c.conn.QueryRow(ctx, fmt.Sprintf(`SELECT confirmed_flush_lsn::text, %s, %s, pg_is_in_recovery()
+		FROM pg_replication_slots WHERE slot_name = $1`, syncedSelector, twoPhaseSelector),
+		slot).Scan(&confirmedFlushLSN, &synced, &twoPhase, &inRecovery)
+	if errors.Is(err, pgx.ErrNoRows) {
+		inRecovery, err := c.isInRecovery(ctx)
+		if err != nil {
+			return false, err
+		}
+		if inRecovery {
+			return false, fmt.Errorf("replication slot %s not found on standby: %w", slot, errSlotOnPrimary)
+		}
+		return false, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("replication slot %s not found, if the source failed over the slot was not synced to the new primary",
+				slot), "slot", nil)
+	} else if err != nil {
+		return false, fmt.Errorf("error checking replication slot %s: %w", slot, err)
+	}
+	if synced && inRecovery {
+		return false, fmt.Errorf("replication slot %s is synced from the primary: %w", slot, errSlotOnPrimary)
+	}
+
+	c.logger.Info("resolved replication slot",
+		slog.String("slot", slot),
+		slog.String("confirmedFlushLSN", confirmedFlushLSN.String),
+		slog.Bool("inRecovery", inRecovery),
+		slog.Bool("synced", synced),
+		slog.Bool("twoPhase", twoPhase))
+	return twoPhase, nil
+}
+
+// resolvePrimary reconnects to whichever host of the peer is now the primary, for when a failover left the
+// connector on a standby which has no usable copy of the slot. Hosts are tried in order until one is the primary
+func (c *PostgresConnector) resolvePrimary(ctx context.Context) error {
+	connConfig := c.conn.Config()
+	connConfig.ValidateConnect = pgconn.ValidateConnectTargetSessionAttrsPrimary
+	conn, err := c.ssh.NewPostgresConnFromConfig(ctx, connConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the primary after failover: %w", err)
+	}
+	shared.RegisterCustomTypes(conn.TypeMap(), c.customTypesMapping)
+
+	c.replConfig.ValidateConnect = pgconn.ValidateConnectTargetSessionAttrsPrimary
+	replConn, err := c.CreateReplConn(ctx)
+	if err != nil {
+		conn.Close(ctx)
+		return err
+	}
+
+	if err := c.conn.Close(ctx); err != nil {
+		c.logger.Warn("failed to close connection to standby", slog.Any("error", err))
+	}
+	c.conn = conn
+	if c.replConn != nil {
+		if err := c.replConn.Close(ctx); err != nil {
+			c.logger.Warn("failed to close replication connection to standby", slog.Any("error", err))
+		}
+	}
+	c.replConn = replConn
+	c.logger.Info("reconnected to the primary after failover", slog.String("host", conn.Config().Host))
+	return nil
+}
+
+// createReplicationSlotWithOption creates slot with an option of the Postgres 15+ syntax,
+// FAILOVER or TWO_PHASE, which pglogrepl.CreateReplicationSlot has no option for
+func createReplicationSlotWithOption(
+	ctx context.Context,
+	conn *pgx.Conn,
+	slot string,
+	option string,
+) (pglogrepl.CreateReplicationSlotResult, error) {
+	return pglogrepl.ParseCreateReplicationSlot(conn.PgConn().Exec(ctx,
+		fmt.Sprintf("CREATE_REPLICATION_SLOT %s LOGICAL pgoutput (SNAPSHOT 'export', %s true)", slot, option)))
+}
+
+// ensurePublishViaPartitionRoot has a publication the mirror created before publish changes to partitions
+// as their partitioned table, so partitions created later replicate without the mirror knowing them.
+// Publications owned by another role are left as is, partitions are then mapped to their partitioned table while streaming
+func (c *PostgresConnector) ensurePublishViaPartitionRoot(ctx context.Context, publication string) error {
+	pgversion, err := c.MajorVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("error checking Postgres version: %w", err)
+	}
+	if pgversion < shared.POSTGRES_13 {
+		return nil
+	}
+
+	var pubViaRoot bool
+	if err := c.conn.QueryRow(ctx,
+		"SELECT pubviaroot FROM pg_publication WHERE pubname = $1", publication).Scan(&pubViaRoot); err != nil {
+		return fmt.Errorf("error checking publish_via_partition_root of publication %s: %w", publication, err)
+	}
+	if pubViaRoot {
+		return nil
+	}
+	if _, err := c.conn.Exec(ctx,
+		fmt.Sprintf("ALTER PUBLICATION %s SET (publish_via_partition_root = true)", publication)); err != nil {
+		c.logger.Warn("unable to enable publish_via_partition_root on publication",
+			slog.String("publication", publication), slog.Any("error", err))
+		return nil
+	}
+	c.logger.Info("enabled publish_via_partition_root on publication", slog.String("publication", publication))
+	return nil
+}
+
 // createSlotAndPublication creates the replication slot and publication.
 func (c *PostgresConnector) createSlotAndPublication(
 	ctx context.Context,
@@ -317,6 +506,9 @@ func (c *PostgresConnector) createSlotAndPublication(
 	publication string,
 	tableNameMapping map[string]model.NameAndExclude,
 	doInitialCopy bool,
+	failoverSlot bool,
+	twoPhase bool,
+	existingPublication bool,
 ) error {
 	/*
 		iterating through source tables and creating a publication.
@@ -332,6 +524,18 @@ func (c *PostgresConnector) createSlotAndPublication(
 	}
 	tableNameString := strings.Join(srcTableNames, ", ")
 
+	inRecovery, err := c.isInRecovery(ctx)
+	if err != nil {
+		return err
+	}
+	if inRecovery && !s.PublicationExists {
+		return fmt.Errorf("publication %s does not exist, publications can't be created on a standby, create it on the primary",
+			publication)
+	}
+	if inRecovery && failoverSlot {
+		return errors.New("failover slots can only be created on the primary")
+	}
+
 	if !s.PublicationExists {
 		// check and enable publish_via_partition_root
 		pgversion, err := c.MajorVersion(ctx)
@@ -349,6 +553,11 @@ func (c *PostgresConnector) createSlotAndPublication(
 			c.logger.Warn(fmt.Sprintf("Error creating publication '%s': %v", publication, err))
 			return fmt.Errorf("error creating publication '%s' : %w", publication, err)
 		}
+	} else if !inRecovery && !existingPublication {
+		// publications given to the mirror are left alone, others may depend on them publishing partitions
+		if err := c.ensurePublishViaPartitionRoot(ctx, publication); err != nil {
+			return err
+		}
 	}
 
 	// create slot only after we succeeded in creating publication.
@@ -372,18 +581,30 @@ func (c *PostgresConnector) createSlotAndPublication(
 			return fmt.Errorf("[slot] error setting lock_timeout: %w", err)
 		}
 
-		opts := pglogrepl.CreateReplicationSlotOptions{
-			Temporary: false,
-			Mode:      pglogrepl.LogicalReplication,
-		}
-		res, err := pglogrepl.CreateReplicationSlot(ctx, conn.PgConn(), slot, "pgoutput", opts)
+		pgversion, err := c.MajorVersion(ctx)
 		if err != nil {
-			return fmt.Errorf("[slot] error creating replication slot: %w", err)
+			return fmt.Errorf("[slot] error getting PG version: %w", err)
 		}
 
-		pgversion, err := c.MajorVersion(ctx)
+		// on a standby this waits for the primary to log running transactions, see pg_log_standby_snapshot()
+		var res pglogrepl.CreateReplicationSlotResult
+		if failoverSlot {
+			res, err = createReplicationSlotWithOption(ctx, conn, slot, "FAILOVER")
+		} else if twoPhase {
+			// decode prepared transactions at PREPARE, Postgres 17 can't combine this with FAILOVER
+			if pgversion < shared.POSTGRES_15 {
+				return fmt.Errorf("[slot] two-phase decoding needs Postgres 15 or later, found %d", pgversion)
+			}
+			res, err = createReplicationSlotWithOption(ctx, conn, slot, "TWO_PHASE")
+		} else {
+			opts := pglogrepl.CreateReplicationSlotOptions{
+				Temporary: false,
+				Mode:      pglogrepl.LogicalReplication,
+			}
+			res, err = pglogrepl.CreateReplicationSlot(ctx, conn.PgConn(), slot, "pgoutput", opts)
+		}
 		if err != nil {
-			return fmt.Errorf("[slot] error getting PG version: %w", err)
+			return fmt.Errorf("[slot] error creating replication slot: %w", err)
 		}
 
 		c.logger.Info(fmt.Sprintf("Created replication slot '%s'", slot))
@@ -429,15 +650,17 @@ func getRawTableIdentifier(jobName string) string {
 
 func generateCreateTableSQLForNormalizedTable(
 	sourceTableIdentifier string,
+	dstSchema string,
 	sourceTableSchema *protos.TableSchema,
 	softDeleteColName string,
 	syncedAtColName string,
+	normalizeMode protos.NormalizeMode,
 ) string {
 	createTableSQLArray := make([]string, 0, len(sourceTableSchema.Columns)+2)
 	for _, column := range sourceTableSchema.Columns {
 		pgColumnType := column.Type
 		if sourceTableSchema.System == protos.TypeSystem_Q {
-			pgColumnType = qValueKindToPostgresType(pgColumnType)
+			pgColumnType = postgresColumnType(dstSchema, column)
 		}
 		if column.Type == "numeric" && column.TypeModifier != -1 {
 			precision, scale := numeric.ParseNumericTypmod(column.TypeModifier)
@@ -447,9 +670,25 @@ func generateCreateTableSQLForNormalizedTable(
 			fmt.Sprintf("%s %s", QuoteIdentifier(column.Name), pgColumnType))
 	}
 
-	if softDeleteColName != "" {
+	switch normalizeMode {
+	case protos.NormalizeMode_NORMALIZE_MODE_CHANGELOG:
+		createTableSQLArray = append(createTableSQLArray,
+			QuoteIdentifier(shared.HistoryOpColName)+" TEXT",
+			QuoteIdentifier(shared.HistoryCommitTimeColName)+" TIMESTAMPTZ",
+			QuoteIdentifier(shared.HistoryLSNColName)+" BIGINT",
+			QuoteIdentifier(shared.HistoryBeforeColName)+" JSONB")
+	case protos.NormalizeMode_NORMALIZE_MODE_SCD2:
+		// rows copied by initial load have no history yet, they are the current version
 		createTableSQLArray = append(createTableSQLArray,
-			QuoteIdentifier(softDeleteColName)+` BOOL DEFAULT FALSE`)
+			QuoteIdentifier(shared.HistoryLSNColName)+" BIGINT",
+			QuoteIdentifier(shared.SCD2ValidFromColName)+" TIMESTAMPTZ",
+			QuoteIdentifier(shared.SCD2ValidToColName)+" TIMESTAMPTZ",
+			QuoteIdentifier(shared.SCD2IsCurrentColName)+" BOOL DEFAULT TRUE")
+	default:
+		if softDeleteColName != "" {
+			createTableSQLArray = append(createTableSQLArray,
+				QuoteIdentifier(softDeleteColName)+` BOOL DEFAULT FALSE`)
+		}
 	}
 
 	if syncedAtColName != "" {
@@ -457,8 +696,9 @@ func generateCreateTableSQLForNormalizedTable(
 			QuoteIdentifier(syncedAtColName)+` TIMESTAMP DEFAULT CURRENT_TIMESTAMP`)
 	}
 
-	// add composite primary key to the table
-	if len(sourceTableSchema.PrimaryKeyColumns) > 0 && !sourceTableSchema.IsReplicaIdentityFull {
+	// add composite primary key to the table, history tables hold many rows per key
+	if len(sourceTableSchema.PrimaryKeyColumns) > 0 && !sourceTableSchema.IsReplicaIdentityFull &&
+		normalizeMode == protos.NormalizeMode_NORMALIZE_MODE_MERGE {
 		primaryKeyColsQuoted := make([]string, 0, len(sourceTableSchema.PrimaryKeyColumns))
 		for _, primaryKeyCol := range sourceTableSchema.PrimaryKeyColumns {
 			primaryKeyColsQuoted = append(primaryKeyColsQuoted, QuoteIdentifier(primaryKeyCol))
@@ -514,6 +754,32 @@ func (c *PostgresConnector) jobMetadataExists(ctx context.Context, jobName strin
 	return result.Bool, nil
 }
 
+func getSyncReplicationOrigin(flowJobName string) string {
+	return shared.ReplicationOriginPrefix + "sync_" + flowJobName
+}
+
+func getNormalizeReplicationOrigin(flowJobName string) string {
+	return shared.ReplicationOriginPrefix + "normalize_" + flowJobName
+}
+
+// setupReplicationOrigin makes the session apply changes under replication origin name, creating it if needed,
+// until the returned reset is called. An origin can only be active in one session at a time
+func (c *PostgresConnector) setupReplicationOrigin(ctx context.Context, name string) (func(), error) {
+	if _, err := c.conn.Exec(ctx, createReplicationOriginSQL, name); err != nil {
+		return nil, fmt.Errorf("failed to create replication origin %s: %w", name, err)
+	}
+	if _, err := c.conn.Exec(ctx, setupReplicationOriginSQL, name); err != nil {
+		return nil, fmt.Errorf("failed to setup replication origin %s: %w", name, err)
+	}
+	return func() {
+		resetCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if _, err := c.conn.Exec(resetCtx, resetReplicationOriginSQL); err != nil {
+			c.logger.Error("failed to reset replication origin", slog.String("origin", name), slog.Any("error", err))
+		}
+	}, nil
+}
+
 func (c *PostgresConnector) MajorVersion(ctx context.Context) (shared.PGVersion, error) {
 	return shared.GetMajorVersion(ctx, c.conn)
 }
@@ -0,0 +1,116 @@
+package connpostgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pglogrepl"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/PeerDB-io/peer-flow/connectors/utils"
+	"github.com/PeerDB-io/peer-flow/shared"
+)
+
+const (
+	// runs as its owner, so checking the replication origin doesn't need privileges of the writer
+	createCutoverFenceFunctionSQL = `CREATE OR REPLACE FUNCTION %s.%s() RETURNS trigger
+	LANGUAGE plpgsql SECURITY DEFINER SET search_path = pg_catalog AS $$
+	BEGIN
+		-- mirrors apply changes under a replication origin, which lets a reverse mirror write through the fence
+		IF NOT pg_replication_origin_session_is_setup() THEN
+			RAISE EXCEPTION 'table %%.%% is read-only while its mirror is cut over', TG_TABLE_SCHEMA, TG_TABLE_NAME;
+		END IF;
+		RETURN NULL;
+	END $$`
+	createCutoverFenceTriggerSQL = `CREATE OR REPLACE TRIGGER %s BEFORE INSERT OR UPDATE OR DELETE OR TRUNCATE ON %s
+	FOR EACH STATEMENT EXECUTE FUNCTION %s.%s()`
+	// dropping the function drops the triggers of every fenced table with it
+	dropCutoverFenceSQL = "DROP FUNCTION IF EXISTS %s.%s() CASCADE"
+	emitCutoverFenceSQL = "SELECT pg_logical_emit_message(true, $1, $2)::text"
+)
+
+func getCutoverFenceName(flowJobName string) string {
+	return "peerdb_cutover_fence_" + flowJobName
+}
+
+// FenceForCutover makes the tables read-only with statement triggers and emits a cutover fence message,
+// creating the triggers waits for transactions writing to the tables, so the fence follows every write to them
+func (c *PostgresConnector) FenceForCutover(
+	ctx context.Context,
+	flowJobName string,
+	tableIdentifiers []string,
+) (int64, error) {
+	pgversion, err := c.MajorVersion(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if pgversion < shared.POSTGRES_14 {
+		return 0, fmt.Errorf("cutover needs Postgres 14 or later on the source, found %d", pgversion)
+	}
+
+	fenceName := QuoteIdentifier(getCutoverFenceName(flowJobName))
+	tx, err := c.conn.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction for cutover fence: %w", err)
+	}
+	defer shared.RollbackTx(tx, c.logger)
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf(createSchemaSQL, c.metadataSchema)); err != nil {
+		return 0, fmt.Errorf("error creating internal schema: %w", err)
+	}
+	if _, err := tx.Exec(ctx, fmt.Sprintf(createCutoverFenceFunctionSQL, c.metadataSchema, fenceName)); err != nil {
+		return 0, fmt.Errorf("error creating cutover fence function: %w", err)
+	}
+	for _, tableIdentifier := range tableIdentifiers {
+		schemaTable, err := utils.ParseSchemaTable(tableIdentifier)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec(ctx, fmt.Sprintf(createCutoverFenceTriggerSQL,
+			fenceName, schemaTable.String(), c.metadataSchema, fenceName)); err != nil {
+			return 0, fmt.Errorf("error fencing table %s: %w", tableIdentifier, err)
+		}
+	}
+
+	var fenceLSN pgtype.Text
+	if err := tx.QueryRow(ctx, emitCutoverFenceSQL, shared.CutoverFenceMessagePrefix, flowJobName).Scan(&fenceLSN); err != nil {
+		return 0, fmt.Errorf("error emitting cutover fence: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("error committing cutover fence: %w", err)
+	}
+
+	lsn, err := pglogrepl.ParseLSN(fenceLSN.String)
+	if err != nil {
+		return 0, err
+	}
+	c.logger.Info(fmt.Sprintf("fenced %d tables for cutover at %v", len(tableIdentifiers), lsn))
+	return int64(lsn), nil
+}
+
+// UnfenceForCutover makes tables fenced by FenceForCutover writable again
+func (c *PostgresConnector) UnfenceForCutover(ctx context.Context, flowJobName string) error {
+	_, err := c.conn.Exec(ctx, fmt.Sprintf(dropCutoverFenceSQL,
+		c.metadataSchema, QuoteIdentifier(getCutoverFenceName(flowJobName))))
+	if err != nil {
+		return fmt.Errorf("error dropping cutover fence: %w", err)
+	}
+	return nil
+}
+
+func (c *PostgresConnector) CountRows(ctx context.Context, tableIdentifier string, softDeleteColName string) (int64, error) {
+	schemaTable, err := utils.ParseSchemaTable(tableIdentifier)
+	if err != nil {
+		return 0, err
+	}
+	query := "SELECT count(*) FROM " + schemaTable.String()
+	if softDeleteColName != "" {
+		query += " WHERE " + QuoteIdentifier(softDeleteColName) + " IS NOT TRUE"
+	}
+
+	var count int64
+	if err := c.conn.QueryRow(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting rows of %s: %w", tableIdentifier, err)
+	}
+	return count, nil
+}
@@ -25,25 +25,83 @@ type normalizeStmtGenerator struct {
 	unchangedToastColumnsMap map[string][]string
 	// _PEERDB_IS_DELETED and _SYNCED_AT columns
 	peerdbCols *protos.PeerDBColumns
+	// tables normalized as history instead of being merged
+	normalizeModes map[string]protos.NormalizeMode
 	// Postgres metadata schema
 	metadataSchema string
 	// Postgres version 15 introduced MERGE, fallback statements before that
 	supportsMerge bool
+	// replication origin normalize applies changes under, if set conflicting writes are resolved by commit time
+	replicationOrigin string
 }
 
-func (n *normalizeStmtGenerator) columnTypeToPg(schema *protos.TableSchema, columnType string) string {
+// lastWriterWinsCondition limits merging into rows last written by an earlier commit, or by this mirror,
+// whose changes arrive in commit order. Rows without a commit timestamp are always changed
+func (n *normalizeStmtGenerator) lastWriterWinsCondition() string {
+	if n.replicationOrigin == "" {
+		return ""
+	}
+	return fmt.Sprintf(" AND COALESCE((pg_xact_commit_timestamp_origin(dst.xmin)).roident=pg_replication_origin_oid(%s)"+
+		" OR (pg_xact_commit_timestamp_origin(dst.xmin)).timestamp<%s,TRUE)",
+		QuoteLiteral(n.replicationOrigin), commitTimeToTimestamp("src._peerdb_commit_time_ns"))
+}
+
+// columnCast returns the expression extracting column from _peerdb_data as its type in the destination schema
+func (n *normalizeStmtGenerator) columnCast(
+	schema *protos.TableSchema,
+	dstSchema string,
+	column *protos.FieldDescription,
+) string {
+	stringCol := QuoteLiteral(column.Name)
 	switch schema.System {
 	case protos.TypeSystem_Q:
-		return qValueKindToPostgresType(columnType)
+		kind := qvalue.QValueKind(column.Type)
+		pgType := postgresColumnType(dstSchema, column)
+		switch {
+		case kind == qvalue.QValueKindStruct && hasCustomType(column):
+			jsonCol := fmt.Sprintf("(_peerdb_data->%s)", stringCol)
+			return fmt.Sprintf("CASE WHEN JSONB_TYPEOF(%[1]s)='object' THEN JSONB_POPULATE_RECORD(NULL::%[2]s,%[1]s) END", jsonCol, pgType)
+		case kind == qvalue.QValueKindArrayOf(qvalue.QValueKindStruct) && hasCustomType(column):
+			jsonCol := fmt.Sprintf("(_peerdb_data->%s)", stringCol)
+			return fmt.Sprintf("CASE WHEN JSONB_TYPEOF(%[1]s)='array' THEN ARRAY(SELECT CASE WHEN JSONB_TYPEOF(e.v)='object' "+
+				"THEN JSONB_POPULATE_RECORD(NULL::%[2]s,e.v) END FROM JSONB_ARRAY_ELEMENTS(%[1]s) AS e(v)) END",
+				jsonCol, customTypeIdentifier(dstSchema, column))
+		case kind.IsArray():
+			return fmt.Sprintf("ARRAY(SELECT JSON_ARRAY_ELEMENTS_TEXT((_peerdb_data->>%s)::JSON))::%s", stringCol, pgType)
+		case kind.IsRange():
+			jsonCol := fmt.Sprintf("(_peerdb_data->%s)", stringCol)
+			return fmt.Sprintf("CASE WHEN JSONB_TYPEOF(%s)='object' THEN %s END", jsonCol, rangeFromJSON(jsonCol, kind))
+		case kind.IsMultirange():
+			jsonCol := fmt.Sprintf("(_peerdb_data->%s)", stringCol)
+			return fmt.Sprintf("CASE WHEN JSONB_TYPEOF(%s)='array' THEN %s(VARIADIC ARRAY(SELECT %s FROM JSONB_ARRAY_ELEMENTS(%s) AS r(v))) END",
+				jsonCol, strings.ToLower(pgType), rangeFromJSON("r.v", kind.RangeKind()), jsonCol)
+		default:
+			return fmt.Sprintf("(_peerdb_data->>%s)::%s", stringCol, pgType)
+		}
 	case protos.TypeSystem_PG:
-		return columnType
+		return fmt.Sprintf("(_peerdb_data->>%s)::%s", stringCol, column.Type)
 	default:
 		panic(fmt.Sprintf("unsupported system %s", schema.System))
 	}
 }
 
+// rangeFromJSON builds a range of kind from jsonRange, an object with bounds and flags as written by QValueRange
+func rangeFromJSON(jsonRange string, kind qvalue.QValueKind) string {
+	pgType := qValueKindToPostgresType(string(kind))
+	elemType := qValueKindToPostgresType(string(kind.RangeElementKind()))
+	return fmt.Sprintf("CASE WHEN (%[1]s->>'empty')::BOOL THEN 'empty'::%[2]s ELSE %[3]s((%[1]s->>'lower')::%[4]s,(%[1]s->>'upper')::%[4]s,"+
+		"CASE WHEN (%[1]s->>'lower_inclusive')::BOOL THEN '[' ELSE '(' END||CASE WHEN (%[1]s->>'upper_inclusive')::BOOL THEN ']' ELSE ')' END) END",
+		jsonRange, pgType, strings.ToLower(pgType), elemType)
+}
+
 func (n *normalizeStmtGenerator) generateNormalizeStatements(dstTable string) []string {
 	normalizedTableSchema := n.tableSchemaMapping[dstTable]
+	switch n.normalizeModes[dstTable] {
+	case protos.NormalizeMode_NORMALIZE_MODE_CHANGELOG:
+		return []string{n.generateChangelogStatement(dstTable, normalizedTableSchema)}
+	case protos.NormalizeMode_NORMALIZE_MODE_SCD2:
+		return n.generateSCD2Statements(dstTable, normalizedTableSchema)
+	}
 	if n.supportsMerge {
 		unchangedToastColumns := n.unchangedToastColumnsMap[dstTable]
 		return []string{n.generateMergeStatement(dstTable, normalizedTableSchema, unchangedToastColumns)}
@@ -64,18 +122,11 @@ func (n *normalizeStmtGenerator) generateFallbackStatements(
 	columnNames := make([]string, 0, columnCount)
 	flattenedCastsSQLArray := make([]string, 0, columnCount)
 	primaryKeyColumnCasts := make(map[string]string, len(normalizedTableSchema.PrimaryKeyColumns))
+	parsedDstTable, _ := utils.ParseSchemaTable(dstTableName)
 	for _, column := range normalizedTableSchema.Columns {
-		genericColumnType := column.Type
 		quotedCol := QuoteIdentifier(column.Name)
-		stringCol := QuoteLiteral(column.Name)
 		columnNames = append(columnNames, quotedCol)
-		pgType := n.columnTypeToPg(normalizedTableSchema, genericColumnType)
-		var expr string
-		if normalizedTableSchema.System == protos.TypeSystem_Q && qvalue.QValueKind(genericColumnType).IsArray() {
-			expr = fmt.Sprintf("ARRAY(SELECT JSON_ARRAY_ELEMENTS_TEXT((_peerdb_data->>%s)::JSON))::%s", stringCol, pgType)
-		} else {
-			expr = fmt.Sprintf("(_peerdb_data->>%s)::%s", stringCol, pgType)
-		}
+		expr := n.columnCast(normalizedTableSchema, parsedDstTable.Schema, column)
 
 		flattenedCastsSQLArray = append(flattenedCastsSQLArray, fmt.Sprintf("%s AS %s", expr, quotedCol))
 		if slices.Contains(normalizedTableSchema.PrimaryKeyColumns, column.Name) {
@@ -83,7 +134,6 @@ func (n *normalizeStmtGenerator) generateFallbackStatements(
 		}
 	}
 	flattenedCastsSQL := strings.Join(flattenedCastsSQLArray, ",")
-	parsedDstTable, _ := utils.ParseSchemaTable(dstTableName)
 
 	insertColumnsSQL := strings.Join(columnNames, ",")
 	updateColumnsSQLArray := make([]string, 0, columnCount)
@@ -134,21 +184,13 @@ func (n *normalizeStmtGenerator) generateMergeStatement(
 	primaryKeyColumnCasts := make(map[string]string)
 	primaryKeySelectSQLArray := make([]string, 0, len(normalizedTableSchema.PrimaryKeyColumns))
 	for i, column := range normalizedTableSchema.Columns {
-		genericColumnType := column.Type
 		quotedCol := QuoteIdentifier(column.Name)
-		stringCol := QuoteLiteral(column.Name)
 		quotedColumnNames[i] = quotedCol
 
-		pgType := n.columnTypeToPg(normalizedTableSchema, genericColumnType)
-		var expr string
-		if normalizedTableSchema.System == protos.TypeSystem_Q && qvalue.QValueKind(genericColumnType).IsArray() {
-			expr = fmt.Sprintf("ARRAY(SELECT JSON_ARRAY_ELEMENTS_TEXT((_peerdb_data->>%s)::JSON))::%s", stringCol, pgType)
-		} else {
-			expr = fmt.Sprintf("(_peerdb_data->>%s)::%s", stringCol, pgType)
-		}
+		expr := n.columnCast(normalizedTableSchema, parsedDstTable.Schema, column)
 		flattenedCastsSQLArray = append(flattenedCastsSQLArray, fmt.Sprintf("%s AS %s", expr, quotedCol))
 		if slices.Contains(normalizedTableSchema.PrimaryKeyColumns, column.Name) {
-			primaryKeyColumnCasts[column.Name] = fmt.Sprintf("(_peerdb_data->>%s)::%s", stringCol, pgType)
+			primaryKeyColumnCasts[column.Name] = expr
 			primaryKeySelectSQLArray = append(primaryKeySelectSQLArray, fmt.Sprintf("src.%s=dst.%s", quotedCol, quotedCol))
 		}
 	}
@@ -187,8 +229,13 @@ func (n *normalizeStmtGenerator) generateMergeStatement(
 		}
 	}
 
+	commitTimeColumn := ""
+	if n.replicationOrigin != "" {
+		commitTimeColumn = ",_peerdb_commit_time_ns"
+	}
 	mergeStmt := fmt.Sprintf(
 		mergeStatementSQL,
+		commitTimeColumn,
 		strings.Join(maps.Values(primaryKeyColumnCasts), ","),
 		n.metadataSchema,
 		n.rawTableName,
@@ -198,12 +245,111 @@ func (n *normalizeStmtGenerator) generateMergeStatement(
 		insertColumnsSQL,
 		insertValuesSQL,
 		updateStringToastCols,
+		n.lastWriterWinsCondition(),
 		conflictPart,
 	)
 
 	return mergeStmt
 }
 
+// columnCasts returns the quoted columns of a table and the expressions extracting them from _peerdb_data
+func (n *normalizeStmtGenerator) columnCasts(
+	dstSchema string,
+	normalizedTableSchema *protos.TableSchema,
+) ([]string, map[string]string) {
+	quotedColumnNames := make([]string, 0, len(normalizedTableSchema.Columns))
+	casts := make(map[string]string, len(normalizedTableSchema.Columns))
+	for _, column := range normalizedTableSchema.Columns {
+		quotedColumnNames = append(quotedColumnNames, QuoteIdentifier(column.Name))
+		casts[column.Name] = n.columnCast(normalizedTableSchema, dstSchema, column)
+	}
+	return quotedColumnNames, casts
+}
+
+// generateChangelogStatement appends every change of the batch as a row
+func (n *normalizeStmtGenerator) generateChangelogStatement(dstTableName string, normalizedTableSchema *protos.TableSchema) string {
+	parsedDstTable, _ := utils.ParseSchemaTable(dstTableName)
+	quotedColumnNames, casts := n.columnCasts(parsedDstTable.Schema, normalizedTableSchema)
+	selectSQLArray := make([]string, 0, len(quotedColumnNames)+5)
+	for _, column := range normalizedTableSchema.Columns {
+		selectSQLArray = append(selectSQLArray, casts[column.Name])
+	}
+	insertColumnsSQLArray := append(slices.Clone(quotedColumnNames),
+		QuoteIdentifier(shared.HistoryOpColName),
+		QuoteIdentifier(shared.HistoryCommitTimeColName),
+		QuoteIdentifier(shared.HistoryLSNColName),
+		QuoteIdentifier(shared.HistoryBeforeColName))
+	selectSQLArray = append(selectSQLArray,
+		"CASE _peerdb_record_type WHEN 0 THEN 'insert' WHEN 1 THEN 'update' ELSE 'delete' END",
+		commitTimeToTimestamp("_peerdb_commit_time_ns"),
+		"_peerdb_commit_lsn",
+		"NULLIF(_peerdb_match_data,'{}'::JSONB)")
+	if n.peerdbCols.SyncedAtColName != "" {
+		insertColumnsSQLArray = append(insertColumnsSQLArray, QuoteIdentifier(n.peerdbCols.SyncedAtColName))
+		selectSQLArray = append(selectSQLArray, "CURRENT_TIMESTAMP")
+	}
+
+	return fmt.Sprintf(changelogStatementSQL, parsedDstTable.String(),
+		strings.Join(insertColumnsSQLArray, ","), strings.Join(selectSQLArray, ","), n.metadataSchema, n.rawTableName)
+}
+
+// generateSCD2Statements closes the current version of each key changed in the batch,
+// then inserts the versions of the batch, each valid until the next change of its key
+func (n *normalizeStmtGenerator) generateSCD2Statements(dstTableName string, normalizedTableSchema *protos.TableSchema) []string {
+	parsedDstTable, _ := utils.ParseSchemaTable(dstTableName)
+	quotedColumnNames, casts := n.columnCasts(parsedDstTable.Schema, normalizedTableSchema)
+
+	pkeyCount := len(normalizedTableSchema.PrimaryKeyColumns)
+	pkeyPartitionSQLArray := make([]string, 0, pkeyCount)
+	pkeyCastsSQLArray := make([]string, 0, pkeyCount)
+	pkeyMatchSQLArray := make([]string, 0, pkeyCount)
+	for _, pkeyCol := range normalizedTableSchema.PrimaryKeyColumns {
+		quotedPkeyCol := QuoteIdentifier(pkeyCol)
+		pkeyPartitionSQLArray = append(pkeyPartitionSQLArray, casts[pkeyCol])
+		pkeyCastsSQLArray = append(pkeyCastsSQLArray, fmt.Sprintf("%s AS %s", casts[pkeyCol], quotedPkeyCol))
+		pkeyMatchSQLArray = append(pkeyMatchSQLArray, fmt.Sprintf("dst.%s=src.%s", quotedPkeyCol, quotedPkeyCol))
+	}
+
+	closeSetSQL := fmt.Sprintf("%s=%s,%s=FALSE", QuoteIdentifier(shared.SCD2ValidToColName),
+		commitTimeToTimestamp("src._peerdb_first_commit"), QuoteIdentifier(shared.SCD2IsCurrentColName))
+	if n.peerdbCols.SyncedAtColName != "" {
+		closeSetSQL += fmt.Sprintf(",%s=CURRENT_TIMESTAMP", QuoteIdentifier(n.peerdbCols.SyncedAtColName))
+	}
+	closeStatement := fmt.Sprintf(scd2CloseStatementSQL, strings.Join(pkeyCastsSQLArray, ","),
+		n.metadataSchema, n.rawTableName,
+		strings.Join(pkeyPartitionSQLArray, ","), parsedDstTable.String(), closeSetSQL,
+		QuoteIdentifier(shared.SCD2IsCurrentColName), strings.Join(pkeyMatchSQLArray, " AND "))
+
+	flattenedCastsSQLArray := make([]string, 0, len(quotedColumnNames))
+	for i, column := range normalizedTableSchema.Columns {
+		flattenedCastsSQLArray = append(flattenedCastsSQLArray, fmt.Sprintf("%s AS %s", casts[column.Name], quotedColumnNames[i]))
+	}
+	selectSQLArray := append(slices.Clone(quotedColumnNames),
+		"_peerdb_commit_lsn",
+		commitTimeToTimestamp("_peerdb_commit_time_ns"),
+		commitTimeToTimestamp("_peerdb_next_commit"),
+		"_peerdb_next_commit IS NULL")
+	insertColumnsSQLArray := append(slices.Clone(quotedColumnNames),
+		QuoteIdentifier(shared.HistoryLSNColName),
+		QuoteIdentifier(shared.SCD2ValidFromColName),
+		QuoteIdentifier(shared.SCD2ValidToColName),
+		QuoteIdentifier(shared.SCD2IsCurrentColName))
+	if n.peerdbCols.SyncedAtColName != "" {
+		insertColumnsSQLArray = append(insertColumnsSQLArray, QuoteIdentifier(n.peerdbCols.SyncedAtColName))
+		selectSQLArray = append(selectSQLArray, "CURRENT_TIMESTAMP")
+	}
+	insertStatement := fmt.Sprintf(scd2InsertStatementSQL, strings.Join(flattenedCastsSQLArray, ","),
+		strings.Join(pkeyPartitionSQLArray, ","), n.metadataSchema, n.rawTableName, parsedDstTable.String(),
+		strings.Join(insertColumnsSQLArray, ","), strings.Join(selectSQLArray, ","))
+
+	return []string{closeStatement, insertStatement}
+}
+
+// commitTimeToTimestamp converts unix nanoseconds to a timestamp, keeping microsecond precision
+func commitTimeToTimestamp(expr string) string {
+	return fmt.Sprintf("('epoch'::TIMESTAMPTZ+(%s/1000)*INTERVAL '1 microsecond')", expr)
+}
+
 func (n *normalizeStmtGenerator) generateUpdateStatements(quotedCols []string, unchangedToastColumns []string) []string {
 	handleSoftDelete := n.peerdbCols.SoftDelete && (n.peerdbCols.SoftDeleteColName != "")
 	stmtCount := len(unchangedToastColumns)
@@ -234,8 +380,8 @@ func (n *normalizeStmtGenerator) generateUpdateStatements(quotedCols []string, u
 		quotedCols := QuoteLiteral(cols)
 		ssep := strings.Join(tmpArray, ",")
 		updateStmt := fmt.Sprintf(`WHEN MATCHED AND
-			src._peerdb_record_type!=2 AND _peerdb_unchanged_toast_columns=%s
-			THEN UPDATE SET %s`, quotedCols, ssep)
+			src._peerdb_record_type!=2 AND _peerdb_unchanged_toast_columns=%s%s
+			THEN UPDATE SET %s`, quotedCols, n.lastWriterWinsCondition(), ssep)
 		updateStmts = append(updateStmts, updateStmt)
 
 		// generates update statements for the case where updates and deletes happen in the same branch
@@ -245,8 +391,8 @@ func (n *normalizeStmtGenerator) generateUpdateStatements(quotedCols []string, u
 			tmpArray[len(tmpArray)-1] = QuoteIdentifier(n.peerdbCols.SoftDeleteColName) + `=TRUE`
 			ssep := strings.Join(tmpArray, ", ")
 			updateStmt := fmt.Sprintf(`WHEN MATCHED AND
-			src._peerdb_record_type=2 AND _peerdb_unchanged_toast_columns=%s
-			THEN UPDATE SET %s`, quotedCols, ssep)
+			src._peerdb_record_type=2 AND _peerdb_unchanged_toast_columns=%s%s
+			THEN UPDATE SET %s`, quotedCols, n.lastWriterWinsCondition(), ssep)
 			updateStmts = append(updateStmts, updateStmt)
 		}
 	}
@@ -2,10 +2,13 @@ package connpostgres
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/PeerDB-io/peer-flow/connectors/utils"
 	"github.com/PeerDB-io/peer-flow/generated/protos"
+	"github.com/PeerDB-io/peer-flow/model/qvalue"
+	"github.com/PeerDB-io/peer-flow/shared"
 )
 
 func TestGenerateMergeUpdateStatement(t *testing.T) {
@@ -142,3 +145,89 @@ func TestGenerateMergeUpdateStatement_WithUnchangedToastColsAndSoftDelete(t *tes
 		t.Errorf("Unexpected result. Expected: %v, but got: %v", expected, result)
 	}
 }
+
+func TestGenerateMergeUpdateStatement_LastWriterWins(t *testing.T) {
+	allCols := []string{`"col1"`, `"col2"`}
+	unchangedToastCols := []string{""}
+
+	expected := []string{
+		`WHEN MATCHED AND src._peerdb_record_type!=2 AND _peerdb_unchanged_toast_columns=''
+		 AND COALESCE((pg_xact_commit_timestamp_origin(dst.xmin)).roident=pg_replication_origin_oid('peerdb_normalize_m')
+		 OR (pg_xact_commit_timestamp_origin(dst.xmin)).timestamp<('epoch'::TIMESTAMPTZ+(src._peerdb_commit_time_ns/1000)
+		 *INTERVAL '1 microsecond'),TRUE)
+		 THEN UPDATE SET "col1"=src."col1","col2"=src."col2"`,
+	}
+	normalizeGen := normalizeStmtGenerator{
+		peerdbCols:        &protos.PeerDBColumns{},
+		replicationOrigin: "peerdb_normalize_m",
+	}
+	result := normalizeGen.generateUpdateStatements(allCols, unchangedToastCols)
+
+	for i := range expected {
+		expected[i] = utils.RemoveSpacesTabsNewlines(expected[i])
+		result[i] = utils.RemoveSpacesTabsNewlines(result[i])
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Unexpected result. Expected: %v, but got: %v", expected, result)
+	}
+}
+
+func TestGenerateMergeStatement_LastWriterWins(t *testing.T) {
+	normalizeGen := normalizeStmtGenerator{
+		rawTableName: "_peerdb_raw_m",
+		tableSchemaMapping: map[string]*protos.TableSchema{"public.t": {
+			PrimaryKeyColumns: []string{"id"},
+			System:            protos.TypeSystem_PG,
+			Columns:           []*protos.FieldDescription{{Name: "id", Type: "int4"}},
+		}},
+		peerdbCols:        &protos.PeerDBColumns{},
+		metadataSchema:    "_peerdb_internal",
+		supportsMerge:     true,
+		replicationOrigin: "peerdb_normalize_m",
+	}
+	stmt := utils.RemoveSpacesTabsNewlines(normalizeGen.generateNormalizeStatements("public.t")[0])
+
+	for _, expected := range []string{
+		"SELECT _peerdb_data,_peerdb_record_type,_peerdb_unchanged_toast_columns,_peerdb_commit_time_ns,",
+		`WHEN MATCHED AND src._peerdb_record_type=2 AND COALESCE((pg_xact_commit_timestamp_origin(dst.xmin)).roident=`,
+	} {
+		if !strings.Contains(stmt, utils.RemoveSpacesTabsNewlines(expected)) {
+			t.Errorf("expected %s in %s", expected, stmt)
+		}
+	}
+}
+
+func TestDestinationTypeName(t *testing.T) {
+	if name := destinationTypeName(shared.CustomDataType{Name: "status", Schema: "public"}); name != "status" {
+		t.Errorf("expected status, got %s", name)
+	}
+	if name := destinationTypeName(shared.CustomDataType{Name: "status", Schema: "sales"}); name != "sales_status" {
+		t.Errorf("expected sales_status, got %s", name)
+	}
+}
+
+func TestMissingEnumLabelsSQL(t *testing.T) {
+	column := &protos.FieldDescription{Name: "status", Type: string(qvalue.QValueKindEnum), TypeName: "sales_status"}
+	expected := `SELECT DISTINCT (_peerdb_data->>'status') FROM _peerdb_internal._peerdb_raw_mirror
+		WHERE _peerdb_batch_id>$1 AND _peerdb_batch_id<=$2 AND _peerdb_destination_table_name=$3
+		AND (_peerdb_data->>'status') IS NOT NULL AND NOT EXISTS(SELECT 1 FROM pg_enum
+		WHERE enumtypid='"dst"."sales_status"'::regtype AND enumlabel=(_peerdb_data->>'status'))`
+	result := missingEnumLabelsSQL("_peerdb_internal", "_peerdb_raw_mirror", "dst", column)
+	if utils.RemoveSpacesTabsNewlines(result) != utils.RemoveSpacesTabsNewlines(expected) {
+		t.Errorf("unexpected SQL for enum column:\n%s", result)
+	}
+
+	arrayColumn := &protos.FieldDescription{
+		Name: "tags", Type: string(qvalue.QValueKindArrayOf(qvalue.QValueKindEnum)), TypeName: "tag",
+	}
+	expected = `SELECT DISTINCT e.label FROM _peerdb_internal._peerdb_raw_mirror,
+		JSON_ARRAY_ELEMENTS_TEXT((_peerdb_data->>'tags')::JSON) AS e(label)
+		WHERE _peerdb_batch_id>$1 AND _peerdb_batch_id<=$2 AND _peerdb_destination_table_name=$3
+		AND e.label IS NOT NULL AND NOT EXISTS(SELECT 1 FROM pg_enum
+		WHERE enumtypid='"dst"."tag"'::regtype AND enumlabel=e.label)`
+	result = missingEnumLabelsSQL("_peerdb_internal", "_peerdb_raw_mirror", "dst", arrayColumn)
+	if utils.RemoveSpacesTabsNewlines(result) != utils.RemoveSpacesTabsNewlines(expected) {
+		t.Errorf("unexpected SQL for enum array column:\n%s", result)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"strings"
 	"sync"
@@ -26,7 +27,6 @@ import (
 	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/logger"
 	"github.com/PeerDB-io/peer-flow/model"
-	"github.com/PeerDB-io/peer-flow/model/qvalue"
 	"github.com/PeerDB-io/peer-flow/otel_metrics"
 	"github.com/PeerDB-io/peer-flow/peerdbenv"
 	"github.com/PeerDB-io/peer-flow/shared"
@@ -40,12 +40,19 @@ type PostgresConnector struct {
 	replConfig             *pgx.ConnConfig
 	replConn               *pgx.Conn
 	replState              *ReplState
-	customTypesMapping     map[uint32]string
+	customTypesMapping     map[uint32]shared.CustomDataType
 	hushWarnOID            map[uint32]struct{}
 	relationMessageMapping model.RelationMessageMapping
-	connStr                string
-	metadataSchema         string
-	replLock               sync.Mutex
+	// in-progress and prepared transactions decoded on replConn, kept across pulls until they commit or abort,
+	// each a *utils.TxnStore of the Items being pulled
+	streamedTxns map[uint32]io.Closer
+	// PREPARE LSN of transactions decoded at PREPARE and buffered in streamedTxns until COMMIT PREPARED
+	preparedTxns map[uint32]pglogrepl.LSN
+	// a committed transaction whose buffered records didn't all fit in a batch, the next pull adds the rest first
+	committingTxn  *committingTxn
+	connStr        string
+	metadataSchema string
+	replLock       sync.Mutex
 }
 
 type ReplState struct {
@@ -92,6 +99,7 @@ func NewPostgresConnector(ctx context.Context, pgConfig *protos.PostgresConfig)
 		logger.Error("failed to get custom type map", slog.Any("error", err))
 		return nil, fmt.Errorf("failed to get custom type map: %w", err)
 	}
+	shared.RegisterCustomTypes(conn.TypeMap(), customTypeMap)
 
 	metadataSchema := "_peerdb_internal"
 	if pgConfig.MetadataSchema != nil {
@@ -111,6 +119,8 @@ func NewPostgresConnector(ctx context.Context, pgConfig *protos.PostgresConfig)
 		hushWarnOID:            make(map[uint32]struct{}),
 		logger:                 logger,
 		relationMessageMapping: make(model.RelationMessageMapping),
+		streamedTxns:           make(map[uint32]io.Closer),
+		preparedTxns:           make(map[uint32]pglogrepl.LSN),
 	}, nil
 }
 
@@ -153,6 +163,7 @@ func (c *PostgresConnector) MaybeStartReplication(
 	slotName string,
 	publicationName string,
 	lastOffset int64,
+	binaryTransfer bool,
 ) error {
 	if c.replState != nil && (c.replState.Offset != lastOffset ||
 		c.replState.Slot != slotName ||
@@ -165,7 +176,25 @@ func (c *PostgresConnector) MaybeStartReplication(
 	}
 
 	if c.replState == nil {
-		replicationOpts, err := c.replicationOptions(publicationName)
+		twoPhase, err := c.checkReplicationSlot(ctx, slotName)
+		if errors.Is(err, errSlotOnPrimary) {
+			c.logger.Info("connected to a standby without the slot, resolving the primary", slog.Any("reason", err))
+			if err := c.resolvePrimary(ctx); err != nil {
+				return err
+			}
+			twoPhase, err = c.checkReplicationSlot(ctx, slotName)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Postgres streams in-progress transactions again from their start on a new replication connection,
+		// and decodes prepared transactions again as the checkpoint is held before their PREPARE
+		if err := c.closeStreamedTxns(); err != nil {
+			c.logger.Warn("failed to clean up streamed transactions", slog.Any("error", err))
+		}
+
+		replicationOpts, err := c.replicationOptions(ctx, publicationName, binaryTransfer, twoPhase)
 		if err != nil {
 			return fmt.Errorf("error getting replication options: %w", err)
 		}
@@ -199,6 +228,32 @@ func (c *PostgresConnector) MaybeStartReplication(
 	return nil
 }
 
+// supportsBinaryTransfer is whether all column types of tables can be converted from the binary format,
+// domains and types of extensions without a pgx codec are only replicated in text format
+func (c *PostgresConnector) supportsBinaryTransfer(ctx context.Context, srcTableIDNameMapping map[uint32]string) (bool, error) {
+	relIDs := make([]uint32, 0, len(srcTableIDNameMapping))
+	for relID := range srcTableIDNameMapping {
+		relIDs = append(relIDs, relID)
+	}
+	rows, err := c.conn.Query(ctx, `SELECT DISTINCT atttypid FROM pg_attribute
+		WHERE attrelid = ANY($1) AND attnum > 0 AND NOT attisdropped`, relIDs)
+	if err != nil {
+		return false, fmt.Errorf("error getting column types: %w", err)
+	}
+	typeOIDs, err := pgx.CollectRows[uint32](rows, pgx.RowTo)
+	if err != nil {
+		return false, fmt.Errorf("error getting column types: %w", err)
+	}
+	for _, typeOID := range typeOIDs {
+		if !supportsBinaryFormat(c.conn.TypeMap(), c.customTypesMapping, typeOID) {
+			c.logger.Warn("binary format of column type is not supported, replicating in text format",
+				slog.Uint64("typeOID", uint64(typeOID)))
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 func (c *PostgresConnector) startReplication(ctx context.Context, slotName string, opts startReplicationOpts) error {
 	err := pglogrepl.StartReplication(ctx, opts.conn, slotName, opts.startLSN, opts.replicationOpts)
 	if err != nil {
@@ -210,9 +265,36 @@ func (c *PostgresConnector) startReplication(ctx context.Context, slotName strin
 	return nil
 }
 
-func (c *PostgresConnector) replicationOptions(publicationName string) (*pglogrepl.StartReplicationOptions, error) {
-	pluginArguments := []string{
-		"proto_version '1'",
+func (c *PostgresConnector) replicationOptions(
+	ctx context.Context,
+	publicationName string,
+	binaryTransfer bool,
+	twoPhase bool,
+) (*pglogrepl.StartReplicationOptions, error) {
+	pgversion, err := c.MajorVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error checking Postgres version: %w", err)
+	}
+
+	var pluginArguments []string
+	if pgversion >= shared.POSTGRES_14 {
+		// stream large transactions while in progress rather than spilling them on the source until commit
+		if twoPhase {
+			// the slot decodes prepared transactions at PREPARE, only possible on Postgres 15+, which needs protocol 3
+			pluginArguments = append(pluginArguments, "proto_version '3'", "streaming 'on'", "two_phase 'on'")
+		} else {
+			pluginArguments = append(pluginArguments, "proto_version '2'", "streaming 'on'")
+		}
+		if binaryTransfer {
+			pluginArguments = append(pluginArguments, "binary 'true'")
+		}
+		// logical decoding messages carry cutover fences
+		pluginArguments = append(pluginArguments, "messages 'true'")
+	} else {
+		if binaryTransfer {
+			c.logger.Warn("binary transfer needs Postgres 14 or later, replicating in text format")
+		}
+		pluginArguments = append(pluginArguments, "proto_version '1'")
 	}
 
 	if publicationName != "" {
@@ -240,10 +322,25 @@ func (c *PostgresConnector) Close() error {
 		}
 
 		c.ssh.Close()
+		replerr = errors.Join(replerr, c.closeStreamedTxns())
 	}
 	return errors.Join(connerr, replerr)
 }
 
+func (c *PostgresConnector) closeStreamedTxns() error {
+	var err error
+	for xid, txn := range c.streamedTxns {
+		err = errors.Join(err, txn.Close())
+		delete(c.streamedTxns, xid)
+	}
+	clear(c.preparedTxns)
+	if c.committingTxn != nil {
+		err = errors.Join(err, c.committingTxn.txn.Close())
+		c.committingTxn = nil
+	}
+	return err
+}
+
 func (c *PostgresConnector) Conn() *pgx.Conn {
 	return c.conn
 }
@@ -327,6 +424,8 @@ func (c *PostgresConnector) PullPg(
 	catalogPool *pgxpool.Pool,
 	req *model.PullRecordsRequest[model.PgItems],
 ) error {
+	// raw tables hold values in text format, so they are pulled as text
+	req.BinaryTransfer = false
 	return pullCore(ctx, c, catalogPool, req, pgProcessor{})
 }
 
@@ -382,7 +481,13 @@ func pullCore[Items model.Items](
 	c.replLock.Lock()
 	defer c.replLock.Unlock()
 
-	if err := c.MaybeStartReplication(ctx, slotName, publicationName, req.LastOffset); err != nil {
+	binaryTransfer := req.BinaryTransfer
+	if binaryTransfer && c.replState == nil {
+		if binaryTransfer, err = c.supportsBinaryTransfer(ctx, req.SrcTableIDNameMapping); err != nil {
+			return err
+		}
+	}
+	if err := c.MaybeStartReplication(ctx, slotName, publicationName, req.LastOffset, binaryTransfer); err != nil {
 		c.logger.Error("error starting replication", slog.Any("error", err))
 		return err
 	}
@@ -397,6 +502,7 @@ func pullCore[Items model.Items](
 		CatalogPool:            catalogPool,
 		FlowJobName:            req.FlowJobName,
 		RelationMessageMapping: c.relationMessageMapping,
+		SkipReplicatedChanges:  req.SkipReplicatedChanges,
 	})
 
 	if err := PullCdcRecords(ctx, cdc, req, processor); err != nil {
@@ -523,24 +629,39 @@ func syncRecordsCore[Items model.Items](
 				return nil, fmt.Errorf("unsupported record type for Postgres flow connector: %T", typedRecord)
 			}
 
+			if req.CommitInfo {
+				row = append(row, record.GetCheckpointID(), record.GetCommitTime().UnixNano())
+			}
+
 			record.PopulateCountMap(tableNameRowsMapping)
 			numRecords += 1
 			return row, nil
 		}
 	}
 
+	if req.ReplicationOrigin {
+		resetOrigin, err := c.setupReplicationOrigin(ctx, getSyncReplicationOrigin(req.FlowJobName))
+		if err != nil {
+			return nil, err
+		}
+		defer resetOrigin()
+	}
+
 	syncRecordsTx, err := c.conn.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("error starting transaction for syncing records: %w", err)
 	}
 	defer shared.RollbackTx(syncRecordsTx, c.logger)
 
+	rawColumns := []string{
+		"_peerdb_uid", "_peerdb_timestamp", "_peerdb_destination_table_name", "_peerdb_data",
+		"_peerdb_record_type", "_peerdb_match_data", "_peerdb_batch_id", "_peerdb_unchanged_toast_columns",
+	}
+	if req.CommitInfo {
+		rawColumns = append(rawColumns, shared.RawCommitLSNColName, shared.RawCommitTimeNsColName)
+	}
 	syncedRecordsCount, err := syncRecordsTx.CopyFrom(ctx, pgx.Identifier{c.metadataSchema, rawTableIdentifier},
-		[]string{
-			"_peerdb_uid", "_peerdb_timestamp", "_peerdb_destination_table_name", "_peerdb_data",
-			"_peerdb_record_type", "_peerdb_match_data", "_peerdb_batch_id", "_peerdb_unchanged_toast_columns",
-		},
-		pgx.CopyFromFunc(streamReadFunc))
+		rawColumns, pgx.CopyFromFunc(streamReadFunc))
 	if err != nil {
 		return nil, fmt.Errorf("error syncing records: %w", err)
 	}
@@ -617,11 +738,30 @@ func (c *PostgresConnector) NormalizeRecords(
 	if err != nil {
 		return nil, err
 	}
+	destinationTableNames = utils.TablesToNormalize(destinationTableNames, req.TableNameSchemaMapping, c.logger)
 	unchangedToastColumnsMap, err := c.getTableNametoUnchangedCols(ctx, req.FlowJobName,
 		req.SyncBatchID, normBatchID)
 	if err != nil {
 		return nil, err
 	}
+	if err := c.addMissingEnumLabels(ctx, rawTableIdentifier, destinationTableNames,
+		req.TableNameSchemaMapping, normBatchID, req.SyncBatchID); err != nil {
+		return nil, err
+	}
+
+	replicationOrigin := ""
+	if req.ReplicationOrigin {
+		// conflicting writes of other mirrors and applications are resolved by comparing commit times
+		if err := c.CheckCommitTimestamps(ctx); err != nil {
+			return nil, err
+		}
+		replicationOrigin = getNormalizeReplicationOrigin(req.FlowJobName)
+		resetOrigin, err := c.setupReplicationOrigin(ctx, replicationOrigin)
+		if err != nil {
+			return nil, err
+		}
+		defer resetOrigin()
+	}
 
 	normalizeRecordsTx, err := c.conn.Begin(ctx)
 	if err != nil {
@@ -644,8 +784,10 @@ func (c *PostgresConnector) NormalizeRecords(
 			SyncedAtColName:   req.SyncedAtColName,
 			SoftDelete:        req.SoftDelete,
 		},
-		supportsMerge:  pgversion >= shared.POSTGRES_15,
-		metadataSchema: c.metadataSchema,
+		normalizeModes:    req.NormalizeModes,
+		supportsMerge:     pgversion >= shared.POSTGRES_15,
+		metadataSchema:    c.metadataSchema,
+		replicationOrigin: replicationOrigin,
 	}
 
 	for _, destinationTableName := range destinationTableNames {
@@ -678,6 +820,51 @@ func (c *PostgresConnector) NormalizeRecords(
 	}, nil
 }
 
+// addMissingEnumLabels adds labels enums got on the source after setup to their types on the destination,
+// before the normalize transaction since a transaction can't use enum labels it added
+func (c *PostgresConnector) addMissingEnumLabels(
+	ctx context.Context,
+	rawTableIdentifier string,
+	destinationTableNames []string,
+	tableNameSchemaMapping map[string]*protos.TableSchema,
+	normBatchID int64,
+	syncBatchID int64,
+) error {
+	for _, destinationTableName := range destinationTableNames {
+		tableSchema := tableNameSchemaMapping[destinationTableName]
+		if tableSchema.System != protos.TypeSystem_Q {
+			continue
+		}
+		parsedDstTable, err := utils.ParseSchemaTable(destinationTableName)
+		if err != nil {
+			return fmt.Errorf("destination table identifier %s is invalid", destinationTableName)
+		}
+		for _, column := range tableSchema.Columns {
+			if !isEnumColumn(column) {
+				continue
+			}
+			rows, err := c.conn.Query(ctx, missingEnumLabelsSQL(c.metadataSchema, rawTableIdentifier, parsedDstTable.Schema, column),
+				normBatchID, syncBatchID, destinationTableName)
+			if err != nil {
+				return fmt.Errorf("error looking up missing labels of type %s: %w", column.TypeName, err)
+			}
+			labels, err := pgx.CollectRows[string](rows, pgx.RowTo)
+			if err != nil {
+				return fmt.Errorf("error looking up missing labels of type %s: %w", column.TypeName, err)
+			}
+			for _, label := range labels {
+				// added after existing labels, which may sort differently than on the source
+				if _, err := c.conn.Exec(ctx, fmt.Sprintf("ALTER TYPE %s ADD VALUE IF NOT EXISTS %s",
+					customTypeIdentifier(parsedDstTable.Schema, column), QuoteLiteral(label))); err != nil {
+					return fmt.Errorf("error adding label %s to type %s: %w", label, column.TypeName, err)
+				}
+				c.logger.Info("added label to enum", slog.String("type", column.TypeName), slog.String("label", label))
+			}
+		}
+	}
+	return nil
+}
+
 type SlotCheckResult struct {
 	SlotExists        bool
 	PublicationExists bool
@@ -702,6 +889,12 @@ func (c *PostgresConnector) CreateRawTable(ctx context.Context, req *protos.Crea
 	if err != nil {
 		return nil, fmt.Errorf("error creating raw table: %w", err)
 	}
+	if req.CommitInfo {
+		_, err = createRawTableTx.Exec(ctx, fmt.Sprintf(addRawTableCommitInfoSQL, c.metadataSchema, rawTableIdentifier))
+		if err != nil {
+			return nil, fmt.Errorf("error adding commit columns to raw table: %w", err)
+		}
+	}
 	_, err = createRawTableTx.Exec(ctx, fmt.Sprintf(createRawTableBatchIDIndexSQL, rawTableIdentifier,
 		c.metadataSchema, rawTableIdentifier))
 	if err != nil {
@@ -775,36 +968,32 @@ func (c *PostgresConnector) getTableSchemaForTable(
 	columnNames := make([]string, 0, len(fields))
 	columns := make([]*protos.FieldDescription, 0, len(fields))
 	for _, fieldDescription := range fields {
-		var colType string
+		var column *protos.FieldDescription
 		switch system {
 		case protos.TypeSystem_PG:
-			colType = c.postgresOIDToName(fieldDescription.DataTypeOID)
+			colType := c.postgresOIDToName(fieldDescription.DataTypeOID)
 			if colType == "" {
-				typeName, ok := c.customTypesMapping[fieldDescription.DataTypeOID]
+				customType, ok := c.customTypesMapping[fieldDescription.DataTypeOID]
 				if !ok {
 					return nil, fmt.Errorf("error getting type name for %d", fieldDescription.DataTypeOID)
 				}
-				colType = typeName
+				colType = customType.Name
 			}
-		case protos.TypeSystem_Q:
-			qColType := c.postgresOIDToQValueKind(fieldDescription.DataTypeOID)
-			if qColType == qvalue.QValueKindInvalid {
-				typeName, ok := c.customTypesMapping[fieldDescription.DataTypeOID]
-				if ok {
-					qColType = customTypeToQKind(typeName)
-				} else {
-					qColType = qvalue.QValueKindString
-				}
+			column = &protos.FieldDescription{
+				Name:         fieldDescription.Name,
+				Type:         colType,
+				TypeModifier: fieldDescription.TypeModifier,
 			}
-			colType = string(qColType)
+			if customType, ok := c.customTypesMapping[fieldDescription.DataTypeOID]; ok && customType.Type == 'e' {
+				column.TypeName = customType.Name
+				column.EnumLabels = customType.EnumLabels
+			}
+		case protos.TypeSystem_Q:
+			column = c.qFieldDescription(fieldDescription.Name, fieldDescription.DataTypeOID, fieldDescription.TypeModifier)
 		}
 
 		columnNames = append(columnNames, fieldDescription.Name)
-		columns = append(columns, &protos.FieldDescription{
-			Name:         fieldDescription.Name,
-			Type:         colType,
-			TypeModifier: fieldDescription.TypeModifier,
-		})
+		columns = append(columns, column)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -840,10 +1029,9 @@ func (c *PostgresConnector) FinishSetupNormalizedTables(ctx context.Context, tx
 func (c *PostgresConnector) SetupNormalizedTable(
 	ctx context.Context,
 	tx any,
+	config *protos.SetupNormalizedTableBatchInput,
 	tableIdentifier string,
 	tableSchema *protos.TableSchema,
-	softDeleteColName string,
-	syncedAtColName string,
 ) (bool, error) {
 	createNormalizedTablesTx := tx.(pgx.Tx)
 
@@ -859,9 +1047,20 @@ func (c *PostgresConnector) SetupNormalizedTable(
 		return true, nil
 	}
 
+	if tableSchema.System == protos.TypeSystem_Q {
+		for _, column := range tableSchema.Columns {
+			for _, createTypeSQL := range createCustomTypesSQL(parsedNormalizedTable.Schema, column) {
+				if _, err := createNormalizedTablesTx.Exec(ctx, createTypeSQL); err != nil {
+					return false, fmt.Errorf("error while creating type %s: %w", column.TypeName, err)
+				}
+			}
+		}
+	}
+
 	// convert the column names and types to Postgres types
 	normalizedTableCreateSQL := generateCreateTableSQLForNormalizedTable(
-		parsedNormalizedTable.String(), tableSchema, softDeleteColName, syncedAtColName)
+		parsedNormalizedTable.String(), parsedNormalizedTable.Schema, tableSchema, config.SoftDeleteColName, config.SyncedAtColName,
+		shared.NormalizeModeForTable(config.NormalizeMode, config.TableMappings, tableIdentifier))
 	_, err = createNormalizedTablesTx.Exec(ctx, normalizedTableCreateSQL)
 	if err != nil {
 		return false, fmt.Errorf("error while creating normalized table: %w", err)
@@ -894,10 +1093,20 @@ func (c *PostgresConnector) ReplayTableSchemaDeltas(
 			continue
 		}
 
+		dstTable, err := utils.ParseSchemaTable(schemaDelta.DstTableName)
+		if err != nil {
+			return fmt.Errorf("error while parsing table schema and name: %w", err)
+		}
 		for _, addedColumn := range schemaDelta.AddedColumns {
 			columnType := addedColumn.Type
 			if schemaDelta.System == protos.TypeSystem_Q {
-				columnType = qValueKindToPostgresType(columnType)
+				for _, createTypeSQL := range createCustomTypesSQL(dstTable.Schema, addedColumn) {
+					if _, err := tableSchemaModifyTx.Exec(ctx, createTypeSQL); err != nil {
+						return fmt.Errorf("failed to create type %s for table %s: %w", addedColumn.TypeName,
+							schemaDelta.DstTableName, err)
+					}
+				}
+				columnType = postgresColumnType(dstTable.Schema, addedColumn)
 			}
 			_, err = tableSchemaModifyTx.Exec(ctx, fmt.Sprintf(
 				"ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s",
@@ -1054,7 +1263,8 @@ func (c *PostgresConnector) SetupReplication(ctx context.Context, signal SlotSig
 	}
 	// Create the replication slot and publication
 	err = c.createSlotAndPublication(ctx, signal, exists,
-		slotName, publicationName, tableNameMapping, req.DoInitialSnapshot)
+		slotName, publicationName, tableNameMapping, req.DoInitialSnapshot, req.FailoverSlot, req.TwoPhase,
+		req.ExistingPublicationName != "")
 	if err != nil {
 		return fmt.Errorf("error creating replication slot and publication: %w", err)
 	}
@@ -1068,9 +1278,20 @@ func (c *PostgresConnector) PullFlowCleanup(ctx context.Context, jobName string)
 
 	publicationName := c.getDefaultPublicationName(jobName)
 
-	_, err := c.conn.Exec(ctx, "DROP PUBLICATION IF EXISTS "+publicationName)
+	// a standby can't drop the publication, it is left for the primary
+	inRecovery, err := c.isInRecovery(ctx)
 	if err != nil {
-		return fmt.Errorf("error dropping publication: %w", err)
+		return err
+	}
+	if !inRecovery {
+		_, err = c.conn.Exec(ctx, "DROP PUBLICATION IF EXISTS "+publicationName)
+		if err != nil {
+			return fmt.Errorf("error dropping publication: %w", err)
+		}
+		// a cut over mirror leaves its source tables fenced until it is dropped
+		if err := c.UnfenceForCutover(ctx, jobName); err != nil {
+			return err
+		}
 	}
 
 	_, err = c.conn.Exec(ctx, `SELECT pg_drop_replication_slot(slot_name) FROM pg_replication_slots
@@ -1099,6 +1320,11 @@ func (c *PostgresConnector) SyncFlowCleanup(ctx context.Context, jobName string)
 	if err != nil {
 		return fmt.Errorf("unable to delete job metadata: %w", err)
 	}
+	_, err = syncFlowCleanupTx.Exec(ctx, dropReplicationOriginsSQL,
+		[]string{getSyncReplicationOrigin(jobName), getNormalizeReplicationOrigin(jobName)})
+	if err != nil {
+		return fmt.Errorf("unable to drop replication origins: %w", err)
+	}
 	err = syncFlowCleanupTx.Commit(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to commit transaction for sync flow cleanup: %w", err)
@@ -1214,3 +1440,150 @@ func (c *PostgresConnector) AddTablesToPublication(ctx context.Context, req *pro
 
 	return nil
 }
+
+func (c *PostgresConnector) RemoveTablesFromPublication(ctx context.Context, req *protos.RemoveTablesFromPublicationInput) error {
+	// don't modify custom publications, Postgres keeps decoding changes to removed tables
+	// and they're skipped when pulling as the tables are no longer in the source table mapping
+	if req == nil || len(req.TablesToRemove) == 0 || req.PublicationName != "" {
+		return nil
+	}
+
+	for _, tableMapping := range req.TablesToRemove {
+		schemaTable, err := utils.ParseSchemaTable(tableMapping.SourceTableIdentifier)
+		if err != nil {
+			return err
+		}
+		_, err = c.conn.Exec(ctx, fmt.Sprintf("ALTER PUBLICATION %s DROP TABLE %s",
+			utils.QuoteIdentifier(c.getDefaultPublicationName(req.FlowJobName)),
+			schemaTable.String()))
+		// don't error out if table is already removed from our publication
+		if err != nil && !strings.Contains(err.Error(), "SQLSTATE 42704") {
+			return fmt.Errorf("failed to alter publication: %w", err)
+		}
+		c.logger.Info("removed table from publication",
+			slog.String("publication", c.getDefaultPublicationName(req.FlowJobName)),
+			slog.String("table", tableMapping.SourceTableIdentifier))
+	}
+
+	return nil
+}
+
+// RemoveTables deletes rows of tables no longer part of the mirror from the raw table and drops the tables
+func (c *PostgresConnector) RemoveTables(ctx context.Context, req *protos.RemoveTablesInput) error {
+	removeTablesTx, err := c.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction for removing tables: %w", err)
+	}
+	defer shared.RollbackTx(removeTablesTx, c.logger)
+
+	_, err = removeTablesTx.Exec(ctx, fmt.Sprintf(deleteRawTableRowsForTablesSQL, c.metadataSchema,
+		getRawTableIdentifier(req.FlowJobName)), req.DestinationTableIdentifiers)
+	if err != nil {
+		return fmt.Errorf("unable to delete rows of removed tables from raw table: %w", err)
+	}
+	var droppedTables []string
+	if !req.KeepTables {
+		droppedTables = req.DestinationTableIdentifiers
+	}
+	for _, tableIdentifier := range droppedTables {
+		schemaTable, err := utils.ParseSchemaTable(tableIdentifier)
+		if err != nil {
+			return err
+		}
+		_, err = removeTablesTx.Exec(ctx, "DROP TABLE IF EXISTS "+schemaTable.String())
+		if err != nil {
+			return fmt.Errorf("unable to drop table %s: %w", tableIdentifier, err)
+		}
+	}
+
+	if err := removeTablesTx.Commit(ctx); err != nil {
+		return fmt.Errorf("unable to commit transaction for removing tables: %w", err)
+	}
+	return nil
+}
+
+// GetSequenceValues samples the serial and identity sequences of tables, logical decoding doesn't replicate them
+func (c *PostgresConnector) GetSequenceValues(ctx context.Context, tableIdentifiers []string) ([]model.SequenceValue, error) {
+	quotedIdentifiers := make([]string, 0, len(tableIdentifiers))
+	for _, tableIdentifier := range tableIdentifiers {
+		schemaTable, err := utils.ParseSchemaTable(tableIdentifier)
+		if err != nil {
+			return nil, err
+		}
+		quotedIdentifiers = append(quotedIdentifiers, schemaTable.String())
+	}
+
+	rows, err := c.conn.Query(ctx, getSequenceValuesSQL, tableIdentifiers, quotedIdentifiers)
+	if err != nil {
+		return nil, fmt.Errorf("error querying sequence values: %w", err)
+	}
+	values, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (model.SequenceValue, error) {
+		var value model.SequenceValue
+		err := row.Scan(&value.TableIdentifier, &value.ColumnName, &value.LastValue)
+		return value, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading sequence values: %w", err)
+	}
+	return values, nil
+}
+
+// SyncSequences sets the serial and identity sequences of destination columns. Tables created by the mirror
+// have no sequences, so one owned by the column becomes its default. Columns missing on the destination are skipped
+func (c *PostgresConnector) SyncSequences(ctx context.Context, values []model.SequenceValue) error {
+	var skippedColumns []string
+	for _, value := range values {
+		schemaTable, err := utils.ParseSchemaTable(value.TableIdentifier)
+		if err != nil {
+			return err
+		}
+		sequence, err := c.getOrCreateColumnSequence(ctx, schemaTable, value.ColumnName)
+		if err != nil {
+			return fmt.Errorf("error getting sequence of %s.%s: %w", value.TableIdentifier, value.ColumnName, err)
+		}
+		if sequence == "" {
+			skippedColumns = append(skippedColumns, value.TableIdentifier+"."+value.ColumnName)
+			continue
+		}
+		if _, err := c.conn.Exec(ctx, syncSequenceSQL, sequence, value.LastValue); err != nil {
+			return fmt.Errorf("error syncing sequence of %s.%s: %w", value.TableIdentifier, value.ColumnName, err)
+		}
+	}
+	if len(skippedColumns) > 0 {
+		c.logger.Warn("skipped syncing sequences of columns missing on destination", slog.Any("columns", skippedColumns))
+	}
+	return nil
+}
+
+// getOrCreateColumnSequence returns the sequence owned by column, creating one as its default if there is none,
+// or an empty string when the column doesn't exist
+func (c *PostgresConnector) getOrCreateColumnSequence(
+	ctx context.Context,
+	schemaTable *utils.SchemaTable,
+	column string,
+) (string, error) {
+	var exists bool
+	var sequence pgtype.Text
+	if err := c.conn.QueryRow(ctx, getColumnSequenceSQL, schemaTable.String(), column).Scan(&exists, &sequence); err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", nil
+	}
+	if sequence.Valid {
+		return sequence.String, nil
+	}
+
+	sequenceIdentifier := QuoteIdentifier(schemaTable.Schema) + "." + QuoteIdentifier(schemaTable.Table+"_"+column+"_seq")
+	if _, err := c.conn.Exec(ctx, fmt.Sprintf(createColumnSequenceSQL,
+		sequenceIdentifier, schemaTable.String(), QuoteIdentifier(column))); err != nil {
+		return "", fmt.Errorf("error creating sequence: %w", err)
+	}
+	if _, err := c.conn.Exec(ctx, fmt.Sprintf(setColumnSequenceSQL,
+		schemaTable.String(), QuoteIdentifier(column), QuoteLiteral(sequenceIdentifier))); err != nil {
+		return "", fmt.Errorf("error setting sequence as default: %w", err)
+	}
+	c.logger.Info("created sequence for column", slog.String("table", schemaTable.String()),
+		slog.String("column", column), slog.String("sequence", sequenceIdentifier))
+	return sequenceIdentifier, nil
+}
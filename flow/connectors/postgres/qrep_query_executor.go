@@ -11,6 +11,7 @@ import (
 	"go.temporal.io/sdk/log"
 
 	datatypes "github.com/PeerDB-io/peer-flow/datatypes"
+	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/model"
 	"github.com/PeerDB-io/peer-flow/model/qvalue"
 	"github.com/PeerDB-io/peer-flow/shared"
@@ -64,39 +65,32 @@ func (qe *QRepQueryExecutor) executeQueryInTx(ctx context.Context, tx pgx.Tx, cu
 func (qe *QRepQueryExecutor) fieldDescriptionsToSchema(fds []pgconn.FieldDescription) qvalue.QRecordSchema {
 	qfields := make([]qvalue.QField, len(fds))
 	for i, fd := range fds {
-		cname := fd.Name
-		ctype := qe.postgresOIDToQValueKind(fd.DataTypeOID)
-		if ctype == qvalue.QValueKindInvalid {
-			typeName, ok := qe.customTypesMapping[fd.DataTypeOID]
-			if ok {
-				ctype = customTypeToQKind(typeName)
-			} else {
-				ctype = qvalue.QValueKindString
-			}
-		}
 		// there isn't a way to know if a column is nullable or not
 		// TODO fix this.
-		cnullable := true
-		if ctype == qvalue.QValueKindNumeric {
-			precision, scale := datatypes.ParseNumericTypmod(fd.TypeModifier)
-			qfields[i] = qvalue.QField{
-				Name:      cname,
-				Type:      ctype,
-				Nullable:  cnullable,
-				Precision: precision,
-				Scale:     scale,
-			}
-		} else {
-			qfields[i] = qvalue.QField{
-				Name:     cname,
-				Type:     ctype,
-				Nullable: cnullable,
-			}
-		}
+		qfields[i] = fieldDescriptionToQField(qe.qFieldDescription(fd.Name, fd.DataTypeOID, fd.TypeModifier), true)
 	}
 	return qvalue.NewQRecordSchema(qfields)
 }
 
+// fieldDescriptionToQField converts a column or composite member, members of composites may always be null
+func fieldDescriptionToQField(column *protos.FieldDescription, nullable bool) qvalue.QField {
+	qfield := qvalue.QField{
+		Name:     column.Name,
+		Type:     qvalue.QValueKind(column.Type),
+		Nullable: nullable,
+	}
+	if qfield.Type == qvalue.QValueKindNumeric || qfield.Type == qvalue.QValueKindArrayOf(qvalue.QValueKindNumeric) {
+		qfield.Precision, qfield.Scale = datatypes.ParseNumericTypmod(column.TypeModifier)
+	}
+	if len(column.Fields) > 0 {
+		qfield.Fields = make([]qvalue.QField, 0, len(column.Fields))
+		for _, member := range column.Fields {
+			qfield.Fields = append(qfield.Fields, fieldDescriptionToQField(member, true))
+		}
+	}
+	return qfield
+}
+
 func (qe *QRepQueryExecutor) ProcessRows(
 	rows pgx.Rows,
 	fieldDescriptions []pgconn.FieldDescription,
@@ -384,8 +378,8 @@ func (qe *QRepQueryExecutor) mapRowToQRecord(
 
 	for i, fd := range fds {
 		// Check if it's a custom type first
-		typeName, ok := qe.customTypesMapping[fd.DataTypeOID]
-		if !ok {
+		customType, ok := qe.customTypesMapping[fd.DataTypeOID]
+		if !ok || customType.Type == 'c' {
 			tmp, err := qe.parseFieldFromPostgresOID(fd.DataTypeOID, values[i])
 			if err != nil {
 				qe.logger.Error("[pg_query_executor] failed to parse field", slog.Any("error", err))
@@ -393,7 +387,7 @@ func (qe *QRepQueryExecutor) mapRowToQRecord(
 			}
 			record[i] = tmp
 		} else {
-			customQKind := customTypeToQKind(typeName)
+			customQKind := customTypeToQKind(customType)
 			if values[i] == nil {
 				record[i] = qvalue.QValueNull(customQKind)
 			} else {
@@ -410,6 +404,20 @@ func (qe *QRepQueryExecutor) mapRowToQRecord(
 					}
 				case qvalue.QValueKindHStore:
 					record[i] = qvalue.QValueHStore{Val: fmt.Sprint(values[i])}
+				case qvalue.QValueKindEnum:
+					record[i] = qvalue.QValueEnum{Val: fmt.Sprint(values[i])}
+				case qvalue.QValueKindVector:
+					// unregistered types are scanned as text, or as bytes when the binary format was requested
+					var vector []float32
+					if vectorBytes, isBytes := values[i].([]byte); isBytes {
+						vector, err = datatypes.ParseVectorBinary(vectorBytes)
+					} else {
+						vector, err = datatypes.ParseVectorText(fmt.Sprint(values[i]))
+					}
+					if err != nil {
+						return nil, fmt.Errorf("failed to parse vector: %w", err)
+					}
+					record[i] = qvalue.QValueVector{Val: vector}
 				case qvalue.QValueKindString:
 					record[i] = qvalue.QValueString{Val: fmt.Sprint(values[i])}
 				}
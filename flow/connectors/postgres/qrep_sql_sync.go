@@ -59,6 +59,12 @@ func (s *QRepStagingTableSync) SyncQRepRecords(
 	if err != nil {
 		return 0, fmt.Errorf("failed to register hstore: %w", err)
 	}
+	// composites are copied in binary, which needs their members from the destination
+	customTypes, err := shared.GetCustomDataTypes(ctx, txConn)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get custom types: %w", err)
+	}
+	shared.RegisterCustomTypes(txConn.TypeMap(), customTypes)
 
 	// Second transaction - to handle rest of the processing
 	tx, err := txConn.Begin(ctx)
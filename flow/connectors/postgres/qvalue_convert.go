@@ -15,6 +15,7 @@ import (
 	"github.com/shopspring/decimal"
 
 	datatypes "github.com/PeerDB-io/peer-flow/datatypes"
+	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/model/qvalue"
 	"github.com/PeerDB-io/peer-flow/shared"
 )
@@ -108,7 +109,34 @@ func (c *PostgresConnector) postgresOIDToQValueKind(recvOID uint32) qvalue.QValu
 		return qvalue.QValueKindArrayString
 	case pgtype.IntervalOID:
 		return qvalue.QValueKindInterval
+	case pgtype.Int4rangeOID:
+		return qvalue.QValueKindRangeInt32
+	case pgtype.Int8rangeOID:
+		return qvalue.QValueKindRangeInt64
+	case pgtype.NumrangeOID:
+		return qvalue.QValueKindRangeNumeric
+	case pgtype.DaterangeOID:
+		return qvalue.QValueKindRangeDate
+	case pgtype.TsrangeOID:
+		return qvalue.QValueKindRangeTimestamp
+	case pgtype.TstzrangeOID:
+		return qvalue.QValueKindRangeTimestampTZ
+	case pgtype.Int4multirangeOID:
+		return qvalue.QValueKindMultirangeInt32
+	case pgtype.Int8multirangeOID:
+		return qvalue.QValueKindMultirangeInt64
+	case pgtype.NummultirangeOID:
+		return qvalue.QValueKindMultirangeNumeric
+	case pgtype.DatemultirangeOID:
+		return qvalue.QValueKindMultirangeDate
+	case pgtype.TsmultirangeOID:
+		return qvalue.QValueKindMultirangeTimestamp
+	case pgtype.TstzmultirangeOID:
+		return qvalue.QValueKindMultirangeTimestampTZ
 	default:
+		if kind, ok := c.registeredTypeQValueKind(recvOID); ok {
+			return kind
+		}
 		typeName, ok := pgtype.NewMap().TypeForOID(recvOID)
 		if !ok {
 			// workaround for some types not being defined by pgtype
@@ -141,6 +169,162 @@ func (c *PostgresConnector) postgresOIDToQValueKind(recvOID uint32) qvalue.QValu
 	}
 }
 
+// registeredTypeQValueKind maps enums and composites registered on the connection by shared.RegisterCustomTypes,
+// and arrays of anything but strings
+func (c *PostgresConnector) registeredTypeQValueKind(recvOID uint32) (qvalue.QValueKind, bool) {
+	if c.conn == nil {
+		return qvalue.QValueKindInvalid, false
+	}
+	dt, ok := c.conn.TypeMap().TypeForOID(recvOID)
+	if !ok {
+		return qvalue.QValueKindInvalid, false
+	}
+	switch codec := dt.Codec.(type) {
+	case *pgtype.EnumCodec:
+		return qvalue.QValueKindEnum, true
+	case *pgtype.CompositeCodec:
+		return qvalue.QValueKindStruct, true
+	case *pgtype.ArrayCodec:
+		elementKind := c.postgresOIDToQValueKind(codec.ElementType.OID)
+		if elementKind == qvalue.QValueKindInvalid || elementKind == qvalue.QValueKindString || elementKind.IsArray() {
+			return qvalue.QValueKindInvalid, false
+		}
+		return qvalue.QValueKindArrayOf(elementKind), true
+	default:
+		return qvalue.QValueKindInvalid, false
+	}
+}
+
+// qFieldDescription describes a column or composite member of type typeOID in the Q type system,
+// with the labels of enums and the members of composites, which are also set for arrays of them
+func (c *PostgresConnector) qFieldDescription(name string, typeOID uint32, typeModifier int32) *protos.FieldDescription {
+	qKind := c.postgresOIDToQValueKind(typeOID)
+	if qKind == qvalue.QValueKindInvalid {
+		if customType, ok := c.customTypesMapping[typeOID]; ok {
+			qKind = customTypeToQKind(customType)
+		} else {
+			qKind = qvalue.QValueKindString
+		}
+	}
+	column := &protos.FieldDescription{
+		Name:         name,
+		Type:         string(qKind),
+		TypeModifier: typeModifier,
+	}
+
+	if qKind.IsGenericArray() {
+		if dt, ok := c.conn.TypeMap().TypeForOID(typeOID); ok {
+			typeOID = dt.Codec.(*pgtype.ArrayCodec).ElementType.OID
+		}
+	}
+	if customType, ok := c.customTypesMapping[typeOID]; ok {
+		switch customType.Type {
+		case 'e':
+			column.TypeName = destinationTypeName(customType)
+			column.EnumLabels = customType.EnumLabels
+		case 'c':
+			if qKind == qvalue.QValueKindStruct || qKind.IsGenericArray() {
+				column.TypeName = destinationTypeName(customType)
+				column.Fields = make([]*protos.FieldDescription, 0, len(customType.Members))
+				for _, member := range customType.Members {
+					column.Fields = append(column.Fields, c.qFieldDescription(member.Name, member.OID, member.TypeModifier))
+				}
+			}
+		}
+	}
+	return column
+}
+
+// destinationTypeName is the name customType is created with on Postgres destinations, in the schema of the table.
+// Types outside public are prefixed with their schema so types of the same name in different schemas stay apart
+func destinationTypeName(customType shared.CustomDataType) string {
+	if customType.Schema == "" || customType.Schema == "public" {
+		return customType.Name
+	}
+	return customType.Schema + "_" + customType.Name
+}
+
+// hasCustomType is whether column is an enum or composite, or an array of them, created on Postgres destinations
+func hasCustomType(column *protos.FieldDescription) bool {
+	kind := qvalue.QValueKind(column.Type)
+	if kind.IsGenericArray() {
+		kind = kind.ArrayElementKind()
+	}
+	return column.TypeName != "" &&
+		(kind == qvalue.QValueKindEnum || (kind == qvalue.QValueKindStruct && len(column.Fields) > 0))
+}
+
+// postgresColumnType is the type of a Q column in a table of dstSchema, enums and composites are created in the same schema
+func postgresColumnType(dstSchema string, column *protos.FieldDescription) string {
+	if hasCustomType(column) {
+		if qvalue.QValueKind(column.Type).IsArray() {
+			return customTypeIdentifier(dstSchema, column) + "[]"
+		}
+		return customTypeIdentifier(dstSchema, column)
+	}
+	if column.Type == string(qvalue.QValueKindVector) && column.TypeModifier > 0 {
+		return fmt.Sprintf("vector(%d)", column.TypeModifier)
+	}
+	return qValueKindToPostgresType(column.Type)
+}
+
+func customTypeIdentifier(dstSchema string, column *protos.FieldDescription) string {
+	return QuoteIdentifier(dstSchema) + "." + QuoteIdentifier(column.TypeName)
+}
+
+// createCustomTypesSQL creates the enum or composite of column, along with the types of its members,
+// unless types of their names exist already. Enums without labels are replicated as text so there is nothing to create
+func createCustomTypesSQL(dstSchema string, column *protos.FieldDescription) []string {
+	if !hasCustomType(column) {
+		return nil
+	}
+
+	var stmts []string
+	var createType string
+	if len(column.Fields) > 0 {
+		members := make([]string, 0, len(column.Fields))
+		for _, member := range column.Fields {
+			stmts = append(stmts, createCustomTypesSQL(dstSchema, member)...)
+			memberType := postgresColumnType(dstSchema, member)
+			if member.Type == string(qvalue.QValueKindNumeric) && member.TypeModifier != -1 {
+				precision, scale := datatypes.ParseNumericTypmod(member.TypeModifier)
+				memberType = fmt.Sprintf("numeric(%d,%d)", precision, scale)
+			}
+			members = append(members, QuoteIdentifier(member.Name)+" "+memberType)
+		}
+		createType = fmt.Sprintf("CREATE TYPE %s AS (%s)", customTypeIdentifier(dstSchema, column), strings.Join(members, ","))
+	} else {
+		labels := make([]string, 0, len(column.EnumLabels))
+		for _, label := range column.EnumLabels {
+			labels = append(labels, QuoteLiteral(label))
+		}
+		createType = fmt.Sprintf("CREATE TYPE %s AS ENUM (%s)", customTypeIdentifier(dstSchema, column), strings.Join(labels, ","))
+	}
+	return append(stmts, fmt.Sprintf("DO $peerdb$ BEGIN %s; EXCEPTION WHEN duplicate_object THEN NULL; END $peerdb$", createType))
+}
+
+// isEnumColumn is whether column is an enum, or an array of one, created on Postgres destinations
+func isEnumColumn(column *protos.FieldDescription) bool {
+	kind := qvalue.QValueKind(column.Type)
+	return hasCustomType(column) && (kind == qvalue.QValueKindEnum || kind == qvalue.QValueKindArrayOf(qvalue.QValueKindEnum))
+}
+
+// missingEnumLabelsSQL selects labels of enum column in the raw rows of table $3 between batches $1 and $2 which its type
+// in dstSchema lacks. Labels added on the source after setup aren't in the table schema, so they are taken from the rows
+func missingEnumLabelsSQL(metadataSchema string, rawTableIdentifier string, dstSchema string, column *protos.FieldDescription) string {
+	value := fmt.Sprintf("(_peerdb_data->>%s)", QuoteLiteral(column.Name))
+	label := value
+	var elements string
+	if qvalue.QValueKind(column.Type).IsArray() {
+		elements = fmt.Sprintf(",JSON_ARRAY_ELEMENTS_TEXT(%s::JSON) AS e(label)", value)
+		label = "e.label"
+	}
+	return fmt.Sprintf("SELECT DISTINCT %[1]s FROM %[2]s.%[3]s%[4]s WHERE _peerdb_batch_id>$1 AND _peerdb_batch_id<=$2 "+
+		"AND _peerdb_destination_table_name=$3 AND %[1]s IS NOT NULL "+
+		"AND NOT EXISTS(SELECT 1 FROM pg_enum WHERE enumtypid=%[5]s::regtype AND enumlabel=%[1]s)",
+		label, metadataSchema, rawTableIdentifier, elements, QuoteLiteral(customTypeIdentifier(dstSchema, column)))
+}
+
 func qValueKindToPostgresType(colTypeStr string) string {
 	switch qvalue.QValueKind(colTypeStr) {
 	case qvalue.QValueKindBoolean:
@@ -163,8 +347,12 @@ func qValueKindToPostgresType(colTypeStr string) string {
 		return "BYTEA"
 	case qvalue.QValueKindJSON:
 		return "JSON"
+	case qvalue.QValueKindStruct:
+		return "JSONB"
 	case qvalue.QValueKindHStore:
 		return "HSTORE"
+	case qvalue.QValueKindVector:
+		return "vector"
 	case qvalue.QValueKindUUID:
 		return "UUID"
 	case qvalue.QValueKindTime:
@@ -213,7 +401,34 @@ func qValueKindToPostgresType(colTypeStr string) string {
 		return "GEOMETRY"
 	case qvalue.QValueKindPoint:
 		return "POINT"
+	case qvalue.QValueKindRangeInt32:
+		return "INT4RANGE"
+	case qvalue.QValueKindRangeInt64:
+		return "INT8RANGE"
+	case qvalue.QValueKindRangeNumeric:
+		return "NUMRANGE"
+	case qvalue.QValueKindRangeDate:
+		return "DATERANGE"
+	case qvalue.QValueKindRangeTimestamp:
+		return "TSRANGE"
+	case qvalue.QValueKindRangeTimestampTZ:
+		return "TSTZRANGE"
+	case qvalue.QValueKindMultirangeInt32:
+		return "INT4MULTIRANGE"
+	case qvalue.QValueKindMultirangeInt64:
+		return "INT8MULTIRANGE"
+	case qvalue.QValueKindMultirangeNumeric:
+		return "NUMMULTIRANGE"
+	case qvalue.QValueKindMultirangeDate:
+		return "DATEMULTIRANGE"
+	case qvalue.QValueKindMultirangeTimestamp:
+		return "TSMULTIRANGE"
+	case qvalue.QValueKindMultirangeTimestampTZ:
+		return "TSTZMULTIRANGE"
 	default:
+		if kind := qvalue.QValueKind(colTypeStr); kind.IsGenericArray() {
+			return qValueKindToPostgresType(string(kind.ArrayElementKind())) + "[]"
+		}
 		return "TEXT"
 	}
 }
@@ -324,6 +539,8 @@ func parseFieldFromQValueKind(qvalueKind qvalue.QValueKind, value interface{}) (
 	case qvalue.QValueKindString:
 		// handling all unsupported types with strings as well for now.
 		return qvalue.QValueString{Val: fmt.Sprint(value)}, nil
+	case qvalue.QValueKindEnum:
+		return qvalue.QValueEnum{Val: fmt.Sprint(value)}, nil
 	case qvalue.QValueKindUUID:
 		switch v := value.(type) {
 		case string:
@@ -443,6 +660,31 @@ func parseFieldFromQValueKind(qvalueKind qvalue.QValueKind, value interface{}) (
 			Val: fmt.Sprintf("POINT(%f %f)", xCoord, yCoord),
 		}, nil
 	default:
+		if qvalueKind.IsRange() {
+			pgRange, ok := value.(pgtype.Range[any])
+			if !ok {
+				break
+			}
+			r, err := rangeFromPgtype(pgRange)
+			if err != nil {
+				return nil, err
+			}
+			return qvalue.QValueRange{RangeKind: qvalueKind, Val: r}, nil
+		} else if qvalueKind.IsMultirange() {
+			pgMultirange, ok := value.(pgtype.Multirange[pgtype.Range[any]])
+			if !ok {
+				break
+			}
+			ranges := make([]qvalue.Range, 0, len(pgMultirange))
+			for _, pgRange := range pgMultirange {
+				r, err := rangeFromPgtype(pgRange)
+				if err != nil {
+					return nil, err
+				}
+				ranges = append(ranges, r)
+			}
+			return qvalue.QValueMultirange{MultirangeKind: qvalueKind, Val: ranges}, nil
+		}
 		textVal, ok := value.(string)
 		if ok {
 			return qvalue.QValueString{Val: textVal}, nil
@@ -454,7 +696,53 @@ func parseFieldFromQValueKind(qvalueKind qvalue.QValueKind, value interface{}) (
 }
 
 func (c *PostgresConnector) parseFieldFromPostgresOID(oid uint32, value interface{}) (qvalue.QValue, error) {
-	return parseFieldFromQValueKind(c.postgresOIDToQValueKind(oid), value)
+	qvalueKind := c.postgresOIDToQValueKind(oid)
+	if value != nil {
+		if qvalueKind == qvalue.QValueKindStruct {
+			return c.parseComposite(oid, value)
+		} else if qvalueKind.IsGenericArray() {
+			return c.parseArray(oid, qvalueKind, value)
+		}
+	}
+	return parseFieldFromQValueKind(qvalueKind, value)
+}
+
+// parseComposite converts a composite decoded by pgx into a QValueStruct of its members
+func (c *PostgresConnector) parseComposite(oid uint32, value interface{}) (qvalue.QValue, error) {
+	members, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse composite from %T: %v", value, value)
+	}
+	dt, _ := c.conn.TypeMap().TypeForOID(oid)
+	codec := dt.Codec.(*pgtype.CompositeCodec)
+	val := make(map[string]interface{}, len(codec.Fields))
+	for _, field := range codec.Fields {
+		member, err := c.parseFieldFromPostgresOID(field.Type.OID, members[field.Name])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse member %s of %s: %w", field.Name, dt.Name, err)
+		}
+		val[field.Name] = member
+	}
+	return qvalue.QValueStruct{Val: val}, nil
+}
+
+// parseArray converts an array decoded by pgx into a QValueArray, multidimensional arrays are flattened by pgx
+func (c *PostgresConnector) parseArray(oid uint32, qvalueKind qvalue.QValueKind, value interface{}) (qvalue.QValue, error) {
+	elements, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse array %s from %T: %v", qvalueKind, value, value)
+	}
+	dt, _ := c.conn.TypeMap().TypeForOID(oid)
+	elementOID := dt.Codec.(*pgtype.ArrayCodec).ElementType.OID
+	val := make([]qvalue.QValue, 0, len(elements))
+	for _, element := range elements {
+		qv, err := c.parseFieldFromPostgresOID(elementOID, element)
+		if err != nil {
+			return nil, err
+		}
+		val = append(val, qv)
+	}
+	return qvalue.QValueArray{ElementKind: qvalueKind.ArrayElementKind(), Val: val}, nil
 }
 
 func numericToDecimal(numVal pgtype.Numeric) (qvalue.QValue, error) {
@@ -469,14 +757,59 @@ func numericToDecimal(numVal pgtype.Numeric) (qvalue.QValue, error) {
 	}
 }
 
-func customTypeToQKind(typeName string) qvalue.QValueKind {
-	switch typeName {
+// rangeBound converts a bound decoded by pgx, infinite bounds are treated as unbounded
+func rangeBound(bound any, boundType pgtype.BoundType) (any, error) {
+	if boundType == pgtype.Unbounded || boundType == pgtype.Empty {
+		return nil, nil
+	}
+	switch b := bound.(type) {
+	case int32, int64, time.Time:
+		return b, nil
+	case pgtype.Numeric:
+		if b.NaN || b.InfinityModifier != pgtype.Finite {
+			return nil, nil
+		}
+		return decimal.NewFromBigInt(b.Int, b.Exp), nil
+	case pgtype.InfinityModifier:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported range bound %v of type %T", bound, bound)
+	}
+}
+
+func rangeFromPgtype(pgRange pgtype.Range[any]) (qvalue.Range, error) {
+	if pgRange.LowerType == pgtype.Empty {
+		return qvalue.Range{Empty: true}, nil
+	}
+	lower, err := rangeBound(pgRange.Lower, pgRange.LowerType)
+	if err != nil {
+		return qvalue.Range{}, err
+	}
+	upper, err := rangeBound(pgRange.Upper, pgRange.UpperType)
+	if err != nil {
+		return qvalue.Range{}, err
+	}
+	return qvalue.Range{
+		Lower:          lower,
+		Upper:          upper,
+		LowerInclusive: lower != nil && pgRange.LowerType == pgtype.Inclusive,
+		UpperInclusive: upper != nil && pgRange.UpperType == pgtype.Inclusive,
+	}, nil
+}
+
+func customTypeToQKind(customType shared.CustomDataType) qvalue.QValueKind {
+	if customType.Type == 'e' {
+		return qvalue.QValueKindEnum
+	}
+	switch customType.Name {
 	case "geometry":
 		return qvalue.QValueKindGeometry
 	case "geography":
 		return qvalue.QValueKindGeography
 	case "hstore":
 		return qvalue.QValueKindHStore
+	case "vector":
+		return qvalue.QValueKindVector
 	default:
 		return qvalue.QValueKindString
 	}
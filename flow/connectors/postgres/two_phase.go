@@ -0,0 +1,177 @@
+package connpostgres
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pglogrepl"
+)
+
+// pgoutput messages of two-phase decoding, added in protocol 3, which pglogrepl doesn't parse
+const (
+	messageTypeBeginPrepare     pglogrepl.MessageType = 'b'
+	messageTypePrepare          pglogrepl.MessageType = 'P'
+	messageTypeCommitPrepared   pglogrepl.MessageType = 'K'
+	messageTypeRollbackPrepared pglogrepl.MessageType = 'r'
+	messageTypeStreamPrepare    pglogrepl.MessageType = 'p'
+)
+
+// microseconds between the Unix epoch and the Postgres epoch of 2000-01-01
+const postgresEpochMicros = 946684800 * 1000000
+
+// prepareMessage is a BEGIN PREPARE, a PREPARE, or the PREPARE of a streamed transaction
+type prepareMessage struct {
+	msgType     pglogrepl.MessageType
+	PrepareLSN  pglogrepl.LSN
+	EndLSN      pglogrepl.LSN
+	PrepareTime time.Time
+	Xid         uint32
+	GID         string
+}
+
+func (m *prepareMessage) Type() pglogrepl.MessageType {
+	return m.msgType
+}
+
+type commitPreparedMessage struct {
+	CommitLSN  pglogrepl.LSN
+	EndLSN     pglogrepl.LSN
+	CommitTime time.Time
+	Xid        uint32
+	GID        string
+}
+
+func (m *commitPreparedMessage) Type() pglogrepl.MessageType {
+	return messageTypeCommitPrepared
+}
+
+type rollbackPreparedMessage struct {
+	PrepareEndLSN  pglogrepl.LSN
+	RollbackEndLSN pglogrepl.LSN
+	PrepareTime    time.Time
+	RollbackTime   time.Time
+	Xid            uint32
+	GID            string
+}
+
+func (m *rollbackPreparedMessage) Type() pglogrepl.MessageType {
+	return messageTypeRollbackPrepared
+}
+
+type twoPhaseReader struct {
+	src []byte
+	err error
+}
+
+func (r *twoPhaseReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.src) < n {
+		r.err = fmt.Errorf("message truncated, %d bytes left for a %d byte field", len(r.src), n)
+		return nil
+	}
+	field := r.src[:n]
+	r.src = r.src[n:]
+	return field
+}
+
+func (r *twoPhaseReader) uint8() uint8 {
+	if field := r.next(1); field != nil {
+		return field[0]
+	}
+	return 0
+}
+
+func (r *twoPhaseReader) uint32() uint32 {
+	if field := r.next(4); field != nil {
+		return binary.BigEndian.Uint32(field)
+	}
+	return 0
+}
+
+func (r *twoPhaseReader) lsn() pglogrepl.LSN {
+	if field := r.next(8); field != nil {
+		return pglogrepl.LSN(binary.BigEndian.Uint64(field))
+	}
+	return 0
+}
+
+func (r *twoPhaseReader) time() time.Time {
+	if field := r.next(8); field != nil {
+		return time.UnixMicro(int64(binary.BigEndian.Uint64(field)) + postgresEpochMicros)
+	}
+	return time.Time{}
+}
+
+func (r *twoPhaseReader) string() string {
+	if r.err != nil {
+		return ""
+	}
+	end := bytes.IndexByte(r.src, 0)
+	if end < 0 {
+		r.err = fmt.Errorf("message string not null terminated")
+		return ""
+	}
+	s := string(r.src[:end])
+	r.src = r.src[end+1:]
+	return s
+}
+
+// parseTwoPhaseMessage parses data if it holds a two-phase message, returning nil for any other message
+func parseTwoPhaseMessage(data []byte) (pglogrepl.Message, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	msgType := pglogrepl.MessageType(data[0])
+	r := &twoPhaseReader{src: data[1:]}
+	var msg pglogrepl.Message
+	switch msgType {
+	case messageTypeBeginPrepare:
+		msg = &prepareMessage{
+			msgType:     msgType,
+			PrepareLSN:  r.lsn(),
+			EndLSN:      r.lsn(),
+			PrepareTime: r.time(),
+			Xid:         r.uint32(),
+			GID:         r.string(),
+		}
+	case messageTypePrepare, messageTypeStreamPrepare:
+		_ = r.uint8() // flags, unused
+		msg = &prepareMessage{
+			msgType:     msgType,
+			PrepareLSN:  r.lsn(),
+			EndLSN:      r.lsn(),
+			PrepareTime: r.time(),
+			Xid:         r.uint32(),
+			GID:         r.string(),
+		}
+	case messageTypeCommitPrepared:
+		_ = r.uint8() // flags, unused
+		msg = &commitPreparedMessage{
+			CommitLSN:  r.lsn(),
+			EndLSN:     r.lsn(),
+			CommitTime: r.time(),
+			Xid:        r.uint32(),
+			GID:        r.string(),
+		}
+	case messageTypeRollbackPrepared:
+		_ = r.uint8() // flags, unused
+		msg = &rollbackPreparedMessage{
+			PrepareEndLSN:  r.lsn(),
+			RollbackEndLSN: r.lsn(),
+			PrepareTime:    r.time(),
+			RollbackTime:   r.time(),
+			Xid:            r.uint32(),
+			GID:            r.string(),
+		}
+	default:
+		return nil, nil
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("error parsing %c message: %w", msgType, r.err)
+	}
+	return msg, nil
+}
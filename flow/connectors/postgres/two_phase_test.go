@@ -0,0 +1,92 @@
+package connpostgres
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pglogrepl"
+)
+
+func TestParseTwoPhaseMessage(t *testing.T) {
+	prepareTime := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	commitTime := prepareTime.Add(time.Minute)
+	pgTime := func(t time.Time) uint64 {
+		return uint64(t.UnixMicro() - postgresEpochMicros)
+	}
+
+	beginPrepare := []byte{'b'}
+	beginPrepare = binary.BigEndian.AppendUint64(beginPrepare, 0x1000)
+	beginPrepare = binary.BigEndian.AppendUint64(beginPrepare, 0x1100)
+	beginPrepare = binary.BigEndian.AppendUint64(beginPrepare, pgTime(prepareTime))
+	beginPrepare = binary.BigEndian.AppendUint32(beginPrepare, 742)
+	beginPrepare = append(beginPrepare, "txn1\x00"...)
+
+	commitPrepared := []byte{'K', 0}
+	commitPrepared = binary.BigEndian.AppendUint64(commitPrepared, 0x2000)
+	commitPrepared = binary.BigEndian.AppendUint64(commitPrepared, 0x2100)
+	commitPrepared = binary.BigEndian.AppendUint64(commitPrepared, pgTime(commitTime))
+	commitPrepared = binary.BigEndian.AppendUint32(commitPrepared, 742)
+	commitPrepared = append(commitPrepared, "txn1\x00"...)
+
+	testCases := []struct {
+		name     string
+		data     []byte
+		expected pglogrepl.Message
+	}{
+		{
+			name: "begin prepare",
+			data: beginPrepare,
+			expected: &prepareMessage{
+				msgType:     messageTypeBeginPrepare,
+				PrepareLSN:  0x1000,
+				EndLSN:      0x1100,
+				PrepareTime: prepareTime,
+				Xid:         742,
+				GID:         "txn1",
+			},
+		},
+		{
+			name: "commit prepared",
+			data: commitPrepared,
+			expected: &commitPreparedMessage{
+				CommitLSN:  0x2000,
+				EndLSN:     0x2100,
+				CommitTime: commitTime,
+				Xid:        742,
+				GID:        "txn1",
+			},
+		},
+		{
+			name:     "other message",
+			data:     []byte{'C', 0},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			msg, err := parseTwoPhaseMessage(tc.data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if msg != nil {
+				// compare instants without the location of time.UnixMicro
+				switch m := msg.(type) {
+				case *prepareMessage:
+					m.PrepareTime = m.PrepareTime.UTC()
+				case *commitPreparedMessage:
+					m.CommitTime = m.CommitTime.UTC()
+				}
+			}
+			if !reflect.DeepEqual(msg, tc.expected) {
+				t.Errorf("expected %+v, got %+v", tc.expected, msg)
+			}
+		})
+	}
+
+	if _, err := parseTwoPhaseMessage(commitPrepared[:20]); err == nil {
+		t.Error("expected error for truncated message")
+	}
+}
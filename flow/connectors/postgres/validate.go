@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 
 	"github.com/PeerDB-io/peer-flow/connectors/utils"
+	"github.com/PeerDB-io/peer-flow/shared"
 )
 
 func (c *PostgresConnector) CheckSourceTables(ctx context.Context,
@@ -118,6 +121,164 @@ func (c *PostgresConnector) CheckReplicationPermissions(ctx context.Context, use
 	return nil
 }
 
+// CheckSequencePermissions checks the user can read the sequences owned by tables, which syncing sequences samples
+func (c *PostgresConnector) CheckSequencePermissions(ctx context.Context, tables []*utils.SchemaTable) error {
+	quotedTables := make([]string, 0, len(tables))
+	for _, table := range tables {
+		quotedTables = append(quotedTables, table.String())
+	}
+	rows, err := c.conn.Query(ctx, getUnreadableSequencesSQL, quotedTables)
+	if err != nil {
+		return fmt.Errorf("error checking sequence permissions: %w", err)
+	}
+	sequences, err := pgx.CollectRows[string](rows, pgx.RowTo)
+	if err != nil {
+		return fmt.Errorf("error checking sequence permissions: %w", err)
+	}
+	if len(sequences) > 0 {
+		return fmt.Errorf("user needs SELECT or USAGE on sequences %s to sync them", strings.Join(sequences, ", "))
+	}
+	return nil
+}
+
+// CheckCommitTimestamps checks that the server tracks the commit time and origin of transactions,
+// which mirrors applying changes with a replication origin compare to resolve conflicting writes
+func (c *PostgresConnector) CheckCommitTimestamps(ctx context.Context) error {
+	pgversion, err := c.MajorVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if pgversion < shared.POSTGRES_16 {
+		return fmt.Errorf("resolving conflicts by commit time needs Postgres 16 or later, found %d", pgversion)
+	}
+	var trackCommitTimestamp string
+	if err := c.conn.QueryRow(ctx, "SHOW track_commit_timestamp").Scan(&trackCommitTimestamp); err != nil {
+		return fmt.Errorf("error checking track_commit_timestamp: %w", err)
+	}
+	if trackCommitTimestamp != "on" {
+		return errors.New("resolving conflicts by commit time needs track_commit_timestamp to be on")
+	}
+	return nil
+}
+
+// replicationServer holds the settings deciding whether a mirror can replicate from a server,
+// or whether a standby keeps the failover slot of a mirror on its primary in sync
+type replicationServer struct {
+	host                     string
+	synchronizedStandbySlots string
+	pgversion                shared.PGVersion
+	inRecovery               bool
+	hotStandbyFeedback       bool
+	syncReplicationSlots     bool
+}
+
+func getReplicationServer(ctx context.Context, conn *pgx.Conn) (replicationServer, error) {
+	server := replicationServer{host: conn.Config().Host}
+	if err := conn.QueryRow(ctx, `SELECT pg_is_in_recovery(), current_setting('server_version_num')::int,
+		current_setting('hot_standby_feedback') = 'on',
+		coalesce(current_setting('sync_replication_slots', true), '') = 'on',
+		coalesce(current_setting('synchronized_standby_slots', true), '')`).Scan(
+		&server.inRecovery, &server.pgversion, &server.hotStandbyFeedback,
+		&server.syncReplicationSlots, &server.synchronizedStandbySlots); err != nil {
+		return server, fmt.Errorf("error checking replication settings of %s: %w", server.host, err)
+	}
+	return server, nil
+}
+
+// getConfiguredStandbys connects to each host listed in the peer, returning the settings of those which are standbys
+func (c *PostgresConnector) getConfiguredStandbys(ctx context.Context) ([]replicationServer, error) {
+	connConfig := c.conn.Config()
+	hosts := append([]*pgconn.FallbackConfig{{
+		Host: connConfig.Host, Port: connConfig.Port, TLSConfig: connConfig.TLSConfig,
+	}}, connConfig.Fallbacks...)
+	seen := make(map[string]struct{}, len(hosts))
+	var standbys []replicationServer
+	for _, host := range hosts {
+		// hosts are repeated with and without TLS for sslmode=prefer
+		addr := net.JoinHostPort(host.Host, strconv.Itoa(int(host.Port)))
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
+
+		hostConfig := c.conn.Config()
+		hostConfig.Host = host.Host
+		hostConfig.Port = host.Port
+		hostConfig.TLSConfig = host.TLSConfig
+		hostConfig.Fallbacks = nil
+		conn, err := c.ssh.NewPostgresConnFromConfig(ctx, hostConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+		}
+		server, err := getReplicationServer(ctx, conn)
+		conn.Close(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if server.inRecovery {
+			standbys = append(standbys, server)
+		}
+	}
+	return standbys, nil
+}
+
+// CheckReplicationRole validates settings needed to replicate from a standby, or to keep a failover slot on the primary.
+// Standbys listed as hosts of the peer are checked to sync the failover slot, so the mirror continues after promoting one
+func (c *PostgresConnector) CheckReplicationRole(ctx context.Context, pubName string, failoverSlot bool) error {
+	server, err := getReplicationServer(ctx, c.conn)
+	if err != nil {
+		return err
+	}
+	var standbys []replicationServer
+	if failoverSlot && !server.inRecovery {
+		standbys, err = c.getConfiguredStandbys(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to check standbys: %w", err)
+		}
+	}
+	return validateReplicationRole(server, standbys, pubName, failoverSlot)
+}
+
+func validateReplicationRole(server replicationServer, standbys []replicationServer, pubName string, failoverSlot bool) error {
+	if server.inRecovery {
+		if failoverSlot {
+			return errors.New("failover slots can only be created on the primary, not on a standby")
+		}
+		if server.pgversion < shared.POSTGRES_16 {
+			return errors.New("logical replication from a standby requires Postgres 16 or later")
+		}
+		// without feedback the primary can vacuum away catalog rows the slot on the standby still needs
+		if !server.hotStandbyFeedback {
+			return errors.New("hot_standby_feedback must be on to replicate from a standby")
+		}
+		if pubName == "" {
+			return errors.New("publications can't be created on a standby, create one on the primary and provide its name")
+		}
+		return nil
+	}
+
+	if failoverSlot {
+		if server.pgversion < shared.POSTGRES_17 {
+			return errors.New("failover slots require Postgres 17 or later")
+		}
+		// unless standbys are listed here, a standby may be promoted without having received changes already streamed
+		if server.synchronizedStandbySlots == "" {
+			return errors.New("failover slots require synchronized_standby_slots to be set on the primary")
+		}
+		// a standby only keeps a copy of the failover slot when it syncs slots, which needs feedback to the primary
+		for _, standby := range standbys {
+			if !standby.syncReplicationSlots {
+				return fmt.Errorf("standby %s must have sync_replication_slots = on to keep the failover slot", standby.host)
+			}
+			if !standby.hotStandbyFeedback {
+				return fmt.Errorf("standby %s must have hot_standby_feedback = on to sync the failover slot", standby.host)
+			}
+		}
+	}
+
+	return nil
+}
+
 func (c *PostgresConnector) CheckReplicationConnectivity(ctx context.Context) error {
 	// Check if we can create a replication connection
 	conn, err := c.CreateReplConn(ctx)
@@ -0,0 +1,59 @@
+package connpostgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/PeerDB-io/peer-flow/shared"
+)
+
+func TestValidateReplicationRole(t *testing.T) {
+	primary17 := replicationServer{host: "primary", pgversion: shared.POSTGRES_17, synchronizedStandbySlots: "standby_slot"}
+	syncingStandby := replicationServer{
+		host: "standby", pgversion: shared.POSTGRES_17, inRecovery: true, hotStandbyFeedback: true, syncReplicationSlots: true,
+	}
+	testCases := []struct {
+		name         string
+		server       replicationServer
+		standbys     []replicationServer
+		pubName      string
+		failoverSlot bool
+		valid        bool
+	}{
+		{name: "primary", server: replicationServer{pgversion: shared.POSTGRES_14}, valid: true},
+		{name: "standby", server: syncingStandby, pubName: "pub", valid: true},
+		{name: "standby before 16", server: replicationServer{
+			pgversion: shared.POSTGRES_15, inRecovery: true, hotStandbyFeedback: true,
+		}, pubName: "pub"},
+		{name: "standby without feedback", server: replicationServer{
+			pgversion: shared.POSTGRES_16, inRecovery: true,
+		}, pubName: "pub"},
+		{name: "standby without publication", server: syncingStandby},
+		{name: "failover slot on standby", server: syncingStandby, pubName: "pub", failoverSlot: true},
+		{name: "failover slot before 17", server: replicationServer{
+			pgversion: shared.POSTGRES_16, synchronizedStandbySlots: "standby_slot",
+		}, failoverSlot: true},
+		{name: "failover slot without synchronized standbys", server: replicationServer{
+			pgversion: shared.POSTGRES_17,
+		}, failoverSlot: true},
+		{name: "failover slot", server: primary17, standbys: []replicationServer{syncingStandby}, failoverSlot: true, valid: true},
+		{name: "failover slot without standby hosts", server: primary17, failoverSlot: true, valid: true},
+		{name: "standby not syncing slots", server: primary17, standbys: []replicationServer{{
+			host: "standby", pgversion: shared.POSTGRES_17, inRecovery: true, hotStandbyFeedback: true,
+		}}, failoverSlot: true},
+		{name: "syncing standby without feedback", server: primary17, standbys: []replicationServer{{
+			host: "standby", pgversion: shared.POSTGRES_17, inRecovery: true, syncReplicationSlots: true,
+		}}, failoverSlot: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateReplicationRole(tc.server, tc.standbys, tc.pubName, tc.failoverSlot)
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/PeerDB-io/peer-flow/logger"
 	"github.com/PeerDB-io/peer-flow/model"
 	"github.com/PeerDB-io/peer-flow/peerdbenv"
+	"github.com/PeerDB-io/peer-flow/prom_metrics"
 	"github.com/PeerDB-io/peer-flow/pua"
 	"github.com/PeerDB-io/peer-flow/shared"
 )
@@ -160,6 +161,8 @@ func (c *PubSubConnector) createPool(
 						return nil, fmt.Errorf("error creating topic: %w", err)
 					}
 				}
+				// messages with an ordering key are rejected unless ordering is enabled on the publisher
+				topicClient.EnableMessageOrdering = true
 				return topicClient, nil
 			})
 			if err != nil {
@@ -293,12 +296,14 @@ Loop:
 				ls.Push(pua.LuaRecord.New(ls, record))
 				err := ls.PCall(1, -1, nil)
 				if err != nil {
+					prom_metrics.LuaScriptErrors.WithLabelValues(req.FlowJobName).Inc()
 					queueErr(fmt.Errorf("script failed: %w", err))
 					return nil
 				}
 
 				args := ls.GetTop()
 				results := make([]PubSubMessage, 0, args)
+				var defaultKey *string
 				for i := range args {
 					msg, err := lvalueToPubSubMessage(ls, ls.Get(i-args))
 					if err != nil {
@@ -309,6 +314,18 @@ Loop:
 						if msg.Topic == "" {
 							msg.Topic = record.GetDestinationTableName()
 						}
+						// ordering key keeps changes to the same row in order
+						if msg.OrderingKey == "" {
+							if defaultKey == nil {
+								key, err := model.RecToPartitionKey(req.TableNameSchemaMapping, record)
+								if err != nil {
+									queueErr(err)
+									return nil
+								}
+								defaultKey = &key
+							}
+							msg.OrderingKey = *defaultKey
+						}
 						results = append(results, msg)
 						record.PopulateCountMap(tableNameRowsMapping)
 					}
@@ -3,13 +3,15 @@ package connsnowflake
 import "testing"
 
 func TestAvroTransform(t *testing.T) {
-	colNames := []string{"col1", "col2", "col3", "camelCol4", "sync_col", "del_col"}
-	colTypes := []string{"GEOGRAPHY", "VARIANT", "NUMBER", "STRING", "TIMESTAMP_LTZ", "BOOLEAN"}
+	colNames := []string{"col1", "col2", "col3", "camelCol4", "col5", "col6", "sync_col", "del_col"}
+	colTypes := []string{"GEOGRAPHY", "VARIANT", "NUMBER", "STRING", "OBJECT", "ARRAY", "TIMESTAMP_LTZ", "BOOLEAN"}
 
 	expectedTransform := `TO_GEOGRAPHY($1:"col1"::string, true) AS "COL1",` +
 		`PARSE_JSON($1:"col2") AS "COL2",` +
 		`$1:"col3" AS "COL3",` +
 		`($1:"camelCol4")::STRING AS "camelCol4",` +
+		`TO_OBJECT(PARSE_JSON($1:"col5")) AS "COL5",` +
+		`TO_ARRAY(IFF(IS_VARCHAR($1:"col6"), PARSE_JSON($1:"col6"), $1:"col6")) AS "COL6",` +
 		`CURRENT_TIMESTAMP AS "SYNC_COL",` +
 		`FALSE AS "DEL_COL"`
 	transform, cols := getTransformSQL(colNames, colTypes, "sync_col", "del_col")
@@ -17,8 +19,20 @@ func TestAvroTransform(t *testing.T) {
 		t.Errorf("Transform SQL is not correct. Got: %v", transform)
 	}
 
-	expectedCols := `"COL1","COL2","COL3","camelCol4","SYNC_COL","DEL_COL"`
+	expectedCols := `"COL1","COL2","COL3","camelCol4","COL5","COL6","SYNC_COL","DEL_COL"`
 	if cols != expectedCols {
 		t.Errorf("Columns are not correct. Got:%v", cols)
 	}
 }
+
+func TestAvroTransformVector(t *testing.T) {
+	colNames := []string{"col1", "col2"}
+	colTypes := []string{"VECTOR(FLOAT, 3)", "ARRAY"}
+
+	expectedTransform := `CAST(CAST($1:"col1" AS ARRAY) AS VECTOR(FLOAT, 3)) AS "COL1",` +
+		`TO_ARRAY(IFF(IS_VARCHAR($1:"col2"), PARSE_JSON($1:"col2"), $1:"col2")) AS "COL2"`
+	transform, _ := getTransformSQL(colNames, colTypes, "sync_col", "del_col")
+	if transform != expectedTransform {
+		t.Errorf("Transform SQL is not correct. Got: %v", transform)
+	}
+}
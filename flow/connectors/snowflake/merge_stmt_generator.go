@@ -2,6 +2,7 @@ package connsnowflake
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/PeerDB-io/peer-flow/connectors/utils"
@@ -18,25 +19,43 @@ type mergeStmtGenerator struct {
 	unchangedToastColumnsMap map[string][]string
 	// _PEERDB_IS_DELETED and _SYNCED_AT columns
 	peerdbCols *protos.PeerDBColumns
+	// tables normalized as history instead of being merged
+	normalizeModes map[string]protos.NormalizeMode
 	// _PEERDB_RAW_...
 	rawTableName string
 	// Id of the currently merging batch
 	mergeBatchId int64
 }
 
-func (m *mergeStmtGenerator) generateMergeStmt(dstTable string) (string, error) {
-	parsedDstTable, _ := utils.ParseSchemaTable(dstTable)
-	normalizedTableSchema := m.tableSchemaMapping[dstTable]
-	unchangedToastColumns := m.unchangedToastColumnsMap[dstTable]
-	columns := normalizedTableSchema.Columns
+// generateNormalizeStmts returns the statements applying the batch to dstTable, to be run in one transaction
+func (m *mergeStmtGenerator) generateNormalizeStmts(dstTable string) ([]string, error) {
+	switch m.normalizeModes[dstTable] {
+	case protos.NormalizeMode_NORMALIZE_MODE_CHANGELOG:
+		changelogStmt, err := m.generateChangelogStmt(dstTable)
+		if err != nil {
+			return nil, err
+		}
+		return []string{changelogStmt}, nil
+	case protos.NormalizeMode_NORMALIZE_MODE_SCD2:
+		return m.generateSCD2Stmts(dstTable)
+	default:
+		mergeStmt, err := m.generateMergeStmt(dstTable)
+		if err != nil {
+			return nil, err
+		}
+		return []string{mergeStmt}, nil
+	}
+}
 
+// generateFlattenedCasts extracts each column from the VARIANT of _PEERDB_DATA
+func generateFlattenedCasts(columns []*protos.FieldDescription) ([]string, error) {
 	flattenedCastsSQLArray := make([]string, 0, len(columns))
 	for _, column := range columns {
 		genericColumnType := column.Type
 		qvKind := qvalue.QValueKind(genericColumnType)
 		sfType, err := qvKind.ToDWHColumnType(protos.DBType_SNOWFLAKE)
 		if err != nil {
-			return "", fmt.Errorf("failed to convert column type %s to snowflake type: %w", genericColumnType, err)
+			return nil, fmt.Errorf("failed to convert column type %s to snowflake type: %w", genericColumnType, err)
 		}
 
 		targetColumnName := SnowflakeIdentifierNormalize(column.Name)
@@ -67,11 +86,28 @@ func (m *mergeStmtGenerator) generateMergeStmt(dstTable string) (string, error)
 			flattenedCastsSQLArray = append(flattenedCastsSQLArray,
 				fmt.Sprintf("TRY_CAST((%s:\"%s\")::text AS %s) AS %s",
 					toVariantColumnName, column.Name, numericType, targetColumnName))
+		case qvalue.QValueKindVector:
+			flattenedCastsSQLArray = append(flattenedCastsSQLArray, fmt.Sprintf("%s AS %s",
+				snowflakeVectorCast(fmt.Sprintf("%s:\"%s\"", toVariantColumnName, column.Name),
+					snowflakeVectorType(column.TypeModifier)), targetColumnName))
 		default:
 			flattenedCastsSQLArray = append(flattenedCastsSQLArray, fmt.Sprintf("CAST(%s:\"%s\" AS %s) AS %s",
 				toVariantColumnName, column.Name, sfType, targetColumnName))
 		}
 	}
+	return flattenedCastsSQLArray, nil
+}
+
+func (m *mergeStmtGenerator) generateMergeStmt(dstTable string) (string, error) {
+	parsedDstTable, _ := utils.ParseSchemaTable(dstTable)
+	normalizedTableSchema := m.tableSchemaMapping[dstTable]
+	unchangedToastColumns := m.unchangedToastColumnsMap[dstTable]
+	columns := normalizedTableSchema.Columns
+
+	flattenedCastsSQLArray, err := generateFlattenedCasts(columns)
+	if err != nil {
+		return "", err
+	}
 	flattenedCastsSQL := strings.Join(flattenedCastsSQLArray, ",")
 
 	quotedUpperColNames := make([]string, 0, len(columns))
@@ -138,6 +174,116 @@ func (m *mergeStmtGenerator) generateMergeStmt(dstTable string) (string, error)
 	return mergeStatement, nil
 }
 
+// generateFlattenedBatch selects the changes of the batch to dstTable with their columns extracted
+func (m *mergeStmtGenerator) generateFlattenedBatch(columns []*protos.FieldDescription) (string, error) {
+	flattenedCastsSQLArray, err := generateFlattenedCasts(columns)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(flattenedBatchSQL, strings.Join(flattenedCastsSQLArray, ","),
+		toVariantColumnName, m.rawTableName, m.mergeBatchId), nil
+}
+
+// generateChangelogStmt appends every change of the batch as a row,
+// the insert is skipped when an earlier attempt of the batch already appended its rows
+func (m *mergeStmtGenerator) generateChangelogStmt(dstTable string) (string, error) {
+	parsedDstTable, _ := utils.ParseSchemaTable(dstTable)
+	columns := m.tableSchemaMapping[dstTable].Columns
+	flattenedBatch, err := m.generateFlattenedBatch(columns)
+	if err != nil {
+		return "", err
+	}
+
+	insertColumnsSQLArray := make([]string, 0, len(columns)+6)
+	selectSQLArray := make([]string, 0, len(columns)+6)
+	for _, column := range columns {
+		normalizedColName := SnowflakeIdentifierNormalize(column.Name)
+		insertColumnsSQLArray = append(insertColumnsSQLArray, normalizedColName)
+		selectSQLArray = append(selectSQLArray, "SOURCE."+normalizedColName)
+	}
+	insertColumnsSQLArray = append(insertColumnsSQLArray,
+		SnowflakeIdentifierNormalize(shared.HistoryOpColName),
+		SnowflakeIdentifierNormalize(shared.HistoryCommitTimeColName),
+		SnowflakeIdentifierNormalize(shared.HistoryLSNColName),
+		SnowflakeIdentifierNormalize(shared.HistoryBeforeColName),
+		SnowflakeIdentifierNormalize(shared.HistoryBatchIDColName))
+	selectSQLArray = append(selectSQLArray,
+		"CASE SOURCE._PEERDB_RECORD_TYPE WHEN 0 THEN 'insert' WHEN 1 THEN 'update' ELSE 'delete' END",
+		"TO_TIMESTAMP_NTZ(SOURCE._PEERDB_COMMIT_TIME_NS, 9)",
+		"SOURCE._PEERDB_COMMIT_LSN",
+		"PARSE_JSON(NULLIF(SOURCE._PEERDB_MATCH_DATA, ''))",
+		strconv.FormatInt(m.mergeBatchId, 10))
+	if m.peerdbCols.SyncedAtColName != "" {
+		insertColumnsSQLArray = append(insertColumnsSQLArray, SnowflakeIdentifierNormalize(m.peerdbCols.SyncedAtColName))
+		selectSQLArray = append(selectSQLArray, "CURRENT_TIMESTAMP")
+	}
+
+	normalizedDstTable := snowflakeSchemaTableNormalize(parsedDstTable)
+	return fmt.Sprintf(changelogStatementSQL, normalizedDstTable, strings.Join(insertColumnsSQLArray, ","),
+		strings.Join(selectSQLArray, ","), flattenedBatch, normalizedDstTable, m.mergeBatchId), nil
+}
+
+// generateSCD2Stmts closes the current version of each key changed in the batch,
+// then inserts the versions of the batch, each valid until the next change of its key.
+// Both skip work already done by an earlier attempt of the batch through the batch id of the versions
+func (m *mergeStmtGenerator) generateSCD2Stmts(dstTable string) ([]string, error) {
+	parsedDstTable, _ := utils.ParseSchemaTable(dstTable)
+	normalizedTableSchema := m.tableSchemaMapping[dstTable]
+	columns := normalizedTableSchema.Columns
+	flattenedBatch, err := m.generateFlattenedBatch(columns)
+	if err != nil {
+		return nil, err
+	}
+	normalizedDstTable := snowflakeSchemaTableNormalize(parsedDstTable)
+
+	pkeyCount := len(normalizedTableSchema.PrimaryKeyColumns)
+	normalizedPkeyColsArray := make([]string, 0, pkeyCount)
+	pkeyMatchSQLArray := make([]string, 0, pkeyCount)
+	for _, pkeyColName := range normalizedTableSchema.PrimaryKeyColumns {
+		normalizedPkeyColName := SnowflakeIdentifierNormalize(pkeyColName)
+		normalizedPkeyColsArray = append(normalizedPkeyColsArray, normalizedPkeyColName)
+		pkeyMatchSQLArray = append(pkeyMatchSQLArray, fmt.Sprintf("TARGET.%s = SOURCE.%s",
+			normalizedPkeyColName, normalizedPkeyColName))
+	}
+	pkeyColsSQL := strings.Join(normalizedPkeyColsArray, ",")
+
+	closeSetSQL := fmt.Sprintf("%s = TO_TIMESTAMP_NTZ(SOURCE._PEERDB_FIRST_COMMIT, 9), %s = FALSE",
+		SnowflakeIdentifierNormalize(shared.SCD2ValidToColName), SnowflakeIdentifierNormalize(shared.SCD2IsCurrentColName))
+	if m.peerdbCols.SyncedAtColName != "" {
+		closeSetSQL += fmt.Sprintf(", %s = CURRENT_TIMESTAMP", SnowflakeIdentifierNormalize(m.peerdbCols.SyncedAtColName))
+	}
+	closeStmt := fmt.Sprintf(scd2CloseStatementSQL, normalizedDstTable, closeSetSQL, pkeyColsSQL,
+		flattenedBatch, pkeyColsSQL, strings.Join(pkeyMatchSQLArray, " AND "), m.mergeBatchId)
+
+	insertColumnsSQLArray := make([]string, 0, len(columns)+6)
+	selectSQLArray := make([]string, 0, len(columns)+6)
+	for _, column := range columns {
+		normalizedColName := SnowflakeIdentifierNormalize(column.Name)
+		insertColumnsSQLArray = append(insertColumnsSQLArray, normalizedColName)
+		selectSQLArray = append(selectSQLArray, "SOURCE."+normalizedColName)
+	}
+	insertColumnsSQLArray = append(insertColumnsSQLArray,
+		SnowflakeIdentifierNormalize(shared.HistoryLSNColName),
+		SnowflakeIdentifierNormalize(shared.SCD2ValidFromColName),
+		SnowflakeIdentifierNormalize(shared.SCD2ValidToColName),
+		SnowflakeIdentifierNormalize(shared.SCD2IsCurrentColName),
+		SnowflakeIdentifierNormalize(shared.HistoryBatchIDColName))
+	selectSQLArray = append(selectSQLArray,
+		"SOURCE._PEERDB_COMMIT_LSN",
+		"TO_TIMESTAMP_NTZ(SOURCE._PEERDB_COMMIT_TIME_NS, 9)",
+		"TO_TIMESTAMP_NTZ(SOURCE._PEERDB_NEXT_COMMIT, 9)",
+		"SOURCE._PEERDB_NEXT_COMMIT IS NULL",
+		strconv.FormatInt(m.mergeBatchId, 10))
+	if m.peerdbCols.SyncedAtColName != "" {
+		insertColumnsSQLArray = append(insertColumnsSQLArray, SnowflakeIdentifierNormalize(m.peerdbCols.SyncedAtColName))
+		selectSQLArray = append(selectSQLArray, "CURRENT_TIMESTAMP")
+	}
+	insertStmt := fmt.Sprintf(scd2InsertStatementSQL, normalizedDstTable, strings.Join(insertColumnsSQLArray, ","),
+		strings.Join(selectSQLArray, ","), pkeyColsSQL, flattenedBatch, normalizedDstTable, m.mergeBatchId)
+
+	return []string{closeStmt, insertStmt}, nil
+}
+
 /*
 This function generates UPDATE statements for a MERGE operation based on the provided inputs.
 
@@ -152,3 +152,23 @@ func TestGenerateUpdateStatement_WithUnchangedToastColsAndSoftDelete(t *testing.
 		t.Errorf("Unexpected result. Expected: %v, but got: %v", expected, result)
 	}
 }
+
+func TestGenerateFlattenedCastsVector(t *testing.T) {
+	columns := []*protos.FieldDescription{
+		{Name: "embedding", Type: "vector", TypeModifier: 3},
+		{Name: "unbounded", Type: "vector", TypeModifier: -1},
+	}
+
+	expected := []string{
+		`CAST(CAST(VAR_COLS:"embedding" AS ARRAY) AS VECTOR(FLOAT, 3)) AS "EMBEDDING"`,
+		`CAST(VAR_COLS:"unbounded" AS ARRAY) AS "UNBOUNDED"`,
+	}
+	result, err := generateFlattenedCasts(columns)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Unexpected result. Expected: %v, but got: %v", expected, result)
+	}
+}
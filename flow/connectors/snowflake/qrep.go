@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -230,9 +231,58 @@ func (c *SnowflakeConnector) getColsFromTable(ctx context.Context, tableName str
 		return nil, fmt.Errorf("cannot load schema: table %s.%s does not exist", schemaTable.Schema, schemaTable.Table)
 	}
 
+	// INFORMATION_SCHEMA leaves out the dimensions of VECTOR columns, which casts to VECTOR need
+	if slices.ContainsFunc(cols, func(col SnowflakeTableColumn) bool { return col.ColumnType == "VECTOR" }) {
+		vectorTypes, err := c.getVectorColumnTypes(ctx, schemaTable)
+		if err != nil {
+			return nil, err
+		}
+		for i, col := range cols {
+			if vectorType, ok := vectorTypes[col.ColumnName]; ok {
+				cols[i].ColumnType = vectorType
+			}
+		}
+	}
+
 	return cols, nil
 }
 
+// getVectorColumnTypes returns the full type, like VECTOR(FLOAT, 3), of every VECTOR column of the table
+func (c *SnowflakeConnector) getVectorColumnTypes(ctx context.Context, schemaTable *utils.SchemaTable) (map[string]string, error) {
+	//nolint:gosec
+	rows, err := c.database.QueryContext(ctx, "DESCRIBE TABLE "+snowflakeSchemaTableNormalize(schemaTable))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe table: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns of table description: %w", err)
+	}
+	// name and type come first, the remaining columns are not needed
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	vectorTypes := make(map[string]string)
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan table description: %w", err)
+		}
+		if strings.HasPrefix(values[1].String, "VECTOR") {
+			vectorTypes[values[0].String] = values[1].String
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read table description: %w", err)
+	}
+
+	return vectorTypes, nil
+}
+
 // dropStage drops the stage for the given job.
 func (c *SnowflakeConnector) dropStage(ctx context.Context, stagingPath string, job string) error {
 	stageName := c.getStageNameForJob(job)
@@ -9,6 +9,7 @@ import (
 
 	"github.com/PeerDB-io/peer-flow/connectors/utils"
 	"github.com/PeerDB-io/peer-flow/generated/protos"
+	"github.com/PeerDB-io/peer-flow/otel_tracing"
 	"github.com/PeerDB-io/peer-flow/shared"
 )
 
@@ -93,6 +94,12 @@ func getTransformSQL(colNames []string, colTypes []string, syncedAtCol string, i
 			continue
 		}
 
+		// rows of the initial load are the current version of a SCD2 table, other history columns stay NULL
+		if strings.EqualFold(avroColName, shared.SCD2IsCurrentColName) {
+			transformations = append(transformations, "TRUE AS "+normalizedColName)
+			continue
+		}
+
 		if utils.IsUpper(avroColName) {
 			avroColName = strings.ToLower(avroColName)
 		}
@@ -116,10 +123,23 @@ func getTransformSQL(colNames []string, colTypes []string, syncedAtCol string, i
 		case "VARIANT":
 			transformations = append(transformations,
 				fmt.Sprintf("PARSE_JSON($1:\"%s\") AS %s", avroColName, normalizedColName))
+		// ranges are written as JSON strings, multiranges share ARRAY with arrays written as Avro arrays
+		case "OBJECT":
+			transformations = append(transformations,
+				fmt.Sprintf("TO_OBJECT(PARSE_JSON($1:\"%s\")) AS %s", avroColName, normalizedColName))
+		case "ARRAY":
+			transformations = append(transformations,
+				fmt.Sprintf("TO_ARRAY(IFF(IS_VARCHAR($1:\"%[1]s\"), PARSE_JSON($1:\"%[1]s\"), $1:\"%[1]s\")) AS %[2]s",
+					avroColName, normalizedColName))
 
 		default:
-			transformations = append(transformations,
-				fmt.Sprintf("($1:\"%s\")::%s AS %s", avroColName, colType, normalizedColName))
+			if strings.HasPrefix(colType, "VECTOR") {
+				transformations = append(transformations, fmt.Sprintf("%s AS %s",
+					snowflakeVectorCast(fmt.Sprintf("$1:\"%s\"", avroColName), colType), normalizedColName))
+			} else {
+				transformations = append(transformations,
+					fmt.Sprintf("($1:\"%s\")::%s AS %s", avroColName, colType, normalizedColName))
+			}
 		}
 	}
 	transformationSQL := strings.Join(transformations, ",")
@@ -144,7 +164,9 @@ func (s *SnowflakeAvroConsolidateHandler) handleAppendMode(ctx context.Context)
 	parsedDstTable, _ := utils.ParseSchemaTable(s.dstTableName)
 	copyCmd := s.getCopyTransformation(snowflakeSchemaTableNormalize(parsedDstTable))
 	s.connector.logger.Info("running copy command: " + copyCmd)
-	_, err := s.connector.database.ExecContext(ctx, copyCmd)
+	copyCtx, span := otel_tracing.Start(ctx, "snowflake.CopyInto", otel_tracing.Table(s.dstTableName))
+	_, err := s.connector.database.ExecContext(copyCtx, copyCmd)
+	otel_tracing.End(span, err)
 	if err != nil {
 		return fmt.Errorf("failed to run COPY INTO command: %w", err)
 	}
@@ -225,7 +247,9 @@ func (s *SnowflakeAvroConsolidateHandler) handleUpsertMode(ctx context.Context)
 	s.connector.logger.Info("created temp table " + tempTableName)
 
 	copyCmd := s.getCopyTransformation(tempTableName)
-	_, err = s.connector.database.ExecContext(ctx, copyCmd)
+	copyCtx, span := otel_tracing.Start(ctx, "snowflake.CopyInto", otel_tracing.Table(s.dstTableName))
+	_, err = s.connector.database.ExecContext(copyCtx, copyCmd)
+	otel_tracing.End(span, err)
 	if err != nil {
 		return fmt.Errorf("failed to run COPY INTO command: %w", err)
 	}
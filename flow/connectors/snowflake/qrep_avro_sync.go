@@ -17,6 +17,7 @@ import (
 	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/model"
 	"github.com/PeerDB-io/peer-flow/model/qvalue"
+	"github.com/PeerDB-io/peer-flow/otel_tracing"
 	"github.com/PeerDB-io/peer-flow/shared"
 )
 
@@ -267,7 +268,10 @@ func (s *SnowflakeAvroSyncHandler) putFileToStage(ctx context.Context, avroFile
 
 	putCmd := fmt.Sprintf("PUT file://%s @%s", avroFile.FilePath, stage)
 
-	if _, err := s.connector.database.ExecContext(ctx, putCmd); err != nil {
+	putCtx, span := otel_tracing.Start(ctx, "snowflake.PutFileToStage")
+	_, err := s.connector.database.ExecContext(putCtx, putCmd)
+	otel_tracing.End(span, err)
+	if err != nil {
 		return fmt.Errorf("failed to put file to stage: %w", err)
 	}
 
@@ -25,14 +25,17 @@ import (
 	"github.com/PeerDB-io/peer-flow/logger"
 	"github.com/PeerDB-io/peer-flow/model"
 	"github.com/PeerDB-io/peer-flow/model/qvalue"
+	"github.com/PeerDB-io/peer-flow/otel_tracing"
 	"github.com/PeerDB-io/peer-flow/peerdbenv"
 	"github.com/PeerDB-io/peer-flow/shared"
 )
 
 const (
-	rawTablePrefix    = "_PEERDB_RAW"
-	createSchemaSQL   = "CREATE TRANSIENT SCHEMA IF NOT EXISTS %s"
-	createRawTableSQL = `CREATE TABLE IF NOT EXISTS %s.%s(_PEERDB_UID STRING NOT NULL,
+	rawTablePrefix = "_PEERDB_RAW"
+	// VECTOR columns hold at most this many dimensions
+	maxSnowflakeVectorDimensions = 4096
+	createSchemaSQL              = "CREATE TRANSIENT SCHEMA IF NOT EXISTS %s"
+	createRawTableSQL            = `CREATE TABLE IF NOT EXISTS %s.%s(_PEERDB_UID STRING NOT NULL,
 		_PEERDB_TIMESTAMP INT NOT NULL,_PEERDB_DESTINATION_TABLE_NAME STRING NOT NULL,_PEERDB_DATA STRING NOT NULL,
 		_PEERDB_RECORD_TYPE INTEGER NOT NULL, _PEERDB_MATCH_DATA STRING,_PEERDB_BATCH_ID INT,
 		_PEERDB_UNCHANGED_TOAST_COLUMNS STRING)`
@@ -55,6 +58,19 @@ const (
 		 WHEN NOT MATCHED AND (SOURCE._PEERDB_RECORD_TYPE != 2) THEN INSERT (%s) VALUES(%s)
 		 %s
 		 WHEN MATCHED AND (SOURCE._PEERDB_RECORD_TYPE = 2) THEN %s`
+	flattenedBatchSQL = `(SELECT %s,_PEERDB_TIMESTAMP,_PEERDB_RECORD_TYPE,_PEERDB_MATCH_DATA,_PEERDB_COMMIT_LSN,
+		_PEERDB_COMMIT_TIME_NS FROM (SELECT TO_VARIANT(PARSE_JSON(_PEERDB_DATA)) %s,_PEERDB_TIMESTAMP,_PEERDB_RECORD_TYPE,
+		_PEERDB_MATCH_DATA,_PEERDB_COMMIT_LSN,_PEERDB_COMMIT_TIME_NS FROM _PEERDB_INTERNAL.%s
+		WHERE _PEERDB_BATCH_ID = %d AND _PEERDB_DESTINATION_TABLE_NAME = ?))`
+	changelogStatementSQL = `INSERT INTO %s (%s) SELECT %s FROM %s SOURCE
+		WHERE NOT EXISTS (SELECT 1 FROM %s WHERE _PEERDB_BATCH_ID = %d)`
+	scd2CloseStatementSQL = `UPDATE %s TARGET SET %s FROM (SELECT %s,MIN(_PEERDB_COMMIT_TIME_NS) AS _PEERDB_FIRST_COMMIT
+		FROM %s GROUP BY %s) SOURCE
+		WHERE TARGET._PEERDB_IS_CURRENT AND %s AND (TARGET._PEERDB_BATCH_ID IS NULL OR TARGET._PEERDB_BATCH_ID != %d)`
+	scd2InsertStatementSQL = `INSERT INTO %s (%s) SELECT %s FROM (SELECT *,LEAD(_PEERDB_COMMIT_TIME_NS) OVER
+		(PARTITION BY %s ORDER BY _PEERDB_COMMIT_TIME_NS,_PEERDB_COMMIT_LSN,_PEERDB_TIMESTAMP) AS _PEERDB_NEXT_COMMIT FROM %s) SOURCE
+		WHERE SOURCE._PEERDB_RECORD_TYPE != 2 AND NOT EXISTS (SELECT 1 FROM %s WHERE _PEERDB_BATCH_ID = %d)`
+	addRawTableCommitInfoSQL         = "ALTER TABLE %s.%s ADD COLUMN IF NOT EXISTS %s INT"
 	getDistinctDestinationTableNames = `SELECT DISTINCT _PEERDB_DESTINATION_TABLE_NAME FROM %s.%s WHERE
 	 _PEERDB_BATCH_ID = %d`
 	getTableNameToUnchangedColsSQL = `SELECT _PEERDB_DESTINATION_TABLE_NAME,
@@ -68,13 +84,14 @@ const (
 	 WHERE TABLE_SCHEMA=? and TABLE_NAME=?`
 	checkIfSchemaExistsSQL = `SELECT TO_BOOLEAN(COUNT(1)) FROM INFORMATION_SCHEMA.SCHEMATA
 	 WHERE SCHEMA_NAME=?`
-	getLastOffsetSQL            = "SELECT OFFSET FROM %s.%s WHERE MIRROR_JOB_NAME=?"
-	setLastOffsetSQL            = "UPDATE %s.%s SET OFFSET=GREATEST(OFFSET, ?) WHERE MIRROR_JOB_NAME=?"
-	getLastSyncBatchID_SQL      = "SELECT SYNC_BATCH_ID FROM %s.%s WHERE MIRROR_JOB_NAME=?"
-	getLastNormalizeBatchID_SQL = "SELECT NORMALIZE_BATCH_ID FROM %s.%s WHERE MIRROR_JOB_NAME=?"
-	dropTableIfExistsSQL        = "DROP TABLE IF EXISTS %s.%s"
-	deleteJobMetadataSQL        = "DELETE FROM %s.%s WHERE MIRROR_JOB_NAME=?"
-	dropSchemaIfExistsSQL       = "DROP SCHEMA IF EXISTS %s"
+	getLastOffsetSQL              = "SELECT OFFSET FROM %s.%s WHERE MIRROR_JOB_NAME=?"
+	setLastOffsetSQL              = "UPDATE %s.%s SET OFFSET=GREATEST(OFFSET, ?) WHERE MIRROR_JOB_NAME=?"
+	getLastSyncBatchID_SQL        = "SELECT SYNC_BATCH_ID FROM %s.%s WHERE MIRROR_JOB_NAME=?"
+	getLastNormalizeBatchID_SQL   = "SELECT NORMALIZE_BATCH_ID FROM %s.%s WHERE MIRROR_JOB_NAME=?"
+	dropTableIfExistsSQL          = "DROP TABLE IF EXISTS %s.%s"
+	deleteJobMetadataSQL          = "DELETE FROM %s.%s WHERE MIRROR_JOB_NAME=?"
+	deleteRawTableRowsForTableSQL = "DELETE FROM %s.%s WHERE _PEERDB_DESTINATION_TABLE_NAME=?"
+	dropSchemaIfExistsSQL         = "DROP SCHEMA IF EXISTS %s"
 )
 
 type SnowflakeConnector struct {
@@ -316,10 +333,9 @@ func (c *SnowflakeConnector) CleanupSetupNormalizedTables(_ context.Context, _ i
 func (c *SnowflakeConnector) SetupNormalizedTable(
 	ctx context.Context,
 	tx interface{},
+	config *protos.SetupNormalizedTableBatchInput,
 	tableIdentifier string,
 	tableSchema *protos.TableSchema,
-	softDeleteColName string,
-	syncedAtColName string,
 ) (bool, error) {
 	normalizedSchemaTable, err := utils.ParseSchemaTable(tableIdentifier)
 	if err != nil {
@@ -334,7 +350,8 @@ func (c *SnowflakeConnector) SetupNormalizedTable(
 	}
 
 	normalizedTableCreateSQL := generateCreateTableSQLForNormalizedTable(
-		normalizedSchemaTable, tableSchema, softDeleteColName, syncedAtColName)
+		normalizedSchemaTable, tableSchema, config.SoftDeleteColName, config.SyncedAtColName,
+		shared.NormalizeModeForTable(config.NormalizeMode, config.TableMappings, tableIdentifier))
 	_, err = c.database.ExecContext(ctx, normalizedTableCreateSQL)
 	if err != nil {
 		return false, fmt.Errorf("[sf] error while creating normalized table: %w", err)
@@ -380,6 +397,8 @@ func (c *SnowflakeConnector) ReplayTableSchemaDeltas(
 			if addedColumn.Type == string(qvalue.QValueKindNumeric) {
 				precision, scale := numeric.GetNumericTypeForWarehouse(addedColumn.TypeModifier, numeric.SnowflakeNumericCompatibility{})
 				sfColtype = fmt.Sprintf("NUMERIC(%d,%d)", precision, scale)
+			} else if addedColumn.Type == string(qvalue.QValueKindVector) {
+				sfColtype = snowflakeVectorType(addedColumn.TypeModifier)
 			}
 
 			_, err = tableSchemaModifyTx.ExecContext(ctx,
@@ -430,6 +449,7 @@ func (c *SnowflakeConnector) syncRecordsViaAvro(
 ) (*model.SyncResponse, error) {
 	tableNameRowsMapping := utils.InitialiseTableRowsMap(req.TableMappings)
 	streamReq := model.NewRecordsToStreamRequest(req.Records.GetRecords(), tableNameRowsMapping, syncBatchID)
+	streamReq.CommitInfo = req.CommitInfo
 	stream, err := utils.RecordsToRawTableStream(streamReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert records to raw table stream: %w", err)
@@ -485,7 +505,7 @@ func (c *SnowflakeConnector) NormalizeRecords(ctx context.Context, req *model.No
 	for batchId := normBatchID + 1; batchId <= req.SyncBatchID; batchId++ {
 		c.logger.Info(fmt.Sprintf("normalizing records for batch %d [of %d]", batchId, req.SyncBatchID))
 		mergeErr := c.mergeTablesForBatch(ctx, batchId,
-			req.FlowJobName, req.TableNameSchemaMapping,
+			req.FlowJobName, req.TableNameSchemaMapping, req.NormalizeModes,
 			&protos.PeerDBColumns{
 				SoftDelete:        req.SoftDelete,
 				SoftDeleteColName: req.SoftDeleteColName,
@@ -514,12 +534,14 @@ func (c *SnowflakeConnector) mergeTablesForBatch(
 	batchId int64,
 	flowName string,
 	tableToSchema map[string]*protos.TableSchema,
+	normalizeModes map[string]protos.NormalizeMode,
 	peerdbCols *protos.PeerDBColumns,
 ) error {
 	destinationTableNames, err := c.getDistinctTableNamesInBatch(ctx, flowName, batchId)
 	if err != nil {
 		return err
 	}
+	destinationTableNames = utils.TablesToNormalize(destinationTableNames, tableToSchema, c.logger)
 
 	tableNameToUnchangedToastCols, err := c.getTableNameToUnchangedCols(ctx, flowName, batchId)
 	if err != nil {
@@ -536,6 +558,7 @@ func (c *SnowflakeConnector) mergeTablesForBatch(
 		tableSchemaMapping:       tableToSchema,
 		unchangedToastColumnsMap: tableNameToUnchangedToastCols,
 		peerdbCols:               peerdbCols,
+		normalizeModes:           normalizeModes,
 	}
 
 	for _, tableName := range destinationTableNames {
@@ -544,7 +567,7 @@ func (c *SnowflakeConnector) mergeTablesForBatch(
 		}
 
 		g.Go(func() error {
-			mergeStatement, err := mergeGen.generateMergeStmt(tableName)
+			normalizeStatements, err := mergeGen.generateNormalizeStmts(tableName)
 			if err != nil {
 				return err
 			}
@@ -552,21 +575,18 @@ func (c *SnowflakeConnector) mergeTablesForBatch(
 			startTime := time.Now()
 			c.logger.Info("[merge] merging records...", "destTable", tableName, "batchId", batchId)
 
-			result, err := c.database.ExecContext(gCtx, mergeStatement, tableName)
+			stmtCtx, span := otel_tracing.Start(gCtx, "snowflake.NormalizeTable",
+				otel_tracing.Table(tableName), otel_tracing.BatchID(batchId))
+			rowsAffected, err := c.execNormalizeStatements(stmtCtx, normalizeStatements, tableName)
+			otel_tracing.End(span, err)
 			if err != nil {
-				return fmt.Errorf("failed to merge records into %s (statement: %s): %w",
-					tableName, mergeStatement, err)
+				return err
 			}
 
 			endTime := time.Now()
 			c.logger.Info(fmt.Sprintf("[merge] merged records into %s, took: %d seconds",
 				tableName, endTime.Sub(startTime)/time.Second), "batchId", batchId)
 
-			rowsAffected, err := result.RowsAffected()
-			if err != nil {
-				return fmt.Errorf("failed to get rows affected by merge statement for table %s: %w", tableName, err)
-			}
-
 			atomic.AddInt64(&totalRowsAffected, rowsAffected)
 			return nil
 		})
@@ -582,6 +602,52 @@ func (c *SnowflakeConnector) mergeTablesForBatch(
 	return nil
 }
 
+// execNormalizeStatements runs the statements normalizing a batch into tableName,
+// statements after the first run in a transaction with it so a table never sees half a batch
+func (c *SnowflakeConnector) execNormalizeStatements(ctx context.Context, statements []string, tableName string) (int64, error) {
+	if len(statements) == 1 {
+		result, err := c.database.ExecContext(ctx, statements[0], tableName)
+		if err != nil {
+			return 0, fmt.Errorf("failed to merge records into %s (statement: %s): %w",
+				tableName, statements[0], err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get rows affected by merge statement for table %s: %w", tableName, err)
+		}
+		return rowsAffected, nil
+	}
+
+	normalizeTx, err := c.database.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin transaction for normalizing %s: %w", tableName, err)
+	}
+	defer func() {
+		deferErr := normalizeTx.Rollback()
+		if deferErr != sql.ErrTxDone && deferErr != nil {
+			c.logger.Error("error rolling back transaction for normalize", "destTable", tableName, "error", deferErr)
+		}
+	}()
+
+	var totalRowsAffected int64
+	for _, statement := range statements {
+		result, err := normalizeTx.ExecContext(ctx, statement, tableName)
+		if err != nil {
+			return 0, fmt.Errorf("failed to normalize records into %s (statement: %s): %w", tableName, statement, err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get rows affected by normalize statement for table %s: %w", tableName, err)
+		}
+		totalRowsAffected += rowsAffected
+	}
+
+	if err := normalizeTx.Commit(); err != nil {
+		return 0, fmt.Errorf("unable to commit transaction for normalizing %s: %w", tableName, err)
+	}
+	return totalRowsAffected, nil
+}
+
 func (c *SnowflakeConnector) CreateRawTable(ctx context.Context, req *protos.CreateRawTableInput) (*protos.CreateRawTableOutput, error) {
 	var schemaExists sql.NullBool
 	err := c.database.QueryRowContext(ctx, checkIfSchemaExistsSQL, c.rawSchema).Scan(&schemaExists)
@@ -608,6 +674,16 @@ func (c *SnowflakeConnector) CreateRawTable(ctx context.Context, req *protos.Cre
 	if err != nil {
 		return nil, fmt.Errorf("unable to create raw table: %w", err)
 	}
+	if req.CommitInfo {
+		// raw tables created before history modes were in use lack the commit columns
+		for _, colName := range []string{shared.RawCommitLSNColName, shared.RawCommitTimeNsColName} {
+			_, err = createRawTableTx.ExecContext(ctx, fmt.Sprintf(addRawTableCommitInfoSQL,
+				c.rawSchema, rawTableIdentifier, strings.ToUpper(colName)))
+			if err != nil {
+				return nil, fmt.Errorf("unable to add commit columns to raw table: %w", err)
+			}
+		}
+	}
 	err = createRawTableTx.Commit()
 	if err != nil {
 		return nil, fmt.Errorf("unable to commit transaction for creation of raw table: %w", err)
@@ -658,13 +734,32 @@ func (c *SnowflakeConnector) checkIfTableExists(
 	return result.Bool, nil
 }
 
+// snowflakeVectorType is the type of a pgvector column of dimensions typeModifier,
+// VECTOR needs a fixed number of dimensions within Snowflake's limit so others stay an ARRAY
+func snowflakeVectorType(typeModifier int32) string {
+	if typeModifier <= 0 || typeModifier > maxSnowflakeVectorDimensions {
+		return "ARRAY"
+	}
+	return fmt.Sprintf("VECTOR(FLOAT, %d)", typeModifier)
+}
+
+// snowflakeVectorCast converts the VARIANT array expr to vectorType,
+// a VARIANT can't be cast to VECTOR directly so it goes through ARRAY
+func snowflakeVectorCast(expr string, vectorType string) string {
+	if vectorType == "ARRAY" {
+		return fmt.Sprintf("CAST(%s AS ARRAY)", expr)
+	}
+	return fmt.Sprintf("CAST(CAST(%s AS ARRAY) AS %s)", expr, vectorType)
+}
+
 func generateCreateTableSQLForNormalizedTable(
 	dstSchemaTable *utils.SchemaTable,
 	sourceTableSchema *protos.TableSchema,
 	softDeleteColName string,
 	syncedAtColName string,
+	normalizeMode protos.NormalizeMode,
 ) string {
-	createTableSQLArray := make([]string, 0, len(sourceTableSchema.Columns)+2)
+	createTableSQLArray := make([]string, 0, len(sourceTableSchema.Columns)+7)
 	for _, column := range sourceTableSchema.Columns {
 		genericColumnType := column.Type
 		normalizedColName := SnowflakeIdentifierNormalize(column.Name)
@@ -678,14 +773,35 @@ func generateCreateTableSQLForNormalizedTable(
 		if genericColumnType == "numeric" {
 			precision, scale := numeric.GetNumericTypeForWarehouse(column.TypeModifier, numeric.SnowflakeNumericCompatibility{})
 			sfColType = fmt.Sprintf("NUMERIC(%d,%d)", precision, scale)
+		} else if genericColumnType == string(qvalue.QValueKindVector) {
+			sfColType = snowflakeVectorType(column.TypeModifier)
 		}
 
 		createTableSQLArray = append(createTableSQLArray, fmt.Sprintf(`%s %s`, normalizedColName, sfColType))
 	}
 
+	// history modes keep one row per change or version instead of soft deleting
+	switch normalizeMode {
+	case protos.NormalizeMode_NORMALIZE_MODE_CHANGELOG:
+		createTableSQLArray = append(createTableSQLArray,
+			SnowflakeIdentifierNormalize(shared.HistoryOpColName)+" STRING",
+			SnowflakeIdentifierNormalize(shared.HistoryCommitTimeColName)+" TIMESTAMP_NTZ",
+			SnowflakeIdentifierNormalize(shared.HistoryLSNColName)+" INT",
+			SnowflakeIdentifierNormalize(shared.HistoryBeforeColName)+" VARIANT",
+			SnowflakeIdentifierNormalize(shared.HistoryBatchIDColName)+" INT")
+	case protos.NormalizeMode_NORMALIZE_MODE_SCD2:
+		// rows of the initial load are the current version of their key
+		createTableSQLArray = append(createTableSQLArray,
+			SnowflakeIdentifierNormalize(shared.HistoryLSNColName)+" INT",
+			SnowflakeIdentifierNormalize(shared.SCD2ValidFromColName)+" TIMESTAMP_NTZ",
+			SnowflakeIdentifierNormalize(shared.SCD2ValidToColName)+" TIMESTAMP_NTZ",
+			SnowflakeIdentifierNormalize(shared.SCD2IsCurrentColName)+" BOOLEAN DEFAULT TRUE",
+			SnowflakeIdentifierNormalize(shared.HistoryBatchIDColName)+" INT")
+	}
+
 	// add a _peerdb_is_deleted column to the normalized table
 	// this is boolean default false, and is used to mark records as deleted
-	if softDeleteColName != "" {
+	if softDeleteColName != "" && normalizeMode == protos.NormalizeMode_NORMALIZE_MODE_MERGE {
 		createTableSQLArray = append(createTableSQLArray, softDeleteColName+" BOOLEAN DEFAULT FALSE")
 	}
 
@@ -696,8 +812,9 @@ func generateCreateTableSQLForNormalizedTable(
 		createTableSQLArray = append(createTableSQLArray, syncedAtColName+" TIMESTAMP DEFAULT CURRENT_TIMESTAMP")
 	}
 
-	// add composite primary key to the table
-	if len(sourceTableSchema.PrimaryKeyColumns) > 0 && !sourceTableSchema.IsReplicaIdentityFull {
+	// add composite primary key to the table, history tables hold many rows per key
+	if len(sourceTableSchema.PrimaryKeyColumns) > 0 && !sourceTableSchema.IsReplicaIdentityFull &&
+		normalizeMode == protos.NormalizeMode_NORMALIZE_MODE_MERGE {
 		normalizedPrimaryKeyCols := make([]string, 0, len(sourceTableSchema.PrimaryKeyColumns))
 		for _, primaryKeyCol := range sourceTableSchema.PrimaryKeyColumns {
 			normalizedPrimaryKeyCols = append(normalizedPrimaryKeyCols,
@@ -848,3 +965,42 @@ func (c *SnowflakeConnector) CreateTablesFromExisting(ctx context.Context, req *
 		FlowJobName: req.FlowJobName,
 	}, nil
 }
+
+// RemoveTables deletes rows of tables no longer part of the mirror from the raw table and drops the tables
+func (c *SnowflakeConnector) RemoveTables(ctx context.Context, req *protos.RemoveTablesInput) error {
+	removeTablesTx, err := c.database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction for removing tables: %w", err)
+	}
+	defer func() {
+		deferErr := removeTablesTx.Rollback()
+		if deferErr != sql.ErrTxDone && deferErr != nil {
+			c.logger.Error("error rolling back transaction for removing tables", "error", deferErr)
+		}
+	}()
+
+	rawTableIdentifier := getRawTableIdentifier(req.FlowJobName)
+	for _, tableIdentifier := range req.DestinationTableIdentifiers {
+		schemaTable, err := utils.ParseSchemaTable(tableIdentifier)
+		if err != nil {
+			return err
+		}
+		_, err = removeTablesTx.ExecContext(ctx,
+			fmt.Sprintf(deleteRawTableRowsForTableSQL, c.rawSchema, rawTableIdentifier), tableIdentifier)
+		if err != nil {
+			return fmt.Errorf("unable to delete rows of table %s from raw table: %w", tableIdentifier, err)
+		}
+		if req.KeepTables {
+			continue
+		}
+		_, err = removeTablesTx.ExecContext(ctx, "DROP TABLE IF EXISTS "+snowflakeSchemaTableNormalize(schemaTable))
+		if err != nil {
+			return fmt.Errorf("unable to drop table %s: %w", tableIdentifier, err)
+		}
+	}
+
+	if err := removeTablesTx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit transaction for removing tables: %w", err)
+	}
+	return nil
+}
@@ -27,6 +27,7 @@ var qValueKindToSQLServerTypeMap = map[qvalue.QValueKind]string{
 
 	// for all array types, we use NTEXT
 	qvalue.QValueKindArrayFloat32: "NTEXT",
+	qvalue.QValueKindVector:       "NTEXT",
 	qvalue.QValueKindArrayFloat64: "NTEXT",
 	qvalue.QValueKindArrayInt32:   "NTEXT",
 	qvalue.QValueKindArrayInt64:   "NTEXT",
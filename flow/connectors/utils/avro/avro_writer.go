@@ -22,6 +22,7 @@ import (
 	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/logger"
 	"github.com/PeerDB-io/peer-flow/model"
+	"github.com/PeerDB-io/peer-flow/otel_tracing"
 )
 
 type (
@@ -170,7 +171,12 @@ func (p *peerDBOCFWriter) WriteOCF(ctx context.Context, w io.Writer) (int, error
 
 func (p *peerDBOCFWriter) WriteRecordsToS3(
 	ctx context.Context, bucketName, key string, s3Creds utils.AWSCredentialsProvider,
-) (*AvroFile, error) {
+) (_ *AvroFile, err error) {
+	ctx, span := otel_tracing.Start(ctx, "WriteAvroToS3")
+	defer func() {
+		otel_tracing.End(span, err)
+	}()
+
 	logger := logger.LoggerFromCtx(ctx)
 	s3svc, err := utils.CreateS3Client(ctx, s3Creds)
 	if err != nil {
@@ -220,7 +226,12 @@ func (p *peerDBOCFWriter) WriteRecordsToS3(
 	}, nil
 }
 
-func (p *peerDBOCFWriter) WriteRecordsToAvroFile(ctx context.Context, filePath string) (*AvroFile, error) {
+func (p *peerDBOCFWriter) WriteRecordsToAvroFile(ctx context.Context, filePath string) (_ *AvroFile, err error) {
+	ctx, span := otel_tracing.Start(ctx, "WriteAvroFile")
+	defer func() {
+		otel_tracing.End(span, err)
+	}()
+
 	file, err := os.Create(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temporary Avro file: %w", err)
@@ -77,6 +77,7 @@ func init() {
 	gob.Register(qvalue.QValueInt64{})
 	gob.Register(qvalue.QValueBoolean{})
 	gob.Register(qvalue.QValueStruct{})
+	gob.Register(qvalue.QValueArray{})
 	gob.Register(qvalue.QValueQChar{})
 	gob.Register(qvalue.QValueString{})
 	gob.Register(qvalue.QValueTimestamp{})
@@ -98,6 +99,7 @@ func init() {
 	gob.Register(qvalue.QValueINET{})
 	gob.Register(qvalue.QValueMacaddr{})
 	gob.Register(qvalue.QValueArrayFloat32{})
+	gob.Register(qvalue.QValueVector{})
 	gob.Register(qvalue.QValueArrayFloat64{})
 	gob.Register(qvalue.QValueArrayInt16{})
 	gob.Register(qvalue.QValueArrayInt32{})
@@ -107,6 +109,9 @@ func init() {
 	gob.Register(qvalue.QValueArrayTimestamp{})
 	gob.Register(qvalue.QValueArrayTimestampTZ{})
 	gob.Register(qvalue.QValueArrayBoolean{})
+	gob.Register(qvalue.QValueEnum{})
+	gob.Register(qvalue.QValueRange{})
+	gob.Register(qvalue.QValueMultirange{})
 }
 
 func (c *cdcStore[T]) initPebbleDB() error {
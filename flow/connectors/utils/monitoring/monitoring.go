@@ -15,6 +15,7 @@ import (
 	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/logger"
 	"github.com/PeerDB-io/peer-flow/model"
+	"github.com/PeerDB-io/peer-flow/peerdbenv"
 	"github.com/PeerDB-io/peer-flow/shared"
 )
 
@@ -59,6 +60,17 @@ func UpdateLatestLSNAtTargetForCDCFlow(ctx context.Context, pool *pgxpool.Pool,
 	return nil
 }
 
+func GetLatestLSNAtTargetForCDCFlow(ctx context.Context, pool *pgxpool.Pool, flowJobName string) (int64, error) {
+	var latestLSNAtTarget int64
+	err := pool.QueryRow(ctx,
+		"SELECT latest_lsn_at_target FROM peerdb_stats.cdc_flows WHERE flow_name=$1",
+		flowJobName).Scan(&latestLSNAtTarget)
+	if err != nil {
+		return 0, fmt.Errorf("[target] error while querying flow in cdc_flows: %w", err)
+	}
+	return latestLSNAtTarget, nil
+}
+
 func AddCDCBatchForFlow(ctx context.Context, pool *pgxpool.Pool, flowJobName string,
 	batchInfo CDCBatchInfo,
 ) error {
@@ -162,6 +174,10 @@ func InitializeQRepRun(
 	if err != nil {
 		return fmt.Errorf("unable to marshal flow config: %w", err)
 	}
+	cfgBytes, err = peerdbenv.EncryptCatalogData(cfgBytes)
+	if err != nil {
+		return fmt.Errorf("unable to encrypt flow config: %w", err)
+	}
 
 	_, err = pool.Exec(ctx,
 		"UPDATE peerdb_stats.qrep_runs SET config_proto = $1 WHERE flow_name = $2",
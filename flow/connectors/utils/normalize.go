@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"log/slog"
+
+	"go.temporal.io/sdk/log"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+)
+
+// TablesToNormalize leaves out destination tables without a schema, these were removed from the mirror
+// and only have rows in the raw table until removing them finishes
+func TablesToNormalize(
+	tableNames []string,
+	tableNameSchemaMapping map[string]*protos.TableSchema,
+	logger log.Logger,
+) []string {
+	tablesToNormalize := make([]string, 0, len(tableNames))
+	for _, tableName := range tableNames {
+		if _, ok := tableNameSchemaMapping[tableName]; ok {
+			tablesToNormalize = append(tablesToNormalize, tableName)
+		} else {
+			logger.Warn("skipping normalize of table removed from mirror", slog.String("table", tableName))
+		}
+	}
+	return tablesToNormalize
+}
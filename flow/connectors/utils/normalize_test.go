@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+	"github.com/PeerDB-io/peer-flow/logger"
+)
+
+func TestTablesToNormalizeAfterRemoval(t *testing.T) {
+	// public.removed was taken out of the mirror with rows of it left in the raw table
+	tableNameSchemaMapping := map[string]*protos.TableSchema{
+		"public.kept":  {TableIdentifier: "public.kept"},
+		"public.other": {TableIdentifier: "public.other"},
+	}
+	tableNames := []string{"public.kept", "public.removed", "public.other"}
+
+	tablesToNormalize := TablesToNormalize(tableNames, tableNameSchemaMapping, logger.LoggerFromCtx(context.Background()))
+	if !slices.Equal(tablesToNormalize, []string{"public.kept", "public.other"}) {
+		t.Errorf("unexpected tables to normalize %v", tablesToNormalize)
+	}
+}
@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+)
+
+// SecretProvider resolves the path of a secret reference <scheme>:<path> to the secret value
+type SecretProvider interface {
+	Resolve(ctx context.Context, path string) (string, error)
+}
+
+// fileSecretProvider reads file:///run/secrets/pg, trailing newlines are trimmed
+type fileSecretProvider struct{}
+
+func (fileSecretProvider) Resolve(_ context.Context, path string) (string, error) {
+	content, err := os.ReadFile(strings.TrimPrefix(path, "//"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// envSecretProvider reads env:PG_PASS
+type envSecretProvider struct{}
+
+func (envSecretProvider) Resolve(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+var (
+	secretProvidersLock sync.RWMutex
+	secretProviders     = map[string]SecretProvider{
+		"file": fileSecretProvider{},
+		"env":  envSecretProvider{},
+	}
+)
+
+// RegisterSecretProvider makes references with the given scheme resolve through provider,
+// replacing any provider previously registered for it
+func RegisterSecretProvider(scheme string, provider SecretProvider) {
+	secretProvidersLock.Lock()
+	defer secretProvidersLock.Unlock()
+	secretProviders[scheme] = provider
+}
+
+// ResolveSecret returns the value a secret reference points to,
+// values without a registered scheme are literal secrets and returned as is
+func ResolveSecret(ctx context.Context, value string) (string, error) {
+	scheme, path, ok := strings.Cut(value, ":")
+	if !ok {
+		return value, nil
+	}
+	secretProvidersLock.RLock()
+	provider, ok := secretProviders[scheme]
+	secretProvidersLock.RUnlock()
+	if !ok {
+		return value, nil
+	}
+	return provider.Resolve(ctx, path)
+}
+
+// string fields of any peer config message which may hold a secret reference
+var secretFieldNames = map[protoreflect.Name]struct{}{
+	"password":          {},
+	"private_key":       {},
+	"private_key_id":    {},
+	"access_key_id":     {},
+	"secret_access_key": {},
+	"api_key":           {},
+	"auth_value":        {},
+	"signing_secret":    {},
+}
+
+// ResolvePeerSecrets returns a copy of peer with secret references replaced by their values,
+// peer itself is left untouched so resolved secrets never make it back to the catalog
+func ResolvePeerSecrets(ctx context.Context, peer *protos.Peer) (*protos.Peer, error) {
+	resolved, err := ResolveConfigSecrets(ctx, peer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve secrets of peer %s: %w", peer.Name, err)
+	}
+	return resolved, nil
+}
+
+// ResolveConfigSecrets is ResolvePeerSecrets for a bare peer config, such as a PostgresConfig
+func ResolveConfigSecrets[T proto.Message](ctx context.Context, config T) (T, error) {
+	resolved := proto.Clone(config).(T)
+	if err := resolveMessageSecrets(ctx, resolved.ProtoReflect()); err != nil {
+		var none T
+		return none, err
+	}
+	return resolved, nil
+}
+
+func resolveMessageSecrets(ctx context.Context, msg protoreflect.Message) error {
+	var nested []protoreflect.Message
+	var secretFields []protoreflect.FieldDescriptor
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsMap():
+			if fd.MapValue().Kind() == protoreflect.MessageKind {
+				v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+					nested = append(nested, mv.Message())
+					return true
+				})
+			}
+		case fd.IsList():
+			if fd.Kind() == protoreflect.MessageKind {
+				for i := range v.List().Len() {
+					nested = append(nested, v.List().Get(i).Message())
+				}
+			}
+		case fd.Kind() == protoreflect.MessageKind:
+			nested = append(nested, v.Message())
+		case fd.Kind() == protoreflect.StringKind:
+			if _, ok := secretFieldNames[fd.Name()]; ok {
+				secretFields = append(secretFields, fd)
+			}
+		}
+		return true
+	})
+
+	for _, fd := range secretFields {
+		value, err := ResolveSecret(ctx, msg.Get(fd).String())
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", fd.FullName(), err)
+		}
+		msg.Set(fd, protoreflect.ValueOfString(value))
+	}
+	for _, nestedMsg := range nested {
+		if err := resolveMessageSecrets(ctx, nestedMsg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
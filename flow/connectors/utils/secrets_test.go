@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+)
+
+type staticSecretProvider map[string]string
+
+func (p staticSecretProvider) Resolve(_ context.Context, path string) (string, error) {
+	return p[path], nil
+}
+
+func TestResolvePeerSecrets(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "pg")
+	require.NoError(t, os.WriteFile(secretFile, []byte("from-file\n"), 0o600))
+	t.Setenv("PEERDB_TEST_SSH_PASSWORD", "from-env")
+	RegisterSecretProvider("static", staticSecretProvider{"ssh/key": "from-static"})
+
+	peer := &protos.Peer{
+		Name: "pg",
+		Config: &protos.Peer_PostgresConfig{PostgresConfig: &protos.PostgresConfig{
+			Host:     "file:///not/a/secret",
+			Password: "file://" + secretFile,
+			SshConfig: &protos.SSHConfig{
+				Password:   "env:PEERDB_TEST_SSH_PASSWORD",
+				PrivateKey: "static:ssh/key",
+			},
+		}},
+	}
+	resolved, err := ResolvePeerSecrets(context.Background(), peer)
+	require.NoError(t, err)
+
+	pgConfig := resolved.GetPostgresConfig()
+	require.Equal(t, "file:///not/a/secret", pgConfig.Host)
+	require.Equal(t, "from-file", pgConfig.Password)
+	require.Equal(t, "from-env", pgConfig.SshConfig.Password)
+	require.Equal(t, "from-static", pgConfig.SshConfig.PrivateKey)
+	// catalog copy keeps the references
+	require.Equal(t, "env:PEERDB_TEST_SSH_PASSWORD", peer.GetPostgresConfig().SshConfig.Password)
+
+	literal, err := ResolveSecret(context.Background(), "pass:word")
+	require.NoError(t, err)
+	require.Equal(t, "pass:word", literal)
+
+	_, err = ResolveSecret(context.Background(), "env:PEERDB_TEST_UNSET_SECRET")
+	require.Error(t, err)
+}
@@ -9,54 +9,65 @@ import (
 	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/model"
 	"github.com/PeerDB-io/peer-flow/model/qvalue"
+	"github.com/PeerDB-io/peer-flow/shared"
 )
 
 func RecordsToRawTableStream[Items model.Items](req *model.RecordsToStreamRequest[Items]) (*model.QRecordStream, error) {
 	recordStream := model.NewQRecordStream(1 << 17)
-	recordStream.SetSchema(qvalue.QRecordSchema{
-		Fields: []qvalue.QField{
-			{
-				Name:     "_peerdb_uid",
-				Type:     qvalue.QValueKindString,
-				Nullable: false,
-			},
-			{
-				Name:     "_peerdb_timestamp",
-				Type:     qvalue.QValueKindInt64,
-				Nullable: false,
-			},
-			{
-				Name:     "_peerdb_destination_table_name",
-				Type:     qvalue.QValueKindString,
-				Nullable: false,
-			},
-			{
-				Name:     "_peerdb_data",
-				Type:     qvalue.QValueKindString,
-				Nullable: false,
-			},
-			{
-				Name:     "_peerdb_record_type",
-				Type:     qvalue.QValueKindInt64,
-				Nullable: true,
-			},
-			{
-				Name:     "_peerdb_match_data",
-				Type:     qvalue.QValueKindString,
-				Nullable: true,
-			},
-			{
-				Name:     "_peerdb_batch_id",
-				Type:     qvalue.QValueKindInt64,
-				Nullable: true,
-			},
-			{
-				Name:     "_peerdb_unchanged_toast_columns",
-				Type:     qvalue.QValueKindString,
-				Nullable: true,
-			},
+	fields := []qvalue.QField{
+		{
+			Name:     "_peerdb_uid",
+			Type:     qvalue.QValueKindString,
+			Nullable: false,
 		},
-	})
+		{
+			Name:     "_peerdb_timestamp",
+			Type:     qvalue.QValueKindInt64,
+			Nullable: false,
+		},
+		{
+			Name:     "_peerdb_destination_table_name",
+			Type:     qvalue.QValueKindString,
+			Nullable: false,
+		},
+		{
+			Name:     "_peerdb_data",
+			Type:     qvalue.QValueKindString,
+			Nullable: false,
+		},
+		{
+			Name:     "_peerdb_record_type",
+			Type:     qvalue.QValueKindInt64,
+			Nullable: true,
+		},
+		{
+			Name:     "_peerdb_match_data",
+			Type:     qvalue.QValueKindString,
+			Nullable: true,
+		},
+		{
+			Name:     "_peerdb_batch_id",
+			Type:     qvalue.QValueKindInt64,
+			Nullable: true,
+		},
+		{
+			Name:     "_peerdb_unchanged_toast_columns",
+			Type:     qvalue.QValueKindString,
+			Nullable: true,
+		},
+	}
+	if req.CommitInfo {
+		fields = append(fields, qvalue.QField{
+			Name:     shared.RawCommitLSNColName,
+			Type:     qvalue.QValueKindInt64,
+			Nullable: true,
+		}, qvalue.QField{
+			Name:     shared.RawCommitTimeNsColName,
+			Type:     qvalue.QValueKindInt64,
+			Nullable: true,
+		})
+	}
+	recordStream.SetSchema(qvalue.QRecordSchema{Fields: fields})
 
 	go func() {
 		for record := range req.GetRecords() {
@@ -66,6 +77,12 @@ func RecordsToRawTableStream[Items model.Items](req *model.RecordsToStreamReques
 				recordStream.Close(err)
 				return
 			} else {
+				if req.CommitInfo {
+					qRecord = append(qRecord,
+						qvalue.QValueInt64{Val: record.GetCheckpointID()},
+						qvalue.QValueInt64{Val: record.GetCommitTime().UnixNano()},
+					)
+				}
 				recordStream.Records <- qRecord
 			}
 		}
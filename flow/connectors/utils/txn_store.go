@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"go.temporal.io/sdk/log"
+
+	"github.com/PeerDB-io/peer-flow/model"
+)
+
+// txnStoreTableName keys buffered records by position instead of primary key
+const txnStoreTableName = "_peerdb_txn"
+
+type txnStoreSubxact struct {
+	xid   uint32
+	first int
+}
+
+// TxnStore buffers the records of an in-progress transaction in order until it commits or aborts,
+// spilling to disk with the same thresholds as the cdcStore
+type TxnStore[Items model.Items] struct {
+	store *cdcStore[Items]
+	// position of the first record of each subtransaction, in order
+	subxacts []txnStoreSubxact
+	len      int
+}
+
+func NewTxnStore[Items model.Items](flowJobName string, xid uint32) *TxnStore[Items] {
+	return &TxnStore[Items]{
+		store: NewCDCStore[Items](fmt.Sprintf("%s_txn_%d", flowJobName, xid)),
+	}
+}
+
+func txnStoreKey(pos int) model.TableWithPkey {
+	key := model.TableWithPkey{TableName: txnStoreTableName}
+	binary.BigEndian.PutUint64(key.PkeyColVal[:], uint64(pos))
+	return key
+}
+
+// Append adds rec, a change of subtransaction subXid, after the records buffered so far
+func (t *TxnStore[Items]) Append(logger log.Logger, subXid uint32, rec model.Record[Items]) error {
+	if len(t.subxacts) == 0 || t.subxacts[len(t.subxacts)-1].xid != subXid {
+		t.subxacts = append(t.subxacts, txnStoreSubxact{xid: subXid, first: t.len})
+	}
+	if err := t.store.Set(logger, txnStoreKey(t.len), rec); err != nil {
+		return err
+	}
+	t.len += 1
+	return nil
+}
+
+// AbortSubxact discards the records of subtransaction subXid along with every record buffered after them,
+// which belong to its own subtransactions
+func (t *TxnStore[Items]) AbortSubxact(subXid uint32) {
+	for i, subxact := range t.subxacts {
+		if subxact.xid == subXid {
+			t.len = subxact.first
+			t.subxacts = t.subxacts[:i]
+			return
+		}
+	}
+}
+
+func (t *TxnStore[Items]) Len() int {
+	return t.len
+}
+
+// Iterate calls f with every buffered record in order
+func (t *TxnStore[Items]) Iterate(f func(model.Record[Items]) error) error {
+	_, err := t.IterateFrom(0, func(rec model.Record[Items]) (bool, error) {
+		return true, f(rec)
+	})
+	return err
+}
+
+// IterateFrom calls f with the buffered records in order from position start, until f returns false,
+// returning the position after the last record passed to f
+func (t *TxnStore[Items]) IterateFrom(start int, f func(model.Record[Items]) (bool, error)) (int, error) {
+	for pos := start; pos < t.len; pos++ {
+		rec, ok, err := t.store.Get(txnStoreKey(pos))
+		if err != nil {
+			return pos, err
+		} else if !ok {
+			return pos, fmt.Errorf("missing record %d of transaction", pos)
+		}
+		if more, err := f(rec); err != nil || !more {
+			return pos + 1, err
+		}
+	}
+	return t.len, nil
+}
+
+func (t *TxnStore[Items]) Close() error {
+	return t.store.Close()
+}
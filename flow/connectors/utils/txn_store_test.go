@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/PeerDB-io/peer-flow/model"
+)
+
+func TestTxnStoreOrderAndSubxactAbort(t *testing.T) {
+	t.Parallel()
+	txnStore := NewTxnStore[model.RecordItems]("test_txn_store", 1)
+	txnStore.store.numRecordsSwitchThreshold = 2
+
+	// xid 1 is the top level transaction, 2 and 3 its subtransactions
+	for i, xid := range []uint32{1, 1, 2, 3, 3} {
+		_, rec := genKeyAndRec(t)
+		rec.(*model.InsertRecord[model.RecordItems]).CommitID = int64(i)
+		require.NoError(t, txnStore.Append(slog.Default(), xid, rec))
+	}
+	require.NotNil(t, txnStore.store.pebbleDB)
+
+	txnStore.AbortSubxact(2)
+	require.Equal(t, 2, txnStore.Len())
+
+	_, rec := genKeyAndRec(t)
+	rec.(*model.InsertRecord[model.RecordItems]).CommitID = 5
+	require.NoError(t, txnStore.Append(slog.Default(), 1, rec))
+
+	var commitIDs []int64
+	require.NoError(t, txnStore.Iterate(func(rec model.Record[model.RecordItems]) error {
+		commitIDs = append(commitIDs, rec.(*model.InsertRecord[model.RecordItems]).CommitID)
+		return nil
+	}))
+	require.Equal(t, []int64{0, 1, 5}, commitIDs)
+
+	require.NoError(t, txnStore.Close())
+}
+
+func TestTxnStoreIterateFrom(t *testing.T) {
+	t.Parallel()
+	txnStore := NewTxnStore[model.RecordItems]("test_txn_store_from", 1)
+	for i := range 5 {
+		_, rec := genKeyAndRec(t)
+		rec.(*model.InsertRecord[model.RecordItems]).CommitID = int64(i)
+		require.NoError(t, txnStore.Append(slog.Default(), 1, rec))
+	}
+
+	// consume the transaction two records at a time, as batches split it
+	var batches [][]int64
+	for pos := 0; pos < txnStore.Len(); {
+		var batch []int64
+		next, err := txnStore.IterateFrom(pos, func(rec model.Record[model.RecordItems]) (bool, error) {
+			batch = append(batch, rec.(*model.InsertRecord[model.RecordItems]).CommitID)
+			return len(batch) < 2, nil
+		})
+		require.NoError(t, err)
+		require.Greater(t, next, pos)
+		batches = append(batches, batch)
+		pos = next
+	}
+	require.Equal(t, [][]int64{{0, 1}, {2, 3}, {4}}, batches)
+
+	require.NoError(t, txnStore.Close())
+}
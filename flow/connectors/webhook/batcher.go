@@ -0,0 +1,229 @@
+package connwebhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+)
+
+const (
+	signatureHeader = "X-PeerDB-Signature"
+	timestampHeader = "X-PeerDB-Timestamp"
+)
+
+type webhookPayload struct {
+	body         []byte
+	checkpointID int64
+}
+
+type webhookStatusError struct {
+	statusCode int
+	body       string
+}
+
+func (e *webhookStatusError) Error() string {
+	return fmt.Sprintf("webhook responded with status %d: %s", e.statusCode, e.body)
+}
+
+func webhookTimeout(config *protos.WebhookConfig) time.Duration {
+	if config.TimeoutSeconds == 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(config.TimeoutSeconds) * time.Second
+}
+
+func webhookBatchMaxRecords(config *protos.WebhookConfig) int {
+	if config.BatchMaxRecords == 0 {
+		return 100
+	}
+	return int(config.BatchMaxRecords)
+}
+
+func webhookBatchMaxBytes(config *protos.WebhookConfig) int {
+	if config.BatchMaxBytes == 0 {
+		return 1 << 20
+	}
+	return int(config.BatchMaxBytes)
+}
+
+func webhookMaxRetries(config *protos.WebhookConfig) int {
+	if config.MaxRetries == 0 {
+		return 5
+	}
+	return int(config.MaxRetries)
+}
+
+func webhookBackoff(config *protos.WebhookConfig) (time.Duration, time.Duration) {
+	initial := 500 * time.Millisecond
+	if config.InitialBackoffMs != 0 {
+		initial = time.Duration(config.InitialBackoffMs) * time.Millisecond
+	}
+	maximum := 30 * time.Second
+	if config.MaxBackoffMs != 0 {
+		maximum = time.Duration(config.MaxBackoffMs) * time.Millisecond
+	}
+	return initial, max(initial, maximum)
+}
+
+// webhookBatcher accumulates payloads into a JSON array until a count or size cap is hit,
+// onFlushed is called with the highest checkpoint of every batch the endpoint accepted (or skipped)
+type webhookBatcher struct {
+	conn        *WebhookConnector
+	onFlushed   func(int64)
+	flowJobName string
+	buf         bytes.Buffer
+	count       int
+	checkpoint  int64
+	lock        sync.Mutex
+}
+
+func (c *WebhookConnector) newBatcher(flowJobName string, onFlushed func(int64)) *webhookBatcher {
+	return &webhookBatcher{
+		conn:        c,
+		onFlushed:   onFlushed,
+		flowJobName: flowJobName,
+	}
+}
+
+func (b *webhookBatcher) Add(ctx context.Context, payload webhookPayload) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.count > 0 && b.buf.Len()+len(payload.body)+2 > webhookBatchMaxBytes(b.conn.config) {
+		if err := b.flushLocked(ctx); err != nil {
+			return err
+		}
+	}
+
+	if b.count == 0 {
+		b.buf.WriteByte('[')
+	} else {
+		b.buf.WriteByte(',')
+	}
+	b.buf.Write(payload.body)
+	b.count += 1
+	b.checkpoint = max(b.checkpoint, payload.checkpointID)
+
+	if b.count >= webhookBatchMaxRecords(b.conn.config) || b.buf.Len() >= webhookBatchMaxBytes(b.conn.config) {
+		return b.flushLocked(ctx)
+	}
+	return nil
+}
+
+func (b *webhookBatcher) Flush(ctx context.Context) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.flushLocked(ctx)
+}
+
+func (b *webhookBatcher) flushLocked(ctx context.Context) error {
+	if b.count == 0 {
+		return nil
+	}
+	b.buf.WriteByte(']')
+
+	if err := b.conn.send(ctx, b.buf.Bytes()); err != nil {
+		var statusErr *webhookStatusError
+		if b.conn.config.FailurePolicy != protos.WebhookFailurePolicy_WEBHOOK_FAILURE_POLICY_SKIP ||
+			!errors.As(err, &statusErr) {
+			// keep the batch open so it may be flushed again
+			b.buf.Truncate(b.buf.Len() - 1)
+			return err
+		}
+		b.conn.logger.Warn("[webhook] skipping batch rejected by endpoint",
+			slog.Int("records", b.count), slog.Any("error", err))
+		_ = b.conn.LogFlowInfo(ctx, b.flowJobName,
+			fmt.Sprintf("skipped webhook batch of %d records: %v", b.count, err))
+	}
+
+	b.onFlushed(b.checkpoint)
+	b.buf.Reset()
+	b.count = 0
+	return nil
+}
+
+// send POSTs body, retrying with exponential backoff on transport errors, 429 and 5xx
+func (c *WebhookConnector) send(ctx context.Context, body []byte) error {
+	backoff, maxBackoff := webhookBackoff(c.config)
+	maxRetries := webhookMaxRetries(c.config)
+	for attempt := 0; ; attempt++ {
+		err := c.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+
+		var statusErr *webhookStatusError
+		retryable := !errors.As(err, &statusErr) ||
+			statusErr.statusCode == http.StatusTooManyRequests || statusErr.statusCode >= 500
+		if !retryable || attempt >= maxRetries {
+			return err
+		}
+
+		c.logger.Warn("[webhook] request failed, retrying",
+			slog.Int("attempt", attempt+1), slog.Duration("backoff", backoff), slog.Any("error", err))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+func (c *WebhookConnector) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.Url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range c.config.Headers {
+		req.Header.Set(k, v)
+	}
+	if c.config.AuthValue != "" {
+		authHeader := c.config.AuthHeader
+		if authHeader == "" {
+			authHeader = "Authorization"
+		}
+		req.Header.Set(authHeader, c.config.AuthValue)
+	}
+	if c.config.SigningSecret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(timestampHeader, timestamp)
+		req.Header.Set(signatureHeader, "sha256="+signPayload(c.config.SigningSecret, timestamp, body))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &webhookStatusError{statusCode: resp.StatusCode, body: string(respBody)}
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// signPayload computes hex(HMAC-SHA256(secret, timestamp + "." + body)),
+// including the timestamp lets receivers reject replayed requests
+func signPayload(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,107 @@
+package connwebhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/log"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+)
+
+type recordingServer struct {
+	bodies   [][]json.RawMessage
+	failures int
+	lock     sync.Mutex
+}
+
+func (s *recordingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.failures > 0 {
+		s.failures -= 1
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	timestamp := r.Header.Get(timestampHeader)
+	if r.Header.Get(signatureHeader) != "sha256="+signPayload("secret", timestamp, body) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var batch []json.RawMessage
+	if err := json.Unmarshal(body, &batch); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.bodies = append(s.bodies, batch)
+	w.WriteHeader(http.StatusOK)
+}
+
+func newTestConnector(t *testing.T, url string, config *protos.WebhookConfig) *WebhookConnector {
+	t.Helper()
+	config.Url = url
+	config.SigningSecret = "secret"
+	config.InitialBackoffMs = 1
+	config.MaxBackoffMs = 2
+	return &WebhookConnector{
+		client: http.DefaultClient,
+		config: config,
+		logger: log.NewStructuredLogger(slog.Default()),
+	}
+}
+
+func TestBatcherSplitsOnRecordCount(t *testing.T) {
+	srv := &recordingServer{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	var flushed int64
+	conn := newTestConnector(t, ts.URL, &protos.WebhookConfig{BatchMaxRecords: 2})
+	batcher := conn.newBatcher("test", func(checkpoint int64) { flushed = checkpoint })
+	for i := range int64(5) {
+		require.NoError(t, batcher.Add(context.Background(), webhookPayload{body: []byte(`{"a":1}`), checkpointID: i + 1}))
+	}
+	require.Equal(t, int64(4), flushed)
+	require.NoError(t, batcher.Flush(context.Background()))
+	require.Equal(t, int64(5), flushed)
+
+	require.Len(t, srv.bodies, 3)
+	require.Len(t, srv.bodies[0], 2)
+	require.Len(t, srv.bodies[2], 1)
+}
+
+func TestBatcherRetriesServerErrors(t *testing.T) {
+	srv := &recordingServer{failures: 2}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	conn := newTestConnector(t, ts.URL, &protos.WebhookConfig{MaxRetries: 3})
+	batcher := conn.newBatcher("test", func(int64) {})
+	require.NoError(t, batcher.Add(context.Background(), webhookPayload{body: []byte(`1`), checkpointID: 1}))
+	require.NoError(t, batcher.Flush(context.Background()))
+	require.Len(t, srv.bodies, 1)
+}
+
+func TestBatcherFailsAfterRetries(t *testing.T) {
+	srv := &recordingServer{failures: 10}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	var flushed int64
+	conn := newTestConnector(t, ts.URL, &protos.WebhookConfig{MaxRetries: 2})
+	batcher := conn.newBatcher("test", func(checkpoint int64) { flushed = checkpoint })
+	require.NoError(t, batcher.Add(context.Background(), webhookPayload{body: []byte(`1`), checkpointID: 1}))
+	err := batcher.Flush(context.Background())
+	var statusErr *webhookStatusError
+	require.ErrorAs(t, err, &statusErr)
+	require.Equal(t, http.StatusServiceUnavailable, statusErr.statusCode)
+	require.Zero(t, flushed)
+}
@@ -0,0 +1,87 @@
+package connwebhook
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+	"github.com/PeerDB-io/peer-flow/model"
+)
+
+func (*WebhookConnector) SetupQRepMetadataTables(_ context.Context, _ *protos.QRepConfig) error {
+	return nil
+}
+
+func (c *WebhookConnector) SyncQRepRecords(
+	ctx context.Context,
+	config *protos.QRepConfig,
+	partition *protos.QRepPartition,
+	stream *model.QRecordStream,
+) (int, error) {
+	startTime := time.Now()
+	numRecords := atomic.Int64{}
+	schema := stream.Schema()
+	batcher := c.newBatcher(config.FlowJobName, func(int64) {})
+
+	queueCtx, queueErr := context.WithCancelCause(ctx)
+	pool, err := c.createPool(queueCtx, config.Script, config.FlowJobName, batcher, queueErr)
+	if err != nil {
+		return 0, err
+	}
+	defer pool.Close()
+
+Loop:
+	for {
+		select {
+		case qrecord, ok := <-stream.Records:
+			if !ok {
+				c.logger.Info("flushing batches because no more records")
+				break Loop
+			}
+
+			pool.Run(func(ls *lua.LState) []webhookPayload {
+				items := model.NewRecordItems(len(qrecord))
+				for i, val := range qrecord {
+					items.AddColumn(schema.Fields[i].Name, val)
+				}
+				record := &model.InsertRecord[model.RecordItems]{
+					BaseRecord:           model.BaseRecord{},
+					Items:                items,
+					SourceTableName:      config.WatermarkTable,
+					DestinationTableName: config.DestinationTableIdentifier,
+					CommitID:             0,
+				}
+
+				results, err := runScript(ls, config.FlowJobName, record)
+				if err != nil {
+					queueErr(err)
+					return nil
+				}
+				numRecords.Add(1)
+				return results
+			})
+
+		case <-queueCtx.Done():
+			break Loop
+		}
+	}
+
+	if err := pool.Wait(queueCtx); err != nil {
+		return 0, err
+	}
+	if err := batcher.Flush(queueCtx); err != nil {
+		return 0, fmt.Errorf("[webhook] final flush error: %w", err)
+	}
+	if err := context.Cause(queueCtx); err != nil {
+		return 0, err
+	}
+
+	if err := c.FinishQRepPartition(ctx, partition, config.FlowJobName, startTime); err != nil {
+		return 0, err
+	}
+	return int(numRecords.Load()), nil
+}
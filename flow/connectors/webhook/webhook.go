@@ -0,0 +1,259 @@
+package connwebhook
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	"go.temporal.io/sdk/log"
+
+	metadataStore "github.com/PeerDB-io/peer-flow/connectors/external_metadata"
+	"github.com/PeerDB-io/peer-flow/connectors/utils"
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+	"github.com/PeerDB-io/peer-flow/logger"
+	"github.com/PeerDB-io/peer-flow/model"
+	"github.com/PeerDB-io/peer-flow/peerdbenv"
+	"github.com/PeerDB-io/peer-flow/prom_metrics"
+	"github.com/PeerDB-io/peer-flow/pua"
+	"github.com/PeerDB-io/peer-flow/shared"
+)
+
+type WebhookConnector struct {
+	*metadataStore.PostgresMetadata
+	client *http.Client
+	config *protos.WebhookConfig
+	logger log.Logger
+}
+
+func NewWebhookConnector(
+	ctx context.Context,
+	config *protos.WebhookConfig,
+) (*WebhookConnector, error) {
+	endpoint, err := url.Parse(config.Url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook url: %w", err)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported webhook url scheme: %s", endpoint.Scheme)
+	}
+
+	pgMetadata, err := metadataStore.NewPostgresMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebhookConnector{
+		PostgresMetadata: pgMetadata,
+		client:           &http.Client{Timeout: webhookTimeout(config)},
+		config:           config,
+		logger:           logger.LoggerFromCtx(ctx),
+	}, nil
+}
+
+func (c *WebhookConnector) Close() error {
+	if c != nil {
+		c.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// probing the endpoint would deliver a bogus event, only the url is validated on construction
+func (c *WebhookConnector) ConnectionActive(_ context.Context) error {
+	return nil
+}
+
+func (c *WebhookConnector) CreateRawTable(ctx context.Context, req *protos.CreateRawTableInput) (*protos.CreateRawTableOutput, error) {
+	return &protos.CreateRawTableOutput{TableIdentifier: "n/a"}, nil
+}
+
+func (c *WebhookConnector) ReplayTableSchemaDeltas(_ context.Context, flowJobName string, schemaDeltas []*protos.TableSchemaDelta) error {
+	return nil
+}
+
+func lvalueToWebhookPayload(ls *lua.LState, value lua.LValue) ([]byte, error) {
+	switch v := value.(type) {
+	case lua.LString:
+		return shared.UnsafeFastStringToReadOnlyBytes(string(v)), nil
+	case *lua.LTable:
+		payload, err := utils.LVAsReadOnlyBytes(ls, ls.GetField(v, "value"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value, %w", err)
+		}
+		return payload, nil
+	case *lua.LNilType:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("script returned invalid value: %s", value)
+	}
+}
+
+func (c *WebhookConnector) createPool(
+	ctx context.Context,
+	script string,
+	flowJobName string,
+	batcher *webhookBatcher,
+	queueErr func(error),
+) (*utils.LPool[[]webhookPayload], error) {
+	return utils.LuaPool(func() (*lua.LState, error) {
+		ls, err := utils.LoadScript(ctx, script, func(ls *lua.LState) int {
+			top := ls.GetTop()
+			ss := make([]string, top)
+			for i := range top {
+				ss[i] = ls.ToStringMeta(ls.Get(i + 1)).String()
+			}
+			_ = c.LogFlowInfo(ctx, flowJobName, strings.Join(ss, "\t"))
+			return 0
+		})
+		if err != nil {
+			return nil, err
+		}
+		if script == "" {
+			ls.Env.RawSetString("onRecord", ls.NewFunction(utils.DefaultOnRecord))
+		}
+		return ls, nil
+	}, func(payloads []webhookPayload) {
+		for _, payload := range payloads {
+			if err := batcher.Add(ctx, payload); err != nil {
+				queueErr(err)
+				return
+			}
+		}
+	})
+}
+
+// runScript calls onRecord and collects the payloads it returns, nil payloads are dropped
+func runScript(ls *lua.LState, flowJobName string, record model.Record[model.RecordItems]) ([]webhookPayload, error) {
+	lfn := ls.Env.RawGetString("onRecord")
+	fn, ok := lfn.(*lua.LFunction)
+	if !ok {
+		return nil, fmt.Errorf("script should define `onRecord` as function, not %s", lfn)
+	}
+
+	ls.Push(fn)
+	ls.Push(pua.LuaRecord.New(ls, record))
+	if err := ls.PCall(1, -1, nil); err != nil {
+		prom_metrics.LuaScriptErrors.WithLabelValues(flowJobName).Inc()
+		return nil, fmt.Errorf("script failed: %w", err)
+	}
+
+	args := ls.GetTop()
+	results := make([]webhookPayload, 0, args)
+	for i := range args {
+		body, err := lvalueToWebhookPayload(ls, ls.Get(i-args))
+		if err != nil {
+			return nil, err
+		}
+		if body != nil {
+			results = append(results, webhookPayload{
+				body:         body,
+				checkpointID: record.GetCheckpointID(),
+			})
+		}
+	}
+	ls.SetTop(0)
+	return results, nil
+}
+
+func (c *WebhookConnector) SyncRecords(ctx context.Context, req *model.SyncRecordsRequest[model.RecordItems]) (*model.SyncResponse, error) {
+	numRecords := atomic.Int64{}
+	tableNameRowsMapping := utils.InitialiseTableRowsMap(req.TableMappings)
+
+	lastSeenLSN := atomic.Int64{}
+	batcher := c.newBatcher(req.FlowJobName, func(checkpointID int64) {
+		shared.AtomicInt64Max(&lastSeenLSN, checkpointID)
+	})
+
+	queueCtx, queueErr := context.WithCancelCause(ctx)
+	pool, err := c.createPool(queueCtx, req.Script, req.FlowJobName, batcher, queueErr)
+	if err != nil {
+		return nil, err
+	}
+	defer pool.Close()
+
+	flushLoopDone := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(peerdbenv.PeerDBQueueFlushTimeoutSeconds())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-flushLoopDone:
+				return
+			// flush loop doesn't block processing new messages
+			case <-ticker.C:
+				if err := batcher.Flush(queueCtx); err != nil {
+					queueErr(err)
+					return
+				}
+				lastSeen := lastSeenLSN.Load()
+				if lastSeen > req.ConsumedOffset.Load() {
+					if err := c.SetLastOffset(ctx, req.FlowJobName, lastSeen); err != nil {
+						c.logger.Warn("[webhook] SetLastOffset error", slog.Any("error", err))
+					} else {
+						shared.AtomicInt64Max(req.ConsumedOffset, lastSeen)
+						c.logger.Info("processBatch", slog.Int64("updated last offset", lastSeen))
+					}
+				}
+			}
+		}
+	}()
+
+Loop:
+	for {
+		select {
+		case record, ok := <-req.Records.GetRecords():
+			if !ok {
+				c.logger.Info("flushing batches because no more records")
+				break Loop
+			}
+
+			pool.Run(func(ls *lua.LState) []webhookPayload {
+				results, err := runScript(ls, req.FlowJobName, record)
+				if err != nil {
+					queueErr(err)
+					return nil
+				}
+				if len(results) > 0 {
+					record.PopulateCountMap(tableNameRowsMapping)
+				}
+				numRecords.Add(1)
+				return results
+			})
+
+		case <-queueCtx.Done():
+			break Loop
+		}
+	}
+
+	close(flushLoopDone)
+	if err := pool.Wait(queueCtx); err != nil {
+		return nil, err
+	}
+	if err := batcher.Flush(queueCtx); err != nil {
+		return nil, fmt.Errorf("[webhook] final flush error: %w", err)
+	}
+	if err := context.Cause(queueCtx); err != nil {
+		return nil, err
+	}
+
+	lastCheckpoint := req.Records.GetLastCheckpoint()
+	if err := c.FinishBatch(ctx, req.FlowJobName, req.SyncBatchID, lastCheckpoint); err != nil {
+		return nil, err
+	}
+
+	return &model.SyncResponse{
+		CurrentSyncBatchID:     req.SyncBatchID,
+		LastSyncedCheckpointID: lastCheckpoint,
+		NumRecordsSynced:       numRecords.Load(),
+		TableNameRowsMapping:   tableNameRowsMapping,
+		TableSchemaDeltas:      req.Records.SchemaDeltas,
+	}, nil
+}
@@ -8,6 +8,7 @@ It's simplified to only parse the subset which `hstore_out` outputs.
 package datatypes
 
 import (
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -226,3 +227,59 @@ func ParseHstore(s string) (string, error) {
 
 	return string(jsonBytes), nil
 }
+
+// HstoreBinaryToText converts the binary send format of hstore, a pair count followed by
+// length prefixed keys and values with -1 for NULL, to the text format hstore_out outputs
+func HstoreBinaryToText(b []byte) (string, error) {
+	readString := func() (*string, error) {
+		if len(b) < 4 {
+			return nil, errors.New("invalid hstore: truncated length")
+		}
+		length := int32(binary.BigEndian.Uint32(b))
+		b = b[4:]
+		if length == -1 {
+			return nil, nil
+		}
+		if length < 0 || int(length) > len(b) {
+			return nil, fmt.Errorf("invalid hstore: length %d out of bounds", length)
+		}
+		s := string(b[:length])
+		b = b[length:]
+		return &s, nil
+	}
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+	}
+
+	if len(b) < 4 {
+		return "", errors.New("invalid hstore: missing pair count")
+	}
+	numPairs := int(binary.BigEndian.Uint32(b))
+	b = b[4:]
+
+	var sb strings.Builder
+	for i := range numPairs {
+		key, err := readString()
+		if err != nil {
+			return "", err
+		} else if key == nil {
+			return "", errors.New("invalid hstore: NULL key")
+		}
+		value, err := readString()
+		if err != nil {
+			return "", err
+		}
+
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(quote(*key))
+		sb.WriteString("=>")
+		if value == nil {
+			sb.WriteString("NULL")
+		} else {
+			sb.WriteString(quote(*value))
+		}
+	}
+	return sb.String(), nil
+}
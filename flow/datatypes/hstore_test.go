@@ -101,3 +101,28 @@ func TestHStoreDuplicate(t *testing.T) {
 		t.Errorf("Unexpected result. Expected: %v, but got: %v", expected, result)
 	}
 }
+
+func TestHStoreBinary(t *testing.T) {
+	testCase := []byte{
+		0, 0, 0, 2,
+		0, 0, 0, 3, 'a', '"', 'b',
+		0, 0, 0, 1, '\\',
+		0, 0, 0, 1, 'c',
+		0xff, 0xff, 0xff, 0xff,
+	}
+	expected := `{"a\"b":"\\","c":null}`
+
+	text, err := HstoreBinaryToText(testCase)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	result, err := ParseHstore(text)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("Unexpected result. Expected: %v, but got: %v", expected, result)
+	}
+}
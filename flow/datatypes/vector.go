@@ -0,0 +1,64 @@
+package datatypes
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// pgvector's binary format is a uint16 dimension count, an unused uint16,
+// then every element as a big endian float4.
+// https://github.com/pgvector/pgvector/blob/master/src/vector.c
+const vectorHeaderSize = 4
+
+// ParseVectorText parses the text output of pgvector, [1,2.5,3]
+func ParseVectorText(s string) ([]float32, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '[' || s[len(s)-1] != ']' {
+		return nil, fmt.Errorf("invalid vector: %q", s)
+	}
+	s = s[1 : len(s)-1]
+	if strings.TrimSpace(s) == "" {
+		return []float32{}, nil
+	}
+
+	vector := make([]float32, 0, strings.Count(s, ",")+1)
+	for _, element := range strings.Split(s, ",") {
+		f, err := strconv.ParseFloat(strings.TrimSpace(element), 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vector element %q: %w", element, err)
+		}
+		vector = append(vector, float32(f))
+	}
+	return vector, nil
+}
+
+// ParseVectorBinary parses the binary send format of pgvector
+func ParseVectorBinary(b []byte) ([]float32, error) {
+	if len(b) < vectorHeaderSize {
+		return nil, errors.New("invalid vector: missing header")
+	}
+	dim := int(binary.BigEndian.Uint16(b))
+	if len(b) != vectorHeaderSize+4*dim {
+		return nil, fmt.Errorf("invalid vector: expected %d dimensions in %d bytes", dim, len(b))
+	}
+
+	vector := make([]float32, dim)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.BigEndian.Uint32(b[vectorHeaderSize+4*i:]))
+	}
+	return vector, nil
+}
+
+// VectorBinary encodes a vector in the binary receive format of pgvector
+func VectorBinary(vector []float32) []byte {
+	b := make([]byte, vectorHeaderSize, vectorHeaderSize+4*len(vector))
+	binary.BigEndian.PutUint16(b, uint16(len(vector)))
+	for _, f := range vector {
+		b = binary.BigEndian.AppendUint32(b, math.Float32bits(f))
+	}
+	return b
+}
@@ -0,0 +1,43 @@
+package datatypes
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestVectorText(t *testing.T) {
+	result, err := ParseVectorText("[1,-2.5,3e-05]")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	expected := []float32{1, -2.5, 3e-05}
+	if !slices.Equal(result, expected) {
+		t.Errorf("Unexpected result. Expected: %v, but got: %v", expected, result)
+	}
+}
+
+func TestVectorTextInvalid(t *testing.T) {
+	for _, testCase := range []string{"", "1,2", "[1,a]"} {
+		if _, err := ParseVectorText(testCase); err == nil {
+			t.Errorf("Expected error for %q", testCase)
+		}
+	}
+}
+
+func TestVectorBinaryRoundTrip(t *testing.T) {
+	expected := []float32{0.25, -1, 1536}
+
+	result, err := ParseVectorBinary(VectorBinary(expected))
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if !slices.Equal(result, expected) {
+		t.Errorf("Unexpected result. Expected: %v, but got: %v", expected, result)
+	}
+
+	if _, err := ParseVectorBinary(VectorBinary(expected)[:9]); err == nil {
+		t.Error("Expected error for truncated vector")
+	}
+}
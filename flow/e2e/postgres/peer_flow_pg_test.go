@@ -1020,6 +1020,41 @@ func (s PeerFlowE2ETestSuitePG) Test_Dynamic_Mirror_Config_Via_Signals() {
 		assert.Len(s.t, workflowState.SyncFlowOptions.TableMappings, 2)
 		assert.Len(s.t, workflowState.SyncFlowOptions.SrcTableIdNameMapping, 2)
 		assert.Len(s.t, workflowState.SyncFlowOptions.TableNameSchemaMapping, 2)
+
+		e2e.SignalWorkflow(env, model.FlowSignal, model.PauseSignal)
+		e2e.EnvWaitFor(s.t, env, 1*time.Minute, "paused workflow", func() bool {
+			addRows(1)
+			return getFlowStatus() == protos.FlowStatus_STATUS_PAUSED
+		})
+
+		e2e.SignalWorkflow(env, model.CDCDynamicPropertiesSignal, &protos.CDCFlowConfigUpdate{
+			RemovedTables: []*protos.TableMapping{
+				{
+					SourceTableIdentifier:      srcTable2Name,
+					DestinationTableIdentifier: dstTable2Name,
+				},
+			},
+			DropRemovedTables: true,
+		})
+		e2e.SignalWorkflow(env, model.FlowSignal, model.NoopSignal)
+
+		e2e.EnvWaitFor(s.t, env, 1*time.Minute, "resumed workflow", func() bool {
+			return getFlowStatus() == protos.FlowStatus_STATUS_RUNNING
+		})
+		addRows(6)
+		e2e.EnvWaitFor(s.t, env, 1*time.Minute, "normalize records - first table after removal", func() bool {
+			return s.comparePGTables(srcTable1Name, dstTable1Name, "id,t") == nil
+		})
+
+		var dstTable2Exists bool
+		err = s.Conn().QueryRow(context.Background(), "SELECT to_regclass($1) IS NOT NULL", dstTable2Name).Scan(&dstTable2Exists)
+		e2e.EnvNoError(s.t, env, err)
+		assert.False(s.t, dstTable2Exists)
+
+		workflowState = getWorkflowState()
+		assert.Len(s.t, workflowState.SyncFlowOptions.TableMappings, 1)
+		assert.Len(s.t, workflowState.SyncFlowOptions.SrcTableIdNameMapping, 1)
+		assert.Len(s.t, workflowState.SyncFlowOptions.TableNameSchemaMapping, 1)
 	}
 
 	env.Cancel()
@@ -1073,3 +1108,198 @@ func (s PeerFlowE2ETestSuitePG) Test_TypeSystem_PG() {
 	env.Cancel()
 	e2e.RequireEnvCanceled(s.t, env)
 }
+
+func (s PeerFlowE2ETestSuitePG) Test_Sync_Sequences() {
+	ctx := context.Background()
+	srcTableName := s.attachSchemaSuffix("test_sync_sequences")
+	dstTableName := s.attachSchemaSuffix("test_sync_sequences_dst")
+	_, err := s.Conn().Exec(ctx, fmt.Sprintf(`
+		CREATE TABLE %s (id SERIAL PRIMARY KEY, val TEXT);
+		INSERT INTO %[1]s(val) SELECT 'v' FROM generate_series(1, 5);
+		CREATE TABLE %s (id INT PRIMARY KEY, val TEXT);
+	`, srcTableName, dstTableName))
+	require.NoError(s.t, err)
+
+	values, err := s.conn.GetSequenceValues(ctx, []string{srcTableName})
+	require.NoError(s.t, err)
+	require.Len(s.t, values, 1)
+	require.Equal(s.t, "id", values[0].ColumnName)
+	require.Equal(s.t, int64(5), values[0].LastValue)
+
+	// the destination table has no sequence, one is created as the default of the column
+	values[0].TableIdentifier = dstTableName
+	values[0].LastValue += 10
+	require.NoError(s.t, s.conn.SyncSequences(ctx, values))
+	var nextID int64
+	require.NoError(s.t, s.Conn().QueryRow(ctx,
+		fmt.Sprintf("INSERT INTO %s(val) VALUES ('w') RETURNING id", dstTableName)).Scan(&nextID))
+	require.Equal(s.t, int64(16), nextID)
+
+	// sequences are never moved back, and missing columns are skipped
+	values[0].LastValue = 1
+	values = append(values, model.SequenceValue{TableIdentifier: dstTableName, ColumnName: "missing", LastValue: 1})
+	require.NoError(s.t, s.conn.SyncSequences(ctx, values))
+	require.NoError(s.t, s.Conn().QueryRow(ctx,
+		fmt.Sprintf("INSERT INTO %s(val) VALUES ('w') RETURNING id", dstTableName)).Scan(&nextID))
+	require.Equal(s.t, int64(17), nextID)
+}
+
+func (s PeerFlowE2ETestSuitePG) Test_Large_Streamed_Transaction() {
+	ctx := context.Background()
+	srcTableName := s.attachSchemaSuffix("test_large_streamed_txn")
+	dstTableName := s.attachSchemaSuffix("test_large_streamed_txn_dst")
+
+	_, err := s.Conn().Exec(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id INT PRIMARY KEY,
+			value TEXT NOT NULL
+		);
+	`, srcTableName))
+	require.NoError(s.t, err)
+
+	// with the smallest decoding memory the transaction is streamed while in progress
+	_, err = s.Conn().Exec(ctx, "ALTER SYSTEM SET logical_decoding_work_mem = '64kB'")
+	require.NoError(s.t, err)
+	_, err = s.Conn().Exec(ctx, "SELECT pg_reload_conf()")
+	require.NoError(s.t, err)
+	s.t.Cleanup(func() {
+		_, _ = s.Conn().Exec(ctx, "ALTER SYSTEM RESET logical_decoding_work_mem")
+		_, _ = s.Conn().Exec(ctx, "SELECT pg_reload_conf()")
+	})
+
+	connectionGen := e2e.FlowConnectionGenerationConfig{
+		FlowJobName:      s.attachSuffix("test_large_streamed_txn_flow"),
+		TableNameMapping: map[string]string{srcTableName: dstTableName},
+		Destination:      s.peer,
+	}
+
+	flowConnConfig := connectionGen.GenerateFlowConnectionConfigs()
+	flowConnConfig.MaxBatchSize = 100
+
+	tc := e2e.NewTemporalClient(s.t)
+	env := e2e.ExecutePeerflow(tc, peerflow.CDCFlowWorkflow, flowConnConfig, nil)
+	e2e.SetupCDCFlowStatusQuery(s.t, env, connectionGen)
+
+	// one transaction of many batches is split over them
+	_, err = s.Conn().Exec(ctx, fmt.Sprintf(`
+		INSERT INTO %s(id, value) SELECT i, repeat(md5(i::text), 10) FROM generate_series(1, 2000) i
+	`, srcTableName))
+	e2e.EnvNoError(s.t, env, err)
+	_, err = s.Conn().Exec(ctx, fmt.Sprintf(`INSERT INTO %s(id, value) VALUES (2001, 'after')`, srcTableName))
+	e2e.EnvNoError(s.t, env, err)
+
+	e2e.EnvWaitFor(s.t, env, 4*time.Minute, "normalize streamed transaction", func() bool {
+		return s.comparePGTables(srcTableName, dstTableName, "id,value") == nil
+	})
+	env.Cancel()
+
+	e2e.RequireEnvCanceled(s.t, env)
+}
+
+func (s PeerFlowE2ETestSuitePG) Test_Large_Streamed_Transaction_History_Resume() {
+	ctx := context.Background()
+	srcChangelogName := s.attachSchemaSuffix("test_split_txn_changelog")
+	dstChangelogName := s.attachSchemaSuffix("test_split_txn_changelog_dst")
+	srcSCD2Name := s.attachSchemaSuffix("test_split_txn_scd2")
+	dstSCD2Name := s.attachSchemaSuffix("test_split_txn_scd2_dst")
+
+	for _, srcTableName := range []string{srcChangelogName, srcSCD2Name} {
+		_, err := s.Conn().Exec(ctx, fmt.Sprintf(`
+			CREATE TABLE IF NOT EXISTS %s (
+				id INT PRIMARY KEY,
+				value TEXT NOT NULL
+			);
+		`, srcTableName))
+		require.NoError(s.t, err)
+	}
+
+	_, err := s.Conn().Exec(ctx, "ALTER SYSTEM SET logical_decoding_work_mem = '64kB'")
+	require.NoError(s.t, err)
+	_, err = s.Conn().Exec(ctx, "SELECT pg_reload_conf()")
+	require.NoError(s.t, err)
+	s.t.Cleanup(func() {
+		_, _ = s.Conn().Exec(ctx, "ALTER SYSTEM RESET logical_decoding_work_mem")
+		_, _ = s.Conn().Exec(ctx, "SELECT pg_reload_conf()")
+	})
+
+	connectionGen := e2e.FlowConnectionGenerationConfig{
+		FlowJobName: s.attachSuffix("test_split_txn_history_flow"),
+		TableMappings: []*protos.TableMapping{
+			{
+				SourceTableIdentifier:      srcChangelogName,
+				DestinationTableIdentifier: dstChangelogName,
+				NormalizeMode:              protos.NormalizeMode_NORMALIZE_MODE_CHANGELOG.Enum(),
+			},
+			{
+				SourceTableIdentifier:      srcSCD2Name,
+				DestinationTableIdentifier: dstSCD2Name,
+				NormalizeMode:              protos.NormalizeMode_NORMALIZE_MODE_SCD2.Enum(),
+			},
+		},
+		Destination: s.peer,
+	}
+
+	flowConnConfig := connectionGen.GenerateFlowConnectionConfigs()
+	flowConnConfig.MaxBatchSize = 100
+
+	tc := e2e.NewTemporalClient(s.t)
+	env := e2e.ExecutePeerflow(tc, peerflow.CDCFlowWorkflow, flowConnConfig, nil)
+	e2e.SetupCDCFlowStatusQuery(s.t, env, connectionGen)
+
+	getFlowStatus := func() protos.FlowStatus {
+		var flowStatus protos.FlowStatus
+		val, err := env.Query(shared.FlowStatusQuery)
+		e2e.EnvNoError(s.t, env, err)
+		err = val.Get(&flowStatus)
+		e2e.EnvNoError(s.t, env, err)
+
+		return flowStatus
+	}
+	countRows := func(tableName string, where string) int64 {
+		var count int64
+		err := s.Conn().QueryRow(ctx, fmt.Sprintf("SELECT count(*) FROM %s WHERE %s", tableName, where)).Scan(&count)
+		e2e.EnvNoError(s.t, env, err)
+		return count
+	}
+
+	// one transaction split over many batches, which also updates rows it inserted
+	tx, err := s.Conn().Begin(ctx)
+	e2e.EnvNoError(s.t, env, err)
+	for _, srcTableName := range []string{srcChangelogName, srcSCD2Name} {
+		_, err = tx.Exec(ctx, fmt.Sprintf(`
+			INSERT INTO %s(id, value) SELECT i, repeat(md5(i::text), 10) FROM generate_series(1, 2000) i
+		`, srcTableName))
+		e2e.EnvNoError(s.t, env, err)
+		_, err = tx.Exec(ctx, fmt.Sprintf(`UPDATE %s SET value = 'updated' WHERE id <= 10`, srcTableName))
+		e2e.EnvNoError(s.t, env, err)
+	}
+	e2e.EnvNoError(s.t, env, tx.Commit(ctx))
+
+	// pausing restarts replication, the transaction is decoded again and resumes after the records synced
+	e2e.EnvWaitFor(s.t, env, 2*time.Minute, "normalize part of the transaction", func() bool {
+		return countRows(dstChangelogName, "true") > 0
+	})
+	e2e.SignalWorkflow(env, model.FlowSignal, model.PauseSignal)
+	e2e.EnvWaitFor(s.t, env, time.Minute, "paused workflow", func() bool {
+		return getFlowStatus() == protos.FlowStatus_STATUS_PAUSED
+	})
+	require.Less(s.t, countRows(dstSCD2Name, "true"), int64(2010), "transaction was synced before the pause")
+	e2e.SignalWorkflow(env, model.FlowSignal, model.NoopSignal)
+
+	for _, srcTableName := range []string{srcChangelogName, srcSCD2Name} {
+		_, err = s.Conn().Exec(ctx, fmt.Sprintf(`INSERT INTO %s(id, value) VALUES (2001, 'after')`, srcTableName))
+		e2e.EnvNoError(s.t, env, err)
+	}
+	e2e.EnvWaitFor(s.t, env, 4*time.Minute, "normalize the rest of the transaction", func() bool {
+		return countRows(dstChangelogName, "id = 2001") == 1 && countRows(dstSCD2Name, "id = 2001") == 1
+	})
+
+	// every change exactly once
+	require.EqualValues(s.t, 2011, countRows(dstChangelogName, "true"))
+	require.EqualValues(s.t, 2011, countRows(dstSCD2Name, "true"))
+	require.EqualValues(s.t, 2001, countRows(dstSCD2Name, "_peerdb_is_current"))
+	require.EqualValues(s.t, 10, countRows(dstSCD2Name, "_peerdb_is_current AND value = 'updated'"))
+
+	env.Cancel()
+	e2e.RequireEnvCanceled(s.t, env)
+}
@@ -54,6 +54,32 @@ func main() {
 		Sources: cli.EnvVars("ENABLE_OTEL_METRICS"),
 	}
 
+	otelTracingFlag := &cli.BoolFlag{
+		Name:    "enable-otel-tracing",
+		Value:   false, // Default is off
+		Usage:   "Enable OpenTelemetry tracing for the application",
+		Sources: cli.EnvVars("ENABLE_OTEL_TRACING"),
+	}
+	prometheusMetricsFlag := &cli.BoolFlag{
+		Name:    "enable-prometheus-metrics",
+		Value:   false, // Default is off
+		Usage:   "Serve Prometheus metrics on /metrics",
+		Sources: cli.EnvVars("ENABLE_PROMETHEUS_METRICS"),
+	}
+	prometheusMetricsPortFlag := &cli.UintFlag{
+		Name:    "prometheus-metrics-port",
+		Value:   9464,
+		Usage:   "Port for the Prometheus /metrics endpoint",
+		Sources: cli.EnvVars("PROMETHEUS_METRICS_PORT"),
+	}
+	// the API defaults to another port than the worker, so both can run on one host
+	apiPrometheusMetricsPortFlag := &cli.UintFlag{
+		Name:    "prometheus-metrics-port",
+		Value:   9465,
+		Usage:   "Port for the Prometheus /metrics endpoint",
+		Sources: cli.EnvVars("API_PROMETHEUS_METRICS_PORT"),
+	}
+
 	pyroscopeServerFlag := &cli.StringFlag{
 		Name:    "pyroscope-server-address",
 		Value:   "http://pyroscope:4040",
@@ -93,6 +119,9 @@ func main() {
 						TemporalHostPort:                   temporalHostPort,
 						EnableProfiling:                    clicmd.Bool("enable-profiling"),
 						EnableOtelMetrics:                  clicmd.Bool("enable-otel-metrics"),
+						EnableOtelTracing:                  clicmd.Bool("enable-otel-tracing"),
+						EnablePrometheusMetrics:            clicmd.Bool("enable-prometheus-metrics"),
+						PrometheusMetricsPort:              uint16(clicmd.Uint("prometheus-metrics-port")),
 						PyroscopeServer:                    clicmd.String("pyroscope-server-address"),
 						TemporalNamespace:                  clicmd.String("temporal-namespace"),
 						TemporalCert:                       clicmd.String("temporal-cert"),
@@ -110,6 +139,9 @@ func main() {
 					temporalHostPortFlag,
 					profilingFlag,
 					otelMetricsFlag,
+					otelTracingFlag,
+					prometheusMetricsFlag,
+					prometheusMetricsPortFlag,
 					pyroscopeServerFlag,
 					temporalNamespaceFlag,
 					&temporalCertFlag,
@@ -158,20 +190,33 @@ func main() {
 					temporalNamespaceFlag,
 					&temporalCertFlag,
 					&temporalKeyFlag,
+					otelTracingFlag,
+					prometheusMetricsFlag,
+					apiPrometheusMetricsPortFlag,
 				},
 				Action: func(ctx context.Context, clicmd *cli.Command) error {
 					temporalHostPort := clicmd.String("temporal-host-port")
 
 					return cmd.APIMain(ctx, &cmd.APIServerParams{
-						Port:              uint16(clicmd.Uint("port")),
-						TemporalHostPort:  temporalHostPort,
-						GatewayPort:       uint16(clicmd.Uint("gateway-port")),
-						TemporalNamespace: clicmd.String("temporal-namespace"),
-						TemporalCert:      clicmd.String("temporal-cert"),
-						TemporalKey:       clicmd.String("temporal-key"),
+						Port:                    uint16(clicmd.Uint("port")),
+						TemporalHostPort:        temporalHostPort,
+						GatewayPort:             uint16(clicmd.Uint("gateway-port")),
+						TemporalNamespace:       clicmd.String("temporal-namespace"),
+						TemporalCert:            clicmd.String("temporal-cert"),
+						TemporalKey:             clicmd.String("temporal-key"),
+						EnableOtelTracing:       clicmd.Bool("enable-otel-tracing"),
+						EnablePrometheusMetrics: clicmd.Bool("enable-prometheus-metrics"),
+						PrometheusMetricsPort:   uint16(clicmd.Uint("prometheus-metrics-port")),
 					})
 				},
 			},
+			{
+				Name:  "reencrypt-catalog",
+				Usage: "re-encrypt peer configs in the catalog with PEERDB_CURRENT_ENC_KEY_ID",
+				Action: func(ctx context.Context, clicmd *cli.Command) error {
+					return cmd.ReencryptCatalog(ctx)
+				},
+			},
 		},
 	}
 
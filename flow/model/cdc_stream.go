@@ -1,7 +1,9 @@
 package model
 
 import (
+	"context"
 	"sync/atomic"
+	"time"
 
 	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/peerdbenv"
@@ -17,6 +19,10 @@ type CDCStream[T Items] struct {
 	lastCheckpointSet bool
 	// lastCheckpointID is the last ID of the commit that corresponds to this batch.
 	lastCheckpointID atomic.Int64
+	// lastCommitTimeNano is the latest commit time of the records added to this batch.
+	lastCommitTimeNano atomic.Int64
+	// splitTxn is set when the batch ends within a transaction
+	splitTxn SplitTxnPosition
 }
 
 func NewCDCStream[T Items]() *CDCStream[T] {
@@ -41,10 +47,33 @@ func (r *CDCStream[T]) GetLastCheckpoint() int64 {
 	return r.lastCheckpointID.Load()
 }
 
+// SetSplitTxn records that the batch ends within a transaction, before the rest of its records
+func (r *CDCStream[T]) SetSplitTxn(pos SplitTxnPosition) {
+	r.splitTxn = pos
+}
+
+// GetSplitTxn returns where the batch ends within a transaction, zero if it ends after a commit
+func (r *CDCStream[T]) GetSplitTxn() SplitTxnPosition {
+	if !r.lastCheckpointSet {
+		panic("last checkpoint not set, stream is still active")
+	}
+	return r.splitTxn
+}
+
 func (r *CDCStream[T]) AddRecord(record Record[T]) {
+	shared.AtomicInt64Max(&r.lastCommitTimeNano, record.GetCommitTime().UnixNano())
 	r.records <- record
 }
 
+// GetLastCommitTime returns the latest commit time of the records added so far, zero if there are none
+func (r *CDCStream[T]) GetLastCommitTime() time.Time {
+	nano := r.lastCommitTimeNano.Load()
+	if nano == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nano)
+}
+
 func (r *CDCStream[T]) SignalAsEmpty() {
 	r.emptySignal <- true
 }
@@ -76,3 +105,68 @@ func (r *CDCStream[T]) AddSchemaDelta(
 ) {
 	r.SchemaDeltas = append(r.SchemaDeltas, delta)
 }
+
+// Tee fans r out to one stream per destination, each receiving every record, schema delta and the final checkpoint,
+// except records at or before skipUpTo of that destination, which it already synced in an earlier batch.
+// Records of a buffered transaction are skipped by their commit instead, and if splitTxns is set,
+// the first Records of the split transaction of a destination are skipped too.
+// run forwards records until r is closed and must be called exactly once
+func (r *CDCStream[T]) Tee(skipUpTo []int64, splitTxns []SplitTxnPosition) ([]*CDCStream[T], func(context.Context) error) {
+	streams := make([]*CDCStream[T], 0, len(skipUpTo))
+	for range skipUpTo {
+		streams = append(streams, NewCDCStream[T]())
+	}
+	closeStreams := func() {
+		for _, stream := range streams {
+			stream.Close()
+		}
+	}
+
+	splitSkipped := make([]int, len(skipUpTo))
+	skip := func(i int, record Record[T]) bool {
+		commitLSN := record.GetCommitLSN()
+		if commitLSN == 0 {
+			return record.GetCheckpointID() <= skipUpTo[i]
+		}
+		if commitLSN <= skipUpTo[i] {
+			return true
+		}
+		if splitTxns != nil && commitLSN == splitTxns[i].CommitLSN && splitSkipped[i] < splitTxns[i].Records {
+			splitSkipped[i] += 1
+			return true
+		}
+		return false
+	}
+
+	run := func(ctx context.Context) error {
+		defer closeStreams()
+
+		if isEmpty, ok := <-r.emptySignal; ok {
+			for _, stream := range streams {
+				stream.emptySignal <- isEmpty
+			}
+		}
+
+		for record := range r.records {
+			for i, stream := range streams {
+				if skip(i, record) {
+					continue
+				}
+				select {
+				case stream.records <- record:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+
+		// r is closed, so its checkpoint and schema deltas are final
+		for _, stream := range streams {
+			stream.SchemaDeltas = r.SchemaDeltas
+			stream.UpdateLatestCheckpoint(r.lastCheckpointID.Load())
+			stream.splitTxn = r.splitTxn
+		}
+		return nil
+	}
+	return streams, run
+}
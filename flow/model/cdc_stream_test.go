@@ -0,0 +1,78 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+	"github.com/PeerDB-io/peer-flow/model"
+)
+
+func TestCDCStreamTee(t *testing.T) {
+	source := model.NewCDCStream[model.RecordItems]()
+	streams, run := source.Tee([]int64{0, 2}, nil)
+	require.Len(t, streams, 2)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- run(context.Background())
+	}()
+
+	source.SignalAsNotEmpty()
+	for checkpoint := range int64(3) {
+		source.AddRecord(&model.InsertRecord[model.RecordItems]{BaseRecord: model.BaseRecord{CheckpointID: checkpoint + 1}})
+	}
+	source.AddSchemaDelta(nil, &protos.TableSchemaDelta{SrcTableName: "public.t"})
+	source.UpdateLatestCheckpoint(3)
+	source.Close()
+
+	var checkpoints [2][]int64
+	for i, stream := range streams {
+		require.False(t, stream.WaitAndCheckEmpty())
+		for record := range stream.GetRecords() {
+			checkpoints[i] = append(checkpoints[i], record.GetCheckpointID())
+		}
+	}
+	require.NoError(t, <-done)
+
+	// the second destination already has everything up to checkpoint 2
+	require.Equal(t, []int64{1, 2, 3}, checkpoints[0])
+	require.Equal(t, []int64{3}, checkpoints[1])
+	for _, stream := range streams {
+		require.Equal(t, int64(3), stream.GetLastCheckpoint())
+		require.Len(t, stream.SchemaDeltas, 1)
+	}
+}
+
+func TestCDCStreamTeeSplitTxn(t *testing.T) {
+	source := model.NewCDCStream[model.RecordItems]()
+	// the second destination synced 2 records of the transaction committed at 10,
+	// the third all of it
+	streams, run := source.Tee([]int64{0, 9, 10}, []model.SplitTxnPosition{{}, {CommitLSN: 10, Records: 2}, {}})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- run(context.Background())
+	}()
+
+	source.SignalAsNotEmpty()
+	source.AddRecord(&model.InsertRecord[model.RecordItems]{BaseRecord: model.BaseRecord{CheckpointID: 8}})
+	for range 4 {
+		source.AddRecord(&model.InsertRecord[model.RecordItems]{BaseRecord: model.BaseRecord{CheckpointID: 9, CommitLSN: 10}})
+	}
+	source.AddRecord(&model.InsertRecord[model.RecordItems]{BaseRecord: model.BaseRecord{CheckpointID: 12}})
+	source.UpdateLatestCheckpoint(12)
+	source.Close()
+
+	var counts [3]int
+	for i, stream := range streams {
+		require.False(t, stream.WaitAndCheckEmpty())
+		for range stream.GetRecords() {
+			counts[i] += 1
+		}
+	}
+	require.NoError(t, <-done)
+	require.Equal(t, [3]int{6, 3, 1}, counts)
+}
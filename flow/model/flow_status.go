@@ -0,0 +1,37 @@
+package model
+
+import (
+	"context"
+	"fmt"
+
+	"go.temporal.io/sdk/client"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+	"github.com/PeerDB-io/peer-flow/shared"
+)
+
+// GetFlowStatus queries the status of a mirror's workflow
+func GetFlowStatus(ctx context.Context, c client.Client, workflowID string) (protos.FlowStatus, error) {
+	res, err := c.QueryWorkflow(ctx, workflowID, "", shared.FlowStatusQuery)
+	if err != nil {
+		return protos.FlowStatus_STATUS_UNKNOWN, fmt.Errorf("failed to get status in workflow with ID %s: %w", workflowID, err)
+	}
+	var state protos.FlowStatus
+	if err := res.Get(&state); err != nil {
+		return protos.FlowStatus_STATUS_UNKNOWN, fmt.Errorf("failed to get status in workflow with ID %s: %w", workflowID, err)
+	}
+	return state, nil
+}
+
+// PauseFlow marks a running mirror as pausing and signals it to pause
+func PauseFlow(ctx context.Context, c client.Client, workflowID string) error {
+	if _, err := c.UpdateWorkflow(ctx, workflowID, "", shared.FlowStatusUpdate, protos.FlowStatus_STATUS_PAUSING); err != nil {
+		return fmt.Errorf("failed to update state in workflow with ID %s: %w", workflowID, err)
+	}
+	return FlowSignal.SignalClientWorkflow(ctx, c, workflowID, "", PauseSignal)
+}
+
+// ResumeFlow signals a paused mirror to resume
+func ResumeFlow(ctx context.Context, c client.Client, workflowID string) error {
+	return FlowSignal.SignalClientWorkflow(ctx, c, workflowID, "", NoopSignal)
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"sync/atomic"
 	"time"
@@ -50,6 +51,19 @@ type PullRecordsRequest[T Items] struct {
 	MaxBatchSize uint32
 	// IdleTimeout is the timeout to wait for new records.
 	IdleTimeout time.Duration
+	// BinaryTransfer requests column values in binary format from Postgres sources
+	BinaryTransfer bool
+	// SkipReplicatedChanges skips changes applied to Postgres sources by other mirrors
+	SkipReplicatedChanges bool
+	// SplitTxn is how far every destination got through a transaction split over batches
+	SplitTxn SplitTxnPosition
+}
+
+// SplitTxnPosition counts the records synced of a transaction that was split over several batches,
+// CommitLSN is zero when no transaction is split
+type SplitTxnPosition struct {
+	CommitLSN int64
+	Records   int
 }
 
 type ToJSONOptions struct {
@@ -99,6 +113,36 @@ func RecToTablePKey[T Items](
 	}, nil
 }
 
+// RecToPartitionKey derives a stable key from the primary key of a record,
+// queue destinations use it to route changes to the same row to the same partition.
+// Returns an empty string for relation records and tables without a primary key.
+func RecToPartitionKey[T Items](
+	tableNameSchemaMapping map[string]*protos.TableSchema,
+	rec Record[T],
+) (string, error) {
+	if _, ok := rec.(*RelationRecord[T]); ok {
+		return "", nil
+	}
+	schema, ok := tableNameSchemaMapping[rec.GetDestinationTableName()]
+	if !ok || len(schema.PrimaryKeyColumns) == 0 {
+		return "", nil
+	}
+
+	if len(schema.PrimaryKeyColumns) == 1 {
+		pkeyColBytes, err := rec.GetItems().GetBytesByColName(schema.PrimaryKeyColumns[0])
+		if err != nil {
+			return "", fmt.Errorf("error getting pkey column value: %w", err)
+		}
+		return string(pkeyColBytes), nil
+	}
+
+	tablePkey, err := RecToTablePKey(tableNameSchemaMapping, rec)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(tablePkey.PkeyColVal[:]), nil
+}
+
 type SyncRecordsRequest[T Items] struct {
 	Records *CDCStream[T]
 	// ConsumedOffset allows destination to confirm lsn for slot
@@ -114,15 +158,24 @@ type SyncRecordsRequest[T Items] struct {
 	// source:destination mappings
 	TableMappings []*protos.TableMapping
 	SyncBatchID   int64
+	// store commit LSN and commit time of each record in the raw table,
+	// for history normalize modes and resolving conflicts of mirrors with a replication origin
+	CommitInfo bool
+	// tag changes with a replication origin, for Postgres destinations
+	ReplicationOrigin bool
 }
 
 type NormalizeRecordsRequest struct {
 	TableNameSchemaMapping map[string]*protos.TableSchema
-	FlowJobName            string
-	SoftDeleteColName      string
-	SyncedAtColName        string
-	SyncBatchID            int64
-	SoftDelete             bool
+	// destination table -> normalize mode, tables missing from it are merged
+	NormalizeModes    map[string]protos.NormalizeMode
+	FlowJobName       string
+	SoftDeleteColName string
+	SyncedAtColName   string
+	SyncBatchID       int64
+	SoftDelete        bool
+	// tag changes with a replication origin, for Postgres destinations
+	ReplicationOrigin bool
 }
 
 type SyncResponse struct {
@@ -135,10 +188,13 @@ type SyncResponse struct {
 	// NumRecordsSynced is the number of records that were synced.
 	NumRecordsSynced   int64
 	CurrentSyncBatchID int64
+	// AdditionalSyncBatchIDs maps each additional destination peer to the batch synced to it
+	AdditionalSyncBatchIDs map[string]int64
 }
 
 type NormalizePayload struct {
 	TableNameSchemaMapping map[string]*protos.TableSchema
+	AdditionalSyncBatchIDs map[string]int64
 	Done                   bool
 	SyncBatchID            int64
 }
@@ -151,3 +207,24 @@ type NormalizeResponse struct {
 }
 
 type RelationMessageMapping map[uint32]*pglogrepl.RelationMessage
+
+// SequenceValue is the last value handed out by the sequence owned by a column
+type SequenceValue struct {
+	TableIdentifier string
+	ColumnName      string
+	LastValue       int64
+}
+
+// CutoverProgress is how far the destination of a mirror has caught up, for a cutover to wait on
+type CutoverProgress struct {
+	LatestLSNAtTarget int64
+	SyncBatchID       int64
+	NormalizeBatchID  int64
+}
+
+// MaintenanceWindowAction is a pause or resume due to a mirror entering or leaving a maintenance window
+type MaintenanceWindowAction struct {
+	FlowJobName string
+	WorkflowID  string
+	Pause       bool
+}
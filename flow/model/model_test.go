@@ -0,0 +1,145 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+	"github.com/PeerDB-io/peer-flow/model"
+	"github.com/PeerDB-io/peer-flow/model/qvalue"
+)
+
+func TestRecToPartitionKey(t *testing.T) {
+	schemas := map[string]*protos.TableSchema{
+		"single":    {PrimaryKeyColumns: []string{"id"}},
+		"composite": {PrimaryKeyColumns: []string{"a", "b"}},
+		"nopkey":    {},
+	}
+	newRecord := func(table string, a int64) model.Record[model.RecordItems] {
+		items := model.NewRecordItems(3)
+		items.AddColumn("id", qvalue.QValueInt64{Val: 42})
+		items.AddColumn("a", qvalue.QValueInt64{Val: a})
+		items.AddColumn("b", qvalue.QValueString{Val: "x"})
+		return &model.InsertRecord[model.RecordItems]{Items: items, DestinationTableName: table}
+	}
+
+	key, err := model.RecToPartitionKey(schemas, newRecord("single", 1))
+	require.NoError(t, err)
+	require.Equal(t, "42", key)
+
+	key1, err := model.RecToPartitionKey(schemas, newRecord("composite", 1))
+	require.NoError(t, err)
+	key2, err := model.RecToPartitionKey(schemas, newRecord("composite", 1))
+	require.NoError(t, err)
+	key3, err := model.RecToPartitionKey(schemas, newRecord("composite", 2))
+	require.NoError(t, err)
+	require.NotEmpty(t, key1)
+	require.Equal(t, key1, key2)
+	require.NotEqual(t, key1, key3)
+
+	key, err = model.RecToPartitionKey(schemas, newRecord("nopkey", 1))
+	require.NoError(t, err)
+	require.Empty(t, key)
+
+	key, err = model.RecToPartitionKey(schemas, &model.RelationRecord[model.RecordItems]{})
+	require.NoError(t, err)
+	require.Empty(t, key)
+}
+
+func TestRangeAvroConversion(t *testing.T) {
+	schema := qvalue.QRecordSchema{Fields: []qvalue.QField{
+		{Name: "during", Type: qvalue.QValueKindRangeTimestampTZ, Nullable: true},
+		{Name: "ids", Type: qvalue.QValueKindMultirangeInt32, Nullable: true},
+	}}
+	during := qvalue.QValueRange{RangeKind: qvalue.QValueKindRangeTimestampTZ, Val: qvalue.Range{
+		Lower:          time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		LowerInclusive: true,
+	}}
+	ids := qvalue.QValueMultirange{MultirangeKind: qvalue.QValueKindMultirangeInt32, Val: []qvalue.Range{
+		{Lower: int32(1), Upper: int32(5), LowerInclusive: true},
+		{Empty: true},
+	}}
+
+	for _, dwh := range []protos.DBType{protos.DBType_CLICKHOUSE, protos.DBType_SNOWFLAKE} {
+		avroSchema, err := model.GetAvroSchemaDefinition("tbl", schema, dwh)
+		require.NoError(t, err)
+		codec, err := goavro.NewCodec(avroSchema.Schema)
+		require.NoError(t, err)
+
+		converter := model.NewQRecordAvroConverter(avroSchema, dwh, []string{"during", "ids"}, nil)
+		record, err := converter.Convert([]qvalue.QValue{during, ids})
+		require.NoError(t, err)
+		_, err = codec.BinaryFromNative(nil, record)
+		require.NoError(t, err, dwh.String())
+	}
+
+	items := model.NewRecordItems(2)
+	items.AddColumn("during", during)
+	items.AddColumn("ids", ids)
+	itemsJSON, err := model.ItemsToJSON(items)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"during": {"lower": "2024-01-01 00:00:00+0000", "upper": null, "lower_inclusive": true, "upper_inclusive": false, "empty": false},
+		"ids": [
+			{"lower": 1, "upper": 5, "lower_inclusive": true, "upper_inclusive": false, "empty": false},
+			{"lower": null, "upper": null, "lower_inclusive": false, "upper_inclusive": false, "empty": true}
+		]
+	}`, itemsJSON)
+}
+
+func TestCompositeAvroConversion(t *testing.T) {
+	members := []qvalue.QField{
+		{Name: "street", Type: qvalue.QValueKindString, Nullable: true},
+		{Name: "zip", Type: qvalue.QValueKindInt32, Nullable: true},
+		{Name: "valid", Type: qvalue.QValueKindRangeDate, Nullable: true},
+	}
+	schema := qvalue.QRecordSchema{Fields: []qvalue.QField{
+		{Name: "home", Type: qvalue.QValueKindStruct, Nullable: true, Fields: members},
+		{Name: "previous", Type: qvalue.QValueKindArrayOf(qvalue.QValueKindStruct), Nullable: true, Fields: members},
+		{Name: "prices", Type: qvalue.QValueKindArrayOf(qvalue.QValueKindNumeric), Nullable: true},
+	}}
+	home := qvalue.QValueStruct{Val: map[string]interface{}{
+		"street": qvalue.QValueString{Val: "Main St"},
+		"zip":    qvalue.QValueNull(qvalue.QValueKindInt32),
+		"valid": qvalue.QValueRange{RangeKind: qvalue.QValueKindRangeDate, Val: qvalue.Range{
+			Lower: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), LowerInclusive: true,
+		}},
+	}}
+	previous := qvalue.QValueArray{ElementKind: qvalue.QValueKindStruct, Val: []qvalue.QValue{
+		home, qvalue.QValueNull(qvalue.QValueKindStruct),
+	}}
+	prices := qvalue.QValueArray{ElementKind: qvalue.QValueKindNumeric, Val: []qvalue.QValue{
+		qvalue.QValueNumeric{Val: decimal.NewFromFloat(1.5)}, qvalue.QValueNull(qvalue.QValueKindNumeric),
+	}}
+
+	for _, dwh := range []protos.DBType{protos.DBType_CLICKHOUSE, protos.DBType_BIGQUERY, protos.DBType_SNOWFLAKE} {
+		avroSchema, err := model.GetAvroSchemaDefinition("tbl", schema, dwh)
+		require.NoError(t, err)
+		codec, err := goavro.NewCodec(avroSchema.Schema)
+		require.NoError(t, err)
+
+		converter := model.NewQRecordAvroConverter(avroSchema, dwh, []string{"home", "previous", "prices"}, nil)
+		record, err := converter.Convert([]qvalue.QValue{home, previous, prices})
+		require.NoError(t, err)
+		_, err = codec.BinaryFromNative(nil, record)
+		require.NoError(t, err, dwh.String())
+	}
+
+	items := model.NewRecordItems(3)
+	items.AddColumn("home", home)
+	items.AddColumn("previous", previous)
+	items.AddColumn("prices", prices)
+	itemsJSON, err := model.ItemsToJSON(items)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"home": {"street": "Main St", "zip": null,
+			"valid": {"lower": "2024-01-01", "upper": null, "lower_inclusive": true, "upper_inclusive": false, "empty": false}},
+		"previous": [{"street": "Main St", "zip": null,
+			"valid": {"lower": "2024-01-01", "upper": null, "lower_inclusive": true, "upper_inclusive": false, "empty": false}}, null],
+		"prices": ["1.5", null]
+	}`, itemsJSON)
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -81,148 +82,151 @@ func (src *QRecordBatchCopyFromSource) Values() ([]interface{}, error) {
 		return nil, src.err
 	}
 
+	fields := src.stream.Schema().Fields
 	values := make([]interface{}, len(src.currentRecord))
 	for i, qValue := range src.currentRecord {
-		if qValue.Value() == nil {
-			values[i] = nil
-			continue
+		value, err := pgValue(qValue, &fields[i])
+		if err != nil {
+			src.err = err
+			return nil, src.err
 		}
+		values[i] = value
+	}
+	return values, nil
+}
 
-		switch v := qValue.(type) {
-		case qvalue.QValueFloat32:
-			values[i] = v.Val
-		case qvalue.QValueFloat64:
-			values[i] = v.Val
-		case qvalue.QValueInt16:
-			values[i] = v.Val
-		case qvalue.QValueInt32:
-			values[i] = v.Val
-		case qvalue.QValueInt64:
-			values[i] = v.Val
-		case qvalue.QValueBoolean:
-			values[i] = v.Val
-		case qvalue.QValueQChar:
-			values[i] = rune(v.Val)
-		case qvalue.QValueString:
-			values[i] = v.Val
-		case qvalue.QValueCIDR, qvalue.QValueINET, qvalue.QValueMacaddr:
-			str, ok := v.Value().(string)
-			if !ok {
-				src.err = errors.New("invalid INET/CIDR/MACADDR value")
-				return nil, src.err
-			}
-			values[i] = str
-		case qvalue.QValueTime:
-			values[i] = pgtype.Time{Microseconds: v.Val.UnixMicro(), Valid: true}
-		case qvalue.QValueTimestamp:
-			values[i] = pgtype.Timestamp{Time: v.Val, Valid: true}
-		case qvalue.QValueTimestampTZ:
-			values[i] = pgtype.Timestamptz{Time: v.Val, Valid: true}
-		case qvalue.QValueUUID:
-			values[i] = uuid.UUID(v.Val)
-		case qvalue.QValueNumeric:
-			values[i] = v.Val
-		case qvalue.QValueBit:
-			values[i] = v.Val
-		case qvalue.QValueBytes:
-			values[i] = v.Val
-		case qvalue.QValueDate:
-			values[i] = pgtype.Date{Time: v.Val, Valid: true}
-		case qvalue.QValueHStore:
-			values[i] = v.Val
-		case qvalue.QValueGeography, qvalue.QValueGeometry, qvalue.QValuePoint:
-			geoWkt, ok := v.Value().(string)
-			if !ok {
-				src.err = errors.New("invalid Geospatial value")
-				return nil, src.err
-			}
-
-			if strings.HasPrefix(geoWkt, "SRID=") {
-				_, wkt, found := strings.Cut(geoWkt, ";")
-				if found {
-					geoWkt = wkt
-				}
-			}
-
-			wkb, err := geo.GeoToWKB(geoWkt)
-			if err != nil {
-				src.err = fmt.Errorf("failed to convert Geospatial value to wkb: %v", err)
-				return nil, src.err
-			}
-
-			values[i] = wkb
-		case qvalue.QValueArrayString:
-			a, err := constructArray[string](qValue, "ArrayString")
-			if err != nil {
-				src.err = err
-				return nil, src.err
-			}
-			values[i] = a
-
-		case qvalue.QValueArrayDate, qvalue.QValueArrayTimestamp, qvalue.QValueArrayTimestampTZ:
-			a, err := constructArray[time.Time](qValue, "ArrayTime")
-			if err != nil {
-				src.err = err
-				return nil, src.err
-			}
-			values[i] = a
-
-		case qvalue.QValueArrayInt16:
-			a, err := constructArray[int16](qValue, "ArrayInt16")
-			if err != nil {
-				src.err = err
-				return nil, src.err
-			}
-			values[i] = a
+// pgValue converts qValue of field for pgx to encode, composites are encoded with the members of field in order
+func pgValue(qValue qvalue.QValue, field *qvalue.QField) (interface{}, error) {
+	if qValue == nil || qValue.Value() == nil {
+		return nil, nil
+	}
 
-		case qvalue.QValueArrayInt32:
-			a, err := constructArray[int32](qValue, "ArrayInt32")
-			if err != nil {
-				src.err = err
-				return nil, src.err
-			}
-			values[i] = a
+	switch v := qValue.(type) {
+	case qvalue.QValueFloat32:
+		return v.Val, nil
+	case qvalue.QValueFloat64:
+		return v.Val, nil
+	case qvalue.QValueInt16:
+		return v.Val, nil
+	case qvalue.QValueInt32:
+		return v.Val, nil
+	case qvalue.QValueInt64:
+		return v.Val, nil
+	case qvalue.QValueBoolean:
+		return v.Val, nil
+	case qvalue.QValueQChar:
+		return rune(v.Val), nil
+	case qvalue.QValueString:
+		return v.Val, nil
+	case qvalue.QValueCIDR, qvalue.QValueINET, qvalue.QValueMacaddr:
+		str, ok := v.Value().(string)
+		if !ok {
+			return nil, errors.New("invalid INET/CIDR/MACADDR value")
+		}
+		return str, nil
+	case qvalue.QValueTime:
+		return pgtype.Time{Microseconds: v.Val.UnixMicro(), Valid: true}, nil
+	case qvalue.QValueTimestamp:
+		return pgtype.Timestamp{Time: v.Val, Valid: true}, nil
+	case qvalue.QValueTimestampTZ:
+		return pgtype.Timestamptz{Time: v.Val, Valid: true}, nil
+	case qvalue.QValueUUID:
+		return uuid.UUID(v.Val), nil
+	case qvalue.QValueNumeric:
+		return v.Val, nil
+	case qvalue.QValueBit:
+		return v.Val, nil
+	case qvalue.QValueBytes:
+		return v.Val, nil
+	case qvalue.QValueDate:
+		return pgtype.Date{Time: v.Val, Valid: true}, nil
+	case qvalue.QValueHStore:
+		return v.Val, nil
+	case qvalue.QValueGeography, qvalue.QValueGeometry, qvalue.QValuePoint:
+		geoWkt, ok := v.Value().(string)
+		if !ok {
+			return nil, errors.New("invalid Geospatial value")
+		}
 
-		case qvalue.QValueArrayInt64:
-			a, err := constructArray[int64](qValue, "ArrayInt64")
-			if err != nil {
-				src.err = err
-				return nil, src.err
+		if strings.HasPrefix(geoWkt, "SRID=") {
+			_, wkt, found := strings.Cut(geoWkt, ";")
+			if found {
+				geoWkt = wkt
 			}
-			values[i] = a
+		}
 
-		case qvalue.QValueArrayFloat32:
-			a, err := constructArray[float32](qValue, "ArrayFloat32")
+		wkb, err := geo.GeoToWKB(geoWkt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert Geospatial value to wkb: %v", err)
+		}
+		return wkb, nil
+	case qvalue.QValueArrayString:
+		return constructArray[string](qValue, "ArrayString")
+	case qvalue.QValueArrayDate, qvalue.QValueArrayTimestamp, qvalue.QValueArrayTimestampTZ:
+		return constructArray[time.Time](qValue, "ArrayTime")
+	case qvalue.QValueArrayInt16:
+		return constructArray[int16](qValue, "ArrayInt16")
+	case qvalue.QValueArrayInt32:
+		return constructArray[int32](qValue, "ArrayInt32")
+	case qvalue.QValueArrayInt64:
+		return constructArray[int64](qValue, "ArrayInt64")
+	case qvalue.QValueArrayFloat32:
+		return constructArray[float32](qValue, "ArrayFloat32")
+	case qvalue.QValueArrayFloat64:
+		return constructArray[float64](qValue, "ArrayFloat64")
+	case qvalue.QValueVector:
+		// vector is not registered on the destination, bytes are sent as is in its binary format
+		return geo.VectorBinary(v.Val), nil
+	case qvalue.QValueArrayBoolean:
+		return constructArray[bool](qValue, "ArrayBool")
+	case qvalue.QValueJSON:
+		return v.Val, nil
+	case qvalue.QValueEnum:
+		return v.Val, nil
+	case qvalue.QValueRange:
+		return pgRange(v.Val), nil
+	case qvalue.QValueMultirange:
+		multirange := make(pgtype.Multirange[pgtype.Range[any]], 0, len(v.Val))
+		for _, r := range v.Val {
+			multirange = append(multirange, pgRange(r))
+		}
+		return multirange, nil
+	case qvalue.QValueStruct:
+		// composites of unknown members are stored as JSON
+		if len(field.Fields) == 0 {
+			jsonVal, err := json.Marshal(v.JSONValue())
 			if err != nil {
-				src.err = err
-				return nil, src.err
+				return nil, fmt.Errorf("failed to marshal composite to JSON: %w", err)
 			}
-			values[i] = a
-
-		case qvalue.QValueArrayFloat64:
-			a, err := constructArray[float64](qValue, "ArrayFloat64")
+			return string(jsonVal), nil
+		}
+		members := make(pgtype.CompositeFields, 0, len(field.Fields))
+		for j := range field.Fields {
+			member := &field.Fields[j]
+			memberQv, _ := v.Val[member.Name].(qvalue.QValue)
+			memberVal, err := pgValue(memberQv, member)
 			if err != nil {
-				src.err = err
-				return nil, src.err
+				return nil, fmt.Errorf("failed to convert member %s: %w", member.Name, err)
 			}
-			values[i] = a
-		case qvalue.QValueArrayBoolean:
-			a, err := constructArray[bool](qValue, "ArrayBool")
+			members = append(members, memberVal)
+		}
+		return members, nil
+	case qvalue.QValueArray:
+		elementField := qvalue.QField{Name: field.Name, Type: v.ElementKind, Nullable: true, Fields: field.Fields}
+		elements := make([]interface{}, 0, len(v.Val))
+		for _, element := range v.Val {
+			elementVal, err := pgValue(element, &elementField)
 			if err != nil {
-				src.err = err
-				return nil, src.err
+				return nil, err
 			}
-			values[i] = a
-		case qvalue.QValueJSON:
-			values[i] = v.Val
-
-		// And so on for the other types...
-		default:
-			src.err = fmt.Errorf("unsupported value type %T", qValue)
-			return nil, src.err
+			elements = append(elements, elementVal)
 		}
+		return elements, nil
+
+	// And so on for the other types...
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", qValue)
 	}
-	return values, nil
 }
 
 func (src *QRecordBatchCopyFromSource) NumRecords() int {
@@ -232,3 +236,26 @@ func (src *QRecordBatchCopyFromSource) NumRecords() int {
 func (src *QRecordBatchCopyFromSource) Err() error {
 	return src.err
 }
+
+func pgRangeBoundType(bound any, inclusive bool) pgtype.BoundType {
+	if bound == nil {
+		return pgtype.Unbounded
+	} else if inclusive {
+		return pgtype.Inclusive
+	}
+	return pgtype.Exclusive
+}
+
+// pgRange converts r for pgx to encode as the range type of the destination column
+func pgRange(r qvalue.Range) pgtype.Range[any] {
+	if r.Empty {
+		return pgtype.Range[any]{LowerType: pgtype.Empty, UpperType: pgtype.Empty, Valid: true}
+	}
+	return pgtype.Range[any]{
+		Lower:     r.Lower,
+		Upper:     r.Upper,
+		LowerType: pgRangeBoundType(r.Lower, r.LowerInclusive),
+		UpperType: pgRangeBoundType(r.Upper, r.UpperInclusive),
+		Valid:     true,
+	}
+}
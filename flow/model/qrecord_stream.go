@@ -22,6 +22,8 @@ type RecordsToStreamRequest[T Items] struct {
 	records      <-chan Record[T]
 	TableMapping map[string]*RecordTypeCounts
 	BatchID      int64
+	// adds the commit LSN and commit time columns to the stream
+	CommitInfo bool
 }
 
 func NewRecordsToStreamRequest[T Items](
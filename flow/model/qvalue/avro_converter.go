@@ -1,7 +1,7 @@
 package qvalue
 
 import (
-	"errors"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"math"
@@ -24,8 +24,8 @@ type AvroSchemaArray struct {
 }
 
 type AvroSchemaComplexArray struct {
-	Type  string          `json:"type"`
-	Items AvroSchemaField `json:"items"`
+	Type  string      `json:"type"`
+	Items interface{} `json:"items"`
 }
 
 type AvroSchemaNumeric struct {
@@ -114,9 +114,9 @@ func GetAvroSchemaFromQValueKind(kind QValueKind, targetDWH protos.DBType, preci
 			}, nil
 		}
 		return "string", nil
-	case QValueKindHStore, QValueKindJSON, QValueKindStruct:
+	case QValueKindHStore, QValueKindJSON, QValueKindStruct, QValueKindEnum:
 		return "string", nil
-	case QValueKindArrayFloat32:
+	case QValueKindArrayFloat32, QValueKindVector:
 		return AvroSchemaArray{
 			Type:  "array",
 			Items: "float",
@@ -164,14 +164,137 @@ func GetAvroSchemaFromQValueKind(kind QValueKind, targetDWH protos.DBType, preci
 	}
 }
 
+// rangeBoundAvroType returns the schema of the bounds of ranges of kind, along with its name in unions
+func rangeBoundAvroType(kind QValueKind, targetDWH protos.DBType) (interface{}, string) {
+	switch kind.RangeElementKind() {
+	case QValueKindInt32:
+		if targetDWH == protos.DBType_BIGQUERY {
+			return "long", "long"
+		}
+		return "int", "int"
+	case QValueKindInt64:
+		return "long", "long"
+	case QValueKindNumeric:
+		avroNumericPrecision, avroNumericScale := DetermineNumericSettingForDWH(0, 0, targetDWH)
+		return AvroSchemaNumeric{
+			Type:        "bytes",
+			LogicalType: "decimal",
+			Precision:   avroNumericPrecision,
+			Scale:       avroNumericScale,
+		}, "bytes.decimal"
+	case QValueKindDate:
+		return AvroSchemaLogical{Type: "int", LogicalType: "date"}, "int.date"
+	default:
+		return AvroSchemaLogical{Type: "long", LogicalType: "timestamp-micros"}, "long.timestamp-micros"
+	}
+}
+
+// GetAvroRangeSchema returns the Avro schema of a range or multirange column called name.
+// ClickHouse and BigQuery load ranges from records into Tuple and RECORD columns, named after their column
+// so several range columns don't redefine one type, while Snowflake parses a JSON string into an OBJECT
+func GetAvroRangeSchema(name string, kind QValueKind, targetDWH protos.DBType) interface{} {
+	if targetDWH != protos.DBType_CLICKHOUSE && targetDWH != protos.DBType_BIGQUERY {
+		return "string"
+	}
+
+	boundType, _ := rangeBoundAvroType(kind, targetDWH)
+	record := AvroSchemaRecord{
+		Type: "record",
+		Name: name,
+		Fields: []AvroSchemaField{
+			{Name: "lower", Type: []interface{}{"null", boundType}},
+			{Name: "upper", Type: []interface{}{"null", boundType}},
+			{Name: "lower_inclusive", Type: "boolean"},
+			{Name: "upper_inclusive", Type: "boolean"},
+			{Name: "empty", Type: "boolean"},
+		},
+	}
+	if kind.IsMultirange() {
+		return AvroSchemaComplexArray{
+			Type:  "array",
+			Items: record,
+		}
+	}
+	return record
+}
+
+// nestedAvroTarget is whether targetDWH loads composites and arrays of any kind from nested Avro values
+func nestedAvroTarget(targetDWH protos.DBType) bool {
+	return targetDWH == protos.DBType_CLICKHOUSE || targetDWH == protos.DBType_BIGQUERY
+}
+
+// GetAvroFieldSchema returns the Avro schema of field, where path names the records it defines.
+// Members of composites are named path_member, elements of arrays share the path of their array.
+// ClickHouse allows null array elements, BigQuery doesn't so they're dropped when converting
+func GetAvroFieldSchema(path string, field *QField, targetDWH protos.DBType) (interface{}, error) {
+	switch {
+	case field.Type.IsRange() || field.Type.IsMultirange():
+		return GetAvroRangeSchema(path, field.Type, targetDWH), nil
+	case field.Type == QValueKindStruct:
+		if !nestedAvroTarget(targetDWH) || len(field.Fields) == 0 {
+			return "string", nil
+		}
+		fields := make([]AvroSchemaField, 0, len(field.Fields))
+		for i := range field.Fields {
+			member := &field.Fields[i]
+			memberType, err := GetAvroFieldSchema(path+"_"+member.Name, member, targetDWH)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, AvroSchemaField{Name: member.Name, Type: []interface{}{"null", memberType}})
+		}
+		return AvroSchemaRecord{
+			Type:   "record",
+			Name:   path,
+			Fields: fields,
+		}, nil
+	case field.Type.IsGenericArray():
+		if !nestedAvroTarget(targetDWH) {
+			return "string", nil
+		}
+		elementField := field.arrayElementField(field.Type.ArrayElementKind(), targetDWH)
+		items, err := GetAvroFieldSchema(path, &elementField, targetDWH)
+		if err != nil {
+			return nil, err
+		}
+		if elementField.Nullable {
+			items = []interface{}{"null", items}
+		}
+		return AvroSchemaComplexArray{
+			Type:  "array",
+			Items: items,
+		}, nil
+	default:
+		return GetAvroSchemaFromQValueKind(field.Type, targetDWH, field.Precision, field.Scale)
+	}
+}
+
+// arrayElementField describes the elements of kind of the generic array field
+func (field *QField) arrayElementField(elementKind QValueKind, targetDWH protos.DBType) QField {
+	return QField{
+		Name:      field.Name,
+		Type:      elementKind,
+		Precision: field.Precision,
+		Scale:     field.Scale,
+		Nullable:  targetDWH != protos.DBType_BIGQUERY,
+		Fields:    field.Fields,
+	}
+}
+
 type QValueAvroConverter struct {
 	*QField
 	logger    log.Logger
+	avroName  string
 	TargetDWH protos.DBType
 }
 
 func QValueToAvro(value QValue, field *QField, targetDWH protos.DBType, logger log.Logger) (interface{}, error) {
-	if value.Value() == nil {
+	return qvalueToAvro(value, field, field.Name, targetDWH, logger)
+}
+
+// qvalueToAvro converts value of field, where avroName is the path passed to GetAvroFieldSchema
+func qvalueToAvro(value QValue, field *QField, avroName string, targetDWH protos.DBType, logger log.Logger) (interface{}, error) {
+	if value == nil || value.Value() == nil {
 		return nil, nil
 	}
 
@@ -179,6 +302,7 @@ func QValueToAvro(value QValue, field *QField, targetDWH protos.DBType, logger l
 		QField:    field,
 		TargetDWH: targetDWH,
 		logger:    logger,
+		avroName:  avroName,
 	}
 
 	switch v := value.(type) {
@@ -288,6 +412,22 @@ func QValueToAvro(value QValue, field *QField, targetDWH protos.DBType, logger l
 		return t, nil
 	case QValueQChar:
 		return c.processNullableUnion("string", string(v.Val))
+	case QValueEnum:
+		return c.processNullableUnion("string", v.Val)
+	case QValueRange:
+		if !nestedAvroTarget(c.TargetDWH) {
+			return c.processNestedJSON(v.JSONValue())
+		}
+		return c.processNullableUnion(c.avroName, c.processRange(v.Val, v.RangeKind))
+	case QValueMultirange:
+		if !nestedAvroTarget(c.TargetDWH) {
+			return c.processNestedJSON(v.JSONValue())
+		}
+		ranges := make([]interface{}, 0, len(v.Val))
+		for _, r := range v.Val {
+			ranges = append(ranges, c.processRange(r, v.MultirangeKind))
+		}
+		return c.processNullableUnion("array", ranges)
 	case QValueString, QValueCIDR, QValueINET, QValueMacaddr, QValueInterval:
 		if c.TargetDWH == protos.DBType_SNOWFLAKE && v.Value() != nil &&
 			(len(v.Value().(string)) > 15*1024*1024) {
@@ -310,7 +450,15 @@ func QValueToAvro(value QValue, field *QField, targetDWH protos.DBType, logger l
 	case QValueBoolean:
 		return c.processNullableUnion("boolean", v.Val)
 	case QValueStruct:
-		return nil, errors.New("QValueStruct not supported")
+		if !nestedAvroTarget(c.TargetDWH) || len(c.Fields) == 0 {
+			return c.processNestedJSON(v.JSONValue())
+		}
+		return c.processStruct(v)
+	case QValueArray:
+		if !nestedAvroTarget(c.TargetDWH) {
+			return c.processNestedJSON(v.JSONValue())
+		}
+		return c.processArray(v)
 	case QValueNumeric:
 		return c.processNumeric(v.Val), nil
 	case QValueBytes:
@@ -323,6 +471,8 @@ func QValueToAvro(value QValue, field *QField, targetDWH protos.DBType, logger l
 		return c.processHStore(v.Val)
 	case QValueArrayFloat32:
 		return c.processArrayFloat32(v.Val), nil
+	case QValueVector:
+		return c.processArrayFloat32(v.Val), nil
 	case QValueArrayFloat64:
 		return c.processArrayFloat64(v.Val), nil
 	case QValueArrayInt16:
@@ -474,6 +624,65 @@ func (c *QValueAvroConverter) processNumeric(num decimal.Decimal) interface{} {
 	return rat
 }
 
+// processNestedJSON converts ranges, composites and arrays to JSON for targets without nested Avro values
+func (c *QValueAvroConverter) processNestedJSON(value any) (interface{}, error) {
+	jsonVal, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s to JSON: %w", c.Type, err)
+	}
+	return c.processNullableUnion("string", string(jsonVal))
+}
+
+// processStruct converts v to a record of the schema returned by GetAvroFieldSchema
+func (c *QValueAvroConverter) processStruct(v QValueStruct) (interface{}, error) {
+	record := make(map[string]interface{}, len(c.Fields))
+	for _, member := range c.Fields {
+		member.Nullable = true
+		memberQv, _ := v.Val[member.Name].(QValue)
+		memberVal, err := qvalueToAvro(memberQv, &member, c.avroName+"_"+member.Name, c.TargetDWH, c.logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert member %s: %w", member.Name, err)
+		}
+		record[member.Name] = memberVal
+	}
+	return c.processNullableUnion(c.avroName, record)
+}
+
+// processArray converts v to an array of the schema returned by GetAvroFieldSchema
+func (c *QValueAvroConverter) processArray(v QValueArray) (interface{}, error) {
+	elementField := c.arrayElementField(v.ElementKind, c.TargetDWH)
+	elements := make([]interface{}, 0, len(v.Val))
+	for _, element := range v.Val {
+		elementVal, err := qvalueToAvro(element, &elementField, c.avroName, c.TargetDWH, c.logger)
+		if err != nil {
+			return nil, err
+		}
+		if elementVal == nil && !elementField.Nullable {
+			continue
+		}
+		elements = append(elements, elementVal)
+	}
+	return c.processNullableUnion("array", elements)
+}
+
+// processRange converts r to a record of the schema returned by GetAvroRangeSchema
+func (c *QValueAvroConverter) processRange(r Range, kind QValueKind) map[string]interface{} {
+	_, boundUnion := rangeBoundAvroType(kind, c.TargetDWH)
+	return r.Map(func(bound any) any {
+		switch b := bound.(type) {
+		case decimal.Decimal:
+			return goavro.Union(boundUnion, b.Rat())
+		case time.Time:
+			if DisallowedTimestamp(c.TargetDWH, b, c.logger) {
+				return nil
+			}
+			return goavro.Union(boundUnion, b)
+		default:
+			return goavro.Union(boundUnion, b)
+		}
+	})
+}
+
 func (c *QValueAvroConverter) processBytes(byteData []byte) interface{} {
 	if c.Nullable {
 		return goavro.Union("bytes", byteData)
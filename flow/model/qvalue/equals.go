@@ -46,6 +46,11 @@ func Equals(qv QValue, other QValue) bool {
 			return q.Val == otherVal.Val
 		}
 		return false
+	case QValueArray:
+		if otherVal, ok := other.(QValueArray); ok {
+			return q.compareArray(otherVal)
+		}
+		return false
 	case QValueStruct:
 		if otherVal, ok := other.(QValueStruct); ok {
 			return q.compareStruct(otherVal)
@@ -84,7 +89,7 @@ func Equals(qv QValue, other QValue) bool {
 		return compareGeometry(q.Val, otherValue)
 	case QValueHStore:
 		return compareHStore(q.Val, otherValue)
-	case QValueArrayInt32, QValueArrayInt16, QValueArrayInt64, QValueArrayFloat32, QValueArrayFloat64:
+	case QValueArrayInt32, QValueArrayInt16, QValueArrayInt64, QValueArrayFloat32, QValueArrayFloat64, QValueVector:
 		return compareNumericArrays(qvValue, otherValue)
 	case QValueArrayDate:
 		return compareDateArrays(q.Val, otherValue)
@@ -94,6 +99,14 @@ func Equals(qv QValue, other QValue) bool {
 		return compareBoolArrays(q.Val, otherValue)
 	case QValueArrayString:
 		return compareArrayString(q.Val, otherValue)
+	case QValueEnum:
+		return compareString(q.Val, otherValue)
+	case QValueRange:
+		r2, ok := otherValue.(Range)
+		return ok && compareRange(q.Val, r2)
+	case QValueMultirange:
+		m2, ok := otherValue.([]Range)
+		return ok && slices.EqualFunc(q.Val, m2, compareRange)
 	default:
 		return false
 	}
@@ -202,6 +215,40 @@ func compareGeometry(geoWkt string, value2 interface{}) bool {
 	return geo1.Equals(geo2)
 }
 
+func compareRangeBound(b1, b2 any) bool {
+	if b1 == nil || b2 == nil {
+		return b1 == b2
+	}
+	switch v1 := b1.(type) {
+	case decimal.Decimal:
+		return compareNumeric(v1, b2)
+	case time.Time:
+		return compareGoTime(v1, b2)
+	default:
+		return b1 == b2
+	}
+}
+
+func compareRange(r1, r2 Range) bool {
+	if r1.Empty || r2.Empty {
+		return r1.Empty == r2.Empty
+	}
+	return r1.LowerInclusive == r2.LowerInclusive && r1.UpperInclusive == r2.UpperInclusive &&
+		compareRangeBound(r1.Lower, r2.Lower) && compareRangeBound(r1.Upper, r2.Upper)
+}
+
+func (v QValueArray) compareArray(value2 QValueArray) bool {
+	if len(v.Val) != len(value2.Val) {
+		return false
+	}
+	for i, element := range v.Val {
+		if !Equals(element, value2.Val[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 func (v QValueStruct) compareStruct(value2 QValueStruct) bool {
 	struct1 := v.Val
 	struct2 := value2.Val
@@ -35,6 +35,9 @@ const (
 	QValueKindGeography   QValueKind = "geography"
 	QValueKindGeometry    QValueKind = "geometry"
 	QValueKindPoint       QValueKind = "point"
+	QValueKindEnum        QValueKind = "enum"
+	// pgvector embeddings, the type modifier is the number of dimensions
+	QValueKindVector QValueKind = "vector"
 
 	// network types
 	QValueKindCIDR    QValueKind = "cidr"
@@ -52,12 +55,77 @@ const (
 	QValueKindArrayTimestamp   QValueKind = "array_timestamp"
 	QValueKindArrayTimestampTZ QValueKind = "array_timestamptz"
 	QValueKindArrayBoolean     QValueKind = "array_bool"
+
+	// range types, named after the kind of their bounds
+	QValueKindRangeInt32       QValueKind = "range_int32"
+	QValueKindRangeInt64       QValueKind = "range_int64"
+	QValueKindRangeNumeric     QValueKind = "range_numeric"
+	QValueKindRangeDate        QValueKind = "range_date"
+	QValueKindRangeTimestamp   QValueKind = "range_timestamp"
+	QValueKindRangeTimestampTZ QValueKind = "range_timestamptz"
+
+	// multirange types, a sorted list of non overlapping ranges
+	QValueKindMultirangeInt32       QValueKind = "multirange_int32"
+	QValueKindMultirangeInt64       QValueKind = "multirange_int64"
+	QValueKindMultirangeNumeric     QValueKind = "multirange_numeric"
+	QValueKindMultirangeDate        QValueKind = "multirange_date"
+	QValueKindMultirangeTimestamp   QValueKind = "multirange_timestamp"
+	QValueKindMultirangeTimestampTZ QValueKind = "multirange_timestamptz"
 )
 
 func (kind QValueKind) IsArray() bool {
 	return strings.HasPrefix(string(kind), "array_")
 }
 
+// QValueKindArrayOf is the kind of arrays of elemKind, array kinds are named after the kind of their elements
+func QValueKindArrayOf(elemKind QValueKind) QValueKind {
+	return "array_" + elemKind
+}
+
+// ArrayElementKind returns the kind of the elements of an array kind
+func (kind QValueKind) ArrayElementKind() QValueKind {
+	return QValueKind(strings.TrimPrefix(string(kind), "array_"))
+}
+
+// IsGenericArray is true for array kinds held by QValueArray, arrays of scalars predating it have their own QValue
+func (kind QValueKind) IsGenericArray() bool {
+	if !kind.IsArray() {
+		return false
+	}
+	switch kind {
+	case QValueKindArrayFloat32, QValueKindArrayFloat64, QValueKindArrayInt16, QValueKindArrayInt32, QValueKindArrayInt64,
+		QValueKindArrayString, QValueKindArrayDate, QValueKindArrayTimestamp, QValueKindArrayTimestampTZ, QValueKindArrayBoolean:
+		return false
+	default:
+		return true
+	}
+}
+
+func (kind QValueKind) IsRange() bool {
+	return strings.HasPrefix(string(kind), "range_")
+}
+
+func (kind QValueKind) IsMultirange() bool {
+	return strings.HasPrefix(string(kind), "multirange_")
+}
+
+// RangeElementKind returns the kind of the bounds of a range or multirange kind
+func (kind QValueKind) RangeElementKind() QValueKind {
+	_, elemKind, _ := strings.Cut(string(kind), "range_")
+	return QValueKind(elemKind)
+}
+
+// RangeKind returns the kind of the ranges making up a multirange kind
+func (kind QValueKind) RangeKind() QValueKind {
+	return QValueKind("range_" + kind.RangeElementKind())
+}
+
+// clickhouseRangeTuple is the named Tuple ranges are stored as, bounds are NULL when unbounded
+func clickhouseRangeTuple(elemType string) string {
+	return fmt.Sprintf("Tuple(lower Nullable(%[1]s), upper Nullable(%[1]s), lower_inclusive Bool, upper_inclusive Bool, empty Bool)",
+		elemType)
+}
+
 var QValueKindToSnowflakeTypeMap = map[QValueKind]string{
 	QValueKindBoolean:     "BOOLEAN",
 	QValueKindInt16:       "INTEGER",
@@ -77,13 +145,31 @@ var QValueKindToSnowflakeTypeMap = map[QValueKind]string{
 	QValueKindDate:        "DATE",
 	QValueKindBit:         "BINARY",
 	QValueKindBytes:       "BINARY",
-	QValueKindStruct:      "STRING",
+	QValueKindStruct:      "OBJECT",
 	QValueKindUUID:        "STRING",
 	QValueKindInvalid:     "STRING",
 	QValueKindHStore:      "VARIANT",
 	QValueKindGeography:   "GEOGRAPHY",
 	QValueKindGeometry:    "GEOMETRY",
 	QValueKindPoint:       "GEOMETRY",
+	QValueKindEnum:        "STRING",
+
+	// ranges are objects of bounds and inclusivity, multiranges arrays of those objects
+	QValueKindRangeInt32:            "OBJECT",
+	QValueKindRangeInt64:            "OBJECT",
+	QValueKindRangeNumeric:          "OBJECT",
+	QValueKindRangeDate:             "OBJECT",
+	QValueKindRangeTimestamp:        "OBJECT",
+	QValueKindRangeTimestampTZ:      "OBJECT",
+	QValueKindMultirangeInt32:       "ARRAY",
+	QValueKindMultirangeInt64:       "ARRAY",
+	QValueKindMultirangeNumeric:     "ARRAY",
+	QValueKindMultirangeDate:        "ARRAY",
+	QValueKindMultirangeTimestamp:   "ARRAY",
+	QValueKindMultirangeTimestampTZ: "ARRAY",
+
+	// without dimensions a vector can't be a VECTOR column
+	QValueKindVector: "ARRAY",
 
 	// array types will be mapped to VARIANT
 	QValueKindArrayFloat32:     "VARIANT",
@@ -120,6 +206,22 @@ var QValueKindToClickhouseTypeMap = map[QValueKind]string{
 	QValueKindTimeTZ:      "String",
 	QValueKindInvalid:     "String",
 	QValueKindHStore:      "String",
+	// enum labels can be added at the source at any time, which would break a ClickHouse Enum
+	QValueKindEnum:   "LowCardinality(String)",
+	QValueKindVector: "Array(Float32)",
+
+	QValueKindRangeInt32:            clickhouseRangeTuple("Int32"),
+	QValueKindRangeInt64:            clickhouseRangeTuple("Int64"),
+	QValueKindRangeNumeric:          clickhouseRangeTuple("Decimal(76, 38)"),
+	QValueKindRangeDate:             clickhouseRangeTuple("Date"),
+	QValueKindRangeTimestamp:        clickhouseRangeTuple("DateTime64(6)"),
+	QValueKindRangeTimestampTZ:      clickhouseRangeTuple("DateTime64(6)"),
+	QValueKindMultirangeInt32:       "Array(" + clickhouseRangeTuple("Int32") + ")",
+	QValueKindMultirangeInt64:       "Array(" + clickhouseRangeTuple("Int64") + ")",
+	QValueKindMultirangeNumeric:     "Array(" + clickhouseRangeTuple("Decimal(76, 38)") + ")",
+	QValueKindMultirangeDate:        "Array(" + clickhouseRangeTuple("Date") + ")",
+	QValueKindMultirangeTimestamp:   "Array(" + clickhouseRangeTuple("DateTime64(6)") + ")",
+	QValueKindMultirangeTimestampTZ: "Array(" + clickhouseRangeTuple("DateTime64(6)") + ")",
 
 	// array types will be mapped to VARIANT
 	QValueKindArrayFloat32: "Array(Float32)",
@@ -136,12 +238,20 @@ func (kind QValueKind) ToDWHColumnType(dwhType protos.DBType) (string, error) {
 	case protos.DBType_SNOWFLAKE:
 		if val, ok := QValueKindToSnowflakeTypeMap[kind]; ok {
 			return val, nil
+		} else if kind.IsGenericArray() {
+			return "ARRAY", nil
 		} else {
 			return "STRING", nil
 		}
 	case protos.DBType_CLICKHOUSE:
 		if val, ok := QValueKindToClickhouseTypeMap[kind]; ok {
 			return val, nil
+		} else if kind.IsGenericArray() {
+			elemType, err := kind.ArrayElementKind().ToDWHColumnType(dwhType)
+			if err != nil {
+				return "", err
+			}
+			return "Array(" + elemType + ")", nil
 		} else {
 			return "String", nil
 		}
@@ -149,3 +259,29 @@ func (kind QValueKind) ToDWHColumnType(dwhType protos.DBType) (string, error) {
 		return "", fmt.Errorf("unknown dwh type: %v", dwhType)
 	}
 }
+
+// ToDWHColumnTypeWithFields is ToDWHColumnType for columns which may be composites or arrays of composites,
+// fields are the members of the composite, ClickHouse nests them in a named Tuple
+func (kind QValueKind) ToDWHColumnTypeWithFields(dwhType protos.DBType, fields []*protos.FieldDescription) (string, error) {
+	if dwhType != protos.DBType_CLICKHOUSE || len(fields) == 0 {
+		return kind.ToDWHColumnType(dwhType)
+	}
+	if kind == QValueKindStruct {
+		members := make([]string, 0, len(fields))
+		for _, field := range fields {
+			memberType, err := QValueKind(field.Type).ToDWHColumnTypeWithFields(dwhType, field.Fields)
+			if err != nil {
+				return "", err
+			}
+			members = append(members, fmt.Sprintf("`%s` %s", field.Name, memberType))
+		}
+		return "Tuple(" + strings.Join(members, ", ") + ")", nil
+	} else if kind.IsArray() {
+		elemType, err := kind.ArrayElementKind().ToDWHColumnTypeWithFields(dwhType, fields)
+		if err != nil {
+			return "", err
+		}
+		return "Array(" + elemType + ")", nil
+	}
+	return kind.ToDWHColumnType(dwhType)
+}
@@ -10,6 +10,8 @@ type QField struct {
 	Precision int16
 	Scale     int16
 	Nullable  bool
+	// members of composites, or of the composite elements of arrays
+	Fields []QField
 }
 
 type QRecordSchema struct {
@@ -2,6 +2,7 @@ package qvalue
 
 import (
 	"encoding/json"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -145,6 +146,7 @@ func (v QValueBoolean) LValue(ls *lua.LState) lua.LValue {
 	return lua.LBool(v.Val)
 }
 
+// QValueStruct is a composite, its members are QValues keyed by name
 type QValueStruct struct {
 	Val map[string]interface{}
 }
@@ -157,8 +159,21 @@ func (v QValueStruct) Value() any {
 	return v.Val
 }
 
+// JSONValue is the composite as an object of its members formatted like top level columns
+func (v QValueStruct) JSONValue() map[string]any {
+	members := make(map[string]any, len(v.Val))
+	for name, member := range v.Val {
+		if qv, ok := member.(QValue); ok {
+			members[name] = nestedJSONValue(qv)
+		} else {
+			members[name] = member
+		}
+	}
+	return members
+}
+
 func (v QValueStruct) LValue(ls *lua.LState) lua.LValue {
-	bytes, err := json.Marshal(v.Val)
+	bytes, err := json.Marshal(v.JSONValue())
 	if err != nil {
 		return lua.LString(err.Error())
 	} else {
@@ -504,6 +519,24 @@ func (v QValueArrayFloat32) LValue(ls *lua.LState) lua.LValue {
 	})
 }
 
+type QValueVector struct {
+	Val []float32
+}
+
+func (QValueVector) Kind() QValueKind {
+	return QValueKindVector
+}
+
+func (v QValueVector) Value() any {
+	return v.Val
+}
+
+func (v QValueVector) LValue(ls *lua.LState) lua.LValue {
+	return shared.SliceToLTable(ls, v.Val, func(f float32) lua.LValue {
+		return lua.LNumber(f)
+	})
+}
+
 type QValueArrayFloat64 struct {
 	Val []float64
 }
@@ -665,3 +698,224 @@ func (v QValueArrayBoolean) LValue(ls *lua.LState) lua.LValue {
 		return lua.LBool(x)
 	})
 }
+
+type QValueEnum struct {
+	Val string
+}
+
+func (QValueEnum) Kind() QValueKind {
+	return QValueKindEnum
+}
+
+func (v QValueEnum) Value() any {
+	return v.Val
+}
+
+func (v QValueEnum) LValue(ls *lua.LState) lua.LValue {
+	return lua.LString(v.Val)
+}
+
+// Range holds the bounds of a range, Lower and Upper are nil when unbounded,
+// otherwise an int32, int64, decimal.Decimal or time.Time depending on the range kind
+type Range struct {
+	Lower          any
+	Upper          any
+	LowerInclusive bool
+	UpperInclusive bool
+	Empty          bool
+}
+
+// Map returns the range as lower, upper, lower_inclusive, upper_inclusive and empty fields,
+// bounds being passed through convertBound unless unbounded
+func (r Range) Map(convertBound func(any) any) map[string]any {
+	m := map[string]any{
+		"lower":           nil,
+		"upper":           nil,
+		"lower_inclusive": r.LowerInclusive,
+		"upper_inclusive": r.UpperInclusive,
+		"empty":           r.Empty,
+	}
+	if r.Lower != nil {
+		m["lower"] = convertBound(r.Lower)
+	}
+	if r.Upper != nil {
+		m["upper"] = convertBound(r.Upper)
+	}
+	return m
+}
+
+// jsonRangeBound formats bounds the same way scalars of their kind are written to raw tables
+func jsonRangeBound(elemKind QValueKind) func(any) any {
+	return func(bound any) any {
+		switch b := bound.(type) {
+		case decimal.Decimal:
+			return b.String()
+		case time.Time:
+			switch elemKind {
+			case QValueKindDate:
+				return b.Format("2006-01-02")
+			case QValueKindTimestampTZ:
+				return b.Format("2006-01-02 15:04:05.999999-0700")
+			default:
+				return b.Format("2006-01-02 15:04:05.999999")
+			}
+		default:
+			return b
+		}
+	}
+}
+
+func rangeBoundLValue(ls *lua.LState, bound any) lua.LValue {
+	switch b := bound.(type) {
+	case int32:
+		return lua.LNumber(b)
+	case int64:
+		return glua64.I64.New(ls, b)
+	case decimal.Decimal:
+		return shared.LuaDecimal.New(ls, b)
+	case time.Time:
+		return shared.LuaTime.New(ls, b)
+	default:
+		return lua.LNil
+	}
+}
+
+func (r Range) LValue(ls *lua.LState) lua.LValue {
+	tbl := ls.NewTable()
+	for k, v := range r.Map(func(bound any) any { return rangeBoundLValue(ls, bound) }) {
+		switch v := v.(type) {
+		case lua.LValue:
+			tbl.RawSetString(k, v)
+		case bool:
+			tbl.RawSetString(k, lua.LBool(v))
+		}
+	}
+	return tbl
+}
+
+type QValueRange struct {
+	RangeKind QValueKind
+	Val       Range
+}
+
+func (v QValueRange) Kind() QValueKind {
+	return v.RangeKind
+}
+
+func (v QValueRange) Value() any {
+	return v.Val
+}
+
+// JSONValue is the range as an object of JSON friendly bounds
+func (v QValueRange) JSONValue() map[string]any {
+	return v.Val.Map(jsonRangeBound(v.RangeKind.RangeElementKind()))
+}
+
+func (v QValueRange) LValue(ls *lua.LState) lua.LValue {
+	return v.Val.LValue(ls)
+}
+
+type QValueMultirange struct {
+	MultirangeKind QValueKind
+	Val            []Range
+}
+
+func (v QValueMultirange) Kind() QValueKind {
+	return v.MultirangeKind
+}
+
+func (v QValueMultirange) Value() any {
+	return v.Val
+}
+
+// JSONValue is the multirange as an array of range objects with JSON friendly bounds
+func (v QValueMultirange) JSONValue() []map[string]any {
+	convertBound := jsonRangeBound(v.MultirangeKind.RangeElementKind())
+	ranges := make([]map[string]any, 0, len(v.Val))
+	for _, r := range v.Val {
+		ranges = append(ranges, r.Map(convertBound))
+	}
+	return ranges
+}
+
+func (v QValueMultirange) LValue(ls *lua.LState) lua.LValue {
+	return shared.SliceToLTable(ls, v.Val, func(r Range) lua.LValue {
+		return r.LValue(ls)
+	})
+}
+
+// QValueArray is an array of any kind without a dedicated array QValue,
+// elements are QValues of ElementKind or QValueNull
+type QValueArray struct {
+	ElementKind QValueKind
+	Val         []QValue
+}
+
+func (v QValueArray) Kind() QValueKind {
+	return QValueKindArrayOf(v.ElementKind)
+}
+
+func (v QValueArray) Value() any {
+	return v.Val
+}
+
+// JSONValue is the array with elements formatted like top level columns
+func (v QValueArray) JSONValue() []any {
+	elements := make([]any, 0, len(v.Val))
+	for _, element := range v.Val {
+		elements = append(elements, nestedJSONValue(element))
+	}
+	return elements
+}
+
+func (v QValueArray) LValue(ls *lua.LState) lua.LValue {
+	return shared.SliceToLTable(ls, v.Val, func(x QValue) lua.LValue {
+		if x == nil {
+			return lua.LNil
+		}
+		return x.LValue(ls)
+	})
+}
+
+// nestedJSONValue converts members of composites and elements of arrays to JSON friendly values
+func nestedJSONValue(qv QValue) any {
+	if qv == nil || qv.Value() == nil {
+		return nil
+	}
+	switch v := qv.(type) {
+	case QValueStruct:
+		return v.JSONValue()
+	case QValueArray:
+		return v.JSONValue()
+	case QValueRange:
+		return v.JSONValue()
+	case QValueMultirange:
+		return v.JSONValue()
+	case QValueNumeric:
+		return v.Val.String()
+	case QValueUUID:
+		return uuid.UUID(v.Val).String()
+	case QValueQChar:
+		return string(v.Val)
+	case QValueTimestamp:
+		return v.Val.Format("2006-01-02 15:04:05.999999")
+	case QValueTimestampTZ:
+		return v.Val.Format("2006-01-02 15:04:05.999999-0700")
+	case QValueDate:
+		return v.Val.Format("2006-01-02")
+	case QValueTime, QValueTimeTZ:
+		return v.Value().(time.Time).Format("15:04:05.999999")
+	case QValueFloat32:
+		if math.IsNaN(float64(v.Val)) || math.IsInf(float64(v.Val), 0) {
+			return nil
+		}
+		return v.Val
+	case QValueFloat64:
+		if math.IsNaN(v.Val) || math.IsInf(v.Val, 0) {
+			return nil
+		}
+		return v.Val
+	default:
+		return v.Value()
+	}
+}
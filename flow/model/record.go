@@ -9,6 +9,10 @@ import (
 type Record[T Items] interface {
 	GetCheckpointID() int64
 	GetCommitTime() time.Time
+	// GetCommitLSN is the commit of a streamed or prepared transaction, zero for records of other transactions
+	GetCommitLSN() int64
+	// streamed transactions only learn their commit once all their records were decoded
+	SetCommit(checkpointID int64, commitLSN int64, commitTime time.Time)
 	GetDestinationTableName() string
 	GetSourceTableName() string
 	// get columns and values for the record
@@ -21,6 +25,8 @@ type BaseRecord struct {
 	CheckpointID int64 `json:"checkpointId"`
 	// BeginMessage.CommitTime.UnixNano(), 16 bytes smaller than time.Time
 	CommitTimeNano int64 `json:"commitTimeNano"`
+	// CommitLSN is set on records of buffered transactions, which may be split over several batches
+	CommitLSN int64 `json:"commitLsn,omitempty"`
 }
 
 func (r *BaseRecord) GetCheckpointID() int64 {
@@ -31,6 +37,16 @@ func (r *BaseRecord) GetCommitTime() time.Time {
 	return time.Unix(0, r.CommitTimeNano)
 }
 
+func (r *BaseRecord) GetCommitLSN() int64 {
+	return r.CommitLSN
+}
+
+func (r *BaseRecord) SetCommit(checkpointID int64, commitLSN int64, commitTime time.Time) {
+	r.CheckpointID = checkpointID
+	r.CommitLSN = commitLSN
+	r.CommitTimeNano = commitTime.UnixNano()
+}
+
 type InsertRecord[T Items] struct {
 	// Items is a map of column name to value.
 	Items T
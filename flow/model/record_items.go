@@ -199,6 +199,9 @@ func (r RecordItems) toMap(opts ToJSONOptions) (map[string]interface{}, error) {
 				}
 			}
 			jsonStruct[col] = nullableFloatArr
+		case qvalue.QValueVector:
+			// pgvector rejects NaN and infinity
+			jsonStruct[col] = v.Val
 		case qvalue.QValueArrayFloat32:
 			floatArr := v.Val
 			nullableFloatArr := make([]interface{}, 0, len(floatArr))
@@ -210,6 +213,14 @@ func (r RecordItems) toMap(opts ToJSONOptions) (map[string]interface{}, error) {
 				}
 			}
 			jsonStruct[col] = nullableFloatArr
+		case qvalue.QValueRange:
+			jsonStruct[col] = v.JSONValue()
+		case qvalue.QValueMultirange:
+			jsonStruct[col] = v.JSONValue()
+		case qvalue.QValueStruct:
+			jsonStruct[col] = v.JSONValue()
+		case qvalue.QValueArray:
+			jsonStruct[col] = v.JSONValue()
 
 		default:
 			jsonStruct[col] = v.Value()
@@ -135,6 +135,10 @@ var CDCDynamicPropertiesSignal = TypedSignal[*protos.CDCFlowConfigUpdate]{
 	Name: "cdc-dynamic-properties",
 }
 
+var QRepDynamicPropertiesSignal = TypedSignal[*protos.QRepFlowConfigUpdate]{
+	Name: "qrep-dynamic-properties",
+}
+
 var SyncStopSignal = TypedSignal[struct{}]{
 	Name: "sync-stop",
 }
@@ -19,8 +19,8 @@ type OtelManager struct {
 	Int64GaugesCache   map[string]*Int64Gauge
 }
 
-// newOtelResource returns a resource describing this application.
-func newOtelResource(otelServiceName string) (*resource.Resource, error) {
+// NewOtelResource returns a resource describing this application.
+func NewOtelResource(otelServiceName string) (*resource.Resource, error) {
 	r, err := resource.Merge(
 		resource.Default(),
 		resource.NewWithAttributes(
@@ -40,7 +40,7 @@ func SetupOtelMetricsExporter(otelServiceName string) (*sdkmetric.MeterProvider,
 		return nil, fmt.Errorf("failed to create OpenTelemetry metrics exporter: %w", err)
 	}
 
-	resource, err := newOtelResource(otelServiceName)
+	resource, err := NewOtelResource(otelServiceName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create OpenTelemetry resource: %w", err)
 	}
@@ -0,0 +1,37 @@
+package otel_tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/PeerDB-io/peer-flow/otel_metrics"
+)
+
+// SetupOtelTraceExporter exports spans over OTLP, configured by the same OTEL_EXPORTER_OTLP_* variables as metrics.
+// The provider is installed globally along with W3C trace context propagation, so spans started before setup are dropped
+func SetupOtelTraceExporter(otelServiceName string) (*sdktrace.TracerProvider, error) {
+	traceExporter, err := otlptracehttp.New(context.Background(),
+		otlptracehttp.WithCompression(otlptracehttp.GzipCompression),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OpenTelemetry trace exporter: %w", err)
+	}
+
+	resource, err := otel_metrics.NewOtelResource(otelServiceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OpenTelemetry resource: %w", err)
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(traceExporter),
+		sdktrace.WithResource(resource),
+	)
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return tracerProvider, nil
+}
@@ -0,0 +1,44 @@
+package otel_tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/PeerDB-io/peer-flow/shared"
+)
+
+// tracer delegates to the global provider, a noop until SetupOtelTraceExporter is called
+var tracer = otel.Tracer("io.peerdb.flow")
+
+func BatchID(batchID int64) attribute.KeyValue {
+	return attribute.Int64("peerdb.batch_id", batchID)
+}
+
+func Table(tableName string) attribute.KeyValue {
+	return attribute.String("peerdb.table", tableName)
+}
+
+func Peer(peerName string) attribute.KeyValue {
+	return attribute.String("peerdb.peer", peerName)
+}
+
+// Start starts a span as a child of any span in ctx, tagged with the flow name in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if flowName, ok := ctx.Value(shared.FlowNameKey).(string); ok {
+		attrs = append(attrs, attribute.String("peerdb.flow_name", flowName))
+	}
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End marks span as failed if err is set, then ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,20 @@
+package otel_tracing
+
+import (
+	"go.opentelemetry.io/otel"
+	temporalotel "go.temporal.io/sdk/contrib/opentelemetry"
+	"go.temporal.io/sdk/interceptor"
+)
+
+// NewTemporalTracingInterceptor propagates spans through workflow and activity headers,
+// so a sync batch shows up as one trace from the CDC workflow down to connector spans.
+// It is registered on clients only as workers created from a client share its interceptors
+func NewTemporalTracingInterceptor() (interceptor.Interceptor, error) {
+	return temporalotel.NewTracingInterceptor(temporalotel.TracerOptions{
+		Tracer:            tracer,
+		TextMapPropagator: otel.GetTextMapPropagator(),
+		// signals and queries are frequent and short, they'd crowd out sync spans
+		DisableSignalTracing: true,
+		DisableQueryTracing:  true,
+	})
+}
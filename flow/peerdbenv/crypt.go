@@ -0,0 +1,207 @@
+package peerdbenv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// encrypted catalog blobs are laid out as
+// encMagic | len(keyID) | keyID | nonce | sealed data key | nonce | sealed payload
+// a leading zero byte is never valid protobuf, so unencrypted rows stay readable
+var encMagic = []byte("\x00PDBENC1")
+
+const (
+	encKeySize        = 32
+	encNonceSize      = 12
+	encTagSize        = 16
+	encWrappedKeySize = encNonceSize + encKeySize + encTagSize
+)
+
+type EncKey struct {
+	ID    string `json:"id"`
+	Value string `json:"value"`
+}
+
+type EncKeys []EncKey
+
+// PEERDB_ENC_KEYS_FILE, falls back to PEERDB_ENC_KEYS
+// both hold a JSON array of {"id": ..., "value": <base64 of 32 bytes>}
+func PeerDBEncKeys() (EncKeys, error) {
+	var raw []byte
+	if path := GetEnvString("PEERDB_ENC_KEYS_FILE", ""); path != "" {
+		var err error
+		raw, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read encryption keys file: %w", err)
+		}
+	} else {
+		raw = []byte(GetEnvString("PEERDB_ENC_KEYS", ""))
+	}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, nil
+	}
+
+	var keys EncKeys
+	if err := json.Unmarshal(raw, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse encryption keys: %w", err)
+	}
+	return keys, nil
+}
+
+// PEERDB_CURRENT_ENC_KEY_ID, catalog blobs are written unencrypted when empty
+func PeerDBCurrentEncKeyID() string {
+	return GetEnvString("PEERDB_CURRENT_ENC_KEY_ID", "")
+}
+
+func (keys EncKeys) Get(id string) (EncKey, error) {
+	for _, key := range keys {
+		if key.ID == id {
+			return key, nil
+		}
+	}
+	return EncKey{}, fmt.Errorf("encryption key %s not found", id)
+}
+
+func (key EncKey) aead() (cipher.AEAD, error) {
+	value, err := base64.StdEncoding.DecodeString(key.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode encryption key %s: %w", key.ID, err)
+	}
+	if len(value) != encKeySize {
+		return nil, fmt.Errorf("encryption key %s must be %d bytes, got %d", key.ID, encKeySize, len(value))
+	}
+	return newAEAD(value)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, dst []byte, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, encNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	dst = append(dst, nonce...)
+	return aead.Seal(dst, nonce, plaintext, nil), nil
+}
+
+// Encrypt seals plaintext with a fresh data key, which is in turn sealed by the key with currentID
+func (keys EncKeys) Encrypt(currentID string, plaintext []byte) ([]byte, error) {
+	if currentID == "" {
+		return plaintext, nil
+	}
+	if len(currentID) > 255 {
+		return nil, errors.New("encryption key id must be at most 255 bytes")
+	}
+	key, err := keys.Get(currentID)
+	if err != nil {
+		return nil, err
+	}
+	keyAEAD, err := key.aead()
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := make([]byte, encKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(encMagic)+1+len(currentID)+encWrappedKeySize+encNonceSize+len(plaintext)+encTagSize)
+	out = append(out, encMagic...)
+	out = append(out, byte(len(currentID)))
+	out = append(out, currentID...)
+	if out, err = seal(keyAEAD, out, dataKey); err != nil {
+		return nil, err
+	}
+	return seal(dataAEAD, out, plaintext)
+}
+
+// EncKeyID returns the id of the key data was encrypted with, ok is false for unencrypted data
+func EncKeyID(data []byte) (string, bool) {
+	if !bytes.HasPrefix(data, encMagic) || len(data) <= len(encMagic) {
+		return "", false
+	}
+	idLen := int(data[len(encMagic)])
+	idStart := len(encMagic) + 1
+	if len(data) < idStart+idLen {
+		return "", false
+	}
+	return string(data[idStart : idStart+idLen]), true
+}
+
+// Decrypt opens data sealed by Encrypt, unencrypted data is returned as is
+func (keys EncKeys) Decrypt(data []byte) ([]byte, error) {
+	keyID, ok := EncKeyID(data)
+	if !ok {
+		return data, nil
+	}
+	key, err := keys.Get(keyID)
+	if err != nil {
+		return nil, err
+	}
+	keyAEAD, err := key.aead()
+	if err != nil {
+		return nil, err
+	}
+
+	rest := data[len(encMagic)+1+len(keyID):]
+	if len(rest) < encWrappedKeySize+encNonceSize+encTagSize {
+		return nil, errors.New("encrypted data is truncated")
+	}
+	dataKey, err := keyAEAD.Open(nil, rest[:encNonceSize], rest[encNonceSize:encWrappedKeySize], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key with key %s: %w", keyID, err)
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	rest = rest[encWrappedKeySize:]
+	plaintext, err := dataAEAD.Open(nil, rest[:encNonceSize], rest[encNonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data: %w", err)
+	}
+	return plaintext, nil
+}
+
+// EncryptCatalogData encrypts a blob about to be stored in the catalog with the current key
+func EncryptCatalogData(plaintext []byte) ([]byte, error) {
+	currentID := PeerDBCurrentEncKeyID()
+	if currentID == "" {
+		return plaintext, nil
+	}
+	keys, err := PeerDBEncKeys()
+	if err != nil {
+		return nil, err
+	}
+	return keys.Encrypt(currentID, plaintext)
+}
+
+// DecryptCatalogData decrypts a blob loaded from the catalog, keys are only needed for encrypted blobs
+func DecryptCatalogData(data []byte) ([]byte, error) {
+	if _, ok := EncKeyID(data); !ok {
+		return data, nil
+	}
+	keys, err := PeerDBEncKeys()
+	if err != nil {
+		return nil, err
+	}
+	return keys.Decrypt(data)
+}
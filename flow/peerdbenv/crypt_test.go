@@ -0,0 +1,43 @@
+package peerdbenv
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(id string, fill byte) EncKey {
+	return EncKey{ID: id, Value: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{fill}, encKeySize))}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	keys := EncKeys{testKey("old", 1), testKey("new", 2)}
+	plaintext := []byte("\x0a\x04host\x10\x01")
+
+	oldData, err := keys.Encrypt("old", plaintext)
+	require.NoError(t, err)
+	keyID, ok := EncKeyID(oldData)
+	require.True(t, ok)
+	require.Equal(t, "old", keyID)
+	newData, err := keys.Encrypt("new", plaintext)
+	require.NoError(t, err)
+
+	for _, data := range [][]byte{oldData, newData, plaintext} {
+		decrypted, err := keys.Decrypt(data)
+		require.NoError(t, err)
+		require.Equal(t, plaintext, decrypted)
+	}
+
+	_, err = EncKeys{testKey("new", 2)}.Decrypt(oldData)
+	require.Error(t, err, "decrypting with a missing key")
+	tampered := bytes.Clone(newData)
+	tampered[len(tampered)-1] ^= 1
+	_, err = keys.Decrypt(tampered)
+	require.Error(t, err, "decrypting tampered data")
+
+	unencrypted, err := keys.Encrypt("", plaintext)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, unencrypted)
+}
@@ -0,0 +1,105 @@
+package prom_metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/PeerDB-io/peer-flow/model"
+)
+
+const (
+	flowNameLabel = "flow_name"
+	peerLabel     = "peer"
+	tableLabel    = "table"
+	opLabel       = "op"
+)
+
+// Registry holds every metric served on /metrics, kept apart from the global registry
+// so only PeerDB and process metrics are exposed
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+var factory = promauto.With(Registry)
+
+// batch durations range from sub-second for idle mirrors to hours for large backfills
+var batchDurationBuckets = prometheus.ExponentialBuckets(0.5, 2, 16)
+
+var (
+	RecordsPulled = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "peerdb",
+		Name:      "records_pulled_total",
+		Help:      "Records pulled from the source, by destination table and operation",
+	}, []string{flowNameLabel, tableLabel, opLabel})
+
+	RecordsSynced = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "peerdb",
+		Name:      "records_synced_total",
+		Help:      "Records synced to the destination, by destination table and operation",
+	}, []string{flowNameLabel, tableLabel, opLabel})
+
+	SyncBatchDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "peerdb",
+		Name:      "sync_batch_duration_seconds",
+		Help:      "Time taken to sync a batch of records to the destination",
+		Buckets:   batchDurationBuckets,
+	}, []string{flowNameLabel})
+
+	NormalizeBatchDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "peerdb",
+		Name:      "normalize_batch_duration_seconds",
+		Help:      "Time taken to normalize synced batches into destination tables",
+		Buckets:   batchDurationBuckets,
+	}, []string{flowNameLabel})
+
+	SyncLag = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "peerdb",
+		Name:      "sync_lag_seconds",
+		Help:      "Time between the source commit of the latest record in a batch and the batch being synced",
+		Buckets:   batchDurationBuckets,
+	}, []string{flowNameLabel})
+
+	RawTableBacklog = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "peerdb",
+		Name:      "raw_table_backlog_batches",
+		Help:      "Batches synced to the raw table which are not yet normalized, by destination peer",
+	}, []string{flowNameLabel, peerLabel})
+
+	QRepPartitionsInFlight = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "peerdb",
+		Name:      "qrep_partitions_in_flight",
+		Help:      "QRep partitions currently being replicated by this process",
+	}, []string{flowNameLabel})
+
+	LuaScriptErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "peerdb",
+		Name:      "lua_script_errors_total",
+		Help:      "Errors raised by a mirror's Lua script",
+	}, []string{flowNameLabel})
+)
+
+// AddRecordCounts adds per table insert, update and delete counts to counter
+func AddRecordCounts(counter *prometheus.CounterVec, flowName string, counts map[string]*model.RecordTypeCounts) {
+	for table, count := range counts {
+		if count.InsertCount > 0 {
+			counter.WithLabelValues(flowName, table, "insert").Add(float64(count.InsertCount))
+		}
+		if count.UpdateCount > 0 {
+			counter.WithLabelValues(flowName, table, "update").Add(float64(count.UpdateCount))
+		}
+		if count.DeleteCount > 0 {
+			counter.WithLabelValues(flowName, table, "delete").Add(float64(count.DeleteCount))
+		}
+	}
+}
+
+// SetRawTableBacklog sets the batches synced to the raw table of a mirror on peer which are not yet normalized
+func SetRawTableBacklog(flowName string, peerName string, syncBatchID int64, normalizeBatchID int64) {
+	RawTableBacklog.WithLabelValues(flowName, peerName).Set(float64(syncBatchID - normalizeBatchID))
+}
@@ -0,0 +1,67 @@
+package prom_metrics
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PeerDB-io/peer-flow/model"
+)
+
+func TestAddRecordCounts(t *testing.T) {
+	AddRecordCounts(RecordsSynced, "counts", map[string]*model.RecordTypeCounts{
+		"public.a": {InsertCount: 3, UpdateCount: 2},
+		"public.b": {DeleteCount: 1},
+	})
+	AddRecordCounts(RecordsSynced, "counts", map[string]*model.RecordTypeCounts{
+		"public.a": {InsertCount: 1},
+	})
+
+	require.InDelta(t, 4, testutil.ToFloat64(RecordsSynced.WithLabelValues("counts", "public.a", "insert")), 0)
+	require.InDelta(t, 2, testutil.ToFloat64(RecordsSynced.WithLabelValues("counts", "public.a", "update")), 0)
+	require.InDelta(t, 1, testutil.ToFloat64(RecordsSynced.WithLabelValues("counts", "public.b", "delete")), 0)
+	// operations without records don't create series
+	require.Equal(t, 3, testutil.CollectAndCount(RecordsSynced))
+}
+
+func TestSetRawTableBacklog(t *testing.T) {
+	SetRawTableBacklog("fanout", "primary", 10, 7)
+	SetRawTableBacklog("fanout", "secondary", 10, 2)
+	SetRawTableBacklog("fanout", "primary", 11, 11)
+
+	require.InDelta(t, 0, testutil.ToFloat64(RawTableBacklog.WithLabelValues("fanout", "primary")), 0)
+	require.InDelta(t, 8, testutil.ToFloat64(RawTableBacklog.WithLabelValues("fanout", "secondary")), 0)
+}
+
+func TestStartServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+
+	LuaScriptErrors.WithLabelValues("served").Inc()
+	stop := StartServer(uint16(port))
+	require.True(t, Enabled())
+
+	var body []byte
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/metrics", port))
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+		body, err = io.ReadAll(resp.Body)
+		return err == nil && resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Contains(t, string(body), `peerdb_lua_script_errors_total{flow_name="served"} 1`)
+	require.Contains(t, string(body), "go_goroutines")
+
+	stop()
+	require.False(t, Enabled())
+}
@@ -0,0 +1,47 @@
+package prom_metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var enabled atomic.Bool
+
+// Enabled reports whether a scrape endpoint is running,
+// metrics which cost extra queries to compute are skipped otherwise
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// StartServer serves Registry on /metrics at port, returning a function to shut the server down
+func StartServer(port uint16) func() {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry}))
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	enabled.Store(true)
+	slog.Info(fmt.Sprintf("Starting Prometheus metrics server on port %d", port))
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Prometheus metrics server failed", slog.Any("error", err))
+		}
+	}()
+
+	return func() {
+		enabled.Store(false)
+		if err := server.Shutdown(context.Background()); err != nil {
+			slog.Error("Failed to shutdown Prometheus metrics server", slog.Any("error", err))
+		}
+	}
+}
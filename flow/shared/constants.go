@@ -14,6 +14,7 @@ const (
 	CDCFlowStateQuery  = "q-cdc-flow-state"
 	QRepFlowStateQuery = "q-qrep-flow-state"
 	FlowStatusQuery    = "q-flow-status"
+	CutoverStatusQuery = "q-cutover-status"
 
 	// Updates
 	FlowStatusUpdate = "u-flow-status"
@@ -25,6 +26,16 @@ const (
 	FlowNameKey      ContextKey = "flowName"
 	PartitionIDKey   ContextKey = "partitionId"
 	DeploymentUIDKey ContextKey = "deploymentUid"
+	// name of the peer a connector is created for, set by connectors.GetConnector
+	PeerNameKey ContextKey = "peerName"
 )
 
 const FetchAndChannelSize = 256 * 1024
+
+// ReplicationOriginPrefix names the replication origins mirrors apply changes under,
+// mirrors skipping replicated changes skip changes from these origins when pulling from Postgres
+const ReplicationOriginPrefix = "peerdb_"
+
+// CutoverFenceMessagePrefix is the prefix of the logical decoding message marking the last write before a cutover,
+// the pull returns once its transaction commits so the destination catches up to it
+const CutoverFenceMessagePrefix = "peerdb_cutover"
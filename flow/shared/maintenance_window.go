@@ -0,0 +1,74 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+)
+
+// windows are enforced by a workflow running every minute, shorter ones could be missed
+const minMaintenanceWindowDuration = time.Minute
+
+// ValidateMaintenanceWindow checks the cron expression parses and the duration can be enforced
+func ValidateMaintenanceWindow(window *protos.MaintenanceWindow) error {
+	if _, err := cron.ParseStandard(window.CronExpression); err != nil {
+		return fmt.Errorf("invalid cron expression %q: %w", window.CronExpression, err)
+	}
+	if time.Duration(window.DurationSeconds)*time.Second < minMaintenanceWindowDuration {
+		return errors.New("maintenance window must last at least a minute")
+	}
+	return nil
+}
+
+// MaintenanceWindowAt returns the start and end of the occurrence of window containing at,
+// ok is false when at is outside every occurrence
+func MaintenanceWindowAt(window *protos.MaintenanceWindow, at time.Time) (time.Time, time.Time, bool, error) {
+	schedule, err := cron.ParseStandard(window.CronExpression)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, fmt.Errorf("invalid cron expression %q: %w", window.CronExpression, err)
+	}
+	duration := time.Duration(window.DurationSeconds) * time.Second
+	// occurrences starting after at-duration contain at, when they overlap the last to start ends last
+	start := schedule.Next(at.UTC().Add(-duration))
+	if start.IsZero() || start.After(at) {
+		return time.Time{}, time.Time{}, false, nil
+	}
+	for next := schedule.Next(start); !next.IsZero() && !next.After(at); next = schedule.Next(next) {
+		start = next
+	}
+	return start, start.Add(duration), true, nil
+}
+
+// NextMaintenanceWindow returns the start and end of the first occurrence of window starting after at
+func NextMaintenanceWindow(window *protos.MaintenanceWindow, at time.Time) (time.Time, time.Time, error) {
+	schedule, err := cron.ParseStandard(window.CronExpression)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid cron expression %q: %w", window.CronExpression, err)
+	}
+	start := schedule.Next(at.UTC())
+	return start, start.Add(time.Duration(window.DurationSeconds) * time.Second), nil
+}
+
+// ActiveMaintenanceWindowEnd returns when the windows active at at end, ok is false when none are active
+// or all active windows end by skipUntil
+func ActiveMaintenanceWindowEnd(
+	windows []*protos.MaintenanceWindow,
+	at time.Time,
+	skipUntil time.Time,
+) (time.Time, bool, error) {
+	var end time.Time
+	for _, window := range windows {
+		_, windowEnd, ok, err := MaintenanceWindowAt(window, at)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if ok && windowEnd.After(skipUntil) && windowEnd.After(end) {
+			end = windowEnd
+		}
+	}
+	return end, !end.IsZero(), nil
+}
@@ -0,0 +1,97 @@
+package shared
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+)
+
+func TestMaintenanceWindowAt(t *testing.T) {
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	nightly := &protos.MaintenanceWindow{CronExpression: "0 2 * * *", DurationSeconds: 3600}
+	// hourly windows lasting two hours overlap, each instant is inside two of them
+	overlapping := &protos.MaintenanceWindow{CronExpression: "0 * * * *", DurationSeconds: 2 * 3600}
+	testCases := []struct {
+		name   string
+		window *protos.MaintenanceWindow
+		at     time.Time
+		start  time.Time
+		end    time.Time
+		ok     bool
+	}{
+		{name: "before", window: nightly, at: day.Add(time.Hour + 59*time.Minute)},
+		{name: "at start", window: nightly, at: day.Add(2 * time.Hour),
+			start: day.Add(2 * time.Hour), end: day.Add(3 * time.Hour), ok: true},
+		{name: "inside", window: nightly, at: day.Add(2*time.Hour + 30*time.Minute),
+			start: day.Add(2 * time.Hour), end: day.Add(3 * time.Hour), ok: true},
+		{name: "at end", window: nightly, at: day.Add(3 * time.Hour)},
+		{name: "after", window: nightly, at: day.Add(12 * time.Hour)},
+		{name: "overlapping", window: overlapping, at: day.Add(10*time.Hour + 30*time.Minute),
+			start: day.Add(10 * time.Hour), end: day.Add(12 * time.Hour), ok: true},
+		{name: "overlapping at start", window: overlapping, at: day.Add(10 * time.Hour),
+			start: day.Add(10 * time.Hour), end: day.Add(12 * time.Hour), ok: true},
+		{name: "non utc", window: nightly, at: day.Add(2*time.Hour + 30*time.Minute).In(time.FixedZone("", 5*3600)),
+			start: day.Add(2 * time.Hour), end: day.Add(3 * time.Hour), ok: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end, ok, err := MaintenanceWindowAt(tc.window, tc.at)
+			require.NoError(t, err)
+			require.Equal(t, tc.ok, ok)
+			require.True(t, tc.start.Equal(start), "start %v, expected %v", start, tc.start)
+			require.True(t, tc.end.Equal(end), "end %v, expected %v", end, tc.end)
+		})
+	}
+
+	_, _, _, err := MaintenanceWindowAt(&protos.MaintenanceWindow{CronExpression: "not cron"}, day)
+	require.Error(t, err)
+}
+
+func TestActiveMaintenanceWindowEnd(t *testing.T) {
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	windows := []*protos.MaintenanceWindow{
+		{CronExpression: "0 2 * * *", DurationSeconds: 3600},
+		{CronExpression: "30 2 * * *", DurationSeconds: 2 * 3600},
+		{CronExpression: "0 * * * *", DurationSeconds: 2 * 3600},
+	}
+	testCases := []struct {
+		name      string
+		windows   []*protos.MaintenanceWindow
+		at        time.Time
+		skipUntil time.Time
+		end       time.Time
+		ok        bool
+	}{
+		{name: "none active", windows: windows[:2], at: day.Add(time.Hour)},
+		{name: "one active", windows: windows[:2], at: day.Add(2*time.Hour + 10*time.Minute),
+			end: day.Add(3 * time.Hour), ok: true},
+		{name: "latest end", windows: windows[:2], at: day.Add(2*time.Hour + 40*time.Minute),
+			end: day.Add(4*time.Hour + 30*time.Minute), ok: true},
+		{name: "skipped", windows: windows[:2], at: day.Add(2*time.Hour + 40*time.Minute),
+			skipUntil: day.Add(4*time.Hour + 30*time.Minute)},
+		{name: "skip ends before", windows: windows[:2], at: day.Add(2*time.Hour + 40*time.Minute),
+			skipUntil: day.Add(3 * time.Hour), end: day.Add(4*time.Hour + 30*time.Minute), ok: true},
+		{name: "overlapping", windows: windows[2:], at: day.Add(5*time.Hour + 59*time.Minute),
+			end: day.Add(7 * time.Hour), ok: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			end, ok, err := ActiveMaintenanceWindowEnd(tc.windows, tc.at, tc.skipUntil)
+			require.NoError(t, err)
+			require.Equal(t, tc.ok, ok)
+			require.True(t, tc.end.Equal(end), "end %v, expected %v", end, tc.end)
+		})
+	}
+}
+
+func TestNextMaintenanceWindow(t *testing.T) {
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	window := &protos.MaintenanceWindow{CronExpression: "0 2 * * *", DurationSeconds: 3600}
+	start, end, err := NextMaintenanceWindow(window, day.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.True(t, day.Add(26*time.Hour).Equal(start))
+	require.True(t, day.Add(27*time.Hour).Equal(end))
+}
@@ -0,0 +1,73 @@
+package shared
+
+import (
+	"strings"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+)
+
+// columns of destination tables normalized in a history mode
+const (
+	HistoryOpColName         = "_peerdb_op"
+	HistoryCommitTimeColName = "_peerdb_commit_time"
+	HistoryLSNColName        = "_peerdb_lsn"
+	HistoryBeforeColName     = "_peerdb_before"
+	SCD2ValidFromColName     = "_peerdb_valid_from"
+	SCD2ValidToColName       = "_peerdb_valid_to"
+	SCD2IsCurrentColName     = "_peerdb_is_current"
+	// batch which added a row, Snowflake and BigQuery skip batches already normalized into a table through it
+	HistoryBatchIDColName = "_peerdb_batch_id"
+)
+
+// raw table columns carrying the commit of each change, only present when history modes are in use
+const (
+	RawCommitLSNColName    = "_peerdb_commit_lsn"
+	RawCommitTimeNsColName = "_peerdb_commit_time_ns"
+)
+
+// NormalizeModeForTable returns the normalize mode of a destination table,
+// which is mode unless the table mapping of dstTable overrides it
+func NormalizeModeForTable(mode protos.NormalizeMode, tableMappings []*protos.TableMapping, dstTable string) protos.NormalizeMode {
+	for _, tableMapping := range tableMappings {
+		if tableMapping.DestinationTableIdentifier == dstTable && tableMapping.NormalizeMode != nil {
+			return *tableMapping.NormalizeMode
+		}
+	}
+	return mode
+}
+
+// NormalizeModes maps each destination table to its normalize mode
+func NormalizeModes(mode protos.NormalizeMode, tableMappings []*protos.TableMapping) map[string]protos.NormalizeMode {
+	modes := make(map[string]protos.NormalizeMode, len(tableMappings))
+	for _, tableMapping := range tableMappings {
+		modes[tableMapping.DestinationTableIdentifier] = NormalizeModeForTable(mode, tableMappings,
+			tableMapping.DestinationTableIdentifier)
+	}
+	return modes
+}
+
+// NeedsCommitInfo is true when any table is normalized in a history mode,
+// which needs the commit LSN and commit time of each change to be kept in the raw table
+func NeedsCommitInfo(mode protos.NormalizeMode, tableMappings []*protos.TableMapping) bool {
+	if mode != protos.NormalizeMode_NORMALIZE_MODE_MERGE {
+		return true
+	}
+	for _, tableMapping := range tableMappings {
+		if tableMapping.GetNormalizeMode() != protos.NormalizeMode_NORMALIZE_MODE_MERGE {
+			return true
+		}
+	}
+	return false
+}
+
+// IsHistoryColumn reports whether name is one of the columns added by history modes,
+// these are not part of the source table and get no value during initial load
+func IsHistoryColumn(name string) bool {
+	switch strings.ToLower(name) {
+	case HistoryOpColName, HistoryCommitTimeColName, HistoryLSNColName, HistoryBeforeColName,
+		SCD2ValidFromColName, SCD2ValidToColName, SCD2IsCurrentColName, HistoryBatchIDColName:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,46 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+)
+
+func TestGetPGConnectionString(t *testing.T) {
+	testCases := []struct {
+		name  string
+		host  string
+		hosts []string
+		ports []uint16
+	}{
+		{name: "single host", host: "db.internal", hosts: []string{"db.internal"}, ports: []uint16{5433}},
+		{name: "ipv6", host: "::1", hosts: []string{"::1"}, ports: []uint16{5433}},
+		{
+			name:  "standbys",
+			host:  "primary.internal, standby.internal:5434",
+			hosts: []string{"primary.internal", "standby.internal"},
+			ports: []uint16{5433, 5434},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			connString := GetPGConnectionString(&protos.PostgresConfig{
+				Host: tc.host, Port: 5433, User: "peerdb", Password: "p@ss", Database: "db",
+			})
+			config, err := pgx.ParseConfig(connString + "&sslmode=disable")
+			require.NoError(t, err)
+			hosts := []string{config.Host}
+			ports := []uint16{config.Port}
+			for _, fallback := range config.Fallbacks {
+				hosts = append(hosts, fallback.Host)
+				ports = append(ports, fallback.Port)
+			}
+			require.Equal(t, tc.hosts, hosts)
+			require.Equal(t, tc.ports, ports)
+			require.Equal(t, "p@ss", config.Password)
+		})
+	}
+}
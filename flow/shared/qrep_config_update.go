@@ -0,0 +1,38 @@
+package shared
+
+import (
+	"errors"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+)
+
+// ValidateQRepFlowConfigUpdate rejects updates which would leave a mirror unable to write
+func ValidateQRepFlowConfigUpdate(update *protos.QRepFlowConfigUpdate) error {
+	if update.Query != nil && *update.Query == "" {
+		return errors.New("query of a mirror cannot be empty")
+	}
+	if update.WriteMode.GetWriteType() == protos.QRepWriteType_QREP_WRITE_MODE_UPSERT &&
+		len(update.WriteMode.GetUpsertKeyColumns()) == 0 {
+		return errors.New("upsert write mode needs upsert key columns")
+	}
+	return nil
+}
+
+// ApplyQRepFlowConfigUpdate sets the fields of config given by update
+func ApplyQRepFlowConfigUpdate(config *protos.QRepConfig, update *protos.QRepFlowConfigUpdate) {
+	if update.MaxParallelWorkers > 0 {
+		config.MaxParallelWorkers = update.MaxParallelWorkers
+	}
+	if update.NumRowsPerPartition > 0 {
+		config.NumRowsPerPartition = update.NumRowsPerPartition
+	}
+	if update.WaitBetweenBatchesSeconds > 0 {
+		config.WaitBetweenBatchesSeconds = update.WaitBetweenBatchesSeconds
+	}
+	if update.Query != nil {
+		config.Query = *update.Query
+	}
+	if update.WriteMode != nil {
+		config.WriteMode = update.WriteMode
+	}
+}
@@ -0,0 +1,71 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+)
+
+func TestValidateQRepFlowConfigUpdate(t *testing.T) {
+	emptyQuery := ""
+	query := "SELECT * FROM t WHERE id BETWEEN {{.start}} AND {{.end}}"
+	testCases := []struct {
+		name   string
+		update *protos.QRepFlowConfigUpdate
+		valid  bool
+	}{
+		{name: "workers", update: &protos.QRepFlowConfigUpdate{MaxParallelWorkers: 4}, valid: true},
+		{name: "query", update: &protos.QRepFlowConfigUpdate{Query: &query}, valid: true},
+		{name: "empty query", update: &protos.QRepFlowConfigUpdate{Query: &emptyQuery}, valid: false},
+		{name: "upsert", update: &protos.QRepFlowConfigUpdate{WriteMode: &protos.QRepWriteMode{
+			WriteType:        protos.QRepWriteType_QREP_WRITE_MODE_UPSERT,
+			UpsertKeyColumns: []string{"id"},
+		}}, valid: true},
+		{name: "upsert without keys", update: &protos.QRepFlowConfigUpdate{WriteMode: &protos.QRepWriteMode{
+			WriteType: protos.QRepWriteType_QREP_WRITE_MODE_UPSERT,
+		}}, valid: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateQRepFlowConfigUpdate(tc.update)
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestApplyQRepFlowConfigUpdate(t *testing.T) {
+	config := &protos.QRepConfig{
+		MaxParallelWorkers:        8,
+		NumRowsPerPartition:       100000,
+		WaitBetweenBatchesSeconds: 30,
+		Query:                     "SELECT * FROM t",
+		WriteMode:                 &protos.QRepWriteMode{WriteType: protos.QRepWriteType_QREP_WRITE_MODE_APPEND},
+	}
+
+	// unset fields are left as they are
+	unchanged := proto.Clone(config).(*protos.QRepConfig)
+	ApplyQRepFlowConfigUpdate(unchanged, &protos.QRepFlowConfigUpdate{})
+	require.True(t, proto.Equal(config, unchanged))
+
+	query := "SELECT * FROM t WHERE deleted_at IS NULL"
+	ApplyQRepFlowConfigUpdate(config, &protos.QRepFlowConfigUpdate{
+		MaxParallelWorkers: 2,
+		Query:              &query,
+		WriteMode: &protos.QRepWriteMode{
+			WriteType:        protos.QRepWriteType_QREP_WRITE_MODE_UPSERT,
+			UpsertKeyColumns: []string{"id"},
+		},
+	})
+	require.Equal(t, uint32(2), config.MaxParallelWorkers)
+	require.Equal(t, uint32(100000), config.NumRowsPerPartition)
+	require.Equal(t, uint32(30), config.WaitBetweenBatchesSeconds)
+	require.Equal(t, query, config.Query)
+	require.Equal(t, protos.QRepWriteType_QREP_WRITE_MODE_UPSERT, config.WriteMode.WriteType)
+}
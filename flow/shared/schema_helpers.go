@@ -6,6 +6,7 @@ import (
 
 	"go.temporal.io/sdk/log"
 	"golang.org/x/exp/maps"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/PeerDB-io/peer-flow/generated/protos"
 )
@@ -75,3 +76,22 @@ func BuildProcessedSchemaMapping(tableMappings []*protos.TableMapping,
 	}
 	return processedSchemaMapping
 }
+
+// DestinationConfigs splits a mirror into one config per destination, primary destination first,
+// each config has Destination set and no AdditionalDestinations
+func DestinationConfigs(cfg *protos.FlowConnectionConfigs) []*protos.FlowConnectionConfigs {
+	if len(cfg.AdditionalDestinations) == 0 {
+		return []*protos.FlowConnectionConfigs{cfg}
+	}
+
+	primary := proto.Clone(cfg).(*protos.FlowConnectionConfigs)
+	primary.AdditionalDestinations = nil
+	configs := make([]*protos.FlowConnectionConfigs, 0, 1+len(cfg.AdditionalDestinations))
+	configs = append(configs, primary)
+	for _, destination := range cfg.AdditionalDestinations {
+		dstCfg := proto.Clone(primary).(*protos.FlowConnectionConfigs)
+		dstCfg.Destination = destination
+		configs = append(configs, dstCfg)
+	}
+	return configs
+}
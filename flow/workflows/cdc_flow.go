@@ -95,6 +95,11 @@ func processCDCFlowConfigUpdate(
 
 	if flowConfigUpdate != nil {
 		logger.Info("processing CDCFlowConfigUpdate", slog.Any("updatedState", flowConfigUpdate))
+		if len(flowConfigUpdate.RemovedTables) > 0 {
+			if err := processRemovedTables(ctx, logger, cfg, state, flowConfigUpdate); err != nil {
+				return err
+			}
+		}
 		if len(flowConfigUpdate.AdditionalTables) == 0 {
 			return nil
 		}
@@ -156,6 +161,104 @@ func processCDCFlowConfigUpdate(
 	return nil
 }
 
+// processRemovedTables stops replicating the removed tables without resyncing the remaining ones,
+// their rows are deleted from the raw table while their destination tables are only dropped when asked for
+func processRemovedTables(
+	ctx workflow.Context,
+	logger log.Logger,
+	cfg *protos.FlowConnectionConfigs, state *CDCFlowWorkflowState,
+	flowConfigUpdate *protos.CDCFlowConfigUpdate,
+) error {
+	removedSrcTables := make(map[string]struct{}, len(flowConfigUpdate.RemovedTables))
+	for _, tableMapping := range flowConfigUpdate.RemovedTables {
+		removedSrcTables[tableMapping.SourceTableIdentifier] = struct{}{}
+	}
+	removedTableMappings := make([]*protos.TableMapping, 0, len(flowConfigUpdate.RemovedTables))
+	remainingTableMappings := make([]*protos.TableMapping, 0, len(state.SyncFlowOptions.TableMappings))
+	remainingDstTables := make(map[string]struct{}, len(state.SyncFlowOptions.TableMappings))
+	for _, tableMapping := range state.SyncFlowOptions.TableMappings {
+		if _, ok := removedSrcTables[tableMapping.SourceTableIdentifier]; ok {
+			removedTableMappings = append(removedTableMappings, tableMapping)
+		} else {
+			remainingTableMappings = append(remainingTableMappings, tableMapping)
+			remainingDstTables[tableMapping.DestinationTableIdentifier] = struct{}{}
+		}
+	}
+	if len(removedTableMappings) == 0 {
+		logger.Warn("none of removedTables are part of the mirror")
+		return nil
+	}
+	if len(remainingTableMappings) == 0 {
+		// rejected by the API, only updates signaled directly get here
+		logger.Warn("removedTables would remove every table of the mirror, drop the mirror instead")
+		return nil
+	}
+
+	logger.Info("altering publication for removed tables")
+	removeTablesCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 5 * time.Minute,
+	})
+	alterPublicationRemoveTablesFuture := workflow.ExecuteActivity(
+		removeTablesCtx,
+		flowable.RemoveTablesFromPublication,
+		cfg, removedTableMappings)
+	if err := alterPublicationRemoveTablesFuture.Get(ctx, nil); err != nil {
+		logger.Error("failed to alter publication for removed tables: ", err)
+		return err
+	}
+
+	// rows of removed tables already synced are normalized before their tables are dropped
+	// and their schemas forgotten, normalize skips rows of removed tables synced after that
+	normalizeCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 7 * 24 * time.Hour,
+		HeartbeatTimeout:    time.Minute,
+	})
+	for i, dstConfig := range shared.DestinationConfigs(cfg) {
+		normalizeFuture := workflow.ExecuteActivity(normalizeCtx, flowable.NormalizeRemainingBatches, &protos.StartNormalizeInput{
+			FlowConnectionConfigs:  dstConfig,
+			TableNameSchemaMapping: state.SyncFlowOptions.TableNameSchemaMapping,
+			AdditionalDestination:  i > 0,
+		})
+		if err := normalizeFuture.Get(ctx, nil); err != nil {
+			return fmt.Errorf("failed to normalize remaining batches on %s: %w", dstConfig.Destination.Name, err)
+		}
+	}
+
+	// a destination table still fed by a remaining source table is never dropped
+	removedDstTables := make([]string, 0, len(removedTableMappings))
+	for _, tableMapping := range removedTableMappings {
+		if _, ok := remainingDstTables[tableMapping.DestinationTableIdentifier]; !ok {
+			removedDstTables = append(removedDstTables, tableMapping.DestinationTableIdentifier)
+		}
+	}
+	// kept tables still lose their raw table rows, normalize would skip those rows on every batch otherwise
+	if len(removedDstTables) > 0 {
+		for _, dstConfig := range shared.DestinationConfigs(cfg) {
+			removeTablesFuture := workflow.ExecuteActivity(removeTablesCtx, flowable.RemoveTables, &protos.RemoveTablesInput{
+				FlowJobName:                 cfg.FlowJobName,
+				Peer:                        dstConfig.Destination,
+				DestinationTableIdentifiers: removedDstTables,
+				KeepTables:                  !flowConfigUpdate.DropRemovedTables,
+			})
+			if err := removeTablesFuture.Get(ctx, nil); err != nil {
+				return fmt.Errorf("failed to remove tables on %s: %w", dstConfig.Destination.Name, err)
+			}
+		}
+	}
+
+	for relID, srcTable := range state.SyncFlowOptions.SrcTableIdNameMapping {
+		if _, ok := removedSrcTables[srcTable]; ok {
+			delete(state.SyncFlowOptions.SrcTableIdNameMapping, relID)
+		}
+	}
+	for _, dstTable := range removedDstTables {
+		delete(state.SyncFlowOptions.TableNameSchemaMapping, dstTable)
+	}
+	state.SyncFlowOptions.TableMappings = remainingTableMappings
+	logger.Info("removed tables from sync flow")
+	return nil
+}
+
 func addCdcPropertiesSignalListener(
 	ctx workflow.Context,
 	logger log.Logger,
@@ -177,7 +280,8 @@ func addCdcPropertiesSignalListener(
 		logger.Info("CDC Signal received. Parameters on signal reception:",
 			slog.Int("BatchSize", int(state.SyncFlowOptions.BatchSize)),
 			slog.Int("IdleTimeout", int(state.SyncFlowOptions.IdleTimeoutSeconds)),
-			slog.Any("AdditionalTables", cdcConfigUpdate.AdditionalTables))
+			slog.Any("AdditionalTables", cdcConfigUpdate.AdditionalTables),
+			slog.Any("RemovedTables", cdcConfigUpdate.RemovedTables))
 	})
 }
 
@@ -330,7 +434,6 @@ func CDCFlowWorkflow(
 		if cfg.Resync {
 			renameOpts := &protos.RenameTablesInput{}
 			renameOpts.FlowJobName = cfg.FlowJobName
-			renameOpts.Peer = cfg.Destination
 			if cfg.SoftDelete {
 				renameOpts.SoftDeleteColName = &cfg.SoftDeleteColName
 			}
@@ -355,9 +458,13 @@ func CDCFlowWorkflow(
 				StartToCloseTimeout: 12 * time.Hour,
 				HeartbeatTimeout:    time.Minute,
 			})
-			renameTablesFuture := workflow.ExecuteActivity(renameTablesCtx, flowable.RenameTables, renameOpts)
-			if err := renameTablesFuture.Get(renameTablesCtx, nil); err != nil {
-				return state, fmt.Errorf("failed to execute rename tables activity: %w", err)
+			for _, dstConfig := range shared.DestinationConfigs(cfg) {
+				dstRenameOpts := proto.Clone(renameOpts).(*protos.RenameTablesInput)
+				dstRenameOpts.Peer = dstConfig.Destination
+				renameTablesFuture := workflow.ExecuteActivity(renameTablesCtx, flowable.RenameTables, dstRenameOpts)
+				if err := renameTablesFuture.Get(renameTablesCtx, nil); err != nil {
+					return state, fmt.Errorf("failed to execute rename tables activity: %w", err)
+				}
 			}
 		}
 
@@ -0,0 +1,241 @@
+package peerflow
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pglogrepl"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+	"github.com/PeerDB-io/peer-flow/model"
+	"github.com/PeerDB-io/peer-flow/shared"
+)
+
+const (
+	cutoverProgressInterval = 10 * time.Second
+	cutoverCatchUpTimeout   = time.Hour
+	// time for the reverse mirror to create its slot and start replicating
+	cutoverReverseMirrorTimeout = time.Hour
+	// progress checks before continuing as new, keeping history short while the destination catches up
+	maxCutoverProgressChecks = 100
+)
+
+// ReverseMirrorConfig replicates changes made on the destination of a mirror back to its source,
+// tagged with a replication origin so the mirror skips them if it still runs
+func ReverseMirrorConfig(cfg *protos.FlowConnectionConfigs, name string) *protos.FlowConnectionConfigs {
+	reverseCfg := proto.Clone(cfg).(*protos.FlowConnectionConfigs)
+	reverseCfg.FlowJobName = name
+	reverseCfg.Source, reverseCfg.Destination = cfg.Destination, cfg.Source
+
+	// columns added by the mirror don't exist on the source
+	var exclude []string
+	if cfg.SoftDelete && cfg.SoftDeleteColName != "" {
+		exclude = append(exclude, cfg.SoftDeleteColName)
+	}
+	if cfg.SyncedAtColName != "" {
+		exclude = append(exclude, cfg.SyncedAtColName)
+	}
+	reverseCfg.TableMappings = make([]*protos.TableMapping, 0, len(cfg.TableMappings))
+	for _, tm := range cfg.TableMappings {
+		reverseCfg.TableMappings = append(reverseCfg.TableMappings, &protos.TableMapping{
+			SourceTableIdentifier:      tm.DestinationTableIdentifier,
+			DestinationTableIdentifier: tm.SourceTableIdentifier,
+			Exclude:                    exclude,
+		})
+	}
+
+	reverseCfg.PublicationName = ""
+	reverseCfg.ReplicationSlotName = ""
+	reverseCfg.CdcStagingPath = ""
+	reverseCfg.SnapshotStagingPath = ""
+	reverseCfg.DoInitialSnapshot = false
+	reverseCfg.InitialSnapshotOnly = false
+	reverseCfg.Resync = false
+	reverseCfg.SoftDelete = false
+	reverseCfg.SoftDeleteColName = ""
+	reverseCfg.SyncedAtColName = ""
+	reverseCfg.Script = ""
+	reverseCfg.AdditionalDestinations = nil
+	reverseCfg.NormalizeMode = protos.NormalizeMode_NORMALIZE_MODE_MERGE
+	reverseCfg.ReplicationOrigin = true
+	reverseCfg.SkipReplicatedChanges = true
+	return reverseCfg
+}
+
+// CutoverFlowWorkflow moves applications off the source of a Postgres to Postgres mirror.
+// Once the source is fenced, it waits for the destination to sync and normalize up to the fence,
+// syncs sequences and compares row counts, optionally starting a reverse mirror for rollback.
+// The source stays fenced after a successful cutover until the mirror is dropped, a failed one unfences it
+func CutoverFlowWorkflow(ctx workflow.Context, input *protos.CutoverInput) (*protos.CutoverStatus, error) {
+	cfg := input.ConnectionConfigs
+	logger := log.With(workflow.GetLogger(ctx), slog.String(string(shared.FlowNameKey), cfg.FlowJobName))
+	ctx = workflow.WithValue(ctx, shared.FlowNameKey, cfg.FlowJobName)
+
+	status := &protos.CutoverStatus{
+		Stage:    protos.CutoverStage_CUTOVER_STAGE_FENCING,
+		FenceLsn: input.FenceLsn,
+	}
+	if err := workflow.SetQueryHandler(ctx, shared.CutoverStatusQuery, func() (*protos.CutoverStatus, error) {
+		return status, nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to set `%s` query handler: %w", shared.CutoverStatusQuery, err)
+	}
+
+	activityCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 5 * time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts: 5,
+		},
+	})
+	countCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 12 * time.Hour,
+		HeartbeatTimeout:    10 * time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts: 5,
+		},
+	})
+
+	fenced := input.Fenced
+	fail := func(err error) (*protos.CutoverStatus, error) {
+		logger.Error("cutover failed", slog.Any("error", err))
+		status.Stage = protos.CutoverStage_CUTOVER_STAGE_FAILED
+		status.ErrorMessage = err.Error()
+		if fenced {
+			// unfence even when the cutover was canceled
+			unfenceCtx, _ := workflow.NewDisconnectedContext(activityCtx)
+			if err := workflow.ExecuteActivity(unfenceCtx, flowable.UnfenceForCutover, cfg).Get(unfenceCtx, nil); err != nil {
+				logger.Error("failed to unfence source", slog.Any("error", err))
+				status.ErrorMessage += ", failed to unfence source: " + err.Error()
+			}
+		}
+		return status, nil
+	}
+
+	if status.FenceLsn == 0 {
+		var fenceLSN int64
+		if err := workflow.ExecuteActivity(activityCtx, flowable.FenceForCutover, cfg).Get(ctx, &fenceLSN); err != nil {
+			return fail(fmt.Errorf("failed to fence source: %w", err))
+		}
+		fenced = true
+		status.FenceLsn = uint64(fenceLSN)
+	}
+	logger.Info("waiting for destination to catch up", slog.Uint64("fenceLSN", status.FenceLsn))
+
+	catchUpDeadline := input.CatchUpDeadline.AsTime()
+	if input.CatchUpDeadline == nil {
+		catchUpTimeout := cutoverCatchUpTimeout
+		if input.CatchUpTimeoutSeconds > 0 {
+			catchUpTimeout = time.Duration(input.CatchUpTimeoutSeconds) * time.Second
+		}
+		catchUpDeadline = workflow.Now(ctx).Add(catchUpTimeout)
+	}
+
+	status.Stage = protos.CutoverStage_CUTOVER_STAGE_CATCHING_UP
+	for progressChecks := 1; ; progressChecks++ {
+		var progress *model.CutoverProgress
+		if err := workflow.ExecuteActivity(activityCtx, flowable.GetCutoverProgress, cfg).Get(ctx, &progress); err != nil {
+			return fail(fmt.Errorf("failed to get progress of destination: %w", err))
+		}
+		status.LatestLsnAtTarget = uint64(progress.LatestLSNAtTarget)
+		if status.LatestLsnAtTarget >= status.FenceLsn && progress.NormalizeBatchID >= progress.SyncBatchID {
+			break
+		}
+
+		remaining := catchUpDeadline.Sub(workflow.Now(ctx))
+		if remaining <= 0 {
+			return fail(fmt.Errorf("destination did not catch up to fence %v by %v",
+				pglogrepl.LSN(status.FenceLsn), catchUpDeadline))
+		}
+		if progressChecks >= maxCutoverProgressChecks {
+			nextInput := proto.Clone(input).(*protos.CutoverInput)
+			nextInput.FenceLsn = status.FenceLsn
+			nextInput.Fenced = fenced
+			nextInput.CatchUpDeadline = timestamppb.New(catchUpDeadline)
+			return nil, workflow.NewContinueAsNewError(ctx, CutoverFlowWorkflow, nextInput)
+		}
+		if err := workflow.Sleep(ctx, min(cutoverProgressInterval, remaining)); err != nil {
+			return fail(err)
+		}
+	}
+
+	status.Stage = protos.CutoverStage_CUTOVER_STAGE_SYNCING_SEQUENCES
+	if err := workflow.ExecuteActivity(activityCtx, flowable.SyncSequences, cfg).Get(ctx, nil); err != nil {
+		return fail(fmt.Errorf("failed to sync sequences: %w", err))
+	}
+
+	status.Stage = protos.CutoverStage_CUTOVER_STAGE_VALIDATING
+	if err := workflow.ExecuteActivity(countCtx, flowable.CountCutoverRows, cfg).Get(ctx, &status.TableCounts); err != nil {
+		return fail(fmt.Errorf("failed to count rows: %w", err))
+	}
+	for _, count := range status.TableCounts {
+		if count.SourceCount != count.DestinationCount {
+			return fail(fmt.Errorf("%s has %d rows but %s has %d", count.SourceTableIdentifier, count.SourceCount,
+				count.DestinationTableIdentifier, count.DestinationCount))
+		}
+	}
+
+	if input.CreateReverseMirror {
+		status.Stage = protos.CutoverStage_CUTOVER_STAGE_CREATING_REVERSE_MIRROR
+		reverseName := input.ReverseMirrorName
+		if reverseName == "" {
+			reverseName = cfg.FlowJobName + "_reverse"
+		}
+		reverseCfg := ReverseMirrorConfig(cfg, reverseName)
+		reverseWorkflowID := fmt.Sprintf("%s-peerflow-%s", reverseName, GetUUID(ctx))
+		if err := workflow.ExecuteActivity(activityCtx, flowable.CreateReverseMirrorEntry,
+			reverseCfg, reverseWorkflowID).Get(ctx, nil); err != nil {
+			return fail(fmt.Errorf("failed to create reverse mirror: %w", err))
+		}
+
+		// the reverse mirror outlives the cutover
+		reverseCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+			WorkflowID:        reverseWorkflowID,
+			ParentClosePolicy: enums.PARENT_CLOSE_POLICY_ABANDON,
+			SearchAttributes: map[string]interface{}{
+				shared.MirrorNameSearchAttribute: reverseName,
+			},
+		})
+		reverseFuture := workflow.ExecuteChildWorkflow(reverseCtx, CDCFlowWorkflow, reverseCfg, nil)
+		if err := reverseFuture.GetChildWorkflowExecution().Get(ctx, nil); err != nil {
+			return fail(fmt.Errorf("failed to start reverse mirror: %w", err))
+		}
+		status.ReverseMirrorName = reverseName
+
+		// the reverse mirror creates its slot during setup, writes made on the destination before that are never replicated
+		reverseDeadline := workflow.Now(ctx).Add(cutoverReverseMirrorTimeout)
+		for {
+			if reverseFuture.IsReady() {
+				if err := reverseFuture.Get(ctx, nil); err != nil {
+					return fail(fmt.Errorf("reverse mirror %s failed before it started replicating: %w", reverseName, err))
+				}
+				return fail(fmt.Errorf("reverse mirror %s stopped before it started replicating", reverseName))
+			}
+			var reverseStatus protos.FlowStatus
+			if err := workflow.ExecuteActivity(activityCtx, flowable.GetReverseMirrorStatus,
+				reverseWorkflowID).Get(ctx, &reverseStatus); err != nil {
+				logger.Warn("failed to get status of reverse mirror", slog.Any("error", err))
+			} else if reverseStatus == protos.FlowStatus_STATUS_RUNNING {
+				break
+			}
+
+			remaining := reverseDeadline.Sub(workflow.Now(ctx))
+			if remaining <= 0 {
+				return fail(fmt.Errorf("reverse mirror %s did not start replicating by %v", reverseName, reverseDeadline))
+			}
+			if err := workflow.Sleep(ctx, min(cutoverProgressInterval, remaining)); err != nil {
+				return fail(err)
+			}
+		}
+	}
+
+	status.Stage = protos.CutoverStage_CUTOVER_STAGE_READY
+	logger.Info("cutover ready")
+	return status, nil
+}
@@ -0,0 +1,143 @@
+package peerflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+	"github.com/PeerDB-io/peer-flow/model"
+)
+
+func TestReverseMirrorConfig(t *testing.T) {
+	cfg := &protos.FlowConnectionConfigs{
+		FlowJobName: "forward",
+		Source:      &protos.Peer{Name: "source", Type: protos.DBType_POSTGRES},
+		Destination: &protos.Peer{Name: "destination", Type: protos.DBType_POSTGRES},
+		TableMappings: []*protos.TableMapping{{
+			SourceTableIdentifier:      "public.users",
+			DestinationTableIdentifier: "public.users_copy",
+			Exclude:                    []string{"secret"},
+		}},
+		PublicationName:     "forward_pub",
+		ReplicationSlotName: "forward_slot",
+		DoInitialSnapshot:   true,
+		SoftDelete:          true,
+		SoftDeleteColName:   "_PEERDB_IS_DELETED",
+		SyncedAtColName:     "_PEERDB_SYNCED_AT",
+		AdditionalDestinations: []*protos.Peer{
+			{Name: "other", Type: protos.DBType_POSTGRES},
+		},
+		NormalizeMode: protos.NormalizeMode_NORMALIZE_MODE_CHANGELOG,
+	}
+	original := proto.Clone(cfg)
+
+	reverseCfg := ReverseMirrorConfig(cfg, "forward_reverse")
+
+	require.Equal(t, "forward_reverse", reverseCfg.FlowJobName)
+	require.Equal(t, "destination", reverseCfg.Source.Name)
+	require.Equal(t, "source", reverseCfg.Destination.Name)
+	require.Len(t, reverseCfg.TableMappings, 1)
+	require.Equal(t, "public.users_copy", reverseCfg.TableMappings[0].SourceTableIdentifier)
+	require.Equal(t, "public.users", reverseCfg.TableMappings[0].DestinationTableIdentifier)
+	// columns added by the forward mirror are left out, excluded source columns don't exist on the destination anyway
+	require.ElementsMatch(t, []string{"_PEERDB_IS_DELETED", "_PEERDB_SYNCED_AT"}, reverseCfg.TableMappings[0].Exclude)
+
+	require.Empty(t, reverseCfg.PublicationName)
+	require.Empty(t, reverseCfg.ReplicationSlotName)
+	require.False(t, reverseCfg.DoInitialSnapshot)
+	require.False(t, reverseCfg.SoftDelete)
+	require.Empty(t, reverseCfg.SoftDeleteColName)
+	require.Empty(t, reverseCfg.SyncedAtColName)
+	require.Empty(t, reverseCfg.AdditionalDestinations)
+	require.Equal(t, protos.NormalizeMode_NORMALIZE_MODE_MERGE, reverseCfg.NormalizeMode)
+
+	// the reverse mirror tags its writes and skips the forward mirror's, so changes don't loop
+	require.True(t, reverseCfg.ReplicationOrigin)
+	require.True(t, reverseCfg.SkipReplicatedChanges)
+
+	require.True(t, proto.Equal(original, cfg), "forward mirror config was modified")
+}
+
+func TestCutoverCatchUpTimeoutUnfences(t *testing.T) {
+	var testSuite testsuite.WorkflowTestSuite
+	env := testSuite.NewTestWorkflowEnvironment()
+	env.RegisterActivity(flowable)
+
+	env.OnActivity(flowable.FenceForCutover, mock.Anything, mock.Anything).Return(int64(100), nil)
+	env.OnActivity(flowable.GetCutoverProgress, mock.Anything, mock.Anything).Return(
+		&model.CutoverProgress{LatestLSNAtTarget: 50, SyncBatchID: 2, NormalizeBatchID: 2}, nil)
+	unfenced := false
+	env.OnActivity(flowable.UnfenceForCutover, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, _ *protos.FlowConnectionConfigs) error {
+			unfenced = true
+			return nil
+		})
+
+	env.ExecuteWorkflow(CutoverFlowWorkflow, &protos.CutoverInput{
+		ConnectionConfigs: &protos.FlowConnectionConfigs{
+			FlowJobName: "forward",
+			Source:      &protos.Peer{Name: "source", Type: protos.DBType_POSTGRES},
+			Destination: &protos.Peer{Name: "destination", Type: protos.DBType_POSTGRES},
+		},
+		CatchUpTimeoutSeconds: 60,
+	})
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+
+	var status *protos.CutoverStatus
+	require.NoError(t, env.GetWorkflowResult(&status))
+	require.Equal(t, protos.CutoverStage_CUTOVER_STAGE_FAILED, status.Stage)
+	require.Contains(t, status.ErrorMessage, "did not catch up")
+	require.True(t, unfenced)
+}
+
+func TestCutoverWaitsForReverseMirror(t *testing.T) {
+	var testSuite testsuite.WorkflowTestSuite
+	env := testSuite.NewTestWorkflowEnvironment()
+	env.RegisterActivity(flowable)
+	// the reverse mirror keeps running after the cutover
+	env.RegisterWorkflowWithOptions(func(ctx workflow.Context, _ *protos.FlowConnectionConfigs, _ *CDCFlowWorkflowState) error {
+		return workflow.Sleep(ctx, 24*time.Hour)
+	}, workflow.RegisterOptions{Name: "CDCFlowWorkflow"})
+
+	env.OnActivity(flowable.FenceForCutover, mock.Anything, mock.Anything).Return(int64(100), nil)
+	env.OnActivity(flowable.GetCutoverProgress, mock.Anything, mock.Anything).Return(
+		&model.CutoverProgress{LatestLSNAtTarget: 100, SyncBatchID: 2, NormalizeBatchID: 2}, nil)
+	env.OnActivity(flowable.SyncSequences, mock.Anything, mock.Anything).Return(nil)
+	env.OnActivity(flowable.CountCutoverRows, mock.Anything, mock.Anything).Return(nil, nil)
+	env.OnActivity(flowable.CreateReverseMirrorEntry, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	// the reverse mirror is still setting up on the first checks
+	statusChecks := 0
+	env.OnActivity(flowable.GetReverseMirrorStatus, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, _ string) (protos.FlowStatus, error) {
+			statusChecks += 1
+			if statusChecks < 3 {
+				return protos.FlowStatus_STATUS_SETUP, nil
+			}
+			return protos.FlowStatus_STATUS_RUNNING, nil
+		})
+
+	env.ExecuteWorkflow(CutoverFlowWorkflow, &protos.CutoverInput{
+		ConnectionConfigs: &protos.FlowConnectionConfigs{
+			FlowJobName: "forward",
+			Source:      &protos.Peer{Name: "source", Type: protos.DBType_POSTGRES},
+			Destination: &protos.Peer{Name: "destination", Type: protos.DBType_POSTGRES},
+		},
+		CreateReverseMirror: true,
+	})
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+
+	var status *protos.CutoverStatus
+	require.NoError(t, env.GetWorkflowResult(&status))
+	require.Equal(t, protos.CutoverStage_CUTOVER_STAGE_READY, status.Stage, status.ErrorMessage)
+	require.Equal(t, "forward_reverse", status.ReverseMirrorName)
+	require.Equal(t, 3, statusChecks)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"go.temporal.io/sdk/workflow"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/PeerDB-io/peer-flow/generated/protos"
 	"github.com/PeerDB-io/peer-flow/shared"
@@ -20,14 +21,25 @@ func DropFlowWorkflow(ctx workflow.Context, req *protos.ShutdownRequest) error {
 
 	ctx = workflow.WithValue(ctx, shared.FlowNameKey, req.FlowJobName)
 
-	var sourceError, destinationError error
-	var sourceOk, destinationOk, canceled bool
+	// the destination first, then any additional destinations of a multi-destination mirror
+	dstReqs := []*protos.ShutdownRequest{req}
+	for _, destination := range req.AdditionalDestinationPeers {
+		dstReq := proto.Clone(req).(*protos.ShutdownRequest)
+		dstReq.DestinationPeer = destination
+		dstReqs = append(dstReqs, dstReq)
+	}
+
+	var sourceError error
+	destinationErrors := make([]error, len(dstReqs))
+	var sourceOk, canceled bool
+	destinationsOk := 0
 	selector := workflow.NewNamedSelector(ctx, req.FlowJobName+"-drop")
 	selector.AddReceive(ctx.Done(), func(_ workflow.ReceiveChannel, _ bool) {
 		canceled = true
 	})
 
-	var dropSource, dropDestination func(f workflow.Future)
+	var dropSource func(f workflow.Future)
+	var dropDestination func(idx int) func(f workflow.Future)
 	dropSource = func(f workflow.Future) {
 		sourceError = f.Get(ctx, nil)
 		sourceOk = sourceError == nil
@@ -37,25 +49,30 @@ func DropFlowWorkflow(ctx workflow.Context, req *protos.ShutdownRequest) error {
 			_ = workflow.Sleep(ctx, time.Second)
 		}
 	}
-	dropDestination = func(f workflow.Future) {
-		destinationError = f.Get(ctx, nil)
-		destinationOk = destinationError == nil
-		if !destinationOk {
-			dropDestinationFuture := workflow.ExecuteActivity(ctx, flowable.DropFlowDestination, req)
-			selector.AddFuture(dropDestinationFuture, dropDestination)
-			_ = workflow.Sleep(ctx, time.Second)
+	dropDestination = func(idx int) func(f workflow.Future) {
+		return func(f workflow.Future) {
+			destinationErrors[idx] = f.Get(ctx, nil)
+			if destinationErrors[idx] == nil {
+				destinationsOk += 1
+			} else {
+				dropDestinationFuture := workflow.ExecuteActivity(ctx, flowable.DropFlowDestination, dstReqs[idx])
+				selector.AddFuture(dropDestinationFuture, dropDestination(idx))
+				_ = workflow.Sleep(ctx, time.Second)
+			}
 		}
 	}
 	dropSourceFuture := workflow.ExecuteActivity(ctx, flowable.DropFlowSource, req)
 	selector.AddFuture(dropSourceFuture, dropSource)
-	dropDestinationFuture := workflow.ExecuteActivity(ctx, flowable.DropFlowDestination, req)
-	selector.AddFuture(dropDestinationFuture, dropDestination)
+	for idx, dstReq := range dstReqs {
+		dropDestinationFuture := workflow.ExecuteActivity(ctx, flowable.DropFlowDestination, dstReq)
+		selector.AddFuture(dropDestinationFuture, dropDestination(idx))
+	}
 
 	for {
 		selector.Select(ctx)
 		if canceled {
-			return errors.Join(ctx.Err(), sourceError, destinationError)
-		} else if sourceOk && destinationOk {
+			return errors.Join(ctx.Err(), sourceError, errors.Join(destinationErrors...))
+		} else if sourceOk && destinationsOk == len(dstReqs) {
 			return nil
 		}
 	}
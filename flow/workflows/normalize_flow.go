@@ -15,6 +15,8 @@ import (
 
 type NormalizeState struct {
 	TableNameSchemaMapping map[string]*protos.TableSchema
+	// latest batch synced to each additional destination, normalized alongside the primary destination
+	AdditionalSyncBatchIDs map[string]int64
 	LastSyncBatchID        int64
 	SyncBatchID            int64
 	Wait                   bool
@@ -77,6 +79,14 @@ func NormalizeFlowWorkflow(
 		if s.TableNameSchemaMapping != nil {
 			state.TableNameSchemaMapping = s.TableNameSchemaMapping
 		}
+		for peerName, syncBatchID := range s.AdditionalSyncBatchIDs {
+			if state.AdditionalSyncBatchIDs == nil {
+				state.AdditionalSyncBatchIDs = make(map[string]int64)
+			}
+			if syncBatchID > state.AdditionalSyncBatchIDs[peerName] {
+				state.AdditionalSyncBatchIDs[peerName] = syncBatchID
+			}
+		}
 
 		state.Wait = false
 	})
@@ -92,18 +102,34 @@ func NormalizeFlowWorkflow(
 		state.LastSyncBatchID = state.SyncBatchID
 
 		logger.Info("executing normalize")
-		startNormalizeInput := &protos.StartNormalizeInput{
-			FlowConnectionConfigs:  config,
-			TableNameSchemaMapping: state.TableNameSchemaMapping,
-			SyncBatchID:            state.SyncBatchID,
+		dstConfigs := shared.DestinationConfigs(config)
+		fStartNormalizes := make([]workflow.Future, 0, len(dstConfigs))
+		for i, dstConfig := range dstConfigs {
+			syncBatchID := state.SyncBatchID
+			if i > 0 {
+				var ok bool
+				syncBatchID, ok = state.AdditionalSyncBatchIDs[dstConfig.Destination.Name]
+				if !ok {
+					continue
+				}
+			}
+			startNormalizeInput := &protos.StartNormalizeInput{
+				FlowConnectionConfigs:  dstConfig,
+				TableNameSchemaMapping: state.TableNameSchemaMapping,
+				SyncBatchID:            syncBatchID,
+				AdditionalDestination:  i > 0,
+			}
+			fStartNormalizes = append(fStartNormalizes,
+				workflow.ExecuteActivity(normalizeFlowCtx, flowable.StartNormalize, startNormalizeInput))
 		}
-		fStartNormalize := workflow.ExecuteActivity(normalizeFlowCtx, flowable.StartNormalize, startNormalizeInput)
 
-		var normalizeResponse *model.NormalizeResponse
-		if err := fStartNormalize.Get(normalizeFlowCtx, &normalizeResponse); err != nil {
-			logger.Info("Normalize errored", slog.Any("error", err))
-		} else if normalizeResponse != nil {
-			logger.Info("Normalize finished", slog.Any("result", normalizeResponse))
+		for _, fStartNormalize := range fStartNormalizes {
+			var normalizeResponse *model.NormalizeResponse
+			if err := fStartNormalize.Get(normalizeFlowCtx, &normalizeResponse); err != nil {
+				logger.Info("Normalize errored", slog.Any("error", err))
+			} else if normalizeResponse != nil {
+				logger.Info("Normalize finished", slog.Any("result", normalizeResponse))
+			}
 		}
 	}
 
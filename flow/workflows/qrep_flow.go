@@ -17,6 +17,9 @@ import (
 	"github.com/PeerDB-io/peer-flow/shared"
 )
 
+// partitions each child workflow replicates before config updates are applied
+const maxPartitionsPerBatch = 10
+
 type QRepFlowExecution struct {
 	config          *protos.QRepConfig
 	flowExecutionID string
@@ -152,6 +155,7 @@ func (q *QRepFlowExecution) SetupWatermarkTableOnDestination(ctx workflow.Contex
 				q.config.DestinationTableIdentifier: watermarkTableSchema,
 			},
 			SyncedAtColName:   q.config.SyncedAtColName,
+			SoftDelete:        q.config.SoftDeleteColName != "",
 			SoftDeleteColName: q.config.SoftDeleteColName,
 			FlowName:          q.config.FlowJobName,
 		}
@@ -247,39 +251,58 @@ func (q *QRepFlowExecution) startChildWorkflow(
 	return workflow.ExecuteChildWorkflow(partFlowCtx, QRepPartitionWorkflow, q.config, partitions, q.runUUID)
 }
 
-// processPartitions handles the logic for processing the partitions.
+func (q *QRepFlowExecution) maxParallelWorkers() int {
+	if q.config.MaxParallelWorkers > 0 {
+		return int(q.config.MaxParallelWorkers)
+	}
+	return 16
+}
+
+// processPartitions handles the logic for processing the partitions. They are processed in rounds
+// of batches for up to maxParallelWorkers child workflows, config updates apply from the next round
 func (q *QRepFlowExecution) processPartitions(
 	ctx workflow.Context,
-	maxParallelWorkers int,
+	updateChan model.TypedReceiveChannel[*protos.QRepFlowConfigUpdate],
 	partitions []*protos.QRepPartition,
 ) error {
 	if len(partitions) == 0 {
 		q.logger.Info("no partitions to process")
 		return nil
 	}
-	chunkSize := shared.DivCeil(len(partitions), maxParallelWorkers)
-	batches := make([][]*protos.QRepPartition, 0, len(partitions)/chunkSize+1)
-	for i := 0; i < len(partitions); i += chunkSize {
-		end := min(i+chunkSize, len(partitions))
-		batches = append(batches, partitions[i:end])
-	}
 
-	q.logger.Info("processing partitions in batches", "num batches", len(batches))
+	var batchID int32
+	for len(partitions) > 0 {
+		q.receiveConfigUpdates(updateChan)
+		maxParallelWorkers := q.maxParallelWorkers()
+		round := partitions[:min(len(partitions), maxParallelWorkers*maxPartitionsPerBatch)]
+		partitions = partitions[len(round):]
 
-	partitionWorkflows := make([]workflow.Future, 0, len(batches))
-	for i, parts := range batches {
-		batch := &protos.QRepPartitionBatch{
-			Partitions: parts,
-			BatchId:    int32(i + 1),
+		chunkSize := shared.DivCeil(len(round), maxParallelWorkers)
+		batches := make([][]*protos.QRepPartition, 0, len(round)/chunkSize+1)
+		for i := 0; i < len(round); i += chunkSize {
+			end := min(i+chunkSize, len(round))
+			batches = append(batches, round[i:end])
 		}
-		future := q.startChildWorkflow(ctx, batch)
-		partitionWorkflows = append(partitionWorkflows, future)
-	}
 
-	// wait for all the child workflows to complete
-	for _, future := range partitionWorkflows {
-		if err := future.Get(ctx, nil); err != nil {
-			return fmt.Errorf("failed to wait for child workflow: %w", err)
+		q.logger.Info("processing partitions in batches", "num batches", len(batches),
+			"remaining partitions", len(partitions))
+
+		partitionWorkflows := make([]workflow.Future, 0, len(batches))
+		for _, parts := range batches {
+			batchID += 1
+			batch := &protos.QRepPartitionBatch{
+				Partitions: parts,
+				BatchId:    batchID,
+			}
+			future := q.startChildWorkflow(ctx, batch)
+			partitionWorkflows = append(partitionWorkflows, future)
+		}
+
+		// wait for all the child workflows to complete
+		for _, future := range partitionWorkflows {
+			if err := future.Get(ctx, nil); err != nil {
+				return fmt.Errorf("failed to wait for child workflow: %w", err)
+			}
 		}
 	}
 
@@ -354,6 +377,18 @@ func (q *QRepFlowExecution) waitForNewRows(
 	return waitErr
 }
 
+// receiveConfigUpdates applies pending config updates, taking effect from the next partitions fetched
+func (q *QRepFlowExecution) receiveConfigUpdates(updateChan model.TypedReceiveChannel[*protos.QRepFlowConfigUpdate]) {
+	for {
+		update, ok := updateChan.ReceiveAsync()
+		if !ok {
+			break
+		}
+		shared.ApplyQRepFlowConfigUpdate(q.config, update)
+		q.logger.Info("applied config update", slog.Any("update", update))
+	}
+}
+
 func (q *QRepFlowExecution) handleTableCreationForResync(ctx workflow.Context, state *protos.QRepFlowState) error {
 	if state.NeedsResync && q.config.DstTableFullResync {
 		renamedTableIdentifier := q.config.DestinationTableIdentifier + "_peerdb_resync"
@@ -526,6 +561,7 @@ func QRepFlowWorkflow(
 	}
 
 	signalChan := model.FlowSignal.GetSignalChannel(ctx)
+	updateChan := model.QRepDynamicPropertiesSignal.GetSignalChannel(ctx)
 
 	q := NewQRepFlowExecution(ctx, config, originalRunID)
 	logger := q.logger
@@ -548,11 +584,7 @@ func QRepFlowWorkflow(
 		}
 		state.CurrentFlowStatus = protos.FlowStatus_STATUS_RUNNING
 	}
-
-	maxParallelWorkers := 16
-	if config.MaxParallelWorkers > 0 {
-		maxParallelWorkers = int(config.MaxParallelWorkers)
-	}
+	q.receiveConfigUpdates(updateChan)
 
 	err = q.SetupWatermarkTableOnDestination(ctx)
 	if err != nil {
@@ -584,7 +616,7 @@ func QRepFlowWorkflow(
 		}
 
 		logger.Info(fmt.Sprintf("%d partitions to replicate", len(partitions.Partitions)))
-		if err := q.processPartitions(ctx, maxParallelWorkers, partitions.Partitions); err != nil {
+		if err := q.processPartitions(ctx, updateChan, partitions.Partitions); err != nil {
 			return err
 		}
 
@@ -619,6 +651,7 @@ func QRepFlowWorkflow(
 		}
 		q.activeSignal = model.FlowSignalHandler(q.activeSignal, val, q.logger)
 	}
+	q.receiveConfigUpdates(updateChan)
 
 	logger.Info("Continuing as new workflow",
 		slog.Any("Last Partition", state.LastPartition),
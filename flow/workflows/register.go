@@ -14,8 +14,11 @@ func RegisterFlowWorkerWorkflows(w worker.WorkflowRegistry) {
 	w.RegisterWorkflow(QRepWaitForNewRowsWorkflow)
 	w.RegisterWorkflow(QRepPartitionWorkflow)
 	w.RegisterWorkflow(XminFlowWorkflow)
+	w.RegisterWorkflow(CutoverFlowWorkflow)
 
 	w.RegisterWorkflow(GlobalScheduleManagerWorkflow)
 	w.RegisterWorkflow(HeartbeatFlowWorkflow)
 	w.RegisterWorkflow(RecordSlotSizeWorkflow)
+	w.RegisterWorkflow(SyncSequencesWorkflow)
+	w.RegisterWorkflow(MaintenanceWindowWorkflow)
 }
@@ -4,8 +4,10 @@ import (
 	"time"
 
 	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 
+	"github.com/PeerDB-io/peer-flow/model"
 	"github.com/PeerDB-io/peer-flow/peerdbenv"
 )
 
@@ -33,6 +35,60 @@ func HeartbeatFlowWorkflow(ctx workflow.Context) error {
 	return heartbeatFuture.Get(ctx, nil)
 }
 
+// SyncSequencesWorkflow sets destination sequences of mirrors which sync them
+func SyncSequencesWorkflow(ctx workflow.Context) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Hour,
+		HeartbeatTimeout:    5 * time.Minute,
+	})
+	syncSequencesFuture := workflow.ExecuteActivity(ctx, flowable.SyncAllSequences)
+	return syncSequencesFuture.Get(ctx, nil)
+}
+
+// MaintenanceWindowWorkflow pauses mirrors as their maintenance windows start and resumes them as they end
+func MaintenanceWindowWorkflow(ctx workflow.Context) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 5 * time.Minute,
+	})
+	logger := workflow.GetLogger(ctx)
+
+	var actions []*model.MaintenanceWindowAction
+	if err := workflow.ExecuteActivity(ctx, flowable.GetMaintenanceWindowActions).Get(ctx, &actions); err != nil {
+		return err
+	}
+
+	// a mirror which has completed or been dropped is retried next run, until its windows are removed
+	applyCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute,
+		RetryPolicy:         &temporal.RetryPolicy{MaximumAttempts: 1},
+	})
+	for _, action := range actions {
+		var applied bool
+		if err := workflow.ExecuteActivity(applyCtx, flowable.ApplyMaintenanceWindowAction,
+			action).Get(ctx, &applied); err != nil {
+			logger.Warn("failed to apply maintenance window to mirror",
+				"flowName", action.FlowJobName, "pause", action.Pause, "error", err)
+			continue
+		}
+		if !applied {
+			logger.Info("mirror not running yet, pausing for maintenance window next run", "flowName", action.FlowJobName)
+			continue
+		}
+		logger.Info("applied maintenance window to mirror", "flowName", action.FlowJobName, "pause", action.Pause)
+		if err := workflow.ExecuteActivity(ctx, flowable.SetPausedByMaintenanceWindow,
+			action.FlowJobName, action.Pause).Get(ctx, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func withCronOptions(ctx workflow.Context, workflowID string, cron string) workflow.Context {
 	return workflow.WithChildOptions(ctx,
 		workflow.ChildWorkflowOptions{
@@ -65,6 +121,16 @@ func GlobalScheduleManagerWorkflow(ctx workflow.Context) error {
 		"*/5 * * * *")
 	workflow.ExecuteChildWorkflow(slotSizeCtx, RecordSlotSizeWorkflow)
 
+	syncSequencesCtx := withCronOptions(ctx,
+		"sync-sequences-"+info.OriginalRunID,
+		"*/5 * * * *")
+	workflow.ExecuteChildWorkflow(syncSequencesCtx, SyncSequencesWorkflow)
+
+	maintenanceWindowCtx := withCronOptions(ctx,
+		"maintenance-window-"+info.OriginalRunID,
+		"* * * * *")
+	workflow.ExecuteChildWorkflow(maintenanceWindowCtx, MaintenanceWindowWorkflow)
+
 	ctx.Done().Receive(ctx, nil)
 	return ctx.Err()
 }
@@ -0,0 +1,33 @@
+package peerflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/PeerDB-io/peer-flow/model"
+)
+
+func TestMaintenanceWindowWorkflow(t *testing.T) {
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterActivity(flowable)
+
+	paused := &model.MaintenanceWindowAction{FlowJobName: "paused", WorkflowID: "paused-wf", Pause: true}
+	notRunning := &model.MaintenanceWindowAction{FlowJobName: "setup", WorkflowID: "setup-wf", Pause: true}
+	resumed := &model.MaintenanceWindowAction{FlowJobName: "resumed", WorkflowID: "resumed-wf"}
+	env.OnActivity(flowable.GetMaintenanceWindowActions, mock.Anything).Return(
+		[]*model.MaintenanceWindowAction{paused, notRunning, resumed}, nil)
+	env.OnActivity(flowable.ApplyMaintenanceWindowAction, mock.Anything, paused).Return(true, nil)
+	env.OnActivity(flowable.ApplyMaintenanceWindowAction, mock.Anything, notRunning).Return(false, nil)
+	env.OnActivity(flowable.ApplyMaintenanceWindowAction, mock.Anything, resumed).Return(true, nil)
+	env.OnActivity(flowable.SetPausedByMaintenanceWindow, mock.Anything, "paused", true).Return(nil).Once()
+	env.OnActivity(flowable.SetPausedByMaintenanceWindow, mock.Anything, "resumed", false).Return(nil).Once()
+
+	env.ExecuteWorkflow(MaintenanceWindowWorkflow)
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	env.AssertExpectations(t)
+}
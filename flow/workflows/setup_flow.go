@@ -26,7 +26,7 @@ import (
 //     - initialize pullability on the source peer, as an example on postgres:
 //     - ensuring the required table exists on the source peer
 //     - creating the slot and publication on the source peer
-//  3. Destination Peer:
+//  3. Destination Peers, the destination and any additional destinations:
 //     - setup the metadata table on the destination peer
 //     - creating the raw table on the destination peer
 //     - creating the normalized table on the destination peer
@@ -69,31 +69,33 @@ func (s *SetupFlowExecution) checkConnectionsAndSetupMetadataTables(
 		return fmt.Errorf("failed to check source peer connection: %w", err)
 	}
 
-	dstSetupInput := &protos.SetupInput{
-		Peer:     config.Destination,
-		FlowName: config.FlowJobName,
-	}
-
-	// then check the destination peer connection
-	destConnStatusFuture := workflow.ExecuteLocalActivity(checkCtx, flowable.CheckConnection, dstSetupInput)
-	var destConnStatus activities.CheckConnectionResult
-	if err := destConnStatusFuture.Get(checkCtx, &destConnStatus); err != nil {
-		return fmt.Errorf("failed to check destination peer connection: %w", err)
-	}
+	for _, dstConfig := range shared.DestinationConfigs(config) {
+		dstSetupInput := &protos.SetupInput{
+			Peer:     dstConfig.Destination,
+			FlowName: config.FlowJobName,
+		}
 
-	s.Info("ensuring metadata table exists")
+		// then check the destination peer connection
+		destConnStatusFuture := workflow.ExecuteLocalActivity(checkCtx, flowable.CheckConnection, dstSetupInput)
+		var destConnStatus activities.CheckConnectionResult
+		if err := destConnStatusFuture.Get(checkCtx, &destConnStatus); err != nil {
+			return fmt.Errorf("failed to check destination peer %s connection: %w", dstConfig.Destination.Name, err)
+		}
 
-	// then setup the destination peer metadata tables
-	if destConnStatus.NeedsSetupMetadataTables {
-		setupCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
-			StartToCloseTimeout: 2 * time.Minute,
-		})
-		fDst := workflow.ExecuteActivity(setupCtx, flowable.SetupMetadataTables, dstSetupInput)
-		if err := fDst.Get(setupCtx, nil); err != nil {
-			return fmt.Errorf("failed to setup destination peer metadata tables: %w", err)
+		s.Info("ensuring metadata table exists", slog.String("destination", dstConfig.Destination.Name))
+
+		// then setup the destination peer metadata tables
+		if destConnStatus.NeedsSetupMetadataTables {
+			setupCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+				StartToCloseTimeout: 2 * time.Minute,
+			})
+			fDst := workflow.ExecuteActivity(setupCtx, flowable.SetupMetadataTables, dstSetupInput)
+			if err := fDst.Get(setupCtx, nil); err != nil {
+				return fmt.Errorf("failed to setup destination peer %s metadata tables: %w", dstConfig.Destination.Name, err)
+			}
+		} else {
+			s.Info("destination peer metadata tables already exist", slog.String("destination", dstConfig.Destination.Name))
 		}
-	} else {
-		s.Info("destination peer metadata tables already exist")
 	}
 
 	return nil
@@ -151,16 +153,19 @@ func (s *SetupFlowExecution) createRawTable(
 		StartToCloseTimeout: 5 * time.Minute,
 	})
 
-	// attempt to create the tables.
-	createRawTblInput := &protos.CreateRawTableInput{
-		PeerConnectionConfig: config.Destination,
-		FlowJobName:          s.cdcFlowName,
-		TableNameMapping:     s.tableNameMapping,
-	}
+	// attempt to create the tables, each destination has its own raw table
+	for _, dstConfig := range shared.DestinationConfigs(config) {
+		createRawTblInput := &protos.CreateRawTableInput{
+			PeerConnectionConfig: dstConfig.Destination,
+			FlowJobName:          s.cdcFlowName,
+			TableNameMapping:     s.tableNameMapping,
+			CommitInfo:           shared.NeedsCommitInfo(config.NormalizeMode, config.TableMappings) || config.ReplicationOrigin,
+		}
 
-	rawTblFuture := workflow.ExecuteActivity(ctx, flowable.CreateRawTable, createRawTblInput)
-	if err := rawTblFuture.Get(ctx, nil); err != nil {
-		return fmt.Errorf("failed to create raw table: %w", err)
+		rawTblFuture := workflow.ExecuteActivity(ctx, flowable.CreateRawTable, createRawTblInput)
+		if err := rawTblFuture.Get(ctx, nil); err != nil {
+			return fmt.Errorf("failed to create raw table on %s: %w", dstConfig.Destination.Name, err)
+		}
 	}
 
 	return nil
@@ -204,19 +209,24 @@ func (s *SetupFlowExecution) fetchTableSchemaAndSetupNormalizedTables(
 	normalizedTableMapping := shared.BuildProcessedSchemaMapping(flowConnectionConfigs.TableMappings,
 		tableNameSchemaMapping, s.Logger)
 
-	// now setup the normalized tables on the destination peer
-	setupConfig := &protos.SetupNormalizedTableBatchInput{
-		PeerConnectionConfig:   flowConnectionConfigs.Destination,
-		TableNameSchemaMapping: normalizedTableMapping,
-		SoftDeleteColName:      flowConnectionConfigs.SoftDeleteColName,
-		SyncedAtColName:        flowConnectionConfigs.SyncedAtColName,
-		FlowName:               flowConnectionConfigs.FlowJobName,
-	}
+	// now setup the normalized tables on the destination peers
+	for _, dstConfig := range shared.DestinationConfigs(flowConnectionConfigs) {
+		setupConfig := &protos.SetupNormalizedTableBatchInput{
+			PeerConnectionConfig:   dstConfig.Destination,
+			TableNameSchemaMapping: normalizedTableMapping,
+			SoftDelete:             flowConnectionConfigs.SoftDelete,
+			SoftDeleteColName:      flowConnectionConfigs.SoftDeleteColName,
+			SyncedAtColName:        flowConnectionConfigs.SyncedAtColName,
+			FlowName:               flowConnectionConfigs.FlowJobName,
+			TableMappings:          flowConnectionConfigs.TableMappings,
+			NormalizeMode:          flowConnectionConfigs.NormalizeMode,
+		}
 
-	future = workflow.ExecuteActivity(ctx, flowable.CreateNormalizedTable, setupConfig)
-	if err := future.Get(ctx, nil); err != nil {
-		s.Error("failed to create normalized tables: ", err)
-		return nil, fmt.Errorf("failed to create normalized tables: %w", err)
+		future = workflow.ExecuteActivity(ctx, flowable.CreateNormalizedTable, setupConfig)
+		if err := future.Get(ctx, nil); err != nil {
+			s.Error("failed to create normalized tables: ", err)
+			return nil, fmt.Errorf("failed to create normalized tables on %s: %w", dstConfig.Destination.Name, err)
+		}
 	}
 
 	s.Info("finished setting up normalized tables for peer flow")
@@ -0,0 +1,75 @@
+package peerflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/PeerDB-io/peer-flow/activities"
+	"github.com/PeerDB-io/peer-flow/generated/protos"
+	"github.com/PeerDB-io/peer-flow/shared"
+)
+
+func TestSetupFlowChangelogMirror(t *testing.T) {
+	var testSuite testsuite.WorkflowTestSuite
+	env := testSuite.NewTestWorkflowEnvironment()
+	env.RegisterActivity(flowable)
+
+	config := &protos.FlowConnectionConfigs{
+		FlowJobName: "changelog_mirror",
+		Source:      &protos.Peer{Name: "source", Type: protos.DBType_POSTGRES},
+		Destination: &protos.Peer{Name: "destination", Type: protos.DBType_POSTGRES},
+		TableMappings: []*protos.TableMapping{{
+			SourceTableIdentifier:      "public.events",
+			DestinationTableIdentifier: "public.events_changelog",
+		}},
+		NormalizeMode: protos.NormalizeMode_NORMALIZE_MODE_CHANGELOG,
+	}
+	tableSchema := &protos.TableSchema{
+		TableIdentifier:   "public.events",
+		PrimaryKeyColumns: []string{"id"},
+		Columns:           []*protos.FieldDescription{{Name: "id", Type: "int4"}},
+	}
+
+	env.OnActivity(flowable.CheckConnection, mock.Anything, mock.Anything).Return(
+		&activities.CheckConnectionResult{}, nil)
+	env.OnActivity(flowable.EnsurePullability, mock.Anything, mock.Anything).Return(
+		&protos.EnsurePullabilityBatchOutput{
+			TableIdentifierMapping: map[string]*protos.PostgresTableIdentifier{
+				"public.events": {RelId: 1},
+			},
+		}, nil)
+	env.OnActivity(flowable.GetTableSchema, mock.Anything, mock.Anything).Return(
+		&protos.GetTableSchemaBatchOutput{
+			TableNameSchemaMapping: map[string]*protos.TableSchema{"public.events": tableSchema},
+		}, nil)
+
+	var rawTableInput *protos.CreateRawTableInput
+	env.OnActivity(flowable.CreateRawTable, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, input *protos.CreateRawTableInput) (*protos.CreateRawTableOutput, error) {
+			rawTableInput = input
+			return &protos.CreateRawTableOutput{}, nil
+		})
+	var normalizedTableInput *protos.SetupNormalizedTableBatchInput
+	env.OnActivity(flowable.CreateNormalizedTable, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, input *protos.SetupNormalizedTableBatchInput) (*protos.SetupNormalizedTableBatchOutput, error) {
+			normalizedTableInput = input
+			return &protos.SetupNormalizedTableBatchOutput{}, nil
+		})
+
+	env.ExecuteWorkflow(SetupFlowWorkflow, config)
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+
+	// raw table keeps the commit LSN and time of each change for the changelog
+	require.NotNil(t, rawTableInput)
+	require.True(t, rawTableInput.CommitInfo)
+
+	// normalized table is created with the changelog columns
+	require.NotNil(t, normalizedTableInput)
+	require.Equal(t, protos.NormalizeMode_NORMALIZE_MODE_CHANGELOG, shared.NormalizeModeForTable(
+		normalizedTableInput.NormalizeMode, normalizedTableInput.TableMappings, "public.events_changelog"))
+}
@@ -68,6 +68,8 @@ func (s *SnapshotFlowExecution) setupReplication(
 		DoInitialSnapshot:           s.config.DoInitialSnapshot,
 		ExistingPublicationName:     s.config.PublicationName,
 		ExistingReplicationSlotName: s.config.ReplicationSlotName,
+		FailoverSlot:                s.config.FailoverSlot,
+		TwoPhase:                    s.config.TwoPhase,
 	}
 
 	res := &protos.SetupReplicationOutput{}
@@ -105,6 +107,7 @@ func (s *SnapshotFlowExecution) cloneTable(
 	boundSelector *concurrency.BoundSelector,
 	snapshotName string,
 	mapping *protos.TableMapping,
+	destination *protos.Peer,
 ) error {
 	flowName := s.config.FlowJobName
 	cloneLog := slog.Group("clone-log",
@@ -116,6 +119,9 @@ func (s *SnapshotFlowExecution) cloneTable(
 	originalRunID := workflow.GetInfo(ctx).OriginalRunID
 
 	childWorkflowID := fmt.Sprintf("clone_%s_%s_%s", flowName, dstName, originalRunID)
+	if destination.Name != s.config.Destination.Name {
+		childWorkflowID = fmt.Sprintf("clone_%s_%s_%s_%s", flowName, destination.Name, dstName, originalRunID)
+	}
 	childWorkflowID = shared.ReplaceIllegalCharactersWithUnderscores(childWorkflowID)
 
 	s.logger.Info(fmt.Sprintf("Obtained child id %s for source table %s and destination table %s",
@@ -176,7 +182,7 @@ func (s *SnapshotFlowExecution) cloneTable(
 	}
 	// ensure document IDs are synchronized across initial load and CDC
 	// for the same document
-	if s.config.Destination.Type == protos.DBType_ELASTICSEARCH {
+	if destination.Type == protos.DBType_ELASTICSEARCH {
 		snapshotWriteMode = &protos.QRepWriteMode{
 			WriteType:        protos.QRepWriteType_QREP_WRITE_MODE_UPSERT,
 			UpsertKeyColumns: s.tableNameSchemaMapping[mapping.DestinationTableIdentifier].PrimaryKeyColumns,
@@ -186,7 +192,7 @@ func (s *SnapshotFlowExecution) cloneTable(
 	config := &protos.QRepConfig{
 		FlowJobName:                childWorkflowID,
 		SourcePeer:                 sourcePostgres,
-		DestinationPeer:            s.config.Destination,
+		DestinationPeer:            destination,
 		Query:                      query,
 		WatermarkColumn:            mapping.PartitionKey,
 		WatermarkTable:             srcName,
@@ -239,10 +245,13 @@ func (s *SnapshotFlowExecution) cloneTables(
 		if v.PartitionKey == "" {
 			v.PartitionKey = defaultPartitionCol
 		}
-		err := s.cloneTable(ctx, boundSelector, snapshotName, v)
-		if err != nil {
-			s.logger.Error("failed to start clone child workflow: ", err)
-			continue
+		// every destination loads the same snapshot
+		for _, dstConfig := range shared.DestinationConfigs(s.config) {
+			err := s.cloneTable(ctx, boundSelector, snapshotName, v, dstConfig.Destination)
+			if err != nil {
+				s.logger.Error("failed to start clone child workflow: ", err)
+				continue
+			}
 		}
 	}
 
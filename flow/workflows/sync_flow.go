@@ -187,6 +187,7 @@ func SyncFlowWorkflow(
 						Done:                   false,
 						SyncBatchID:            childSyncFlowRes.CurrentSyncBatchID,
 						TableNameSchemaMapping: options.TableNameSchemaMapping,
+						AdditionalSyncBatchIDs: childSyncFlowRes.AdditionalSyncBatchIDs,
 					},
 				).Get(ctx, nil)
 				if err != nil {
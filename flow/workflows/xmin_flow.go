@@ -27,6 +27,7 @@ func XminFlowWorkflow(
 	}
 
 	signalChan := model.FlowSignal.GetSignalChannel(ctx)
+	updateChan := model.QRepDynamicPropertiesSignal.GetSignalChannel(ctx)
 
 	q := NewQRepFlowExecution(ctx, config, originalRunID)
 	logger := q.logger
@@ -49,6 +50,7 @@ func XminFlowWorkflow(
 		}
 		state.CurrentFlowStatus = protos.FlowStatus_STATUS_RUNNING
 	}
+	q.receiveConfigUpdates(updateChan)
 
 	err = q.SetupWatermarkTableOnDestination(ctx)
 	if err != nil {
@@ -111,6 +113,7 @@ func XminFlowWorkflow(
 		}
 		q.activeSignal = model.FlowSignalHandler(q.activeSignal, val, q.logger)
 	}
+	q.receiveConfigUpdates(updateChan)
 
 	logger.Info("Continuing as new workflow",
 		slog.Any("Last Partition", state.LastPartition),
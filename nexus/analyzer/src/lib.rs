@@ -329,6 +329,50 @@ impl StatementAnalyzer for PeerDDLAnalyzer {
                             _ => "Q".to_string(),
                         };
 
+                        let normalize_mode = match raw_options.remove("normalize_mode") {
+                            Some(Expr::Value(ast::Value::SingleQuotedString(s))) => {
+                                s.to_uppercase()
+                            }
+                            _ => "MERGE".to_string(),
+                        };
+
+                        let failover_slot = match raw_options.remove("failover_slot") {
+                            Some(Expr::Value(ast::Value::Boolean(b))) => *b,
+                            _ => false,
+                        };
+
+                        let binary_transfer = match raw_options.remove("binary_transfer") {
+                            Some(Expr::Value(ast::Value::Boolean(b))) => *b,
+                            _ => false,
+                        };
+
+                        let replication_origin = match raw_options.remove("replication_origin") {
+                            Some(Expr::Value(ast::Value::Boolean(b))) => *b,
+                            _ => false,
+                        };
+
+                        let sync_sequences = match raw_options.remove("sync_sequences") {
+                            Some(Expr::Value(ast::Value::Boolean(b))) => *b,
+                            _ => false,
+                        };
+
+                        let sequence_margin: Option<u32> = match raw_options.remove("sequence_margin")
+                        {
+                            Some(Expr::Value(ast::Value::Number(n, _))) => Some(n.parse::<u32>()?),
+                            _ => None,
+                        };
+
+                        let two_phase = match raw_options.remove("two_phase") {
+                            Some(Expr::Value(ast::Value::Boolean(b))) => *b,
+                            _ => false,
+                        };
+
+                        let skip_replicated_changes =
+                            match raw_options.remove("skip_replicated_changes") {
+                                Some(Expr::Value(ast::Value::Boolean(b))) => *b,
+                                _ => false,
+                            };
+
                         let flow_job = FlowJob {
                             name: cdc.mirror_name.to_string().to_lowercase(),
                             source_peer: cdc.source_peer.to_string().to_lowercase(),
@@ -353,6 +397,14 @@ impl StatementAnalyzer for PeerDDLAnalyzer {
                             initial_snapshot_only: initial_copy_only,
                             script,
                             system,
+                            normalize_mode,
+                            failover_slot,
+                            binary_transfer,
+                            replication_origin,
+                            sync_sequences,
+                            sequence_margin,
+                            two_phase,
+                            skip_replicated_changes,
                         };
 
                         if initial_copy_only && !do_initial_copy {
@@ -746,6 +798,20 @@ fn parse_db_options(db_type: DbType, with_options: &[SqlOption]) -> anyhow::Resu
                     .get("disable_tls")
                     .map(|s| s.parse::<bool>().unwrap_or_default()).unwrap_or_default(),
                 endpoint: opts.get("endpoint").map(|s| s.to_string()),
+                ingest_mode: match opts.get("ingest_mode").map(|s| s.to_lowercase()) {
+                    Some(mode) if mode == "native" => {
+                        pt::peerdb_peers::ClickhouseIngestMode::Native.into()
+                    }
+                    _ => pt::peerdb_peers::ClickhouseIngestMode::S3.into(),
+                },
+                insert_batch_size: opts
+                    .get("insert_batch_size")
+                    .and_then(|s| s.parse::<u32>().ok())
+                    .unwrap_or_default(),
+                cluster: opts
+                    .get("cluster")
+                    .map(|s| s.to_string())
+                    .unwrap_or_default(),
             };
             Config::ClickhouseConfig(clickhouse_config)
         }
@@ -914,6 +980,8 @@ fn parse_db_options(db_type: DbType, with_options: &[SqlOption]) -> anyhow::Resu
                 })
             }
         }
+        // CREATE PEER has no webhook peer type, webhook peers are created through the API
+        DbType::Webhook => anyhow::bail!("webhook peers can't be created with SQL"),
         DbType::Mysql => Config::MysqlConfig(pt::peerdb_peers::MySqlConfig {
             host: opts.get("host").context("no host specified")?.to_string(),
             port: opts
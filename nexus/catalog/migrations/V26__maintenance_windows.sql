@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+  id SERIAL PRIMARY KEY,
+  flow_name TEXT NOT NULL,
+  cron_expression TEXT NOT NULL,
+  duration_seconds BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_windows_flow_name ON maintenance_windows (flow_name);
+
+CREATE TABLE IF NOT EXISTS maintenance_window_state (
+  flow_name TEXT PRIMARY KEY,
+  paused_by_window BOOLEAN NOT NULL DEFAULT false,
+  manually_paused BOOLEAN NOT NULL DEFAULT false,
+  skip_until TIMESTAMPTZ
+);
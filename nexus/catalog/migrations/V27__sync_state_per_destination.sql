@@ -0,0 +1,13 @@
+ALTER TABLE metadata_last_sync_state
+ADD COLUMN peer_name TEXT NOT NULL DEFAULT '';
+
+-- state so far belongs to the destination a mirror was created with
+UPDATE metadata_last_sync_state m
+SET peer_name = p.name
+FROM flows f
+JOIN peers p ON p.id = f.destination_peer
+WHERE f.name = m.job_name;
+
+ALTER TABLE metadata_last_sync_state
+DROP CONSTRAINT metadata_last_sync_state_pkey,
+ADD PRIMARY KEY (job_name, peer_name);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS split_transactions (
+  flow_name TEXT NOT NULL,
+  peer_name TEXT NOT NULL,
+  commit_lsn BIGINT NOT NULL,
+  records_synced INT NOT NULL,
+  PRIMARY KEY (flow_name, peer_name)
+);
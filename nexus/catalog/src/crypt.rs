@@ -0,0 +1,148 @@
+//! Envelope encryption of catalog blobs, must stay in sync with flow/peerdbenv/crypt.go
+//!
+//! encrypted blobs are laid out as
+//! MAGIC | len(key id) | key id | nonce | sealed data key | nonce | sealed payload
+//! a leading zero byte is never valid protobuf, so unencrypted rows stay readable
+
+use anyhow::{anyhow, Context};
+use base64::{engine::general_purpose::STANDARD, Engine};
+use ring::aead::{Aad, LessSafeKey, Nonce, UnboundKey, AES_256_GCM, NONCE_LEN};
+use ring::rand::{SecureRandom, SystemRandom};
+
+const MAGIC: &[u8] = b"\x00PDBENC1";
+const KEY_SIZE: usize = 32;
+const TAG_SIZE: usize = 16;
+const WRAPPED_KEY_SIZE: usize = NONCE_LEN + KEY_SIZE + TAG_SIZE;
+
+struct EncKey {
+    id: String,
+    value: Vec<u8>,
+}
+
+/// PEERDB_ENC_KEYS_FILE, falls back to PEERDB_ENC_KEYS
+/// both hold a JSON array of {"id": ..., "value": <base64 of 32 bytes>}
+fn load_keys() -> anyhow::Result<Vec<EncKey>> {
+    let raw = match std::env::var("PEERDB_ENC_KEYS_FILE") {
+        Ok(path) if !path.is_empty() => {
+            std::fs::read_to_string(path).context("failed to read encryption keys file")?
+        }
+        _ => std::env::var("PEERDB_ENC_KEYS").unwrap_or_default(),
+    };
+    if raw.trim().is_empty() {
+        return Ok(Vec::new());
+    }
+
+    let parsed: Vec<serde_json::Value> =
+        serde_json::from_str(&raw).context("failed to parse encryption keys")?;
+    parsed
+        .into_iter()
+        .map(|key| {
+            let id = key["id"].as_str().unwrap_or_default().to_string();
+            let value = STANDARD
+                .decode(key["value"].as_str().unwrap_or_default())
+                .with_context(|| format!("failed to decode encryption key {}", id))?;
+            if value.len() != KEY_SIZE {
+                return Err(anyhow!(
+                    "encryption key {} must be {} bytes, got {}",
+                    id,
+                    KEY_SIZE,
+                    value.len()
+                ));
+            }
+            Ok(EncKey { id, value })
+        })
+        .collect()
+}
+
+fn aead_key(key: &[u8]) -> anyhow::Result<LessSafeKey> {
+    let unbound = UnboundKey::new(&AES_256_GCM, key).map_err(|_| anyhow!("invalid AES key"))?;
+    Ok(LessSafeKey::new(unbound))
+}
+
+fn seal(
+    rng: &SystemRandom,
+    key: &LessSafeKey,
+    out: &mut Vec<u8>,
+    plaintext: &[u8],
+) -> anyhow::Result<()> {
+    let mut nonce = [0u8; NONCE_LEN];
+    rng.fill(&mut nonce)
+        .map_err(|_| anyhow!("failed to generate nonce"))?;
+    let mut sealed = plaintext.to_vec();
+    key.seal_in_place_append_tag(
+        Nonce::assume_unique_for_key(nonce),
+        Aad::empty(),
+        &mut sealed,
+    )
+    .map_err(|_| anyhow!("failed to encrypt data"))?;
+    out.extend_from_slice(&nonce);
+    out.extend_from_slice(&sealed);
+    Ok(())
+}
+
+fn open(key: &LessSafeKey, data: &[u8]) -> anyhow::Result<Vec<u8>> {
+    let nonce = Nonce::try_assume_unique_for_key(&data[..NONCE_LEN])
+        .map_err(|_| anyhow!("invalid nonce"))?;
+    let mut sealed = data[NONCE_LEN..].to_vec();
+    let plaintext = key
+        .open_in_place(nonce, Aad::empty(), &mut sealed)
+        .map_err(|_| anyhow!("failed to decrypt data"))?;
+    Ok(plaintext.to_vec())
+}
+
+/// encrypts a blob about to be stored in the catalog with PEERDB_CURRENT_ENC_KEY_ID,
+/// blobs are written unencrypted when it is unset
+pub fn encrypt_catalog_data(plaintext: Vec<u8>) -> anyhow::Result<Vec<u8>> {
+    let current_id = std::env::var("PEERDB_CURRENT_ENC_KEY_ID").unwrap_or_default();
+    if current_id.is_empty() {
+        return Ok(plaintext);
+    }
+    if current_id.len() > 255 {
+        return Err(anyhow!("encryption key id must be at most 255 bytes"));
+    }
+    let keys = load_keys()?;
+    let key = keys
+        .iter()
+        .find(|key| key.id == current_id)
+        .ok_or_else(|| anyhow!("encryption key {} not found", current_id))?;
+
+    let rng = SystemRandom::new();
+    let mut data_key = [0u8; KEY_SIZE];
+    rng.fill(&mut data_key)
+        .map_err(|_| anyhow!("failed to generate data key"))?;
+
+    let mut out = Vec::with_capacity(
+        MAGIC.len() + 1 + current_id.len() + WRAPPED_KEY_SIZE + NONCE_LEN + plaintext.len() + TAG_SIZE,
+    );
+    out.extend_from_slice(MAGIC);
+    out.push(current_id.len() as u8);
+    out.extend_from_slice(current_id.as_bytes());
+    seal(&rng, &aead_key(&key.value)?, &mut out, &data_key)?;
+    seal(&rng, &aead_key(&data_key)?, &mut out, &plaintext)?;
+    Ok(out)
+}
+
+/// decrypts a blob loaded from the catalog, unencrypted blobs are returned as is
+pub fn decrypt_catalog_data(data: &[u8]) -> anyhow::Result<Vec<u8>> {
+    if !data.starts_with(MAGIC) || data.len() <= MAGIC.len() {
+        return Ok(data.to_vec());
+    }
+    let id_len = data[MAGIC.len()] as usize;
+    let id_start = MAGIC.len() + 1;
+    if data.len() < id_start + id_len + WRAPPED_KEY_SIZE + NONCE_LEN + TAG_SIZE {
+        return Err(anyhow!("encrypted data is truncated"));
+    }
+    let key_id = std::str::from_utf8(&data[id_start..id_start + id_len])
+        .context("invalid encryption key id")?;
+
+    let keys = load_keys()?;
+    let key = keys
+        .iter()
+        .find(|key| key.id == key_id)
+        .ok_or_else(|| anyhow!("encryption key {} not found", key_id))?;
+
+    let rest = &data[id_start + id_len..];
+    let data_key = open(&aead_key(&key.value)?, &rest[..WRAPPED_KEY_SIZE])
+        .with_context(|| format!("failed to unwrap data key with key {}", key_id))?;
+    open(&aead_key(&data_key)?, &rest[WRAPPED_KEY_SIZE..])
+}
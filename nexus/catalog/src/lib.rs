@@ -15,6 +15,8 @@ use serde_json::Value;
 use sqlparser::ast::Statement;
 use tokio_postgres::{types, Client};
 
+mod crypt;
+
 mod embedded {
     use refinery::embed_migrations;
     embed_migrations!("migrations");
@@ -105,8 +107,10 @@ impl Catalog {
                     elasticsearch_config.encode_to_vec()
                 }
                 Config::MysqlConfig(mysql_config) => mysql_config.encode_to_vec(),
+                Config::WebhookConfig(webhook_config) => webhook_config.encode_to_vec(),
             }
         };
+        let config_blob = crypt::encrypt_catalog_data(config_blob)?;
 
         let stmt = self
             .pg
@@ -257,6 +261,7 @@ impl Catalog {
         name: &str,
         options: &[u8],
     ) -> anyhow::Result<Option<Config>> {
+        let options = &crypt::decrypt_catalog_data(options)?[..];
         Ok(if let Some(db_type) = db_type {
             let err = || {
                 format!(
@@ -326,6 +331,11 @@ impl Catalog {
                         pt::peerdb_peers::MySqlConfig::decode(options).with_context(err)?;
                     Config::MysqlConfig(mysql_config)
                 }
+                DbType::Webhook => {
+                    let webhook_config =
+                        pt::peerdb_peers::WebhookConfig::decode(options).with_context(err)?;
+                    Config::WebhookConfig(webhook_config)
+                }
             })
         } else {
             None
@@ -576,8 +586,8 @@ impl Catalog {
             .await?;
 
         Ok(match row {
-            Some(row) => Some(pt::peerdb_flow::QRepConfig::decode::<&[u8]>(
-                row.get("config_proto"),
+            Some(row) => Some(pt::peerdb_flow::QRepConfig::decode(
+                &crypt::decrypt_catalog_data(row.get("config_proto"))?[..],
             )?),
             None => None,
         })
@@ -1,7 +1,7 @@
 use catalog::WorkflowDetails;
 use pt::{
     flow_model::{FlowJob, QRepFlowJob},
-    peerdb_flow::{QRepWriteMode, QRepWriteType, TypeSystem},
+    peerdb_flow::{NormalizeMode, QRepWriteMode, QRepWriteType, TypeSystem},
     peerdb_route, tonic,
 };
 use serde_json::Value;
@@ -142,6 +142,8 @@ impl FlowGrpcClient {
                 destination_table_identifier: mapping.destination_table_identifier.clone(),
                 partition_key: mapping.partition_key.clone().unwrap_or_default(),
                 exclude: mapping.exclude.clone(),
+                clickhouse_settings: None,
+                normalize_mode: None,
             })
             .collect::<Vec<_>>();
 
@@ -154,6 +156,14 @@ impl FlowGrpcClient {
         let Some(system) = TypeSystem::from_str_name(&job.system) else {
             return anyhow::Result::Err(anyhow::anyhow!("invalid system {}", job.system));
         };
+        let Some(normalize_mode) =
+            NormalizeMode::from_str_name(&format!("NORMALIZE_MODE_{}", job.normalize_mode))
+        else {
+            return anyhow::Result::Err(anyhow::anyhow!(
+                "invalid normalize_mode {}",
+                job.normalize_mode
+            ));
+        };
 
         let flow_conn_cfg = pt::peerdb_flow::FlowConnectionConfigs {
             source: Some(src),
@@ -176,6 +186,14 @@ impl FlowGrpcClient {
             initial_snapshot_only: job.initial_snapshot_only,
             script: job.script.clone(),
             system: system as i32,
+            normalize_mode: normalize_mode as i32,
+            failover_slot: job.failover_slot,
+            binary_transfer: job.binary_transfer,
+            replication_origin: job.replication_origin,
+            sync_sequences: job.sync_sequences,
+            sequence_margin: job.sequence_margin.unwrap_or_default(),
+            two_phase: job.two_phase,
+            skip_replicated_changes: job.skip_replicated_changes,
             ..Default::default()
         };
 
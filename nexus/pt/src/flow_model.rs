@@ -36,6 +36,15 @@ pub struct FlowJob {
     pub initial_snapshot_only: bool,
     pub script: String,
     pub system: String,
+    // MERGE, CHANGELOG or SCD2
+    pub normalize_mode: String,
+    pub failover_slot: bool,
+    pub binary_transfer: bool,
+    pub replication_origin: bool,
+    pub sync_sequences: bool,
+    pub sequence_margin: Option<u32>,
+    pub two_phase: bool,
+    pub skip_replicated_changes: bool,
 }
 
 #[derive(Debug, PartialEq, Eq, Serialize, Deserialize, Clone)]
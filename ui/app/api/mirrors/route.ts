@@ -1,4 +1,5 @@
 import { getTruePeer } from '@/app/api/peers/getTruePeer';
+import { decryptCatalogData } from '@/app/utils/crypt';
 import prisma from '@/app/utils/prisma';
 
 export const dynamic = 'force-dynamic';
@@ -16,6 +17,9 @@ export async function GET(request: Request) {
   const flows = mirrors?.map((mirror: any) => {
     let newMirror: any = {
       ...mirror,
+      config_proto: mirror.config_proto
+        ? decryptCatalogData(mirror.config_proto)
+        : mirror.config_proto,
       sourcePeer: getTruePeer(mirror.sourcePeer),
       destinationPeer: getTruePeer(mirror.destinationPeer),
     };
@@ -1,4 +1,5 @@
 import { CatalogPeer } from '@/app/dto/PeersDTO';
+import { decryptCatalogData } from '@/app/utils/crypt';
 import {
   BigqueryConfig,
   ClickhouseConfig,
@@ -20,7 +21,7 @@ export const getTruePeer = (peer: CatalogPeer) => {
     name: peer.name,
     type: peer.type,
   };
-  const options = peer.options;
+  const options = decryptCatalogData(peer.options);
   let config:
     | BigqueryConfig
     | ClickhouseConfig
@@ -38,6 +38,8 @@ const EditMirror = ({ params: { mirrorId } }: EditMirrorProps) => {
     batchSize: defaultBatchSize,
     idleTimeout: defaultIdleTimeout,
     additionalTables: [],
+    removedTables: [],
+    dropRemovedTables: false,
   });
   const { push } = useRouter();
 
@@ -53,6 +55,8 @@ const EditMirror = ({ params: { mirrorId } }: EditMirrorProps) => {
           (res as MirrorStatusResponse).cdcStatus?.config?.idleTimeoutSeconds ||
           defaultIdleTimeout,
         additionalTables: [],
+        removedTables: [],
+        dropRemovedTables: false,
       });
     });
   }, [mirrorId, defaultBatchSize, defaultIdleTimeout]);
@@ -1,4 +1,5 @@
 import { SyncStatusRow } from '@/app/dto/MirrorsDTO';
+import { decryptCatalogData } from '@/app/utils/crypt';
 import prisma from '@/app/utils/prisma';
 import MirrorActions from '@/components/MirrorActionsDropdown';
 import { FlowConnectionConfigs, FlowStatus } from '@/grpc_generated/flow';
@@ -85,7 +86,9 @@ export default async function ViewMirror({
       }
       return acc;
     }, 0);
-    const mirrorConfig = FlowConnectionConfigs.decode(mirrorInfo.config_proto!);
+    const mirrorConfig = FlowConnectionConfigs.decode(
+      decryptCatalogData(mirrorInfo.config_proto!)
+    );
     syncStatusChild = (
       <SyncStatus rowsSynced={rowsSynced} rows={rows} flowJobName={mirrorId} />
     );
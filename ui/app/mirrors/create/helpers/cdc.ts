@@ -183,4 +183,88 @@ export const cdcSettings: MirrorSetting[] = [
     tips: 'A field to set the name of PeerDBs soft delete column.',
     advanced: AdvancedSettingType.ALL,
   },
+  {
+    label: 'Failover Slot',
+    stateHandler: (value, setter) =>
+      setter((curr: CDCConfig) => ({
+        ...curr,
+        failoverSlot: (value as boolean) ?? false,
+      })),
+    type: 'switch',
+    default: false,
+    tips: 'Postgres 17+ only. Creates the replication slot as a failover slot so the mirror continues after the source fails over to a standby with sync_replication_slots on.',
+    advanced: AdvancedSettingType.ALL,
+  },
+  {
+    label: 'Binary Transfer',
+    stateHandler: (value, setter) =>
+      setter((curr: CDCConfig) => ({
+        ...curr,
+        binaryTransfer: (value as boolean) ?? false,
+      })),
+    type: 'switch',
+    default: false,
+    tips: 'Postgres 14+ only. Replicates column values in their binary format, saving text conversions on the source for numeric, timestamp and bytea heavy tables.',
+    advanced: AdvancedSettingType.ALL,
+  },
+  {
+    label: 'Replication Origin',
+    stateHandler: (value, setter) =>
+      setter((curr: CDCConfig) => ({
+        ...curr,
+        replicationOrigin: (value as boolean) ?? false,
+      })),
+    type: 'switch',
+    default: false,
+    tips: 'Postgres destinations only. Tags changes applied to the destination with a replication origin so a mirror in the opposite direction skipping replicated changes skips them, enabling bidirectional replication.',
+    advanced: AdvancedSettingType.ALL,
+  },
+  {
+    label: 'Skip Replicated Changes',
+    stateHandler: (value, setter) =>
+      setter((curr: CDCConfig) => ({
+        ...curr,
+        skipReplicatedChanges: (value as boolean) ?? false,
+      })),
+    type: 'switch',
+    default: false,
+    tips: 'Postgres sources only. Skips changes applied to the source by mirrors with Replication Origin on. Leave it off when chaining mirrors so their changes are passed on.',
+    advanced: AdvancedSettingType.ALL,
+  },
+  {
+    label: 'Sync Sequences',
+    stateHandler: (value, setter) =>
+      setter((curr: CDCConfig) => ({
+        ...curr,
+        syncSequences: (value as boolean) ?? false,
+      })),
+    type: 'switch',
+    default: false,
+    tips: 'Postgres to Postgres only. Every few minutes, advances sequences owned by destination columns to the value of their source sequence, so no setval script is needed at cutover.',
+    advanced: AdvancedSettingType.ALL,
+  },
+  {
+    label: 'Sequence Margin',
+    stateHandler: (value, setter) =>
+      setter((curr: CDCConfig) => ({
+        ...curr,
+        sequenceMargin: parseInt(value as string, 10) || 0,
+      })),
+    tips: 'Added to source sequence values when syncing sequences, covering values handed out on the source since they were sampled.',
+    type: 'number',
+    default: '0',
+    advanced: AdvancedSettingType.ALL,
+  },
+  {
+    label: 'Two-Phase Decoding',
+    stateHandler: (value, setter) =>
+      setter((curr: CDCConfig) => ({
+        ...curr,
+        twoPhase: (value as boolean) ?? false,
+      })),
+    type: 'switch',
+    default: false,
+    tips: 'Postgres 15+ only. Decodes prepared transactions at PREPARE instead of COMMIT PREPARED. Not available for queue, Elasticsearch and webhook destinations or with a failover slot.',
+    advanced: AdvancedSettingType.ALL,
+  },
 ];
@@ -1,4 +1,8 @@
-import { FlowConnectionConfigs, TypeSystem } from '@/grpc_generated/flow';
+import {
+  FlowConnectionConfigs,
+  NormalizeMode,
+  TypeSystem,
+} from '@/grpc_generated/flow';
 
 export enum AdvancedSettingType {
   QUEUE = 'queue',
@@ -40,6 +44,15 @@ export const blankCDCSetting: FlowConnectionConfigs = {
   idleTimeoutSeconds: 60,
   script: '',
   system: TypeSystem.Q,
+  additionalDestinations: [],
+  normalizeMode: NormalizeMode.NORMALIZE_MODE_MERGE,
+  failoverSlot: false,
+  binaryTransfer: false,
+  replicationOrigin: false,
+  syncSequences: false,
+  sequenceMargin: 0,
+  twoPhase: false,
+  skipReplicatedChanges: false,
 };
 
 export const blankQRepSetting = {
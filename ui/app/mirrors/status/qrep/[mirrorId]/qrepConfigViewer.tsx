@@ -1,3 +1,4 @@
+import { decryptCatalogData } from '@/app/utils/crypt';
 import prisma from '@/app/utils/prisma';
 import { QRepConfig } from '@/grpc_generated/flow';
 import { Badge } from '@/lib/Badge';
@@ -33,7 +34,9 @@ export default async function QRepConfigViewer({
     );
   }
 
-  let qrepConfig = QRepConfig.decode(configBuffer.config_proto);
+  let qrepConfig = QRepConfig.decode(
+    decryptCatalogData(configBuffer.config_proto)
+  );
 
   return (
     <div className='my-4'>
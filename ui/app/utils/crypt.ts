@@ -0,0 +1,55 @@
+import { createDecipheriv } from 'crypto';
+import { readFileSync } from 'fs';
+
+// must stay in sync with flow/peerdbenv/crypt.go
+// encrypted catalog blobs are laid out as
+// MAGIC | len(keyID) | keyID | nonce | sealed data key | nonce | sealed payload
+const MAGIC = Buffer.from('\x00PDBENC1', 'latin1');
+const KEY_SIZE = 32;
+const NONCE_SIZE = 12;
+const TAG_SIZE = 16;
+const WRAPPED_KEY_SIZE = NONCE_SIZE + KEY_SIZE + TAG_SIZE;
+
+type EncKey = { id: string; value: string };
+
+const loadKeys = (): EncKey[] => {
+  const raw = process.env.PEERDB_ENC_KEYS_FILE
+    ? readFileSync(process.env.PEERDB_ENC_KEYS_FILE, 'utf8')
+    : process.env.PEERDB_ENC_KEYS ?? '';
+  return raw.trim() ? JSON.parse(raw) : [];
+};
+
+const open = (key: Buffer, sealed: Buffer): Buffer => {
+  const nonce = sealed.subarray(0, NONCE_SIZE);
+  const ciphertext = sealed.subarray(NONCE_SIZE, sealed.length - TAG_SIZE);
+  const tag = sealed.subarray(sealed.length - TAG_SIZE);
+  const decipher = createDecipheriv('aes-256-gcm', key, nonce);
+  decipher.setAuthTag(tag);
+  return Buffer.concat([decipher.update(ciphertext), decipher.final()]);
+};
+
+// decrypts a peer or mirror config loaded from the catalog,
+// unencrypted configs are returned as is
+export const decryptCatalogData = (data: Uint8Array): Buffer => {
+  const buf = Buffer.from(data);
+  if (
+    buf.length <= MAGIC.length ||
+    !buf.subarray(0, MAGIC.length).equals(MAGIC)
+  ) {
+    return buf;
+  }
+  const idLen = buf[MAGIC.length];
+  const idStart = MAGIC.length + 1;
+  const keyID = buf.subarray(idStart, idStart + idLen).toString('utf8');
+  const key = loadKeys().find((k) => k.id === keyID);
+  if (!key) {
+    throw new Error(`encryption key ${keyID} not found`);
+  }
+
+  const rest = buf.subarray(idStart + idLen);
+  const dataKey = open(
+    Buffer.from(key.value, 'base64'),
+    rest.subarray(0, WRAPPED_KEY_SIZE)
+  );
+  return open(dataKey, rest.subarray(WRAPPED_KEY_SIZE));
+};